@@ -26,6 +26,36 @@ func SignBytes(chainID string, o Signable) []byte {
 	return buf.Bytes()
 }
 
+// Signables whose sign-bytes depend on the version implement VersionedSignable,
+// e.g. to cover fields added in that version.
+type VersionedSignable interface {
+	Signable
+	WriteSignBytesVersion(version int, chainID string, w io.Writer, n *int64, err *error)
+}
+
+// SignBytesVersion returns the bytes to sign of a Signable for a sign-bytes version.
+// Version 0 is SignBytes. Later versions wrap the sign-bytes with the version as
+// {"sign_version":N,"sign_bytes":...}, so a signature for one version never
+// verifies under another.
+func SignBytesVersion(version int, chainID string, o Signable) []byte {
+	if version == 0 {
+		return SignBytes(chainID, o)
+	}
+	buf, n, err := new(bytes.Buffer), new(int64), new(error)
+	binary.WriteTo([]byte(fmt.Sprintf(`{"sign_version":%v,"sign_bytes":`, version)), buf, n, err)
+	if vo, ok := o.(VersionedSignable); ok {
+		vo.WriteSignBytesVersion(version, chainID, buf, n, err)
+	} else {
+		o.WriteSignBytes(chainID, buf, n, err)
+	}
+	binary.WriteTo([]byte(`}`), buf, n, err)
+	if *err != nil {
+		// SOMETHING HAS GONE HORRIBLY WRONG
+		panic(err)
+	}
+	return buf.Bytes()
+}
+
 // HashSignBytes is a convenience method for getting the hash of the bytes of a signable
 func HashSignBytes(chainID string, o Signable) []byte {
 	return merkle.SimpleHashFromBinary(SignBytes(chainID, o))
@@ -56,6 +56,11 @@ func (privAccount *PrivAccount) Sign(chainID string, o Signable) Signature {
 	return privAccount.PrivKey.Sign(SignBytes(chainID, o))
 }
 
+// Signs the sign-bytes of o for a sign-bytes version, see SignBytesVersion.
+func (privAccount *PrivAccount) SignVersion(version int, chainID string, o Signable) Signature {
+	return privAccount.PrivKey.Sign(SignBytesVersion(version, chainID, o))
+}
+
 func (privAccount *PrivAccount) String() string {
 	return Fmt("PrivAccount{%X}", privAccount.Address)
 }
@@ -6,6 +6,8 @@ import (
 	"errors"
 	"io"
 	"reflect"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -34,8 +36,19 @@ type TypeInfo struct {
 type Options struct {
 	JSONName string // (JSON) Corresponding JSON field name. (override with `json=""`)
 	Varint   bool   // (Binary) Use length-prefixed encoding for (u)int*
+	Version  bool   // (Binary) The struct's version, see versionMarker
+	Since    int    // (Binary) Only written from this struct version on
 }
 
+// A struct may have a leading int field tagged `binary:"version"`.
+// Version 0 is not written, so adding the field to a struct doesn't
+// change its existing encoding. Other versions are written as
+// versionMarker then the varint version. Fields tagged
+// `binary:"since=N"` are only written from version N on.
+// The field after the version field must start with a varint length
+// or value, whose first byte is never versionMarker.
+const versionMarker = byte(0xFF)
+
 func getOptionsFromField(field reflect.StructField) (skip bool, opts Options) {
 	jsonName := field.Tag.Get("json")
 	if jsonName == "-" {
@@ -44,14 +57,23 @@ func getOptionsFromField(field reflect.StructField) (skip bool, opts Options) {
 	} else if jsonName == "" {
 		jsonName = field.Name
 	}
-	varint := false
-	binTag := field.Tag.Get("binary")
-	if binTag == "varint" { // TODO: extend
-		varint = true
-	}
 	opts = Options{
 		JSONName: jsonName,
-		Varint:   varint,
+	}
+	for _, binTag := range strings.Split(field.Tag.Get("binary"), ",") {
+		switch {
+		case binTag == "varint":
+			opts.Varint = true
+		case binTag == "version":
+			opts.Version = true
+		case strings.HasPrefix(binTag, "since="):
+			since, err := strconv.Atoi(strings.TrimPrefix(binTag, "since="))
+			if err != nil || since <= 0 {
+				// SANITY CHECK
+				panic(Fmt("Invalid binary tag %v on field %v", binTag, field.Name))
+			}
+			opts.Since = since
+		}
 	}
 	return
 }
@@ -154,6 +176,30 @@ func RegisterInterface(o interface{}, ctypes ...ConcreteType) *TypeInfo {
 	return typeInfo
 }
 
+// Adds a concrete type to an interface registered with RegisterInterface,
+// e.g. from another package. Like RegisterInterface, call it from an init
+// function, before anything of the interface is decoded.
+func RegisterConcreteType(o interface{}, ctype ConcreteType) {
+	it := GetTypeFromStructDeclaration(o)
+	typeInfo := GetTypeInfo(it)
+	if !typeInfo.IsRegisteredInterface {
+		// SANITY CHECK
+		panic(Fmt("Interface %v is not registered", it))
+	}
+	if ctype.Byte == 0x00 {
+		// SANITY CHECK
+		panic(Fmt("Byte of 0x00 is reserved for nil (%v)", ctype))
+	}
+	if typeInfo.ByteToType[ctype.Byte] != nil {
+		// SANITY CHECK
+		panic(Fmt("Duplicate Byte for type %v and %v", ctype, typeInfo.ByteToType[ctype.Byte]))
+	}
+	crt := reflect.TypeOf(ctype.O)
+	SetByteForType(ctype.Byte, crt)
+	typeInfo.ByteToType[ctype.Byte] = crt
+	typeInfo.TypeToByte[crt] = ctype.Byte
+}
+
 func MakeTypeInfo(rt reflect.Type) *TypeInfo {
 	info := &TypeInfo{Type: rt}
 
@@ -177,11 +223,35 @@ func MakeTypeInfo(rt reflect.Type) *TypeInfo {
 			})
 		}
 		info.Fields = structFields
+		checkVersionField(rt, structFields)
 	}
 
 	return info
 }
 
+// SANITY CHECK that a version field is the first field and is followed
+// by a field whose encoding can't start with versionMarker.
+func checkVersionField(rt reflect.Type, fields []StructFieldInfo) {
+	for i, field := range fields {
+		if !field.Version {
+			continue
+		}
+		if i != 0 || field.Type.Kind() != reflect.Int {
+			panic(Fmt("Version field of %v must be its first field, of type int", rt))
+		}
+		if len(fields) < 2 || fields[1].Since != 0 {
+			panic(Fmt("Version field of %v must be followed by an unversioned field", rt))
+		}
+		switch next := fields[1]; next.Type.Kind() {
+		case reflect.Int, reflect.Uint, reflect.String, reflect.Slice:
+		default:
+			if !next.Varint {
+				panic(Fmt("Field after the version field of %v must start with a varint", rt))
+			}
+		}
+	}
+}
+
 // Contract: Caller must ensure that rt is supported
 // (e.g. is recursively composed of supported native types, and structs and slices.)
 func readReflectBinary(rv reflect.Value, rt reflect.Type, opts Options, r io.Reader, n *int64, err *error) {
@@ -287,9 +357,32 @@ func readReflectBinary(rv reflect.Value, rt reflect.Type, opts Options, r io.Rea
 			log.Debug(Fmt("Read time: %v", t))
 			rv.Set(reflect.ValueOf(t))
 		} else {
+			version := 0
 			for _, fieldInfo := range typeInfo.Fields {
 				i, fieldType, opts := fieldInfo.unpack()
 				fieldRv := rv.Field(i)
+				if opts.Version {
+					marker := ReadByte(r, n, err)
+					if *err != nil {
+						return
+					}
+					if marker == versionMarker {
+						version = ReadVarint(r, n, err)
+						if *err == nil && version <= 0 {
+							// Version 0 must not be written.
+							*err = errors.New(Fmt("Invalid version %v for type %v", version, rt))
+							return
+						}
+						fieldRv.SetInt(int64(version))
+					} else {
+						// Version 0, the byte belongs to the next field.
+						r = NewPrefixedReader([]byte{marker}, r)
+					}
+					continue
+				}
+				if opts.Since > version {
+					continue
+				}
 				readReflectBinary(fieldRv, fieldType, opts, r, n, err)
 			}
 		}
@@ -456,9 +549,21 @@ func writeReflectBinary(rv reflect.Value, rt reflect.Type, opts Options, w io.Wr
 			// Special case: time.Time
 			WriteTime(rv.Interface().(time.Time), w, n, err)
 		} else {
+			version := 0
 			for _, fieldInfo := range typeInfo.Fields {
 				i, fieldType, opts := fieldInfo.unpack()
 				fieldRv := rv.Field(i)
+				if opts.Version {
+					version = int(fieldRv.Int())
+					if version != 0 {
+						WriteByte(versionMarker, w, n, err)
+						WriteVarint(version, w, n, err)
+					}
+					continue
+				}
+				if opts.Since > version {
+					continue
+				}
 				writeReflectBinary(fieldRv, fieldType, opts, w, n, err)
 			}
 		}
@@ -464,3 +464,69 @@ func TestBadAlloc(t *testing.T) {
 	res := ReadBinary(instance, b, n, err)
 	fmt.Println(res, *err)
 }
+
+//------------------------------------------------------------------------------
+
+type VersionedStruct struct {
+	Version int `binary:"version"`
+	Name    string
+	Extra   []byte `binary:"since=1"`
+	Count   int
+}
+
+type UnversionedStruct struct {
+	Name  string
+	Count int
+}
+
+func TestVersionedStruct(t *testing.T) {
+	// Version 0 is written like the struct without the versioned fields.
+	v0 := VersionedStruct{Name: "a", Extra: []byte("ignored"), Count: 3}
+	if !bytes.Equal(BinaryBytes(v0), BinaryBytes(UnversionedStruct{"a", 3})) {
+		t.Errorf("Expected version 0 to be written without version fields, got %X", BinaryBytes(v0))
+	}
+	n, err := new(int64), new(error)
+	bz := BinaryBytes(v0)
+	read := ReadBinary(VersionedStruct{}, bytes.NewReader(bz), n, err).(VersionedStruct)
+	if *err != nil {
+		t.Fatal(*err)
+	}
+	if read.Version != 0 || read.Name != "a" || read.Extra != nil || read.Count != 3 {
+		t.Errorf("Unexpected version 0 read: %v", read)
+	}
+
+	v1 := VersionedStruct{Version: 1, Name: "a", Extra: []byte("x"), Count: 3}
+	bz = BinaryBytes(v1)
+	if bz[0] != versionMarker {
+		t.Errorf("Expected version 1 to start with the version marker, got %X", bz)
+	}
+	n, err = new(int64), new(error)
+	read = ReadBinary(VersionedStruct{}, bytes.NewReader(bz), n, err).(VersionedStruct)
+	if *err != nil {
+		t.Fatal(*err)
+	}
+	if !reflect.DeepEqual(read, v1) {
+		t.Errorf("Expected %v, got %v", v1, read)
+	}
+
+	// Version 0 must not be written with the marker.
+	n, err = new(int64), new(error)
+	ReadBinary(VersionedStruct{}, bytes.NewReader(append([]byte{versionMarker, 0x00}, BinaryBytes(v0)...)), n, err)
+	if *err == nil {
+		t.Error("Expected an error reading an explicit version 0")
+	}
+}
+
+type BadVersionedStruct struct {
+	Name    string
+	Version int `binary:"version"`
+}
+
+func TestBadVersionField(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic for a version field that isn't first")
+		}
+	}()
+	BinaryBytes(BadVersionedStruct{})
+}
@@ -0,0 +1,158 @@
+package binary
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// TypeDesc is a machine-readable description of how a type is written
+// by WriteBinary. Types are referred to by their Go name, e.g. "*types.Part".
+type TypeDesc struct {
+	Name     string         `json:"name"`
+	Encoding string         `json:"encoding"`            // See encodingOf()
+	TypeByte string         `json:"type_byte,omitempty"` // Written before the value, e.g. "0x01"
+	Elem     string         `json:"elem,omitempty"`      // If pointer or list
+	Fields   []FieldDesc    `json:"fields,omitempty"`    // If struct, in encoding order
+	Concrete []ConcreteDesc `json:"concrete,omitempty"`  // If registered interface
+}
+
+type FieldDesc struct {
+	Name    string `json:"name"` // JSON name
+	Type    string `json:"type"`
+	Varint  bool   `json:"varint,omitempty"`
+	Version bool   `json:"version,omitempty"` // See versionMarker
+	Since   int    `json:"since,omitempty"`   // Only written from this version on
+}
+
+type ConcreteDesc struct {
+	TypeByte string `json:"type_byte"`
+	Type     string `json:"type"`
+}
+
+// Describes the types of roots and every type reachable from them,
+// including the concrete types of registered interfaces.
+// Roots may be values, or struct{X}{} declarations for interface types.
+// The result is sorted by name.
+func DescribeTypes(roots ...interface{}) []*TypeDesc {
+	descs := map[string]*TypeDesc{}
+	for _, root := range roots {
+		rt := reflect.TypeOf(root)
+		if rt.Kind() == reflect.Struct && rt.NumField() == 1 && rt.Field(0).Anonymous &&
+			rt.Field(0).Type.Kind() == reflect.Interface {
+			rt = rt.Field(0).Type
+		}
+		describeType(rt, descs)
+	}
+	names := make([]string, 0, len(descs))
+	for name := range descs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	result := make([]*TypeDesc, len(names))
+	for i, name := range names {
+		result[i] = descs[name]
+	}
+	return result
+}
+
+func describeType(rt reflect.Type, descs map[string]*TypeDesc) {
+	name := rt.String()
+	if descs[name] != nil {
+		return
+	}
+	typeInfo := GetTypeInfo(rt)
+	desc := &TypeDesc{
+		Name:     name,
+		Encoding: encodingOf(rt),
+	}
+	if typeInfo.Byte != 0x00 {
+		desc.TypeByte = fmt.Sprintf("0x%02X", typeInfo.Byte)
+	}
+	descs[name] = desc
+
+	switch rt.Kind() {
+	case reflect.Interface:
+		typeBytes := make([]int, 0, len(typeInfo.ByteToType))
+		for typeByte := range typeInfo.ByteToType {
+			typeBytes = append(typeBytes, int(typeByte))
+		}
+		sort.Ints(typeBytes)
+		for _, typeByte := range typeBytes {
+			crt := typeInfo.ByteToType[byte(typeByte)]
+			desc.Concrete = append(desc.Concrete, ConcreteDesc{
+				TypeByte: fmt.Sprintf("0x%02X", typeByte),
+				Type:     crt.String(),
+			})
+			describeType(crt, descs)
+		}
+	case reflect.Ptr:
+		desc.Elem = rt.Elem().String()
+		describeType(rt.Elem(), descs)
+	case reflect.Slice:
+		if rt.Elem().Kind() != reflect.Uint8 {
+			desc.Elem = rt.Elem().String()
+			describeType(rt.Elem(), descs)
+		}
+	case reflect.Struct:
+		if rt == timeType {
+			break
+		}
+		for _, fieldInfo := range typeInfo.Fields {
+			desc.Fields = append(desc.Fields, FieldDesc{
+				Name:    fieldInfo.JSONName,
+				Type:    fieldInfo.Type.String(),
+				Varint:  fieldInfo.Varint,
+				Version: fieldInfo.Version,
+				Since:   fieldInfo.Since,
+			})
+			describeType(fieldInfo.Type, descs)
+		}
+	}
+}
+
+// Mirrors the cases of writeReflectBinary():
+//
+//	interface: 0x00 if nil, else the concrete value with its type byte
+//	pointer:   0x00 if nil, else 0x01 (unless elem has a type byte) then elem
+//	list:      varint length then elems
+//	bytes:     varint length then bytes
+//	string:    varint length then bytes
+//	time:      int64 nanoseconds since epoch
+//	varint:    signed varint, uvarint: unsigned varint
+//	intN/uintN: fixed width big-endian, unless the field is varint
+//	bool:      0x00 or 0x01
+//
+// A struct's version field is omitted if 0, else written as 0xFF then
+// the varint version. Fields since a later version are omitted.
+func encodingOf(rt reflect.Type) string {
+	switch rt.Kind() {
+	case reflect.Interface:
+		if !GetTypeInfo(rt).IsRegisteredInterface {
+			return "unregistered interface"
+		}
+		return "interface"
+	case reflect.Ptr:
+		return "pointer"
+	case reflect.Slice:
+		if rt.Elem().Kind() == reflect.Uint8 {
+			return "bytes"
+		}
+		return "list"
+	case reflect.Struct:
+		if rt == timeType {
+			return "time"
+		}
+		return "struct"
+	case reflect.Int:
+		return "varint"
+	case reflect.Uint:
+		return "uvarint"
+	case reflect.String, reflect.Bool,
+		reflect.Int64, reflect.Int32, reflect.Int16, reflect.Int8,
+		reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8:
+		return rt.Kind().String()
+	default:
+		return "unsupported " + rt.Kind().String()
+	}
+}
@@ -0,0 +1,191 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/tendermint/tendermint/binary"
+	. "github.com/tendermint/tendermint/common"
+	dbm "github.com/tendermint/tendermint/db"
+	"github.com/tendermint/tendermint/types"
+)
+
+/*
+Outbox is a delivery log of committed blocks, for an external indexer.
+
+Each block saved to the BlockStore appends an OutboxEvent whose Offset is
+the block height. The indexer reads the events after its last
+acknowledged offset, and acks them once processed, which prunes them.
+Unacked events are delivered again, so an indexer that records its offset
+along with what it indexed processes each block exactly once.
+
+Events are derived from the BlockStore, so none are missed across crashes:
+NewOutbox appends the events of any blocks saved after the last event.
+*/
+type Outbox struct {
+	mtx    sync.Mutex
+	db     dbm.DB
+	height int // Offset of the last event
+	acked  int // Offset of the last ack
+}
+
+var (
+	ErrOutboxAckTooHigh = errors.New("Error outbox ack is past the last event")
+	ErrOutboxAckTooLow  = errors.New("Error outbox ack is before the last ack")
+)
+
+type OutboxEvent struct {
+	Offset    int              `json:"offset"` // The block height
+	BlockMeta *types.BlockMeta `json:"block_meta"`
+	TxIds     [][]byte         `json:"tx_ids"`
+}
+
+// A new outbox starts with the next block saved to store.
+func NewOutbox(db dbm.DB, store *BlockStore) *Outbox {
+	osjson := LoadOutboxStateJSON(db, store.Height())
+	ob := &Outbox{
+		db:     db,
+		height: osjson.Height,
+		acked:  osjson.Acked,
+	}
+	for ob.height > store.Height() {
+		ob.rollback(ob.height)
+	}
+	for height := ob.height + 1; height <= store.Height(); height++ {
+		log.Info("Appending missed block to outbox", "height", height)
+		ob.append(store.LoadBlock(height), store.LoadBlockMeta(height))
+	}
+	return ob
+}
+
+func (ob *Outbox) append(block *types.Block, meta *types.BlockMeta) {
+	ob.mtx.Lock()
+	defer ob.mtx.Unlock()
+	if block.Height != ob.height+1 {
+		// SANITY CHECK
+		panic(Fmt("Outbox can only append contiguous blocks. Wanted %v, got %v", ob.height+1, block.Height))
+	}
+	event := &OutboxEvent{
+		Offset:    block.Height,
+		BlockMeta: meta,
+		TxIds:     make([][]byte, len(block.Txs)),
+	}
+	for i, tx := range block.Txs {
+		event.TxIds[i] = types.TxId(block.ChainID, tx)
+	}
+	// The event goes first, so a crash midway leaves a key that is rewritten.
+	ob.db.Set(calcOutboxEventKey(block.Height), binary.BinaryBytes(event))
+	ob.height = block.Height
+	ob.saveState()
+}
+
+// Drops the event of a rolled back block, see BlockStore.RollbackBlock.
+func (ob *Outbox) rollback(height int) {
+	ob.mtx.Lock()
+	defer ob.mtx.Unlock()
+	if height != ob.height {
+		return
+	}
+	if height <= ob.acked {
+		log.Warn("Rolled back a block the outbox consumer already acked", "height", height)
+		ob.acked = height - 1
+	}
+	ob.height = height - 1
+	ob.saveState()
+	ob.db.Delete(calcOutboxEventKey(height))
+}
+
+// Returns up to limit events after the last ack, oldest first.
+func (ob *Outbox) Pending(limit int) []*OutboxEvent {
+	ob.mtx.Lock()
+	defer ob.mtx.Unlock()
+	events := []*OutboxEvent{}
+	for offset := ob.acked + 1; offset <= ob.height && len(events) < limit; offset++ {
+		var n int64
+		var err error
+		r := bytes.NewReader(ob.db.Get(calcOutboxEventKey(offset)))
+		event := binary.ReadBinary(&OutboxEvent{}, r, &n, &err).(*OutboxEvent)
+		if err != nil {
+			// SOMETHING HAS GONE HORRIBLY WRONG
+			panic(Fmt("Error reading outbox event: %v", err))
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// Acknowledges the events up to offset, which are then pruned.
+// Acking the last ack again is a no-op.
+func (ob *Outbox) Ack(offset int) error {
+	ob.mtx.Lock()
+	defer ob.mtx.Unlock()
+	if offset > ob.height {
+		return ErrOutboxAckTooHigh
+	}
+	if offset < ob.acked {
+		return ErrOutboxAckTooLow
+	}
+	acked := ob.acked
+	ob.acked = offset
+	ob.saveState()
+	ob.db.DeleteRange(calcOutboxEventKey(acked+1), calcOutboxEventKey(offset+1))
+	return nil
+}
+
+// Returns the offset of the last event and of the last ack.
+func (ob *Outbox) Offsets() (height int, acked int) {
+	ob.mtx.Lock()
+	defer ob.mtx.Unlock()
+	return ob.height, ob.acked
+}
+
+func (ob *Outbox) saveState() {
+	OutboxStateJSON{Height: ob.height, Acked: ob.acked}.Save(ob.db)
+}
+
+// Heights are fixed width big-endian, so that keys sort by height.
+func calcOutboxEventKey(height int) []byte {
+	key := make([]byte, 10)
+	copy(key, "E:")
+	PutUint64BE(key[2:], uint64(height))
+	return key
+}
+
+//-----------------------------------------------------------------------------
+
+var outboxKey = []byte("outbox")
+
+type OutboxStateJSON struct {
+	Height int
+	Acked  int
+}
+
+// Saved synchronously, so that an ack or event is never lost.
+func (osj OutboxStateJSON) Save(db dbm.DB) {
+	bytes, err := json.Marshal(osj)
+	if err != nil {
+		// SANITY CHECK
+		panic(Fmt("Could not marshal state bytes: %v", err))
+	}
+	db.SetSync(outboxKey, bytes)
+}
+
+// A new outbox starts at height.
+func LoadOutboxStateJSON(db dbm.DB, height int) OutboxStateJSON {
+	bytes := db.Get(outboxKey)
+	if bytes == nil {
+		return OutboxStateJSON{
+			Height: height,
+			Acked:  height,
+		}
+	}
+	osj := OutboxStateJSON{}
+	err := json.Unmarshal(bytes, &osj)
+	if err != nil {
+		// SOMETHING HAS GONE HORRIBLY WRONG
+		panic(Fmt("Could not unmarshal bytes: %X", bytes))
+	}
+	return osj
+}
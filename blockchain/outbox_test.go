@@ -0,0 +1,71 @@
+package blockchain
+
+import (
+	"testing"
+
+	_ "github.com/tendermint/tendermint/config/tendermint_test"
+	dbm "github.com/tendermint/tendermint/db"
+)
+
+func checkOutboxPending(t *testing.T, ob *Outbox, offsets ...int) {
+	events := ob.Pending(10)
+	if len(events) != len(offsets) {
+		t.Fatalf("Expected %v pending events, got %v", len(offsets), len(events))
+	}
+	for i, event := range events {
+		if event.Offset != offsets[i] || event.BlockMeta.Header.Height != offsets[i] {
+			t.Errorf("Expected event %v to have offset %v, got %v", i, offsets[i], event.Offset)
+		}
+	}
+}
+
+func TestOutbox(t *testing.T) {
+	storeDB, outboxDB := dbm.NewMemDB(), dbm.NewMemDB()
+	bs := NewBlockStore(storeDB)
+	ob := NewOutbox(outboxDB, bs)
+	bs.SetOutbox(ob)
+	for height := 1; height <= 3; height++ {
+		saveTestBlock(bs, height)
+	}
+	checkOutboxPending(t, ob, 1, 2, 3)
+
+	if err := ob.Ack(2); err != nil {
+		t.Fatal(err)
+	}
+	checkOutboxPending(t, ob, 3)
+	for height := 1; height <= 2; height++ {
+		if outboxDB.Get(calcOutboxEventKey(height)) != nil {
+			t.Errorf("Expected acked event %v to be pruned", height)
+		}
+	}
+	if outboxDB.Get(calcOutboxEventKey(3)) == nil {
+		t.Error("Expected the unacked event not to be pruned")
+	}
+	if err := ob.Ack(1); err != ErrOutboxAckTooLow {
+		t.Errorf("Expected ErrOutboxAckTooLow, got %v", err)
+	}
+	if err := ob.Ack(4); err != ErrOutboxAckTooHigh {
+		t.Errorf("Expected ErrOutboxAckTooHigh, got %v", err)
+	}
+
+	// Blocks saved without the outbox, e.g. before a crash, are appended on restart.
+	bs = NewBlockStore(storeDB)
+	saveTestBlock(bs, 4)
+	saveTestBlock(bs, 5)
+	ob = NewOutbox(outboxDB, bs)
+	bs.SetOutbox(ob)
+	checkOutboxPending(t, ob, 3, 4, 5)
+
+	// Rolled back blocks are dropped, and appended again when saved again.
+	bs.RollbackBlock()
+	checkOutboxPending(t, ob, 3, 4)
+	saveTestBlock(bs, 5)
+	checkOutboxPending(t, ob, 3, 4, 5)
+
+	// A new outbox starts with the next block.
+	ob = NewOutbox(dbm.NewMemDB(), bs)
+	if height, acked := ob.Offsets(); height != 5 || acked != 5 {
+		t.Errorf("Expected a new outbox to start at 5, got %v and %v", height, acked)
+	}
+	checkOutboxPending(t, ob)
+}
@@ -245,6 +245,18 @@ func (pool *BlockPool) SetPeerHeight(peerId string, height int) {
 	}
 }
 
+// Returns the highest height reported by a peer, or 0 if there are none.
+func (pool *BlockPool) MaxPeerHeight() int {
+	pool.peersMtx.Lock()
+	defer pool.peersMtx.Unlock()
+
+	maxHeight := 0
+	for _, peer := range pool.peers {
+		maxHeight = MaxInt(maxHeight, peer.height)
+	}
+	return maxHeight
+}
+
 func (pool *BlockPool) RemovePeer(peerId string) {
 	pool.peersMtx.Lock() // Lock
 	defer pool.peersMtx.Unlock()
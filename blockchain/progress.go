@@ -0,0 +1,83 @@
+package blockchain
+
+import (
+	"sync"
+	"time"
+
+	. "github.com/tendermint/tendermint/common"
+)
+
+const (
+	syncRateWindow = 30 * time.Second // Blocks/s is averaged over this long.
+)
+
+// SyncProgress reports how far fast sync has come, for progress bars.
+type SyncProgress struct {
+	Syncing         bool    `json:"syncing"`           // False once we switched to consensus.
+	Height          int     `json:"height"`            // Of our last synced block.
+	NetworkHeight   int     `json:"network_height"`    // Highest height reported by peers. Unverified.
+	BlocksPerSecond float64 `json:"blocks_per_second"` // Over the last syncRateWindow.
+	ETASeconds      float64 `json:"eta_seconds"`       // 0 if unknown or not syncing.
+}
+
+func (bcR *BlockchainReactor) GetSyncProgress() *SyncProgress {
+	height := bcR.store.Height()
+	progress := &SyncProgress{
+		Syncing:       bcR.pool.IsRunning(),
+		Height:        height,
+		NetworkHeight: MaxInt(height, bcR.pool.MaxPeerHeight()),
+	}
+	if progress.Syncing {
+		progress.BlocksPerSecond = bcR.syncRate.Rate(time.Now())
+		if progress.BlocksPerSecond > 0 {
+			progress.ETASeconds = float64(progress.NetworkHeight-height) / progress.BlocksPerSecond
+		}
+	}
+	return progress
+}
+
+//-------------------------------------
+
+// Measures the rate of synced blocks over the last syncRateWindow.
+type syncRateMeter struct {
+	mtx     sync.Mutex
+	samples []syncSample
+}
+
+type syncSample struct {
+	time   time.Time
+	height int
+}
+
+// Records that height was synced at now.
+func (m *syncRateMeter) Mark(height int, now time.Time) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.samples = append(m.samples, syncSample{time: now, height: height})
+	m.prune(now)
+}
+
+// Returns blocks per second, which decays to 0 when syncing stalls.
+func (m *syncRateMeter) Rate(now time.Time) float64 {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.prune(now)
+	if len(m.samples) < 2 {
+		return 0
+	}
+	first, last := m.samples[0], m.samples[len(m.samples)-1]
+	elapsed := now.Sub(first.time).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(last.height-first.height) / elapsed
+}
+
+// CONTRACT: m.mtx is held.
+func (m *syncRateMeter) prune(now time.Time) {
+	i := 0
+	for i < len(m.samples) && now.Sub(m.samples[i].time) > syncRateWindow {
+		i++
+	}
+	m.samples = m.samples[i:]
+}
@@ -0,0 +1,28 @@
+package blockchain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSyncRateMeter(t *testing.T) {
+	m := &syncRateMeter{}
+	start := time.Now()
+	if rate := m.Rate(start); rate != 0 {
+		t.Errorf("Expected no rate without samples, got %v", rate)
+	}
+	for i := 0; i <= 10; i++ {
+		m.Mark(100+i, start.Add(time.Duration(i)*time.Second))
+	}
+	if rate := m.Rate(start.Add(10 * time.Second)); rate != 1 {
+		t.Errorf("Expected 1 block/s, got %v", rate)
+	}
+	// Stalled for a while: the rate decays.
+	if rate := m.Rate(start.Add(20 * time.Second)); rate != 0.5 {
+		t.Errorf("Expected 0.5 blocks/s, got %v", rate)
+	}
+	// Stalled for longer than the window.
+	if rate := m.Rate(start.Add(10*time.Second + syncRateWindow + time.Second)); rate != 0 {
+		t.Errorf("Expected 0 blocks/s, got %v", rate)
+	}
+}
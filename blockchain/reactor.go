@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
-	"sync/atomic"
 	"time"
 
 	"github.com/tendermint/tendermint/binary"
@@ -39,7 +38,8 @@ type consensusReactor interface {
 
 // BlockchainReactor handles long-term catchup syncing.
 type BlockchainReactor struct {
-	sw         *p2p.Switch
+	*p2p.BaseReactor
+
 	state      *sm.State
 	store      *BlockStore
 	pool       *BlockPool
@@ -47,8 +47,9 @@ type BlockchainReactor struct {
 	requestsCh chan BlockRequest
 	timeoutsCh chan string
 	lastBlock  *types.Block
-	quit       chan struct{}
-	running    uint32
+	poolDone   chan struct{} // closed when poolRoutine exits
+	syncRate   *syncRateMeter
+	txIndex    *TxIndex // nil unless set
 
 	evsw events.Fireable
 }
@@ -74,31 +75,31 @@ func NewBlockchainReactor(state *sm.State, store *BlockStore, sync bool) *Blockc
 		sync:       sync,
 		requestsCh: requestsCh,
 		timeoutsCh: timeoutsCh,
-		quit:       make(chan struct{}),
-		running:    uint32(0),
+		poolDone:   make(chan struct{}),
+		syncRate:   &syncRateMeter{},
 	}
+	bcR.BaseReactor = p2p.NewBaseReactor(log, "BlockchainReactor", bcR)
 	return bcR
 }
 
 // Implements Reactor
-func (bcR *BlockchainReactor) Start(sw *p2p.Switch) {
-	if atomic.CompareAndSwapUint32(&bcR.running, 0, 1) {
-		log.Info("Starting BlockchainReactor")
-		bcR.sw = sw
-		if bcR.sync {
-			bcR.pool.Start()
-			go bcR.poolRoutine()
-		}
+func (bcR *BlockchainReactor) OnStart() error {
+	bcR.BaseReactor.OnStart()
+	if bcR.sync {
+		bcR.pool.Start()
+		go bcR.poolRoutine()
+	} else {
+		close(bcR.poolDone)
 	}
+	return nil
 }
 
 // Implements Reactor
-func (bcR *BlockchainReactor) Stop() {
-	if atomic.CompareAndSwapUint32(&bcR.running, 1, 0) {
-		log.Info("Stopping BlockchainReactor")
-		close(bcR.quit)
-		bcR.pool.Stop()
-	}
+// Waits for poolRoutine so that a block is never half-saved.
+func (bcR *BlockchainReactor) OnStop() {
+	bcR.BaseReactor.OnStop()
+	bcR.pool.Stop()
+	<-bcR.poolDone
 }
 
 // Implements Reactor
@@ -149,6 +150,11 @@ func (bcR *BlockchainReactor) Receive(chId byte, src *p2p.Peer, msgBytes []byte)
 		}
 	case *bcBlockResponseMessage:
 		// Got a block.
+		if err := validateBlockResponse(msg.Block); err != nil {
+			log.Warn("Invalid block response", "peer", src, "error", err)
+			bcR.Switch.StopPeerForError(src, err)
+			return
+		}
 		bcR.pool.AddBlock(msg.Block, src.Key)
 	case *bcStatusRequestMessage:
 		// Send peer our state.
@@ -164,10 +170,35 @@ func (bcR *BlockchainReactor) Receive(chId byte, src *p2p.Peer, msgBytes []byte)
 	}
 }
 
+// Stateless sanity checks on a block received from a peer,
+// before it is handed to the pool.
+// The full check happens in state.ExecBlock.
+func validateBlockResponse(block *types.Block) error {
+	if block == nil || block.Header == nil || block.Data == nil || block.LastValidation == nil {
+		return errors.New("Incomplete block")
+	}
+	if block.Height <= 0 {
+		return errors.New("Invalid block height")
+	}
+	if block.NumTxs != len(block.Data.Txs) {
+		return errors.New("Wrong Block.Header.NumTxs")
+	}
+	if err := block.LastBlockParts.ValidateBasic(); err != nil {
+		return err
+	}
+	if block.Height != 1 {
+		if err := block.LastValidation.ValidateBasic(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Handle messages from the poolReactor telling the reactor what to do.
 // NOTE: Don't sleep in the FOR_LOOP or otherwise slow it down!
 // (Except for the SYNC_LOOP, which is the primary purpose and must be synchronous.)
 func (bcR *BlockchainReactor) poolRoutine() {
+	defer close(bcR.poolDone)
 
 	trySyncTicker := time.NewTicker(trySyncIntervalMS * time.Millisecond)
 	statusUpdateTicker := time.NewTicker(statusUpdateIntervalSeconds * time.Second)
@@ -177,7 +208,7 @@ FOR_LOOP:
 	for {
 		select {
 		case request := <-bcR.requestsCh: // chan BlockRequest
-			peer := bcR.sw.Peers().Get(request.PeerId)
+			peer := bcR.Switch.Peers().Get(request.PeerId)
 			if peer == nil {
 				// We can't assign the request.
 				continue FOR_LOOP
@@ -191,9 +222,9 @@ FOR_LOOP:
 			}
 		case peerId := <-bcR.timeoutsCh: // chan string
 			// Peer timed out.
-			peer := bcR.sw.Peers().Get(peerId)
+			peer := bcR.Switch.Peers().Get(peerId)
 			if peer != nil {
-				bcR.sw.StopPeerForError(peer, errors.New("BlockchainReactor Timeout"))
+				bcR.Switch.StopPeerForError(peer, errors.New("BlockchainReactor Timeout"))
 			}
 		case _ = <-statusUpdateTicker.C:
 			// ask for status updates
@@ -201,7 +232,7 @@ FOR_LOOP:
 		case _ = <-switchToConsensusTicker.C:
 			// not thread safe access for numUnassigned and numPending but should be fine
 			// TODO make threadsafe and use exposed functions
-			outbound, inbound, _ := bcR.sw.NumPeers()
+			outbound, inbound, _ := bcR.Switch.NumPeers()
 			log.Debug("Consensus ticker", "numUnassigned", bcR.pool.numUnassigned, "numPending", bcR.pool.numPending,
 				"total", len(bcR.pool.requests), "outbound", outbound, "inbound", inbound)
 			// NOTE: this condition is very strict right now. may need to weaken
@@ -214,7 +245,7 @@ FOR_LOOP:
 				log.Info("Time to switch to consensus reactor!", "height", bcR.pool.height)
 				bcR.pool.Stop()
 
-				conR := bcR.sw.Reactor("CONSENSUS").(consensusReactor)
+				conR := bcR.Switch.Reactor("CONSENSUS").(consensusReactor)
 				conR.SwitchToConsensus(bcR.state)
 
 				break FOR_LOOP
@@ -223,6 +254,9 @@ FOR_LOOP:
 			// This loop can be slow as long as it's doing syncing work.
 		SYNC_LOOP:
 			for i := 0; i < 10; i++ {
+				if !bcR.IsRunning() {
+					break FOR_LOOP
+				}
 				// See if there are any blocks to sync.
 				first, second := bcR.pool.PeekTwoBlocks()
 				//log.Debug("TrySync peeked", "first", first, "second", second)
@@ -230,7 +264,7 @@ FOR_LOOP:
 					// We need both to sync the first block.
 					break SYNC_LOOP
 				}
-				firstParts := first.MakePartSet()
+				firstParts := first.MakePartSetFor(second.LastBlockParts)
 				firstPartsHeader := firstParts.Header()
 				// Finally, verify the first block using the second's validation.
 				err := bcR.state.BondedValidators.VerifyValidation(
@@ -248,25 +282,34 @@ FOR_LOOP:
 					}
 					bcR.store.SaveBlock(first, firstParts, second.LastValidation)
 					bcR.state.Save()
+					if bcR.txIndex != nil {
+						bcR.txIndex.IndexBlock(first, bcR.state.LastBlockGasUsed)
+					}
+					bcR.syncRate.Mark(first.Height, time.Now())
 				}
 			}
 			continue FOR_LOOP
-		case <-bcR.quit:
+		case <-bcR.Quit:
 			break FOR_LOOP
 		}
 	}
 }
 
 func (bcR *BlockchainReactor) BroadcastStatusResponse() error {
-	bcR.sw.Broadcast(BlockchainChannel, &bcStatusResponseMessage{bcR.store.Height()})
+	bcR.Switch.Broadcast(BlockchainChannel, &bcStatusResponseMessage{bcR.store.Height()})
 	return nil
 }
 
 func (bcR *BlockchainReactor) BroadcastStatusRequest() error {
-	bcR.sw.Broadcast(BlockchainChannel, &bcStatusRequestMessage{bcR.store.Height()})
+	bcR.Switch.Broadcast(BlockchainChannel, &bcStatusRequestMessage{bcR.store.Height()})
 	return nil
 }
 
+// Synced blocks are indexed in txIndex. Must be called before Start.
+func (bcR *BlockchainReactor) SetTxIndex(txIndex *TxIndex) {
+	bcR.txIndex = txIndex
+}
+
 // implements events.Eventable
 func (bcR *BlockchainReactor) SetFireable(evsw events.Fireable) {
 	bcR.evsw = evsw
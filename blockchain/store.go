@@ -24,18 +24,31 @@ Currently the precommit signatures are duplicated in the Block parts as
 well as the Validation.  In the future this may change, perhaps by moving
 the Validation data outside the Block.
 
+Blocks may be moved to a second, cold DB once they fall out of
+a window of recent heights. See NewTieredBlockStore.
+
+Saved blocks may also be appended to an Outbox. See SetOutbox.
+
 Panics indicate probable corruption in the data
 */
 type BlockStore struct {
 	height int
 	db     dbm.DB
+
+	// Cold storage, nil if not tiered.
+	cold       dbm.DB
+	hotHeights int // Recent heights kept in db.
+	coldHeight int // Heights up to here have been moved to cold.
+
+	outbox *Outbox // nil unless set
 }
 
 func NewBlockStore(db dbm.DB) *BlockStore {
 	bsjson := LoadBlockStoreStateJSON(db)
 	return &BlockStore{
-		height: bsjson.Height,
-		db:     db,
+		height:     bsjson.Height,
+		db:         db,
+		coldHeight: bsjson.ColdHeight,
 	}
 }
 
@@ -44,8 +57,23 @@ func (bs *BlockStore) Height() int {
 	return bs.height
 }
 
+// Saved blocks are appended to outbox, which should be
+// created with NewOutbox(db, bs).
+func (bs *BlockStore) SetOutbox(outbox *Outbox) {
+	bs.outbox = outbox
+}
+
+// Returns nil unless an outbox is set.
+func (bs *BlockStore) Outbox() *Outbox {
+	return bs.outbox
+}
+
+// Reads fall through to cold storage.
 func (bs *BlockStore) GetReader(key []byte) io.Reader {
 	bytez := bs.db.Get(key)
+	if bytez == nil && bs.cold != nil {
+		bytez = bs.cold.Get(key)
+	}
 	if bytez == nil {
 		return nil
 	}
@@ -65,7 +93,7 @@ func (bs *BlockStore) LoadBlock(height int) *types.Block {
 		panic(Fmt("Error reading block meta: %v", err))
 	}
 	bytez := []byte{}
-	for i := 0; i < meta.PartsHeader.Total; i++ {
+	for i := 0; i < meta.PartsHeader.DataTotal(); i++ {
 		part := bs.LoadBlockPart(height, i)
 		bytez = append(bytez, part.Bytes...)
 	}
@@ -175,10 +203,40 @@ func (bs *BlockStore) SaveBlock(block *types.Block, blockParts *types.PartSet, s
 	bs.db.Set(calcSeenValidationKey(height), seenValidationBytes)
 
 	// Save new BlockStoreStateJSON descriptor
-	BlockStoreStateJSON{Height: height}.Save(bs.db)
+	BlockStoreStateJSON{Height: height, ColdHeight: bs.coldHeight}.Save(bs.db)
 
 	// Done!
 	bs.height = height
+
+	if bs.outbox != nil {
+		bs.outbox.append(block, meta)
+	}
+	bs.moveToCold()
+}
+
+// Deletes the block at the top height, so that it can be saved again.
+// The descriptor is saved first, so a crash midway only leaves unused keys.
+func (bs *BlockStore) RollbackBlock() {
+	height := bs.height
+	if height == 0 {
+		// SANITY CHECK
+		panic(Fmt("BlockStore has no block to roll back"))
+	}
+	meta := bs.LoadBlockMeta(height)
+
+	bs.coldHeight = MinInt(bs.coldHeight, height-1)
+	BlockStoreStateJSON{Height: height - 1, ColdHeight: bs.coldHeight}.Save(bs.db)
+	bs.height = height - 1
+	if bs.outbox != nil {
+		bs.outbox.rollback(height)
+	}
+
+	for _, key := range blockKeys(height, meta.PartsHeader.Total) {
+		bs.db.Delete(key)
+		if bs.cold != nil {
+			bs.cold.Delete(key)
+		}
+	}
 }
 
 func (bs *BlockStore) saveBlockPart(height int, index int, part *types.Part) {
@@ -209,12 +267,24 @@ func calcSeenValidationKey(height int) []byte {
 	return []byte(fmt.Sprintf("SV:%v", height))
 }
 
+// All the keys saved by SaveBlock for the block at height.
+func blockKeys(height int, numParts int) [][]byte {
+	keys := [][]byte{calcBlockMetaKey(height)}
+	for i := 0; i < numParts; i++ {
+		keys = append(keys, calcBlockPartKey(height, i))
+	}
+	keys = append(keys, calcBlockValidationKey(height-1))
+	keys = append(keys, calcSeenValidationKey(height))
+	return keys
+}
+
 //-----------------------------------------------------------------------------
 
 var blockStoreKey = []byte("blockStore")
 
 type BlockStoreStateJSON struct {
-	Height int
+	Height     int
+	ColdHeight int // 0 unless tiered.
 }
 
 func (bsj BlockStoreStateJSON) Save(db dbm.DB) {
@@ -0,0 +1,62 @@
+package blockchain
+
+import (
+	"github.com/tendermint/tendermint/binary"
+	. "github.com/tendermint/tendermint/common"
+	dbm "github.com/tendermint/tendermint/db"
+)
+
+// Blocks moved to cold storage per SaveBlock, so that enabling cold
+// storage on a node with a long history doesn't stall consensus.
+const maxColdMovesPerSave = 10
+
+// Like NewBlockStore, but blocks more than hotHeights below the top
+// are moved from hot to cold, e.g. a DB on a slower, cheaper disk.
+// Reads fall through to cold, so callers needn't know where a block is.
+func NewTieredBlockStore(hot, cold dbm.DB, hotHeights int) *BlockStore {
+	if hotHeights < 1 {
+		// SANITY CHECK
+		// The top block must stay hot for RollbackBlock and consensus.
+		panic(Fmt("BlockStore hotHeights must be at least 1, got %v", hotHeights))
+	}
+	bs := NewBlockStore(hot)
+	bs.cold = cold
+	bs.hotHeights = hotHeights
+	return bs
+}
+
+// Heights up to ColdHeight() are in cold storage.
+func (bs *BlockStore) ColdHeight() int {
+	return bs.coldHeight
+}
+
+// Moves the oldest hot blocks that fell out of the hot window to cold.
+// Each block is written to cold before the descriptor records the move,
+// and deleted from hot after, so a crash at worst leaves a copy in both.
+func (bs *BlockStore) moveToCold() {
+	if bs.cold == nil {
+		return
+	}
+	target := MinInt(bs.height-bs.hotHeights, bs.coldHeight+maxColdMovesPerSave)
+	for height := bs.coldHeight + 1; height <= target; height++ {
+		meta := bs.LoadBlockMeta(height)
+		if meta == nil {
+			// SANITY CHECK
+			panic(Fmt("BlockStore is missing block meta at height %v", height))
+		}
+		keys := blockKeys(height, meta.PartsHeader.Total)
+		// The meta goes last and synced, so that the whole block is in cold.
+		for _, key := range keys[1:] {
+			if value := bs.db.Get(key); value != nil {
+				bs.cold.Set(key, value)
+			}
+		}
+		bs.cold.SetSync(keys[0], binary.BinaryBytes(meta))
+		bs.coldHeight = height
+		BlockStoreStateJSON{Height: bs.height, ColdHeight: bs.coldHeight}.Save(bs.db)
+		for _, key := range keys {
+			bs.db.Delete(key)
+		}
+		log.Debug("Moved block to cold storage", "height", height)
+	}
+}
@@ -0,0 +1,73 @@
+package blockchain
+
+import (
+	"testing"
+	"time"
+
+	_ "github.com/tendermint/tendermint/config/tendermint_test"
+	dbm "github.com/tendermint/tendermint/db"
+	"github.com/tendermint/tendermint/types"
+)
+
+func saveTestBlock(bs *BlockStore, height int) {
+	block := &types.Block{
+		Header: &types.Header{
+			ChainID:   "tiered_test",
+			Height:    height,
+			Time:      time.Unix(int64(height), 0),
+			StateHash: []byte("state_hash"),
+		},
+		Data:           &types.Data{},
+		LastValidation: &types.Validation{},
+	}
+	bs.SaveBlock(block, block.MakePartSet(), &types.Validation{})
+}
+
+func TestTieredBlockStore(t *testing.T) {
+	hot, cold := dbm.NewMemDB(), dbm.NewMemDB()
+	bs := NewTieredBlockStore(hot, cold, 5)
+	for height := 1; height <= 8; height++ {
+		saveTestBlock(bs, height)
+	}
+	if bs.ColdHeight() != 3 {
+		t.Fatalf("Expected heights up to 3 in cold storage, got %v", bs.ColdHeight())
+	}
+	if hot.Get(calcBlockMetaKey(3)) != nil || cold.Get(calcBlockMetaKey(3)) == nil {
+		t.Errorf("Expected block 3 to be moved to cold storage")
+	}
+	if hot.Get(calcBlockMetaKey(4)) == nil || cold.Get(calcBlockMetaKey(4)) != nil {
+		t.Errorf("Expected block 4 to stay hot")
+	}
+	// Reads fall through to cold.
+	for height := 1; height <= 8; height++ {
+		if block := bs.LoadBlock(height); block == nil || block.Height != height {
+			t.Errorf("Failed to load block %v", height)
+		}
+		if bs.LoadSeenValidation(height) == nil {
+			t.Errorf("Failed to load seen validation %v", height)
+		}
+	}
+
+	// Reopening keeps the cold height.
+	bs = NewTieredBlockStore(hot, cold, 5)
+	if bs.Height() != 8 || bs.ColdHeight() != 3 {
+		t.Errorf("Expected height 8 and cold height 3, got %v and %v", bs.Height(), bs.ColdHeight())
+	}
+
+	// Enabling cold storage with a long hot history catches up gradually.
+	hot2, cold2 := dbm.NewMemDB(), dbm.NewMemDB()
+	bs = NewBlockStore(hot2)
+	for height := 1; height <= 30; height++ {
+		saveTestBlock(bs, height)
+	}
+	bs = NewTieredBlockStore(hot2, cold2, 5)
+	saveTestBlock(bs, 31)
+	if bs.ColdHeight() != maxColdMovesPerSave {
+		t.Errorf("Expected %v blocks moved, got %v", maxColdMovesPerSave, bs.ColdHeight())
+	}
+	saveTestBlock(bs, 32)
+	saveTestBlock(bs, 33)
+	if bs.ColdHeight() != 28 {
+		t.Errorf("Expected heights up to 28 in cold storage, got %v", bs.ColdHeight())
+	}
+}
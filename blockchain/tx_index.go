@@ -0,0 +1,79 @@
+package blockchain
+
+import (
+	"bytes"
+
+	"github.com/tendermint/tendermint/binary"
+	. "github.com/tendermint/tendermint/common"
+	dbm "github.com/tendermint/tendermint/db"
+	"github.com/tendermint/tendermint/types"
+)
+
+/*
+TxIndex finds committed txs by id (see types.TxId), with the gas they used.
+
+Consensus and the BlockchainReactor index each block after executing it,
+since the gas used is known only then (see State.LastBlockGasUsed). Blocks
+applied from a state diff are not executed, so their txs are not indexed.
+*/
+type TxIndex struct {
+	db dbm.DB
+}
+
+type TxIndexEntry struct {
+	Height  int   `json:"height"`
+	Index   int   `json:"index"` // In block.Txs
+	GasUsed int64 `json:"gas_used"`
+}
+
+func NewTxIndex(db dbm.DB) *TxIndex {
+	return &TxIndex{db: db}
+}
+
+// gasUsed[i] is the gas used by block.Txs[i].
+func (ti *TxIndex) IndexBlock(block *types.Block, gasUsed []int64) {
+	if len(gasUsed) != len(block.Txs) {
+		// SANITY CHECK
+		panic(Fmt("TxIndex needs the gas used by each tx. Wanted %v, got %v", len(block.Txs), len(gasUsed)))
+	}
+	for i, tx := range block.Txs {
+		entry := &TxIndexEntry{
+			Height:  block.Height,
+			Index:   i,
+			GasUsed: gasUsed[i],
+		}
+		ti.db.Set(calcTxIndexKey(types.TxId(block.ChainID, tx)), binary.BinaryBytes(entry))
+	}
+}
+
+// Removes the txs of a rolled back block, see ConsensusState.Rollback.
+// Entries of another block, e.g. one the same tx is indexed again in,
+// are kept.
+func (ti *TxIndex) UnindexBlock(block *types.Block) {
+	for _, tx := range block.Txs {
+		txId := types.TxId(block.ChainID, tx)
+		if entry := ti.Get(txId); entry != nil && entry.Height == block.Height {
+			ti.db.Delete(calcTxIndexKey(txId))
+		}
+	}
+}
+
+// Returns nil if no tx with txId was indexed.
+func (ti *TxIndex) Get(txId []byte) *TxIndexEntry {
+	bz := ti.db.Get(calcTxIndexKey(txId))
+	if bz == nil {
+		return nil
+	}
+	var n int64
+	var err error
+	entry := binary.ReadBinary(&TxIndexEntry{}, bytes.NewReader(bz), &n, &err).(*TxIndexEntry)
+	if err != nil {
+		// SOMETHING HAS GONE HORRIBLY WRONG
+		panic(Fmt("Error reading tx index entry: %v", err))
+	}
+	return entry
+}
+
+func calcTxIndexKey(txId []byte) []byte {
+	return []byte(Fmt("TX:%X", txId))
+}
@@ -0,0 +1,51 @@
+package blockchain
+
+import (
+	"testing"
+
+	_ "github.com/tendermint/tendermint/config/tendermint_test"
+	dbm "github.com/tendermint/tendermint/db"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestTxIndex(t *testing.T) {
+	ti := NewTxIndex(dbm.NewMemDB())
+	txs := []types.Tx{
+		&types.SendTx{Inputs: []*types.TxInput{{Address: []byte("input_a"), Amount: 1}}},
+		&types.SendTx{Inputs: []*types.TxInput{{Address: []byte("input_b"), Amount: 2}}},
+	}
+	block := &types.Block{
+		Header: &types.Header{ChainID: "tx_index_test", Height: 3},
+		Data:   &types.Data{Txs: txs},
+	}
+	ti.IndexBlock(block, []int64{100, 200})
+
+	for i, tx := range txs {
+		entry := ti.Get(types.TxId(block.ChainID, tx))
+		if entry == nil {
+			t.Fatalf("Expected tx %v to be indexed", i)
+		}
+		if entry.Height != 3 || entry.Index != i || entry.GasUsed != int64(100*(i+1)) {
+			t.Errorf("Unexpected entry for tx %v: %v", i, entry)
+		}
+	}
+	if ti.Get([]byte("unknown")) != nil {
+		t.Error("Expected no entry for an unknown tx")
+	}
+
+	// Rolling back block 4 keeps the entries of block 3.
+	block4 := &types.Block{
+		Header: &types.Header{ChainID: "tx_index_test", Height: 4},
+		Data:   &types.Data{Txs: txs[:1]},
+	}
+	ti.UnindexBlock(block4)
+	if ti.Get(types.TxId(block.ChainID, txs[0])) == nil {
+		t.Error("Expected the entry of another block to be kept")
+	}
+	ti.UnindexBlock(block)
+	for i, tx := range txs {
+		if ti.Get(types.TxId(block.ChainID, tx)) != nil {
+			t.Errorf("Expected tx %v to be unindexed", i)
+		}
+	}
+}
@@ -161,7 +161,8 @@ func (brk *Barak) OpenListener(addr string) (net.Listener, error) {
 	mux.HandleFunc("/register", RegisterHandler)
 	// TODO: mux.HandleFunc("/upload", UploadFile)
 	rpcserver.RegisterRPCFuncs(mux, Routes)
-	listener, err := rpcserver.StartHTTPServer(addr, mux)
+	handler := rpcserver.NewMiddleware(mux, rpcserver.MiddlewareConfig{CORSOrigins: []string{"*"}})
+	listener, err := rpcserver.StartHTTPServer(addr, handler)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	. "github.com/tendermint/tendermint/common"
+	"github.com/tendermint/tendermint/consensus"
+	dbm "github.com/tendermint/tendermint/db"
+	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+)
+
+// Signs a precommit for a block agreed upon out-of-band,
+// for when the validators cannot reach each other over p2p.
+// Import the resulting files with the unsafe/import_precommits RPC,
+// which is served with rpc_unsafe.
+// Refuses while a node runs on the data directory, because signing
+// rewrites the PrivValidator file that the node also signs with.
+func export_precommit() {
+
+	privValidatorFile := config.GetString("priv_validator_file")
+	if _, err := os.Stat(privValidatorFile); err != nil {
+		Exit(Fmt("Could not find PrivValidator file %v", privValidatorFile))
+	}
+
+	if config.GetString("db_backend") != dbm.DBBackendMemDB {
+		lock, err := dbm.LockDir(config.GetString("db_dir"))
+		if err != nil {
+			Exit(Fmt("Refusing to sign while a node may be running: %v", err))
+		}
+		defer lock.Unlock()
+	}
+
+	privValidator := sm.LoadPrivValidator(privValidatorFile)
+
+	// The upgrade schedule decides the vote version.
+	state := sm.LoadState(dbm.GetDB("state"))
+	if state == nil {
+		state = sm.MakeGenesisStateFromFile(dbm.NewMemDB(), config.GetString("genesis_file"))
+	}
+
+	height := getInt("Enter height: ")
+	round := getInt("Enter round: ")
+	blockHash := getByteSliceFromHex("Enter block hash: ")
+	partsTotal := getInt("Enter block parts total: ")
+	partsHash := getByteSliceFromHex("Enter block parts hash: ")
+	filePath := getString("Enter output file: ")
+
+	precommit, err := consensus.SignOfflinePrecommit(privValidator, config.GetString("chain_id"),
+		state.BlockVersionAt(height), height, round, blockHash, types.PartSetHeader{Total: partsTotal, Hash: partsHash})
+	if err != nil {
+		fmt.Printf("Could not sign precommit: %v\n", err)
+		return
+	}
+	if err := consensus.WriteOfflinePrecommitFile(filePath, []*consensus.OfflinePrecommit{precommit}); err != nil {
+		fmt.Printf("Could not write precommit file: %v\n", err)
+		return
+	}
+	fmt.Printf("Exported precommit %v to %v\n", precommit.Vote, filePath)
+}
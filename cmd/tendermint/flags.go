@@ -16,6 +16,7 @@ func parseFlags(config cfg.Config, args []string) {
 		fastSync  bool
 		rpcLaddr  string
 		logLevel  string
+		unlock    bool
 	)
 
 	// Declare flags
@@ -27,6 +28,7 @@ func parseFlags(config cfg.Config, args []string) {
 	flags.BoolVar(&fastSync, "fast_sync", config.GetBool("fast_sync"), "Fast blockchain syncing")
 	flags.StringVar(&rpcLaddr, "rpc_laddr", config.GetString("rpc_laddr"), "RPC listen address. Port required")
 	flags.StringVar(&logLevel, "log_level", config.GetString("log_level"), "Log level")
+	flags.BoolVar(&unlock, "force-unlock", config.GetBool("force_unlock"), "Remove the data directory lock of another node process, once it is known to be gone")
 	flags.Parse(args)
 	if printHelp {
 		flags.PrintDefaults()
@@ -40,4 +42,5 @@ func parseFlags(config cfg.Config, args []string) {
 	config.Set("fast_sync", fastSync)
 	config.Set("rpc_laddr", rpcLaddr)
 	config.Set("log_level", logLevel)
+	config.Set("force_unlock", unlock)
 }
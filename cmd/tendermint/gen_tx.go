@@ -113,7 +113,15 @@ func gen_tx() {
 		Exit(Fmt("Invalid PrivKey. Error: %v", err))
 	}
 
+	// Get the sign-bytes version of the next block
+	var signVersion int
+	if state != nil {
+		signVersion = state.TxSignVersion()
+	} else {
+		signVersion = getInt("Enter block version of the next block: ")
+	}
+
 	// Sign
-	tx.Inputs[0].Signature = srcPrivKey.Sign(account.SignBytes(config.GetString("chain_id"), tx))
+	tx.Inputs[0].Signature = srcPrivKey.Sign(account.SignBytesVersion(signVersion, config.GetString("chain_id"), tx))
 	fmt.Printf("Signed tx: %X\n", binary.BinaryBytes(tx))
 }
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	. "github.com/tendermint/tendermint/common"
+	sm "github.com/tendermint/tendermint/state"
+)
+
+func genesis(args []string) {
+	if len(args) == 0 || args[0] != "migrate" {
+		Exit("Usage: tendermint genesis migrate")
+	}
+	genesis_migrate()
+}
+
+// Upgrades the genesis file to the current version.
+// The original is kept alongside as <genesis_file>.bak.
+func genesis_migrate() {
+	genDocFile := config.GetString("genesis_file")
+	jsonBlob, err := ioutil.ReadFile(genDocFile)
+	if err != nil {
+		Exit(Fmt("Couldn't read GenesisDoc file: %v", err))
+	}
+	genDoc, err := sm.ParseGenesisDoc(jsonBlob)
+	if err != nil {
+		Exit(Fmt("Couldn't read GenesisDoc: %v", err))
+	}
+	fromVersion := genDoc.Version
+	migrated, err := sm.MigrateGenesisDoc(genDoc)
+	if err != nil {
+		Exit(Fmt("Couldn't migrate GenesisDoc: %v\n"+
+			"Set it in the genesis file by hand, to the same value as every other node of the chain.", err))
+	}
+	if !migrated {
+		fmt.Printf("Genesis file %v is already at version %v\n", genDocFile, fromVersion)
+		return
+	}
+	if err := genDoc.ValidateBasic(); err != nil {
+		Exit(Fmt("Migrated GenesisDoc is invalid: %v", err))
+	}
+	newJSONBlob, err := sm.GenesisDocToJSON(genDoc)
+	if err != nil {
+		Exit(Fmt("Couldn't write GenesisDoc: %v", err))
+	}
+	if err := WriteFileAtomic(genDocFile+".bak", jsonBlob); err != nil {
+		Exit(Fmt("Couldn't back up genesis file: %v", err))
+	}
+	if err := WriteFileAtomic(genDocFile, newJSONBlob); err != nil {
+		Exit(Fmt("Couldn't write genesis file: %v", err))
+	}
+	fmt.Printf("Migrated genesis file %v from version %v to %v\n", genDocFile, fromVersion, genDoc.Version)
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	acm "github.com/tendermint/tendermint/account"
+	. "github.com/tendermint/tendermint/common"
+	sm "github.com/tendermint/tendermint/state"
+)
+
+const (
+	localnetChainID  = "localnet_test"
+	localnetBasePort = 46656
+	localnetPidFile  = "localnet.pid"
+	localnetAmount   = int64(1000000000000)
+)
+
+// Keys are derived from the chain ID and node index, and the genesis
+// time is fixed, so that init always generates the same localnet.
+var localnetGenesisTime = time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func localnet(args []string) {
+	usage := `Usage:
+    tendermint localnet init <dir> <num_nodes> [base_port]
+    tendermint localnet start <dir>
+    tendermint localnet stop <dir>`
+	if len(args) < 2 {
+		Exit(usage)
+	}
+	dir := args[1]
+	switch args[0] {
+	case "init":
+		if len(args) < 3 {
+			Exit(usage)
+		}
+		numNodes, err := strconv.Atoi(args[2])
+		if err != nil || numNodes < 1 {
+			Exit(Fmt("Invalid num_nodes %v", args[2]))
+		}
+		basePort := localnetBasePort
+		if len(args) > 3 {
+			basePort, err = strconv.Atoi(args[3])
+			if err != nil {
+				Exit(Fmt("Invalid base_port %v", args[3]))
+			}
+		}
+		localnet_init(dir, numNodes, basePort)
+	case "start":
+		localnet_start(dir)
+	case "stop":
+		localnet_stop(dir)
+	default:
+		Exit(usage)
+	}
+}
+
+// Node i listens on basePort+10*i for peers and on the next port for RPC.
+func localnetPorts(basePort, i int) (nodePort, rpcPort int) {
+	return basePort + 10*i, basePort + 10*i + 1
+}
+
+func localnetNodeDir(dir string, i int) string {
+	return path.Join(dir, Fmt("node%v", i))
+}
+
+// Writes the config, genesis and priv_validator files of each node
+// under dir/node<i>. All nodes are validators and seed each other.
+func localnet_init(dir string, numNodes int, basePort int) {
+	if FileExists(localnetNodeDir(dir, 0)) {
+		Exit(Fmt("%v already has a localnet", dir))
+	}
+
+	privVals := make([]*sm.PrivValidator, numNodes)
+	genDoc := &sm.GenesisDoc{
+		Version:     sm.GenesisVersion,
+		GenesisTime: localnetGenesisTime,
+		ChainID:     localnetChainID,
+	}
+	for i := 0; i < numNodes; i++ {
+		privAcc := acm.GenPrivAccountFromSecret([]byte(Fmt("%v/%v", localnetChainID, i)))
+		privVals[i] = &sm.PrivValidator{
+			Address: privAcc.Address,
+			PubKey:  privAcc.PubKey.(acm.PubKeyEd25519),
+			PrivKey: privAcc.PrivKey.(acm.PrivKeyEd25519),
+		}
+		genDoc.Accounts = append(genDoc.Accounts, sm.GenesisAccount{
+			Address: privAcc.Address,
+			Amount:  localnetAmount,
+		})
+		genDoc.Validators = append(genDoc.Validators, sm.GenesisValidator{
+			PubKey: privVals[i].PubKey,
+			Amount: localnetAmount,
+			UnbondTo: []sm.GenesisAccount{
+				sm.GenesisAccount{Address: privAcc.Address, Amount: localnetAmount},
+			},
+		})
+	}
+	if err := genDoc.ValidateBasic(); err != nil {
+		Exit(Fmt("Invalid localnet GenesisDoc: %v", err))
+	}
+	genDocBytes, err := sm.GenesisDocToJSON(genDoc)
+	if err != nil {
+		Exit(Fmt("Couldn't write GenesisDoc: %v", err))
+	}
+
+	for i := 0; i < numNodes; i++ {
+		nodeDir := localnetNodeDir(dir, i)
+		if err := EnsureDir(nodeDir); err != nil {
+			Exit(Fmt("Couldn't create %v: %v", nodeDir, err))
+		}
+		seeds := []string{}
+		for j := 0; j < numNodes; j++ {
+			if j != i {
+				nodePort, _ := localnetPorts(basePort, j)
+				seeds = append(seeds, Fmt("127.0.0.1:%v", nodePort))
+			}
+		}
+		nodePort, rpcPort := localnetPorts(basePort, i)
+		MustWriteFile(path.Join(nodeDir, "config.toml"), []byte(Fmt(localnetConfigTmpl,
+			i, nodePort, strings.Join(seeds, ","), rpcPort)))
+		MustWriteFile(path.Join(nodeDir, "genesis.json"), genDocBytes)
+		privVals[i].SetFile(path.Join(nodeDir, "priv_validator.json"))
+		privVals[i].Save()
+	}
+	fmt.Printf("Initialized a localnet of %v nodes in %v\n", numNodes, dir)
+}
+
+var localnetConfigTmpl = `# This is a TOML config file.
+# Generated by tendermint localnet init.
+
+moniker = "node%v"
+node_laddr = "127.0.0.1:%v"
+seeds = "%v"
+allow_same_ip_peers = true
+fast_sync = false
+db_backend = "leveldb"
+log_level = "info"
+rpc_laddr = "127.0.0.1:%v"
+`
+
+// Runs each node of the localnet as a subprocess, prefixing their
+// output with the node name, until interrupted or stopped.
+func localnet_start(dir string) {
+	pidFile := path.Join(dir, localnetPidFile)
+	if FileExists(pidFile) {
+		Exit(Fmt("The localnet in %v seems to be running. If not, remove %v", dir, pidFile))
+	}
+
+	var outputMtx sync.Mutex
+	var wg sync.WaitGroup
+	cmds := []*exec.Cmd{}
+	for i := 0; FileExists(localnetNodeDir(dir, i)); i++ {
+		name := Fmt("node%v", i)
+		cmd := exec.Command(os.Args[0], "node")
+		cmd.Env = append(os.Environ(), "TMROOT="+localnetNodeDir(dir, i))
+		output, err := cmd.StdoutPipe()
+		if err != nil {
+			Exit(Fmt("Couldn't start %v: %v", name, err))
+		}
+		cmd.Stderr = cmd.Stdout
+		if err := cmd.Start(); err != nil {
+			Exit(Fmt("Couldn't start %v: %v", name, err))
+		}
+		cmds = append(cmds, cmd)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			scanner := bufio.NewScanner(output)
+			for scanner.Scan() {
+				outputMtx.Lock()
+				fmt.Printf("%v| %v\n", name, scanner.Text())
+				outputMtx.Unlock()
+			}
+			err := cmd.Wait()
+			log.Warn("Localnet node exited", "node", name, "error", err)
+		}()
+	}
+	if len(cmds) == 0 {
+		Exit(Fmt("No localnet in %v. See tendermint localnet init", dir))
+	}
+	MustWriteFile(pidFile, []byte(strconv.Itoa(os.Getpid())))
+
+	stop := func() {
+		for _, cmd := range cmds {
+			cmd.Process.Signal(syscall.SIGTERM)
+		}
+		os.Remove(pidFile)
+	}
+	go func() {
+		wg.Wait()
+		log.Warn("All localnet nodes exited")
+		os.Remove(pidFile)
+		os.Exit(1)
+	}()
+	TrapSignal(stop)
+}
+
+// Signals the localnet started in dir to stop its nodes.
+func localnet_stop(dir string) {
+	pidFile := path.Join(dir, localnetPidFile)
+	pidBytes, err := ioutil.ReadFile(pidFile)
+	if err != nil {
+		Exit(Fmt("The localnet in %v is not running: %v", dir, err))
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(pidBytes)))
+	if err != nil {
+		Exit(Fmt("Invalid pid file %v", pidFile))
+	}
+	if err := syscall.Kill(pid, syscall.SIGTERM); err != nil {
+		os.Remove(pidFile)
+		Exit(Fmt("Couldn't stop the localnet in %v: %v", dir, err))
+	}
+	fmt.Printf("Stopped the localnet in %v\n", dir)
+}
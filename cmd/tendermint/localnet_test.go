@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/tendermint/tendermint/Godeps/_workspace/src/github.com/naoina/toml"
+	. "github.com/tendermint/tendermint/common"
+	sm "github.com/tendermint/tendermint/state"
+)
+
+func TestLocalnetInit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "localnet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	numNodes, basePort := 3, 40000
+	localnet_init(dir, numNodes, basePort)
+
+	genDocBytes, err := ioutil.ReadFile(path.Join(localnetNodeDir(dir, 0), "genesis.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	genDoc, err := sm.ParseGenesisDoc(genDocBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if genDoc.ChainID != localnetChainID || len(genDoc.Validators) != numNodes {
+		t.Fatalf("Expected %v validators of %v, got %v of %v", numNodes, localnetChainID, len(genDoc.Validators), genDoc.ChainID)
+	}
+
+	for i := 0; i < numNodes; i++ {
+		nodeDir := localnetNodeDir(dir, i)
+
+		// Every node has the same genesis.
+		nodeGenDocBytes, err := ioutil.ReadFile(path.Join(nodeDir, "genesis.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(nodeGenDocBytes, genDocBytes) {
+			t.Errorf("Expected node%v to have the genesis of node0", i)
+		}
+
+		// Each node is the validator at its index.
+		privVal := sm.LoadPrivValidator(path.Join(nodeDir, "priv_validator.json"))
+		if !bytes.Equal(privVal.PubKey, genDoc.Validators[i].PubKey) {
+			t.Errorf("Expected node%v to be validator %v", i, i)
+		}
+
+		// Each node listens on its own port and seeds all the others.
+		configBytes, err := ioutil.ReadFile(path.Join(nodeDir, "config.toml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		nodeConfig := map[string]interface{}{}
+		if err := toml.Unmarshal(configBytes, &nodeConfig); err != nil {
+			t.Fatalf("Invalid config of node%v: %v", i, err)
+		}
+		nodePort, rpcPort := localnetPorts(basePort, i)
+		if nodeConfig["moniker"] != Fmt("node%v", i) ||
+			nodeConfig["node_laddr"] != Fmt("127.0.0.1:%v", nodePort) ||
+			nodeConfig["rpc_laddr"] != Fmt("127.0.0.1:%v", rpcPort) ||
+			nodeConfig["allow_same_ip_peers"] != true {
+			t.Errorf("Unexpected config of node%v: %v", i, nodeConfig)
+		}
+		seeds := strings.Split(nodeConfig["seeds"].(string), ",")
+		if len(seeds) != numNodes-1 {
+			t.Errorf("Expected node%v to have %v seeds, got %v", i, numNodes-1, seeds)
+		}
+		for _, seed := range seeds {
+			if seed == nodeConfig["node_laddr"] {
+				t.Errorf("Expected node%v not to seed itself", i)
+			}
+		}
+	}
+}
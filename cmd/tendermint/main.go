@@ -20,7 +20,11 @@ Commands:
     gen_account   Generate new account keypair
     gen_validator Generate new validator keypair
     gen_tx        Generate new transaction
+    export_precommit Sign a precommit for offline vote collection
+    genesis migrate  Upgrade the genesis file to the current version
+    localnet      Init, start or stop a local testnet of validator nodes
     probe_upnp    Test UPnP functionality
+    wire_spec     Print the binary wire format of consensus types
     version       Show version info
 `)
 		return
@@ -40,8 +44,16 @@ Commands:
 		gen_validator()
 	case "gen_tx":
 		gen_tx()
+	case "export_precommit":
+		export_precommit()
+	case "genesis":
+		genesis(args[1:])
+	case "localnet":
+		localnet(args[1:])
 	case "probe_upnp":
 		probe_upnp()
+	case "wire_spec":
+		wire_spec()
 	case "unsafe_reset_priv_validator":
 		reset_priv_validator()
 	case "version":
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/tendermint/tendermint/wire"
+)
+
+func wire_spec() {
+	fmt.Println(string(wire.SpecJSON()))
+}
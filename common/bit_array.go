@@ -186,6 +186,15 @@ func (bA *BitArray) IsFull() bool {
 }
 
 func (bA *BitArray) PickRandom() (int, bool) {
+	return bA.pickRandom(rand.Intn)
+}
+
+// Like PickRandom, but draws from r, e.g. for reproducible picks.
+func (bA *BitArray) PickRandomFrom(r *rand.Rand) (int, bool) {
+	return bA.pickRandom(r.Intn)
+}
+
+func (bA *BitArray) pickRandom(intn func(int) int) (int, bool) {
 	if bA == nil {
 		return 0, false
 	}
@@ -196,12 +205,12 @@ func (bA *BitArray) PickRandom() (int, bool) {
 	if length == 0 {
 		return 0, false
 	}
-	randElemStart := rand.Intn(length)
+	randElemStart := intn(length)
 	for i := 0; i < length; i++ {
 		elemIdx := ((i + randElemStart) % length)
 		if elemIdx < length-1 {
 			if bA.Elems[elemIdx] > 0 {
-				randBitStart := rand.Intn(64)
+				randBitStart := intn(64)
 				for j := 0; j < 64; j++ {
 					bitIdx := ((j + randBitStart) % 64)
 					if (bA.Elems[elemIdx] & (uint64(1) << uint(bitIdx))) > 0 {
@@ -216,7 +225,7 @@ func (bA *BitArray) PickRandom() (int, bool) {
 			if elemBits == 0 {
 				elemBits = 64
 			}
-			randBitStart := rand.Intn(elemBits)
+			randBitStart := intn(elemBits)
 			for j := 0; j < elemBits; j++ {
 				bitIdx := ((j + randBitStart) % elemBits)
 				if (bA.Elems[elemIdx] & (uint64(1) << uint(bitIdx))) > 0 {
@@ -1,6 +1,7 @@
 package common
 
 import (
+	"math/rand"
 	"testing"
 )
 
@@ -118,3 +119,21 @@ func TestPickRandom(t *testing.T) {
 		}
 	}
 }
+
+func TestPickRandomFrom(t *testing.T) {
+	bA := NewBitArray(123)
+	for idx := 0; idx < 123; idx += 3 {
+		bA.SetIndex(idx, true)
+	}
+	r1, r2 := rand.New(rand.NewSource(1)), rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		index1, ok1 := bA.PickRandomFrom(r1)
+		index2, ok2 := bA.PickRandomFrom(r2)
+		if !ok1 || !ok2 || index1%3 != 0 {
+			t.Fatalf("Expected to pick a set element, got %v %v", index1, ok1)
+		}
+		if index1 != index2 {
+			t.Fatalf("Expected the same picks from the same seed, got %v and %v", index1, index2)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package common
+
+import (
+	"sync/atomic"
+
+	"github.com/tendermint/tendermint/Godeps/_workspace/src/github.com/tendermint/log15"
+)
+
+/*
+Service is a long-lived component that can be started once and stopped once.
+
+A concrete service embeds *BaseService and overrides OnStart/OnStop:
+
+	type FooService struct {
+		*BaseService
+		// ...
+	}
+
+	func NewFooService() *FooService {
+		fs := &FooService{}
+		fs.BaseService = NewBaseService(log, "FooService", fs)
+		return fs
+	}
+
+	func (fs *FooService) OnStart() error {
+		fs.BaseService.OnStart() // Always call the overridden method.
+		// initialize private fields
+		// start subroutines, etc.
+		return nil
+	}
+
+	func (fs *FooService) OnStop() {
+		fs.BaseService.OnStop() // Always call the overridden method.
+		// close/destroy private fields
+		// stop subroutines, etc.
+	}
+
+Start() and Stop() are idempotent and safe to call from any goroutine.
+Subroutines should select on Quit, which is closed before OnStop() is called,
+so OnStop() may wait for them to exit.
+*/
+type Service interface {
+	Start() (bool, error)
+	OnStart() error
+
+	Stop() bool
+	OnStop()
+
+	IsRunning() bool
+
+	String() string
+}
+
+type BaseService struct {
+	log     log15.Logger
+	name    string
+	started uint32 // atomic
+	stopped uint32 // atomic
+	Quit    chan struct{}
+
+	// The "subclass" of BaseService
+	impl Service
+}
+
+func NewBaseService(log log15.Logger, name string, impl Service) *BaseService {
+	return &BaseService{
+		log:  log,
+		name: name,
+		Quit: make(chan struct{}),
+		impl: impl,
+	}
+}
+
+// Implements Service
+// Returns false if the service was already started or stopped.
+func (bs *BaseService) Start() (bool, error) {
+	if atomic.CompareAndSwapUint32(&bs.started, 0, 1) {
+		if atomic.LoadUint32(&bs.stopped) == 1 {
+			if bs.log != nil {
+				bs.log.Warn(Fmt("Not starting %v -- already stopped", bs.name))
+			}
+			return false, nil
+		}
+		if bs.log != nil {
+			bs.log.Info(Fmt("Starting %v", bs.name))
+		}
+		err := bs.impl.OnStart()
+		return true, err
+	}
+	return false, nil
+}
+
+// Implements Service
+func (bs *BaseService) OnStart() error { return nil }
+
+// Implements Service
+// Returns false if the service was already stopped.
+// OnStop() is only called if the service was started.
+func (bs *BaseService) Stop() bool {
+	if atomic.CompareAndSwapUint32(&bs.stopped, 0, 1) {
+		close(bs.Quit)
+		if atomic.LoadUint32(&bs.started) == 0 {
+			return true
+		}
+		if bs.log != nil {
+			bs.log.Info(Fmt("Stopping %v", bs.name))
+		}
+		bs.impl.OnStop()
+		return true
+	}
+	return false
+}
+
+// Implements Service
+func (bs *BaseService) OnStop() {}
+
+// Implements Service
+func (bs *BaseService) IsRunning() bool {
+	return atomic.LoadUint32(&bs.started) == 1 && atomic.LoadUint32(&bs.stopped) == 0
+}
+
+// True once Stop() has been called, even if OnStop() has not yet returned.
+func (bs *BaseService) IsStopped() bool {
+	return atomic.LoadUint32(&bs.stopped) == 1
+}
+
+// Blocks until Stop() is called.
+func (bs *BaseService) Wait() {
+	<-bs.Quit
+}
+
+// Implements Service
+func (bs *BaseService) String() string {
+	return bs.name
+}
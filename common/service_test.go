@@ -0,0 +1,99 @@
+package common
+
+import (
+	"testing"
+	"time"
+)
+
+type testService struct {
+	*BaseService
+	started    int
+	stopped    int
+	quitOnStop bool // whether Quit was closed when OnStop ran
+}
+
+func newTestService() *testService {
+	ts := &testService{}
+	ts.BaseService = NewBaseService(nil, "TestService", ts)
+	return ts
+}
+
+func (ts *testService) OnStart() error {
+	ts.BaseService.OnStart()
+	ts.started++
+	return nil
+}
+
+func (ts *testService) OnStop() {
+	ts.BaseService.OnStop()
+	ts.stopped++
+	select {
+	case <-ts.Quit:
+		ts.quitOnStop = true
+	default:
+	}
+}
+
+func TestServiceLifecycle(t *testing.T) {
+	ts := newTestService()
+	if ts.IsRunning() {
+		t.Fatal("Expected service not to be running before Start()")
+	}
+	if ok, err := ts.Start(); !ok || err != nil {
+		t.Fatalf("Expected first Start() to succeed, got %v %v", ok, err)
+	}
+	if ok, _ := ts.Start(); ok {
+		t.Fatal("Expected second Start() to be a no-op")
+	}
+	if !ts.IsRunning() {
+		t.Fatal("Expected service to be running")
+	}
+
+	waited := make(chan struct{})
+	go func() {
+		ts.Wait()
+		close(waited)
+	}()
+	select {
+	case <-waited:
+		t.Fatal("Wait() returned before Stop()")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	if !ts.Stop() {
+		t.Fatal("Expected first Stop() to succeed")
+	}
+	if ts.Stop() {
+		t.Fatal("Expected second Stop() to be a no-op")
+	}
+	select {
+	case <-waited:
+	case <-time.After(time.Second):
+		t.Fatal("Wait() did not return after Stop()")
+	}
+	if ts.IsRunning() || !ts.IsStopped() {
+		t.Fatal("Expected service to be stopped")
+	}
+	if ts.started != 1 || ts.stopped != 1 {
+		t.Errorf("Expected OnStart/OnStop once each, got %v/%v", ts.started, ts.stopped)
+	}
+	if !ts.quitOnStop {
+		t.Error("Expected Quit to be closed before OnStop()")
+	}
+	if ok, _ := ts.Start(); ok {
+		t.Error("Expected Start() after Stop() to be a no-op")
+	}
+}
+
+func TestServiceStopWithoutStart(t *testing.T) {
+	ts := newTestService()
+	if !ts.Stop() {
+		t.Fatal("Expected Stop() to succeed")
+	}
+	if ts.stopped != 0 {
+		t.Error("Expected OnStop() not to be called for a service that never started")
+	}
+	if ok, _ := ts.Start(); ok || ts.started != 0 {
+		t.Error("Expected Start() after Stop() to be a no-op")
+	}
+}
@@ -41,11 +41,27 @@ type Config interface {
 
 type MapConfig map[string]interface{}
 
-func (cfg MapConfig) Get(key string) interface{}    { return cfg[key] }
-func (cfg MapConfig) GetBool(key string) bool       { return cfg[key].(bool) }
-func (cfg MapConfig) GetFloat64(key string) float64 { return cfg[key].(float64) }
-func (cfg MapConfig) GetInt(key string) int         { return cfg[key].(int) }
-func (cfg MapConfig) GetString(key string) string   { return cfg[key].(string) }
+func (cfg MapConfig) Get(key string) interface{} { return cfg[key] }
+func (cfg MapConfig) GetBool(key string) bool    { return cfg[key].(bool) }
+func (cfg MapConfig) GetFloat64(key string) float64 {
+	// TOML integers are decoded as int64.
+	switch v := cfg[key].(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	default:
+		return v.(float64)
+	}
+}
+func (cfg MapConfig) GetInt(key string) int {
+	// TOML integers are decoded as int64.
+	if v, ok := cfg[key].(int64); ok {
+		return int(v)
+	}
+	return cfg[key].(int)
+}
+func (cfg MapConfig) GetString(key string) string { return cfg[key].(string) }
 func (cfg MapConfig) GetStringMap(key string) map[string]interface{} {
 	return cfg[key].(map[string]interface{})
 }
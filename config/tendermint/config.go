@@ -63,13 +63,47 @@ func GetConfig(rootDir string) cfg.Config {
 	mapConfig.SetDefault("moniker", "anonymous")
 	mapConfig.SetDefault("node_laddr", "0.0.0.0:46656")
 	// mapConfig.SetDefault("seeds", "goldenalchemist.chaintest.net:46656")
+	mapConfig.SetDefault("allow_same_ip_peers", false) // tell peers apart by port too, e.g. for a localnet
+	mapConfig.SetDefault("min_signing_peers", 0)       // don't sign votes with fewer peers, e.g. on a minority partition. 0 disables.
+	mapConfig.SetDefault("signing_sentries", "")       // comma separated peer keys (IPs) that must be connected to sign votes
+	mapConfig.SetDefault("alert_missed_blocks", 0)     // alert when the validator misses this many commits in a row, e.g. to fail over. 0 disables.
 	mapConfig.SetDefault("fast_sync", true)
+	mapConfig.SetDefault("fast_restart_peer", "") // RPC address of a trusted node to fetch a state diff from on start, instead of syncing a few missed blocks
 	mapConfig.SetDefault("addrbook_file", rootDir+"/addrbook.json")
 	mapConfig.SetDefault("priv_validator_file", rootDir+"/priv_validator.json")
 	mapConfig.SetDefault("db_backend", "leveldb")
 	mapConfig.SetDefault("db_dir", rootDir+"/data")
+	mapConfig.SetDefault("force_unlock", false)             // remove the data directory locks on start, see db/lock.go. Usually set with --force-unlock.
+	mapConfig.SetDefault("block_store_cold_dir", "")        // if set, older blocks are moved to a blockstore here, e.g. on a slower disk
+	mapConfig.SetDefault("block_store_hot_heights", 100000) // recent heights kept in db_dir when block_store_cold_dir is set
+	mapConfig.SetDefault("outbox", false)                   // log committed blocks for an external indexer, see the outbox_events RPC
+	mapConfig.SetDefault("tx_index", true)                  // index committed txs with the gas they used, see the get_tx RPC
 	mapConfig.SetDefault("log_level", "info")
+	mapConfig.SetDefault("log_module_levels", "")  // e.g. "consensus:info,p2p:warn". Overrides log_level per module.
+	mapConfig.SetDefault("log_format", "terminal") // terminal, json or logfmt
 	mapConfig.SetDefault("rpc_laddr", "0.0.0.0:46657")
+	mapConfig.SetDefault("mempool_audit_size", 0)        // recent mempool admission decisions kept for the mempool_audit RPC. 0 disables.
+	mapConfig.SetDefault("mempool_max_batch_txs", 1000)  // most txs in one broadcast_txs request or peer message. Peers that send more are stopped.
+	mapConfig.SetDefault("vote_broadcast_redundancy", 2) // peers we push our own votes to immediately. 0 leaves them to gossip.
+	mapConfig.SetDefault("block_part_parity_ratio", 0.0) // erasure code proposals with this many parity parts per data part, from block version 1. 0 disables.
+	mapConfig.SetDefault("rpc_rate_limit", 20.0)         // requests per second per IP. 0 disables.
+	mapConfig.SetDefault("rpc_rate_burst", 40)
+	mapConfig.SetDefault("rpc_max_body_bytes", 1048576) // 1MB
+	mapConfig.SetDefault("rpc_cors_origins", "*")       // comma separated
+	mapConfig.SetDefault("rpc_auth_token", "")          // protects write & unsafe endpoints
+	mapConfig.SetDefault("rpc_auth_user", "")
+	mapConfig.SetDefault("rpc_auth_password", "")
+	mapConfig.SetDefault("rpc_tls_cert_file", "") // serve RPC over TLS if set, with rpc_tls_key_file
+	mapConfig.SetDefault("rpc_tls_key_file", "")
+	mapConfig.SetDefault("rpc_admin_client_fingerprints", "") // comma separated SHA-256 client cert fingerprints for unsafe endpoints
+	mapConfig.SetDefault("rpc_unsafe", false)                 // enables the operator routes, e.g. unsafe_rollback
+	mapConfig.SetDefault("faucet_file", "")                   // PrivAccount JSON of a funded key. Test chains only.
+	mapConfig.SetDefault("faucet_max_amount", 1000000)        // per send
+	mapConfig.SetDefault("faucet_interval", 3600)             // seconds between sends to an address
+	mapConfig.SetDefault("faucet_max_sends", 100)             // per faucet_interval, across addresses. 0 disables.
+	mapConfig.SetDefault("chaos_vote_delay", 0)               // ms to delay our votes by, up to. Test chains only, see node/chaos.go. 0 disables.
+	mapConfig.SetDefault("chaos_mempool_drop_percent", 0.0)   // of incoming mempool messages to drop. Test chains only.
+	mapConfig.SetDefault("chaos_peer_restart_interval", 0)    // seconds between restarting a random peer. Test chains only.
 	return mapConfig
 }
 
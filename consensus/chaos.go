@@ -0,0 +1,29 @@
+package consensus
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+// Delays adding and sending each of our votes by up to delay, to exercise
+// the timeouts of the other validators. Test chains only, see
+// types.IsTestChainID. 0 disables.
+func (cs *ConsensusState) SetChaosVoteDelay(delay time.Duration) {
+	cs.mtx.Lock()
+	defer cs.mtx.Unlock()
+	cs.chaosVoteDelay = delay
+}
+
+// Adds our signed vote after a random delay, by when we may have moved on,
+// like a slow validator.
+func (cs *ConsensusState) addOwnVoteLater(vote *types.Vote) {
+	delay := time.Duration(rand.Int63n(int64(cs.chaosVoteDelay)))
+	log.Debug("Chaos: delaying own vote", "vote", vote, "delay", delay)
+	cs.clock.AfterFunc(delay, func() {
+		cs.mtx.Lock()
+		defer cs.mtx.Unlock()
+		cs.addOwnVote(vote)
+	})
+}
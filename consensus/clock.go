@@ -0,0 +1,31 @@
+package consensus
+
+import (
+	"time"
+)
+
+// Tells the time and runs functions after a delay, for the timeouts and
+// timestamps of ConsensusState. A Simulation replaces it with its own,
+// so that consensus runs in simulated time.
+type Clock interface {
+	Now() time.Time
+	// Calls f after d, in another goroutine or later in the same one.
+	AfterFunc(d time.Duration, f func())
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) AfterFunc(d time.Duration, f func()) {
+	time.AfterFunc(d, f)
+}
+
+// Must be called before Start.
+func (cs *ConsensusState) SetClock(clock Clock) {
+	cs.mtx.Lock()
+	defer cs.mtx.Unlock()
+	cs.clock = clock
+	// As set by updateToState, on the new clock.
+	cs.StartTime = clock.Now().Add(timeoutCommit)
+}
@@ -5,6 +5,7 @@ import (
 	"sync"
 
 	. "github.com/tendermint/tendermint/common"
+	"github.com/tendermint/tendermint/events"
 	sm "github.com/tendermint/tendermint/state"
 	"github.com/tendermint/tendermint/types"
 )
@@ -25,6 +26,9 @@ but which round is not known in advance, so when a peer
 provides a precommit for a round greater than mtx.round,
 we create a new entry in roundVoteSets but also remember the
 peer to prevent abuse.
+
+If more than one block gets +1/3 prevotes in a round,
+a VoteDivergence event is fired once for that round.
 */
 type HeightVoteSet struct {
 	height int
@@ -34,6 +38,8 @@ type HeightVoteSet struct {
 	round             int                  // max tracked round
 	roundVoteSets     map[int]RoundVoteSet // keys: [0...round]
 	peerCatchupRounds map[string]int       // keys: peer.Key; values: round
+	divergedRounds    map[int]bool         // rounds with a VoteDivergence
+	evsw              events.Fireable
 }
 
 func NewHeightVoteSet(height int, valSet *sm.ValidatorSet) *HeightVoteSet {
@@ -42,6 +48,7 @@ func NewHeightVoteSet(height int, valSet *sm.ValidatorSet) *HeightVoteSet {
 		valSet:            valSet,
 		roundVoteSets:     make(map[int]RoundVoteSet),
 		peerCatchupRounds: make(map[string]int),
+		divergedRounds:    make(map[int]bool),
 	}
 	hvs.addRound(0)
 	hvs.round = 0
@@ -105,9 +112,42 @@ func (hvs *HeightVoteSet) AddByAddress(address []byte, vote *types.Vote, peerKey
 		return
 	}
 	added, index, err = voteSet.AddByAddress(address, vote)
+	if added && vote.Type == types.VoteTypePrevote && len(vote.BlockHash) > 0 {
+		hvs.checkDivergence(voteSet)
+	}
 	return
 }
 
+// CONTRACT: hvs.mtx is held.
+func (hvs *HeightVoteSet) checkDivergence(prevotes *VoteSet) {
+	if hvs.divergedRounds[prevotes.Round()] {
+		return
+	}
+	blocks := prevotes.OneThirdBlocks()
+	if len(blocks) < 2 {
+		return
+	}
+	hvs.divergedRounds[prevotes.Round()] = true
+	msg := types.EventMsgVoteDivergence{
+		Height:     hvs.height,
+		Round:      prevotes.Round(),
+		Type:       types.VoteTypePrevote,
+		Blocks:     blocks,
+		TotalPower: hvs.valSet.TotalVotingPower(),
+	}
+	log.Warn("Prevotes diverged", "height", msg.Height, "round", msg.Round, "blocks", blocks)
+	if hvs.evsw != nil {
+		go hvs.evsw.FireEvent(types.EventStringVoteDivergence(), msg)
+	}
+}
+
+// implements events.Eventable
+func (hvs *HeightVoteSet) SetFireable(evsw events.Fireable) {
+	hvs.mtx.Lock()
+	defer hvs.mtx.Unlock()
+	hvs.evsw = evsw
+}
+
 func (hvs *HeightVoteSet) Prevotes(round int) *VoteSet {
 	hvs.mtx.Lock()
 	defer hvs.mtx.Unlock()
@@ -120,6 +160,19 @@ func (hvs *HeightVoteSet) Precommits(round int) *VoteSet {
 	return hvs.getVoteSet(round, types.VoteTypePrecommit)
 }
 
+// Whether address, which must be in the validator set, precommitted in
+// any round.
+func (hvs *HeightVoteSet) HasPrecommitByAddress(address []byte) bool {
+	hvs.mtx.Lock()
+	defer hvs.mtx.Unlock()
+	for _, rvs := range hvs.roundVoteSets {
+		if rvs.Precommits.GetByAddress(address) != nil {
+			return true
+		}
+	}
+	return false
+}
+
 // Last round that has +2/3 prevotes for a particular block or nik.
 // Returns -1 if no such round exists.
 func (hvs *HeightVoteSet) POLRound() int {
@@ -0,0 +1,71 @@
+package consensus
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/tendermint/tendermint/common"
+	_ "github.com/tendermint/tendermint/config/tendermint_test"
+	"github.com/tendermint/tendermint/events"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestHeightVoteSetDivergence(t *testing.T) {
+	height, round := 1, 0
+	_, valSet, privValidators := randVoteSet(height, round, types.VoteTypePrevote, 10, 1)
+	hvs := NewHeightVoteSet(height, valSet)
+
+	evsw := new(events.EventSwitch)
+	evsw.Start()
+	defer evsw.Stop()
+	divergences := make(chan types.EventMsgVoteDivergence, 10)
+	evsw.AddListenerForEvent("test", types.EventStringVoteDivergence(), func(msg interface{}) {
+		divergences <- msg.(types.EventMsgVoteDivergence)
+	})
+	hvs.SetFireable(evsw)
+
+	prevote := func(i int, blockHash []byte) {
+		vote := &types.Vote{Height: height, Round: round, Type: types.VoteTypePrevote, BlockHash: blockHash}
+		privValidators[i].SignVoteUnsafe(config.GetString("chain_id"), vote)
+		if _, _, err := hvs.AddByAddress(privValidators[i].Address, vote, "peer"); err != nil {
+			t.Fatalf("Error adding prevote: %v", err)
+		}
+	}
+
+	// 4 of 10 for A is +1/3, and 3 of 10 for B is not.
+	blockA, blockB := CRandBytes(32), CRandBytes(32)
+	for i := 0; i < 4; i++ {
+		prevote(i, blockA)
+	}
+	for i := 4; i < 7; i++ {
+		prevote(i, blockB)
+	}
+	prevote(7, nil)
+	select {
+	case msg := <-divergences:
+		t.Fatalf("Unexpected divergence: %v", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// 4 of 10 for B diverges.
+	prevote(8, blockB)
+	select {
+	case msg := <-divergences:
+		if msg.Height != height || msg.Round != round || len(msg.Blocks) != 2 || msg.TotalPower != 10 {
+			t.Fatalf("Unexpected divergence: %v", msg)
+		}
+		if msg.Blocks[0].Power != 4 || msg.Blocks[1].Power != 4 {
+			t.Errorf("Expected 4 votes for each block, got %v and %v", msg.Blocks[0].Power, msg.Blocks[1].Power)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a divergence event")
+	}
+
+	// Only fired once per round.
+	prevote(9, blockB)
+	select {
+	case msg := <-divergences:
+		t.Fatalf("Unexpected second divergence: %v", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,61 @@
+package consensus
+
+import (
+	"github.com/tendermint/tendermint/alert"
+	. "github.com/tendermint/tendermint/common"
+)
+
+// Pages the operators, see alert.Alert, when the node's own validator
+// has no precommit at the heights of maxMissed blocks in a row while the
+// chain advances without it, e.g. because its key or disk is stuck, so
+// that they can fail over to a standby. Pages again once it's back.
+//
+// A height is judged when the next block is saved, so that a precommit
+// that arrives after the commit, into LastCommit, counts as signed.
+type signingMonitor struct {
+	maxMissed int
+	missed    int
+	alert     func(message string)
+
+	pendingHeight int  // The height to judge next, or 0 if none.
+	pendingSigned bool // Whether it had a precommit in any round.
+}
+
+func (cs *ConsensusState) SetMissedSigningAlert(maxMissed int) {
+	cs.mtx.Lock()
+	defer cs.mtx.Unlock()
+	cs.signingMonitor = &signingMonitor{
+		maxMissed: maxMissed,
+		alert:     alert.Alert,
+	}
+}
+
+// Called as the block at height is saved. bonded and precommitted are
+// whether address was bonded at height and precommitted in any of its
+// rounds, and lastCommit has the precommits for the block before it.
+func (mon *signingMonitor) saveBlock(address []byte, height int, bonded bool, precommitted bool, lastCommit *VoteSet) {
+	if mon.pendingHeight != 0 && mon.pendingHeight == height-1 {
+		signed := mon.pendingSigned ||
+			lastCommit != nil && lastCommit.Height() == height-1 && lastCommit.GetByAddress(address) != nil
+		mon.record(address, height-1, signed)
+	}
+	mon.pendingHeight, mon.pendingSigned = 0, false
+	if bonded {
+		mon.pendingHeight, mon.pendingSigned = height, precommitted
+	}
+}
+
+// SMS alerts are truncated, so the messages start with what to do.
+func (mon *signingMonitor) record(address []byte, height int, signed bool) {
+	if signed {
+		if mon.missed >= mon.maxMissed {
+			mon.alert(Fmt("Signing again at height %v after %v missed blocks, validator %X", height, mon.missed, address))
+		}
+		mon.missed = 0
+		return
+	}
+	mon.missed++
+	if mon.missed == mon.maxMissed {
+		mon.alert(Fmt("Fail over to a standby? Missed %v blocks up to height %v, validator %X", mon.missed, height, address))
+	}
+}
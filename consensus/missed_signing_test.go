@@ -0,0 +1,80 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestSigningMonitor(t *testing.T) {
+	alerts := []string{}
+	mon := &signingMonitor{
+		maxMissed: 3,
+		alert:     func(message string) { alerts = append(alerts, message) },
+	}
+	address := []byte("validator")
+
+	// Misses that are interrupted don't page.
+	mon.record(address, 1, false)
+	mon.record(address, 2, false)
+	mon.record(address, 3, true)
+	if len(alerts) != 0 {
+		t.Fatalf("Expected no alerts, got %v", alerts)
+	}
+
+	// The 3rd miss in a row pages once, and signing again pages again.
+	for height := 4; height <= 8; height++ {
+		mon.record(address, height, false)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("Expected 1 alert, got %v", alerts)
+	}
+	mon.record(address, 9, true)
+	mon.record(address, 10, true)
+	if len(alerts) != 2 {
+		t.Fatalf("Expected a 2nd alert on signing again, got %v", alerts)
+	}
+}
+
+func TestSigningMonitorRounds(t *testing.T) {
+	alerts := []string{}
+	mon := &signingMonitor{
+		maxMissed: 1,
+		alert:     func(message string) { alerts = append(alerts, message) },
+	}
+	_, valSet, privValidators := randVoteSet(1, 0, types.VoteTypePrecommit, 2, 1)
+	address := privValidators[0].Address
+	precommit := func(height int, round int) *types.Vote {
+		vote := &types.Vote{Height: height, Round: round, Type: types.VoteTypePrecommit}
+		privValidators[0].SignVoteUnsafe(config.GetString("chain_id"), vote)
+		return vote
+	}
+
+	// A precommit in a round other than the commit round counts as signed.
+	hvs := NewHeightVoteSet(1, valSet)
+	hvs.SetRound(1)
+	if _, _, err := hvs.AddByAddress(address, precommit(1, 0), ""); err != nil {
+		t.Fatal(err)
+	}
+	mon.saveBlock(address, 1, true, hvs.HasPrecommitByAddress(address), nil)
+	mon.saveBlock(address, 2, true, false, nil)
+	if mon.missed != 0 {
+		t.Fatalf("Expected height 1 to be signed, missed %v", mon.missed)
+	}
+
+	// A precommit that arrives after the commit, into LastCommit, counts as signed.
+	voteSet := NewVoteSet(2, 0, types.VoteTypePrecommit, valSet)
+	if _, _, err := voteSet.AddByAddress(address, precommit(2, 0)); err != nil {
+		t.Fatal(err)
+	}
+	mon.saveBlock(address, 3, true, false, voteSet)
+	if mon.missed != 0 || len(alerts) != 0 {
+		t.Fatalf("Expected height 2 to be signed, got %v", alerts)
+	}
+
+	// Without either, the height is missed.
+	mon.saveBlock(address, 4, true, false, NewVoteSet(3, 0, types.VoteTypePrecommit, valSet))
+	if len(alerts) != 1 {
+		t.Fatalf("Expected height 3 to be missed, got %v", alerts)
+	}
+}
@@ -0,0 +1,113 @@
+package consensus
+
+import (
+	"errors"
+	"io/ioutil"
+	"time"
+
+	"github.com/tendermint/tendermint/binary"
+	. "github.com/tendermint/tendermint/common"
+	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+)
+
+var (
+	ErrOfflinePrecommitWrongChain = errors.New("Error offline precommit for wrong chain")
+	ErrOfflinePrecommitNotCommit  = errors.New("Error offline precommit is not a precommit")
+	ErrOfflinePrecommitNoVote     = errors.New("Error offline precommit has no vote")
+)
+
+// An OfflinePrecommit is a validator's precommit exported out-of-band,
+// for disaster recovery when p2p is down.
+// The vote's sign-bytes commit to the chain id, height, round and block,
+// so an OfflinePrecommit cannot be replayed on another chain, height or round.
+type OfflinePrecommit struct {
+	ChainID string      `json:"chain_id"`
+	Address []byte      `json:"address"`
+	Vote    *types.Vote `json:"vote"`
+}
+
+// The file format is a JSON list of OfflinePrecommits,
+// so files from several validators can be merged by concatenating the lists.
+type OfflinePrecommitFile struct {
+	Precommits []*OfflinePrecommit `json:"precommits"`
+}
+
+// Signs a precommit for the given block with privVal.
+// The PrivValidator's last height/round/step is updated as usual,
+// so a validator cannot be tricked into double signing by exporting.
+// The version is the block version at height, see State.BlockVersionAt.
+func SignOfflinePrecommit(privVal *sm.PrivValidator, chainID string, version int, height int, round int,
+	blockHash []byte, blockParts types.PartSetHeader) (*OfflinePrecommit, error) {
+	vote := &types.Vote{
+		Version:    version,
+		Height:     height,
+		Round:      round,
+		Type:       types.VoteTypePrecommit,
+		BlockHash:  blockHash,
+		BlockParts: blockParts,
+	}
+	if version >= types.VoteTimestampVersion {
+		vote.Timestamp = time.Now().UnixNano()
+	}
+	if err := privVal.SignVote(chainID, vote); err != nil {
+		return nil, err
+	}
+	return &OfflinePrecommit{
+		ChainID: chainID,
+		Address: privVal.Address,
+		Vote:    vote,
+	}, nil
+}
+
+// Basic validation that doesn't involve the validator set.
+// The signature is checked when the precommit is added to a VoteSet.
+func (op *OfflinePrecommit) ValidateBasic(chainID string) error {
+	if op.ChainID != chainID {
+		return ErrOfflinePrecommitWrongChain
+	}
+	if op.Vote == nil {
+		return ErrOfflinePrecommitNoVote
+	}
+	if op.Vote.Type != types.VoteTypePrecommit {
+		return ErrOfflinePrecommitNotCommit
+	}
+	return op.Vote.ValidateBasic()
+}
+
+func WriteOfflinePrecommitFile(filePath string, ops []*OfflinePrecommit) error {
+	jsonBytes := binary.JSONBytes(&OfflinePrecommitFile{Precommits: ops})
+	return WriteFileAtomic(filePath, jsonBytes)
+}
+
+func ReadOfflinePrecommitFile(filePath string) ([]*OfflinePrecommit, error) {
+	jsonBytes, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	file := binary.ReadJSON(&OfflinePrecommitFile{}, jsonBytes, &err).(*OfflinePrecommitFile)
+	if err != nil {
+		return nil, err
+	}
+	return file.Precommits, nil
+}
+
+// Validates and merges an offline precommit into the VoteSet.
+func (voteSet *VoteSet) AddOfflinePrecommit(chainID string, op *OfflinePrecommit) (bool, int, error) {
+	if err := op.ValidateBasic(chainID); err != nil {
+		return false, 0, err
+	}
+	return voteSet.AddByAddress(op.Address, op.Vote)
+}
+
+// Validates and merges an offline precommit into the consensus state,
+// as if it were received from a peer.
+func (cs *ConsensusState) AddOfflinePrecommit(op *OfflinePrecommit) (bool, error) {
+	if err := op.ValidateBasic(config.GetString("chain_id")); err != nil {
+		return false, err
+	}
+	// Use a pseudo peer key per validator for round catchup bookkeeping.
+	peerKey := Fmt("offline:%X", op.Address)
+	added, _, err := cs.AddVote(op.Address, op.Vote, peerKey)
+	return added, err
+}
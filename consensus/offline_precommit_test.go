@@ -0,0 +1,57 @@
+package consensus
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/tendermint/tendermint/common"
+	_ "github.com/tendermint/tendermint/config/tendermint_test"
+	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestOfflinePrecommitFile(t *testing.T) {
+	height, round := 2, 0
+	voteSet, _, privValidators := randVoteSet(height, round, types.VoteTypePrecommit, 10, 1)
+	chainID := config.GetString("chain_id")
+	blockHash, blockParts := CRandBytes(32), types.PartSetHeader{Total: 1, Hash: CRandBytes(32)}
+
+	// Export precommits from 7 validators into one file.
+	precommits := []*OfflinePrecommit{}
+	for i := 0; i < 7; i++ {
+		precommit, err := SignOfflinePrecommit(privValidators[i], chainID, 0, height, round, blockHash, blockParts)
+		if err != nil {
+			t.Fatalf("Error signing offline precommit: %v", err)
+		}
+		precommits = append(precommits, precommit)
+	}
+	_, filePath := sm.Tempfile("offline_precommits_")
+	if err := WriteOfflinePrecommitFile(filePath, precommits); err != nil {
+		t.Fatalf("Error writing offline precommits: %v", err)
+	}
+
+	// Signing for an earlier height is rejected.
+	if _, err := SignOfflinePrecommit(privValidators[0], chainID, 0, height-1, round, blockHash, blockParts); err == nil {
+		t.Errorf("Expected height regression to be rejected")
+	}
+
+	// Import them and check for +2/3.
+	precommits, err := ReadOfflinePrecommitFile(filePath)
+	if err != nil {
+		t.Fatalf("Error reading offline precommits: %v", err)
+	}
+	for _, precommit := range precommits {
+		if added, _, err := voteSet.AddOfflinePrecommit(chainID, precommit); !added || err != nil {
+			t.Errorf("Expected offline precommit to be added, error: %v", err)
+		}
+	}
+	hash, _, ok := voteSet.TwoThirdsMajority()
+	if !ok || !bytes.Equal(hash, blockHash) {
+		t.Errorf("Expected +2/3 for the block")
+	}
+
+	// Precommits for another chain are rejected.
+	if _, _, err := voteSet.AddOfflinePrecommit("other_chain", precommits[0]); err != ErrOfflinePrecommitWrongChain {
+		t.Errorf("Expected ErrOfflinePrecommitWrongChain, got %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package consensus
+
+import (
+	"github.com/tendermint/tendermint/types"
+)
+
+// PartSetGCStats counts the block PartSets that were dropped without
+// their block being committed, e.g. because the round changed or a new
+// proposal replaced the one whose parts we were receiving.
+type PartSetGCStats struct {
+	PartSets   int   `json:"part_sets"`  // Orphaned PartSets released.
+	Incomplete int   `json:"incomplete"` // Of those, how many never completed.
+	Parts      int   `json:"parts"`      // Parts they held.
+	Bytes      int64 `json:"bytes"`      // Part bytes reclaimed.
+}
+
+// Releases the given PartSets unless the round state still references them,
+// and counts them towards the stats. Call with the old ProposalBlockParts
+// and LockedBlockParts after changing either of them.
+// CONTRACT: cs.mtx is held.
+func (cs *ConsensusState) releasePartSets(partSets ...*types.PartSet) {
+	for i, ps := range partSets {
+		if ps == nil || ps == cs.ProposalBlockParts || ps == cs.LockedBlockParts {
+			continue
+		}
+		if containsPartSet(partSets[:i], ps) {
+			continue
+		}
+		cs.partSetGC.PartSets += 1
+		if !ps.IsComplete() {
+			cs.partSetGC.Incomplete += 1
+		}
+		cs.partSetGC.Parts += ps.Count()
+		cs.partSetGC.Bytes += ps.ByteSize()
+		log.Debug("Releasing orphaned block parts", "height", cs.Height, "round", cs.Round, "parts", ps.StringShort())
+		ps.Release()
+	}
+}
+
+func containsPartSet(partSets []*types.PartSet, ps *types.PartSet) bool {
+	for _, other := range partSets {
+		if other == ps {
+			return true
+		}
+	}
+	return false
+}
+
+func (cs *ConsensusState) GetPartSetGCStats() PartSetGCStats {
+	cs.mtx.Lock()
+	defer cs.mtx.Unlock()
+	return cs.partSetGC
+}
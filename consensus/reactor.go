@@ -4,9 +4,9 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"math/rand"
 	"reflect"
 	"sync"
-	"sync/atomic"
 	"time"
 
 	"github.com/tendermint/tendermint/binary"
@@ -34,9 +34,7 @@ const (
 // The reactor's underlying ConsensusState may change state at any time.
 // We atomically copy the RoundState struct before using it.
 type ConsensusReactor struct {
-	sw      *p2p.Switch
-	running uint32
-	quit    chan struct{}
+	*p2p.BaseReactor
 
 	blockStore *bc.BlockStore
 	conS       *ConsensusState
@@ -45,41 +43,41 @@ type ConsensusReactor struct {
 	sync bool
 
 	evsw events.Fireable
+
+	// If set, gossip and broadcasts run on clock instead of in goroutines,
+	// and broadcasts wait for broadcastPending. See Simulation.
+	clock Clock
+	rand  *rand.Rand // For the parts and votes to send, and the peers to push to.
 }
 
 func NewConsensusReactor(consensusState *ConsensusState, blockStore *bc.BlockStore, sync bool) *ConsensusReactor {
 	conR := &ConsensusReactor{
-		quit:       make(chan struct{}),
 		blockStore: blockStore,
 		conS:       consensusState,
 		sync:       sync,
+		rand:       rand.New(&lockedSource{src: rand.NewSource(rand.Int63())}),
 	}
+	conR.BaseReactor = p2p.NewBaseReactor(log, "ConsensusReactor", conR)
 	return conR
 }
 
 // Implements Reactor
-func (conR *ConsensusReactor) Start(sw *p2p.Switch) {
-	if atomic.CompareAndSwapUint32(&conR.running, 0, 1) {
-		log.Info("Starting ConsensusReactor")
-		conR.sw = sw
-		if !conR.sync {
-			conR.conS.Start()
-		}
+func (conR *ConsensusReactor) OnStart() error {
+	conR.BaseReactor.OnStart()
+	if !conR.sync {
+		conR.conS.Start()
+	}
+	if conR.clock == nil {
 		go conR.broadcastNewRoundStepRoutine()
+		go conR.broadcastOwnVoteRoutine()
 	}
+	return nil
 }
 
 // Implements Reactor
-func (conR *ConsensusReactor) Stop() {
-	if atomic.CompareAndSwapUint32(&conR.running, 1, 0) {
-		log.Info("Stopping ConsensusReactor")
-		conR.conS.Stop()
-		close(conR.quit)
-	}
-}
-
-func (conR *ConsensusReactor) IsRunning() bool {
-	return atomic.LoadUint32(&conR.running) == 1
+func (conR *ConsensusReactor) OnStop() {
+	conR.BaseReactor.OnStop()
+	conR.conS.Stop()
 }
 
 // Implements Reactor
@@ -115,8 +113,13 @@ func (conR *ConsensusReactor) AddPeer(peer *p2p.Peer) {
 	peer.Data.Set(PeerStateKey, peerState)
 
 	// Begin gossip routines for this peer.
-	go conR.gossipDataRoutine(peer, peerState)
-	go conR.gossipVotesRoutine(peer, peerState)
+	if conR.clock == nil {
+		go conR.gossipDataRoutine(peer, peerState)
+		go conR.gossipVotesRoutine(peer, peerState)
+	} else {
+		conR.gossipOnClock(peer, func() bool { return conR.gossipData(peer, peerState) })
+		conR.gossipOnClock(peer, func() bool { return conR.gossipVotes(peer, peerState) })
+	}
 
 	// Send our state to peer.
 	conR.sendNewRoundStepMessage(peer)
@@ -162,11 +165,38 @@ func (conR *ConsensusReactor) Receive(chId byte, peer *p2p.Peer, msgBytes []byte
 	case DataChannel:
 		switch msg := msg_.(type) {
 		case *ProposalMessage:
+			if msg.Proposal == nil {
+				conR.Switch.StopPeerForError(peer, errors.New("Nil proposal"))
+				return
+			}
+			if err := msg.Proposal.ValidateBasic(); err != nil {
+				log.Warn("Invalid proposal", "peer", peer, "error", err)
+				conR.Switch.StopPeerForError(peer, err)
+				return
+			}
 			ps.SetHasProposal(msg.Proposal)
+			if msg.Proposal.Height == rs.Height {
+				conR.conS.TraceProposalReceived(msg.Proposal.Height, peer.Key)
+			}
 			err = conR.conS.SetProposal(msg.Proposal)
+			// If conflicting sig, broadcast evidence tx for slashing.
+			if errDupe, ok := err.(*ErrConflictingProposal); ok {
+				log.Warn("Found conflicting proposal. Publish evidence", "height", msg.Proposal.Height, "round", msg.Proposal.Round)
+				conR.conS.mempoolReactor.BroadcastTx(errDupe.EvidenceTx()) // shouldn't need to check returned err
+				err = nil
+			}
 		case *ProposalPOLMessage:
 			ps.ApplyProposalPOLMessage(msg)
 		case *BlockPartMessage:
+			if msg.Part == nil {
+				conR.Switch.StopPeerForError(peer, errors.New("Nil block part"))
+				return
+			}
+			if err := msg.Part.ValidateBasic(); err != nil {
+				log.Warn("Invalid block part", "peer", peer, "error", err)
+				conR.Switch.StopPeerForError(peer, err)
+				return
+			}
 			ps.SetHasProposalBlockPart(msg.Height, msg.Round, msg.Part.Proof.Index)
 			_, err = conR.conS.AddProposalBlockPart(msg.Height, msg.Part)
 		default:
@@ -177,6 +207,15 @@ func (conR *ConsensusReactor) Receive(chId byte, peer *p2p.Peer, msgBytes []byte
 		switch msg := msg_.(type) {
 		case *VoteMessage:
 			vote := msg.Vote
+			if vote == nil {
+				conR.Switch.StopPeerForError(peer, errors.New("Nil vote"))
+				return
+			}
+			if err := vote.ValidateBasic(); err != nil {
+				log.Warn("Invalid vote", "peer", peer, "error", err)
+				conR.Switch.StopPeerForError(peer, err)
+				return
+			}
 			var validators *sm.ValidatorSet
 			if rs.Height == vote.Height {
 				validators = rs.Validators
@@ -191,6 +230,10 @@ func (conR *ConsensusReactor) Receive(chId byte, peer *p2p.Peer, msgBytes []byte
 
 			// We have vote/validators.  Height may not be rs.Height
 
+			if msg.ValidatorIndex < 0 || msg.ValidatorIndex >= validators.Size() {
+				conR.Switch.StopPeerForError(peer, errors.New("Invalid validator index"))
+				return
+			}
 			address, _ := validators.GetByIndex(msg.ValidatorIndex)
 			added, index, err := conR.conS.AddVote(address, vote, peer.Key)
 			if err != nil {
@@ -239,10 +282,10 @@ func (conR *ConsensusReactor) broadcastHasVoteMessage(vote *types.Vote, index in
 		Type:   vote.Type,
 		Index:  index,
 	}
-	conR.sw.Broadcast(StateChannel, msg)
+	conR.Switch.Broadcast(StateChannel, msg)
 	/*
 		// TODO: Make this broadcast more selective.
-		for _, peer := range conR.sw.Peers().List() {
+		for _, peer := range conR.Switch.Peers().List() {
 			ps := peer.Data.Get(PeerStateKey).(*PeerState)
 			prs := ps.GetRoundState()
 			if prs.Height == vote.Height {
@@ -304,18 +347,108 @@ func (conR *ConsensusReactor) broadcastNewRoundStepRoutine() {
 		var rs *RoundState
 		select {
 		case rs = <-conR.conS.NewStepCh():
-		case <-conR.quit:
+		case <-conR.Quit:
+			return
+		}
+
+		conR.broadcastNewRoundStep(rs)
+	}
+}
+
+func (conR *ConsensusReactor) broadcastNewRoundStep(rs *RoundState) {
+	nrsMsg, csMsg := makeRoundStepMessages(rs)
+	if nrsMsg != nil {
+		conR.Switch.Broadcast(StateChannel, nrsMsg)
+	}
+	if csMsg != nil {
+		conR.Switch.Broadcast(StateChannel, csMsg)
+	}
+}
+
+// Pushes the votes we sign to up to vote_broadcast_redundancy peers
+// that are at the vote's height, rather than waiting for gossipVotesRoutine
+// to pick them. Small validator sets may want a higher value, since every
+// missing vote there risks a timeout. 0 leaves it all to gossip.
+func (conR *ConsensusReactor) broadcastOwnVoteRoutine() {
+	for {
+		var msg *VoteMessage
+		select {
+		case msg = <-conR.conS.OwnVoteCh():
+		case <-conR.Quit:
 			return
 		}
 
-		nrsMsg, csMsg := makeRoundStepMessages(rs)
-		if nrsMsg != nil {
-			conR.sw.Broadcast(StateChannel, nrsMsg)
+		conR.broadcastOwnVote(msg)
+	}
+}
+
+func (conR *ConsensusReactor) broadcastOwnVote(msg *VoteMessage) {
+	redundancy := config.GetInt("vote_broadcast_redundancy")
+	if redundancy <= 0 {
+		return
+	}
+	rs := conR.conS.GetRoundState()
+	if rs.Height != msg.Vote.Height {
+		return
+	}
+	numValidators := rs.Validators.Size()
+	peers := conR.Switch.Peers().List()
+	sent := 0
+	for _, i := range conR.rand.Perm(len(peers)) {
+		if sent >= redundancy {
+			break
+		}
+		peer := peers[i]
+		ps := peer.Data.Get(PeerStateKey).(*PeerState)
+		if ps.GetRoundState().Height != msg.Vote.Height {
+			continue
 		}
-		if csMsg != nil {
-			conR.sw.Broadcast(StateChannel, csMsg)
+		if peer.TrySend(VoteChannel, msg) {
+			ps.EnsureVoteBitArrays(msg.Vote.Height, numValidators, nil)
+			ps.SetHasVote(msg.Vote, msg.ValidatorIndex)
+			sent++
 		}
 	}
+	log.Debug("Pushed own vote", "height", msg.Vote.Height, "round", msg.Vote.Round, "type", msg.Vote.Type, "peers", sent)
+}
+
+// Broadcasts the new steps and own votes that conR.conS has queued,
+// in place of broadcastNewRoundStepRoutine and broadcastOwnVoteRoutine
+// when conR.clock is set.
+func (conR *ConsensusReactor) broadcastPending() {
+	for drained := false; !drained; {
+		select {
+		case rs := <-conR.conS.NewStepCh():
+			conR.broadcastNewRoundStep(rs)
+		default:
+			drained = true
+		}
+	}
+	for drained := false; !drained; {
+		select {
+		case msg := <-conR.conS.OwnVoteCh():
+			conR.broadcastOwnVote(msg)
+		default:
+			drained = true
+		}
+	}
+}
+
+// Runs gossip on conR.clock until peer or conR stops, right after gossip
+// sent something, else after peerGossipSleepDuration.
+func (conR *ConsensusReactor) gossipOnClock(peer *p2p.Peer, gossip func() bool) {
+	var next func()
+	next = func() {
+		if !peer.IsRunning() || !conR.IsRunning() {
+			return
+		}
+		if gossip() {
+			conR.clock.AfterFunc(0, next)
+		} else {
+			conR.clock.AfterFunc(peerGossipSleepDuration, next)
+		}
+	}
+	conR.clock.AfterFunc(0, next)
 }
 
 func (conR *ConsensusReactor) sendNewRoundStepMessage(peer *p2p.Peer) {
@@ -330,108 +463,109 @@ func (conR *ConsensusReactor) sendNewRoundStepMessage(peer *p2p.Peer) {
 }
 
 func (conR *ConsensusReactor) gossipDataRoutine(peer *p2p.Peer, ps *PeerState) {
-
-OUTER_LOOP:
-	for {
-		// Manage disconnects from self or peer.
-		if !peer.IsRunning() || !conR.IsRunning() {
-			log.Info(Fmt("Stopping gossipDataRoutine for %v.", peer))
-			return
-		}
-		rs := conR.conS.GetRoundState()
-		prs := ps.GetRoundState()
-
-		// Send proposal Block parts?
-		if rs.ProposalBlockParts.HasHeader(prs.ProposalBlockPartsHeader) {
-			//log.Debug("ProposalBlockParts matched", "blockParts", prs.ProposalBlockParts)
-			if index, ok := rs.ProposalBlockParts.BitArray().Sub(prs.ProposalBlockParts.Copy()).PickRandom(); ok {
-				part := rs.ProposalBlockParts.GetPart(index)
-				msg := &BlockPartMessage{
-					Height: rs.Height, // This tells peer that this part applies to us.
-					Round:  rs.Round,  // This tells peer that this part applies to us.
-					Part:   part,
-				}
-				peer.Send(DataChannel, msg)
-				ps.SetHasProposalBlockPart(prs.Height, prs.Round, index)
-				continue OUTER_LOOP
-			}
+	for peer.IsRunning() && conR.IsRunning() {
+		if !conR.gossipData(peer, ps) {
+			time.Sleep(peerGossipSleepDuration)
 		}
+	}
+	log.Info("Stopping gossipDataRoutine", "peer", peer)
+}
 
-		// If the peer is on a previous height, help catch up.
-		if (0 < prs.Height) && (prs.Height < rs.Height) {
-			//log.Debug("Data catchup", "height", rs.Height, "peerHeight", prs.Height, "peerProposalBlockParts", prs.ProposalBlockParts)
-			if index, ok := prs.ProposalBlockParts.Not().PickRandom(); ok {
-				// Ensure that the peer's PartSetHeader is correct
-				blockMeta := conR.blockStore.LoadBlockMeta(prs.Height)
-				if !blockMeta.PartsHeader.Equals(prs.ProposalBlockPartsHeader) {
-					log.Debug("Peer ProposalBlockPartsHeader mismatch, sleeping",
-						"peerHeight", prs.Height, "blockPartsHeader", blockMeta.PartsHeader, "peerBlockPartsHeader", prs.ProposalBlockPartsHeader)
-					time.Sleep(peerGossipSleepDuration)
-					continue OUTER_LOOP
-				}
-				// Load the part
-				part := conR.blockStore.LoadBlockPart(prs.Height, index)
-				if part == nil {
-					log.Warn("Could not load part", "index", index,
-						"peerHeight", prs.Height, "blockPartsHeader", blockMeta.PartsHeader, "peerBlockPartsHeader", prs.ProposalBlockPartsHeader)
-					time.Sleep(peerGossipSleepDuration)
-					continue OUTER_LOOP
-				}
-				// Send the part
-				msg := &BlockPartMessage{
-					Height: prs.Height, // Not our height, so it doesn't matter.
-					Round:  prs.Round,  // Not our height, so it doesn't matter.
-					Part:   part,
-				}
-				peer.Send(DataChannel, msg)
-				ps.SetHasProposalBlockPart(prs.Height, prs.Round, index)
-				continue OUTER_LOOP
-			} else {
-				//log.Debug("No parts to send in catch-up, sleeping")
-				time.Sleep(peerGossipSleepDuration)
-				continue OUTER_LOOP
+// Sends the peer a proposal or block part it lacks, if any.
+// Returns false if there was nothing to send.
+func (conR *ConsensusReactor) gossipData(peer *p2p.Peer, ps *PeerState) (sent bool) {
+	rs := conR.conS.GetRoundState()
+	prs := ps.GetRoundState()
+
+	// Send proposal Block parts?
+	if rs.ProposalBlockParts.HasHeader(prs.ProposalBlockPartsHeader) {
+		//log.Debug("ProposalBlockParts matched", "blockParts", prs.ProposalBlockParts)
+		if index, ok := rs.ProposalBlockParts.BitArray().Sub(prs.ProposalBlockParts.Copy()).PickRandomFrom(conR.rand); ok {
+			part := rs.ProposalBlockParts.GetPart(index)
+			if part == nil {
+				// The parts were released since we got rs.
+				return true
+			}
+			msg := &BlockPartMessage{
+				Height: rs.Height, // This tells peer that this part applies to us.
+				Round:  rs.Round,  // This tells peer that this part applies to us.
+				Part:   part,
 			}
+			peer.Send(DataChannel, msg)
+			ps.SetHasProposalBlockPart(prs.Height, prs.Round, index)
+			return true
 		}
+	}
 
-		// If height and round don't match, sleep.
-		if (rs.Height != prs.Height) || (rs.Round != prs.Round) {
-			//log.Debug("Peer Height|Round mismatch, sleeping", "peerHeight", prs.Height, "peerRound", prs.Round, "peer", peer)
-			time.Sleep(peerGossipSleepDuration)
-			continue OUTER_LOOP
+	// If the peer is on a previous height, help catch up.
+	if (0 < prs.Height) && (prs.Height < rs.Height) {
+		//log.Debug("Data catchup", "height", rs.Height, "peerHeight", prs.Height, "peerProposalBlockParts", prs.ProposalBlockParts)
+		if index, ok := prs.ProposalBlockParts.Not().PickRandomFrom(conR.rand); ok {
+			// Ensure that the peer's PartSetHeader is correct
+			blockMeta := conR.blockStore.LoadBlockMeta(prs.Height)
+			if !blockMeta.PartsHeader.Equals(prs.ProposalBlockPartsHeader) {
+				log.Debug("Peer ProposalBlockPartsHeader mismatch, sleeping",
+					"peerHeight", prs.Height, "blockPartsHeader", blockMeta.PartsHeader, "peerBlockPartsHeader", prs.ProposalBlockPartsHeader)
+				return false
+			}
+			// Load the part
+			part := conR.blockStore.LoadBlockPart(prs.Height, index)
+			if part == nil {
+				log.Warn("Could not load part", "index", index,
+					"peerHeight", prs.Height, "blockPartsHeader", blockMeta.PartsHeader, "peerBlockPartsHeader", prs.ProposalBlockPartsHeader)
+				return false
+			}
+			// Send the part
+			msg := &BlockPartMessage{
+				Height: prs.Height, // Not our height, so it doesn't matter.
+				Round:  prs.Round,  // Not our height, so it doesn't matter.
+				Part:   part,
+			}
+			peer.Send(DataChannel, msg)
+			ps.SetHasProposalBlockPart(prs.Height, prs.Round, index)
+			return true
+		} else {
+			//log.Debug("No parts to send in catch-up, sleeping")
+			return false
 		}
+	}
 
-		// By here, height and round match.
-		// Proposal block parts were already matched and sent if any were wanted.
-		// (These can match on hash so the round doesn't matter)
-		// Now consider sending other things, like the Proposal itself.
+	// If height and round don't match, sleep.
+	if (rs.Height != prs.Height) || (rs.Round != prs.Round) {
+		//log.Debug("Peer Height|Round mismatch, sleeping", "peerHeight", prs.Height, "peerRound", prs.Round, "peer", peer)
+		return false
+	}
 
-		// Send Proposal && ProposalPOL BitArray?
-		if rs.Proposal != nil && !prs.Proposal {
-			// Proposal
-			{
-				msg := &ProposalMessage{Proposal: rs.Proposal}
-				peer.Send(DataChannel, msg)
-				ps.SetHasProposal(rs.Proposal)
-			}
-			// ProposalPOL.
-			// Peer must receive ProposalMessage first.
-			// rs.Proposal was validated, so rs.Proposal.POLRound <= rs.Round,
-			// so we definitely have rs.Votes.Prevotes(rs.Proposal.POLRound).
-			if 0 <= rs.Proposal.POLRound {
-				msg := &ProposalPOLMessage{
-					Height:           rs.Height,
-					ProposalPOLRound: rs.Proposal.POLRound,
-					ProposalPOL:      rs.Votes.Prevotes(rs.Proposal.POLRound).BitArray(),
-				}
-				peer.Send(DataChannel, msg)
+	// By here, height and round match.
+	// Proposal block parts were already matched and sent if any were wanted.
+	// (These can match on hash so the round doesn't matter)
+	// Now consider sending other things, like the Proposal itself.
+
+	// Send Proposal && ProposalPOL BitArray?
+	if rs.Proposal != nil && !prs.Proposal {
+		// Proposal
+		{
+			msg := &ProposalMessage{Proposal: rs.Proposal}
+			peer.Send(DataChannel, msg)
+			ps.SetHasProposal(rs.Proposal)
+		}
+		// ProposalPOL.
+		// Peer must receive ProposalMessage first.
+		// rs.Proposal was validated, so rs.Proposal.POLRound <= rs.Round,
+		// so we definitely have rs.Votes.Prevotes(rs.Proposal.POLRound).
+		if 0 <= rs.Proposal.POLRound {
+			msg := &ProposalPOLMessage{
+				Height:           rs.Height,
+				ProposalPOLRound: rs.Proposal.POLRound,
+				ProposalPOL:      rs.Votes.Prevotes(rs.Proposal.POLRound).BitArray(),
 			}
-			continue OUTER_LOOP
+			peer.Send(DataChannel, msg)
 		}
-
-		// Nothing to do. Sleep.
-		time.Sleep(peerGossipSleepDuration)
-		continue OUTER_LOOP
+		return true
 	}
+
+	// Nothing to do.
+	return false
 }
 
 func (conR *ConsensusReactor) gossipVotesRoutine(peer *p2p.Peer, ps *PeerState) {
@@ -439,16 +573,7 @@ func (conR *ConsensusReactor) gossipVotesRoutine(peer *p2p.Peer, ps *PeerState)
 	// Simple hack to throttle logs upon sleep.
 	var sleeping = 0
 
-OUTER_LOOP:
-	for {
-		// Manage disconnects from self or peer.
-		if !peer.IsRunning() || !conR.IsRunning() {
-			log.Info(Fmt("Stopping gossipVotesRoutine for %v.", peer))
-			return
-		}
-		rs := conR.conS.GetRoundState()
-		prs := ps.GetRoundState()
-
+	for peer.IsRunning() && conR.IsRunning() {
 		switch sleeping {
 		case 1: // First sleep
 			sleeping = 2
@@ -456,141 +581,173 @@ OUTER_LOOP:
 			sleeping = 0
 		}
 
-		// prsVoteSet: a pointer to a VoteSet field of prs.
-		// Returns true when useful work was done.
-		trySendVote := func(voteSet *VoteSet, prsVoteSet **BitArray) (sent bool) {
-			if voteSet == nil {
-				return false
-			}
+		if conR.gossipVotes(peer, ps) {
+			continue
+		}
+
+		if sleeping == 0 {
+			// We sent nothing. Sleep...
+			sleeping = 1
+			rs, prs := conR.conS.GetRoundState(), ps.GetRoundState()
+			log.Debug("No votes to send, sleeping", "peer", peer,
+				"localPV", rs.Votes.Prevotes(rs.Round).BitArray(), "peerPV", prs.Prevotes,
+				"localPC", rs.Votes.Precommits(rs.Round).BitArray(), "peerPC", prs.Precommits)
+		} else if sleeping == 2 {
+			// Continued sleep...
+			sleeping = 1
+		}
+
+		time.Sleep(peerGossipSleepDuration)
+	}
+	log.Info("Stopping gossipVotesRoutine", "peer", peer)
+}
+
+// Sends the peer a vote it lacks, if any.
+// Returns false if there was nothing to send.
+func (conR *ConsensusReactor) gossipVotes(peer *p2p.Peer, ps *PeerState) (sent bool) {
+	rs := conR.conS.GetRoundState()
+	prs := ps.GetRoundState()
+
+	// prsVoteSet: a pointer to a VoteSet field of prs.
+	// Returns true when useful work was done.
+	trySendVote := func(voteSet *VoteSet, prsVoteSet **BitArray) (sent bool) {
+		if voteSet == nil {
+			return false
+		}
+		if *prsVoteSet == nil {
+			ps.EnsureVoteBitArrays(voteSet.Height(), voteSet.Size(), prs)
+			// We could return true here (useful work was done)
+			// or, we can continue since prsVoteSet is no longer nil.
 			if *prsVoteSet == nil {
-				ps.EnsureVoteBitArrays(voteSet.Height(), voteSet.Size(), prs)
-				// We could return true here (useful work was done)
-				// or, we can continue since prsVoteSet is no longer nil.
-				if *prsVoteSet == nil {
-					panic("prsVoteSet should not be nil after ps.EnsureVoteBitArrays")
-				}
-			}
-			// TODO: give priority to our vote.
-			if index, ok := voteSet.BitArray().Sub((*prsVoteSet).Copy()).PickRandom(); ok {
-				vote := voteSet.GetByIndex(index)
-				msg := &VoteMessage{index, vote}
-				peer.Send(VoteChannel, msg)
-				ps.SetHasVote(vote, index)
-				return true
+				panic("prsVoteSet should not be nil after ps.EnsureVoteBitArrays")
 			}
-			return false
 		}
+		// TODO: give priority to our vote.
+		if vote, index, ok := voteSet.PickVoteToSend(*prsVoteSet, conR.rand); ok {
+			msg := &VoteMessage{index, vote}
+			peer.Send(VoteChannel, msg)
+			ps.SetHasVote(vote, index)
+			return true
+		}
+		return false
+	}
 
-		// prsVoteSet: a pointer to a VoteSet field of prs.
-		// Returns true when useful work was done.
-		trySendPrecommitFromValidation := func(validation *types.Validation, prsVoteSet **BitArray) (sent bool) {
-			if validation == nil {
-				return false
-			} else if *prsVoteSet == nil {
-				ps.EnsureVoteBitArrays(validation.Height(), len(validation.Precommits), prs)
-				// We could return true here (useful work was done)
-				// or, we can continue since prsVoteSet is no longer nil.
-				if *prsVoteSet == nil {
-					panic("prsVoteSet should not be nil after ps.EnsureVoteBitArrays")
-				}
-			}
-			if index, ok := validation.BitArray().Sub((*prsVoteSet).Copy()).PickRandom(); ok {
-				precommit := validation.Precommits[index]
-				log.Debug("Picked precommit to send", "index", index, "precommit", precommit)
-				msg := &VoteMessage{index, precommit}
-				peer.Send(VoteChannel, msg)
-				ps.SetHasVote(precommit, index)
-				return true
-			}
+	// prsVoteSet: a pointer to a VoteSet field of prs.
+	// Returns true when useful work was done.
+	trySendPrecommitFromValidation := func(validation *types.Validation, prsVoteSet **BitArray) (sent bool) {
+		if validation == nil {
 			return false
+		} else if *prsVoteSet == nil {
+			ps.EnsureVoteBitArrays(validation.Height(), len(validation.Precommits), prs)
+			// We could return true here (useful work was done)
+			// or, we can continue since prsVoteSet is no longer nil.
+			if *prsVoteSet == nil {
+				panic("prsVoteSet should not be nil after ps.EnsureVoteBitArrays")
+			}
 		}
+		if index, ok := validation.BitArray().Sub((*prsVoteSet).Copy()).PickRandomFrom(conR.rand); ok {
+			precommit := validation.Precommits[index]
+			log.Debug("Picked precommit to send", "index", index, "precommit", precommit)
+			msg := &VoteMessage{index, precommit}
+			peer.Send(VoteChannel, msg)
+			ps.SetHasVote(precommit, index)
+			return true
+		}
+		return false
+	}
 
-		// If height matches, then send LastCommit, Prevotes, Precommits.
-		if rs.Height == prs.Height {
-			// If there are lastCommits to send...
-			if prs.Step == RoundStepNewHeight {
-				if trySendVote(rs.LastCommit, &prs.LastCommit) {
-					continue OUTER_LOOP
-				}
-			}
-			// If there are prevotes to send...
-			if rs.Round == prs.Round && prs.Step <= RoundStepPrevote {
-				if trySendVote(rs.Votes.Prevotes(rs.Round), &prs.Prevotes) {
-					continue OUTER_LOOP
-				}
+	// If height matches, then send LastCommit, Prevotes, Precommits.
+	if rs.Height == prs.Height {
+		// If there are lastCommits to send...
+		if prs.Step == RoundStepNewHeight {
+			if trySendVote(rs.LastCommit, &prs.LastCommit) {
+				return true
 			}
-			// If there are precommits to send...
-			if rs.Round == prs.Round && prs.Step <= RoundStepPrecommit {
-				if trySendVote(rs.Votes.Precommits(rs.Round), &prs.Precommits) {
-					continue OUTER_LOOP
-				}
+		}
+		// If there are prevotes to send...
+		if rs.Round == prs.Round && prs.Step <= RoundStepPrevote {
+			if trySendVote(rs.Votes.Prevotes(rs.Round), &prs.Prevotes) {
+				return true
 			}
-			// If there are prevotes to send for the last round...
-			if rs.Round == prs.Round+1 && prs.Step <= RoundStepPrevote {
-				if trySendVote(rs.Votes.Prevotes(prs.Round), &prs.Prevotes) {
-					continue OUTER_LOOP
-				}
+		}
+		// If there are precommits to send...
+		if rs.Round == prs.Round && prs.Step <= RoundStepPrecommit {
+			if trySendVote(rs.Votes.Precommits(rs.Round), &prs.Precommits) {
+				return true
 			}
-			// If there are precommits to send for the last round...
-			if rs.Round == prs.Round+1 && prs.Step <= RoundStepPrecommit {
-				if trySendVote(rs.Votes.Precommits(prs.Round), &prs.Precommits) {
-					continue OUTER_LOOP
-				}
+		}
+		// If there are prevotes to send for the last round...
+		if rs.Round == prs.Round+1 && prs.Step <= RoundStepPrevote {
+			if trySendVote(rs.Votes.Prevotes(prs.Round), &prs.Prevotes) {
+				return true
 			}
-			// If there are POLPrevotes to send...
-			if 0 <= prs.ProposalPOLRound {
-				if polPrevotes := rs.Votes.Prevotes(prs.ProposalPOLRound); polPrevotes != nil {
-					if trySendVote(polPrevotes, &prs.ProposalPOL) {
-						continue OUTER_LOOP
-					}
-				}
+		}
+		// If there are precommits to send for the last round...
+		if rs.Round == prs.Round+1 && prs.Step <= RoundStepPrecommit {
+			if trySendVote(rs.Votes.Precommits(prs.Round), &prs.Precommits) {
+				return true
 			}
 		}
-
-		// Special catchup logic.
-		// If peer is lagging by height 1, send LastCommit.
-		if prs.Height != 0 && prs.Height == rs.Height-1 {
-			if prs.Round == rs.LastCommit.Round() {
-				// NOTE: We prefer to use prs.Precommits if
-				// prs.Round matches prs.CatchupCommitRound.
-				if trySendVote(rs.LastCommit, &prs.Precommits) {
-					continue OUTER_LOOP
-				}
-			} else {
-				ps.EnsureCatchupCommitRound(prs.Height, rs.LastCommit.Round())
-				if trySendVote(rs.LastCommit, &prs.CatchupCommit) {
-					continue OUTER_LOOP
+		// If there are POLPrevotes to send...
+		if 0 <= prs.ProposalPOLRound {
+			if polPrevotes := rs.Votes.Prevotes(prs.ProposalPOLRound); polPrevotes != nil {
+				if trySendVote(polPrevotes, &prs.ProposalPOL) {
+					return true
 				}
 			}
 		}
+	}
 
-		// Catchup logic
-		// If peer is lagging by more than 1, send Validation.
-		if prs.Height != 0 && prs.Height <= rs.Height-2 {
-			// Load the block validation for prs.Height,
-			// which contains precommit signatures for prs.Height.
-			validation := conR.blockStore.LoadBlockValidation(prs.Height)
-			log.Debug("Loaded BlockValidation for catch-up", "height", prs.Height, "validation", validation)
-			ps.EnsureCatchupCommitRound(prs.Height, validation.Round())
-			if trySendPrecommitFromValidation(validation, &prs.CatchupCommit) {
-				continue OUTER_LOOP
+	// Special catchup logic.
+	// If peer is lagging by height 1, send LastCommit.
+	if prs.Height != 0 && prs.Height == rs.Height-1 {
+		if prs.Round == rs.LastCommit.Round() {
+			// NOTE: We prefer to use prs.Precommits if
+			// prs.Round matches prs.CatchupCommitRound.
+			if trySendVote(rs.LastCommit, &prs.Precommits) {
+				return true
+			}
+		} else {
+			ps.EnsureCatchupCommitRound(prs.Height, rs.LastCommit.Round())
+			if trySendVote(rs.LastCommit, &prs.CatchupCommit) {
+				return true
 			}
 		}
+	}
 
-		if sleeping == 0 {
-			// We sent nothing. Sleep...
-			sleeping = 1
-			log.Debug("No votes to send, sleeping", "peer", peer,
-				"localPV", rs.Votes.Prevotes(rs.Round).BitArray(), "peerPV", prs.Prevotes,
-				"localPC", rs.Votes.Precommits(rs.Round).BitArray(), "peerPC", prs.Precommits)
-		} else if sleeping == 2 {
-			// Continued sleep...
-			sleeping = 1
+	// Catchup logic
+	// If peer is lagging by more than 1, send Validation.
+	if prs.Height != 0 && prs.Height <= rs.Height-2 {
+		// Load the block validation for prs.Height,
+		// which contains precommit signatures for prs.Height.
+		validation := conR.blockStore.LoadBlockValidation(prs.Height)
+		log.Debug("Loaded BlockValidation for catch-up", "height", prs.Height, "validation", validation)
+		ps.EnsureCatchupCommitRound(prs.Height, validation.Round())
+		if trySendPrecommitFromValidation(validation, &prs.CatchupCommit) {
+			return true
 		}
-
-		time.Sleep(peerGossipSleepDuration)
-		continue OUTER_LOOP
 	}
+
+	return false
+}
+
+// A rand.Source that is safe for concurrent use, like the one behind
+// the top-level functions of math/rand.
+type lockedSource struct {
+	mtx sync.Mutex
+	src rand.Source
+}
+
+func (r *lockedSource) Int63() int64 {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return r.src.Int63()
+}
+
+func (r *lockedSource) Seed(seed int64) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.src.Seed(seed)
 }
 
 //-----------------------------------------------------------------------------
@@ -770,6 +927,7 @@ func (ps *PeerState) ApplyNewRoundStepMessage(msg *NewRoundStepMessage, rs *Roun
 	psHeight := ps.Height
 	psRound := ps.Round
 	//psStep := ps.Step
+	psPrecommits := ps.Precommits
 	psCatchupCommitRound := ps.CatchupCommitRound
 	psCatchupCommit := ps.CatchupCommit
 
@@ -799,7 +957,7 @@ func (ps *PeerState) ApplyNewRoundStepMessage(msg *NewRoundStepMessage, rs *Roun
 		// Shift Precommits to LastCommit.
 		if psHeight+1 == msg.Height && psRound == msg.LastCommitRound {
 			ps.LastCommitRound = msg.LastCommitRound
-			ps.LastCommit = ps.Precommits
+			ps.LastCommit = psPrecommits
 		} else {
 			ps.LastCommitRound = msg.LastCommitRound
 			ps.LastCommit = nil
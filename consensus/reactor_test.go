@@ -0,0 +1,44 @@
+package consensus
+
+import (
+	"math/rand"
+	"testing"
+
+	_ "github.com/tendermint/tendermint/config/tendermint_test"
+	"github.com/tendermint/tendermint/p2p"
+	"github.com/tendermint/tendermint/types"
+)
+
+// The precommits a peer announced are kept as its LastCommit when it
+// moves to the next height, so that gossip doesn't send them again.
+func TestPeerStateLastCommit(t *testing.T) {
+	height, round := 1, 0
+	voteSet, valSet, privValidators := randVoteSet(height, round, types.VoteTypePrecommit, 4, 1)
+	for _, privVal := range privValidators {
+		vote := &types.Vote{Height: height, Round: round, Type: types.VoteTypePrecommit, BlockHash: nil}
+		signAddVote(privVal, vote, voteSet)
+	}
+
+	ps := NewPeerState(&p2p.Peer{Key: "peer"})
+	ps.ApplyNewRoundStepMessage(&NewRoundStepMessage{Height: height, Round: round, Step: RoundStepPrecommit, LastCommitRound: -1}, nil)
+	ps.EnsureVoteBitArrays(height, valSet.Size(), nil)
+	for index := 0; index < 3; index++ {
+		ps.SetHasVote(voteSet.GetByIndex(index), index)
+	}
+	ps.ApplyNewRoundStepMessage(&NewRoundStepMessage{Height: height + 1, Round: 0, Step: RoundStepNewHeight, LastCommitRound: round}, nil)
+
+	// Like gossipVotes, which sends the peer our LastCommit.
+	prs := ps.GetRoundState()
+	ps.EnsureVoteBitArrays(height, valSet.Size(), prs)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 10; i++ {
+		_, index, ok := voteSet.PickVoteToSend(prs.LastCommit, r)
+		if !ok || index != 3 {
+			t.Fatalf("Expected to send only the precommit at 3, got %v (%v)", index, ok)
+		}
+	}
+	prs.LastCommit.SetIndex(3, true)
+	if _, _, ok := voteSet.PickVoteToSend(prs.LastCommit, r); ok {
+		t.Errorf("Expected no precommits to send to a peer with all of them")
+	}
+}
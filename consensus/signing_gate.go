@@ -0,0 +1,64 @@
+package consensus
+
+import (
+	"github.com/tendermint/tendermint/p2p"
+)
+
+// SigningGate keeps a validator from signing prevotes and precommits
+// while it has fewer than MinPeers peers or is missing one of its
+// sentries, so that an isolated validator doesn't vote on a minority
+// partition. Sentries are peer keys, i.e. IPs, or IP:port with
+// allow_same_ip_peers.
+type SigningGate struct {
+	sw       *p2p.Switch
+	minPeers int
+	sentries []string
+}
+
+func NewSigningGate(sw *p2p.Switch, minPeers int, sentries []string) *SigningGate {
+	return &SigningGate{
+		sw:       sw,
+		minPeers: minPeers,
+		sentries: sentries,
+	}
+}
+
+type SigningGateStatus struct {
+	MinPeers        int      `json:"min_peers"`
+	Peers           int      `json:"peers"`
+	MissingSentries []string `json:"missing_sentries"`
+	CanSign         bool     `json:"can_sign"`
+}
+
+func (sg *SigningGate) Status() *SigningGateStatus {
+	peers := sg.sw.Peers()
+	status := &SigningGateStatus{
+		MinPeers:        sg.minPeers,
+		Peers:           peers.Size(),
+		MissingSentries: []string{},
+	}
+	for _, sentry := range sg.sentries {
+		if !peers.Has(sentry) {
+			status.MissingSentries = append(status.MissingSentries, sentry)
+		}
+	}
+	status.CanSign = status.Peers >= sg.minPeers && len(status.MissingSentries) == 0
+	return status
+}
+
+func (cs *ConsensusState) SetSigningGate(gate *SigningGate) {
+	cs.mtx.Lock()
+	defer cs.mtx.Unlock()
+	cs.signingGate = gate
+}
+
+// Returns nil if there is no signing gate.
+func (cs *ConsensusState) GetSigningGateStatus() *SigningGateStatus {
+	cs.mtx.Lock()
+	gate := cs.signingGate
+	cs.mtx.Unlock()
+	if gate == nil {
+		return nil
+	}
+	return gate.Status()
+}
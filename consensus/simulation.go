@@ -0,0 +1,667 @@
+package consensus
+
+import (
+	"bytes"
+	"container/heap"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	acm "github.com/tendermint/tendermint/account"
+	bc "github.com/tendermint/tendermint/blockchain"
+	. "github.com/tendermint/tendermint/common"
+	dbm "github.com/tendermint/tendermint/db"
+	"github.com/tendermint/tendermint/events"
+	mempl "github.com/tendermint/tendermint/mempool"
+	"github.com/tendermint/tendermint/p2p"
+	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+)
+
+const (
+	simDefaultBalance = 1000
+	simReconnectDelay = time.Second // Time before a broken connection is reconnected.
+)
+
+var (
+	ErrSimTimeout = errors.New("Error simulation timed out")
+
+	simGenesisTime = time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+/*
+Simulation runs a network of in-process validators in simulated time.
+Each validator is a node with its own Switch, ConsensusReactor,
+MempoolReactor, ConsensusState and BlockStore, as in a real node, and the
+switches are connected to each other with in-memory connections (see
+p2p.MemConnection). The simulation can delay or drop the messages on
+each connection. Like a TCP connection, a connection breaks when one of
+its messages is lost, and the validators reconnect after
+simReconnectDelay. A partition disconnects validators in different
+groups, and healing reconnects them, as happens with real network
+partitions. Messages still in flight when validators are disconnected
+are lost.
+
+A simulation is reproducible: the validator keys are derived from its
+seed, drop and delay decisions are drawn from a random source per link
+seeded from it, and so are the parts and votes that each reactor picks
+to gossip. The timeouts of the ConsensusStates, the gossip of the
+reactors and the message deliveries run on the simulation's clock, one
+at a time, in order of simulated time, in the goroutine that calls
+RunToHeight. The clock jumps to the next one instead of sleeping.
+
+A scenario is a list of events, one per line:
+
+	# comments start with #
+	delay 10ms 50ms                         (uniform random delay per message)
+	drop 0.1                                (drop probability per message, see above)
+	partition validator 3 at height 5 round 1
+	partition validators 0,1 at height 8    (0,1 vs the rest)
+	heal at height 10                       (reconnect all validators)
+
+An event without "at" happens on start. Otherwise it happens once any
+validator reaches the given height and round (round defaults to 0).
+Events happen in the order they are listed.
+*/
+type Simulation struct {
+	Nodes []*SimNode
+
+	clock     *simClock
+	dropRate  float64
+	minDelay  time.Duration
+	maxDelay  time.Duration
+	partition []int        // partition[i] is the group of node i.
+	links     [][]*simLink // links[src][dst], nil if src == dst
+	scenario  []*SimEvent
+	running   bool
+}
+
+type SimNode struct {
+	Index         int
+	PrivValidator *sm.PrivValidator
+	BlockStore    *bc.BlockStore
+	State         *ConsensusState
+	Reactor       *ConsensusReactor
+	Switch        *p2p.Switch
+	nodeInfo      *types.NodeInfo
+	privValFile   string
+}
+
+// Creates a simulation of numValidators validators with equal voting power.
+// Nodes[i] is the validator at index i in the genesis validator set.
+// Simulations with the same seed and scenario commit the same blocks.
+func NewSimulation(numValidators int, seed int64) *Simulation {
+	genDoc, privValidators := simGenesisDoc(numValidators, seed)
+	sim := &Simulation{
+		Nodes:     make([]*SimNode, numValidators),
+		clock:     newSimClock(genDoc.GenesisTime),
+		partition: make([]int, numValidators),
+		links:     make([][]*simLink, numValidators),
+	}
+	for i, privVal := range privValidators {
+		_, privValFile := sm.Tempfile("priv_validator_")
+		privVal.SetFile(privValFile)
+		state := sm.MakeGenesisState(dbm.NewMemDB(), genDoc)
+		state.Save()
+		blockStore := bc.NewBlockStore(dbm.NewMemDB())
+		mempoolReactor := mempl.NewMempoolReactor(mempl.NewMempool(state.Copy()))
+		conS := NewConsensusState(state, blockStore, mempoolReactor)
+		conS.SetClock(sim.clock)
+		conS.SetPrivValidator(privVal)
+		conR := NewConsensusReactor(conS, blockStore, false)
+		conR.clock = sim.clock
+		// Seeds past those of the links.
+		conR.rand = rand.New(rand.NewSource(seed + int64(numValidators*numValidators+i)))
+		// Not stopped, since saveBlock may still fire events after Stop.
+		evsw := new(events.EventSwitch)
+		evsw.Start()
+		conR.SetFireable(evsw)
+
+		nodeInfo := simNodeInfo(i, genDoc.ChainID)
+		sw := p2p.NewSwitch()
+		sw.SetNodeInfo(nodeInfo)
+		sw.AddReactor("MEMPOOL", mempoolReactor)
+		sw.AddReactor("CONSENSUS", conR)
+
+		sim.Nodes[i] = &SimNode{
+			Index:         i,
+			PrivValidator: privVal,
+			BlockStore:    blockStore,
+			State:         conS,
+			Reactor:       conR,
+			Switch:        sw,
+			nodeInfo:      nodeInfo,
+			privValFile:   privValFile,
+		}
+	}
+	for src := range sim.Nodes {
+		sim.links[src] = make([]*simLink, numValidators)
+		for dst := range sim.Nodes {
+			if src != dst {
+				sim.links[src][dst] = &simLink{
+					rand: rand.New(rand.NewSource(seed + int64(src*numValidators+dst))),
+				}
+			}
+		}
+	}
+	return sim
+}
+
+// Like sm.RandGenesisDoc, with keys derived from seed.
+func simGenesisDoc(numValidators int, seed int64) (*sm.GenesisDoc, []*sm.PrivValidator) {
+	privValidators := make([]*sm.PrivValidator, numValidators)
+	for i := range privValidators {
+		privAccount := acm.GenPrivAccountFromSecret([]byte(Fmt("simulation %v validator %v", seed, i)))
+		privValidators[i] = &sm.PrivValidator{
+			Address: privAccount.Address,
+			PubKey:  privAccount.PubKey.(acm.PubKeyEd25519),
+			PrivKey: privAccount.PrivKey.(acm.PrivKeyEd25519),
+		}
+	}
+	sort.Sort(sm.PrivValidatorsByAddress(privValidators))
+	genDoc := &sm.GenesisDoc{
+		Version:     sm.GenesisVersion,
+		GenesisTime: simGenesisTime,
+		ChainID:     "tendermint_test",
+	}
+	for _, privVal := range privValidators {
+		genDoc.Accounts = append(genDoc.Accounts, sm.GenesisAccount{
+			Address: privVal.Address,
+			Amount:  simDefaultBalance,
+		})
+		genDoc.Validators = append(genDoc.Validators, sm.GenesisValidator{
+			PubKey: privVal.PubKey,
+			Amount: simDefaultBalance,
+			UnbondTo: []sm.GenesisAccount{
+				{
+					Address: privVal.Address,
+					Amount:  simDefaultBalance,
+				},
+			},
+		})
+	}
+	return genDoc, privValidators
+}
+
+// The Host is the peer's key in the other switches.
+func simNodeInfo(index int, chainID string) *types.NodeInfo {
+	return &types.NodeInfo{
+		Moniker:  Fmt("sim%v", index),
+		ChainID:  chainID,
+		Host:     Fmt("sim%v", index),
+		Protocol: types.CurrentProtocolVersion(),
+	}
+}
+
+// Starts all nodes, connects them, and applies the scenario events
+// without a height. Nothing happens until RunToHeight.
+func (sim *Simulation) Start() {
+	if sim.running {
+		return
+	}
+	sim.running = true
+	for _, node := range sim.Nodes {
+		node.Switch.Start()
+	}
+	sim.reconnect()
+	sim.step()
+}
+
+func (sim *Simulation) Stop() {
+	if !sim.running {
+		return
+	}
+	sim.running = false
+	for _, node := range sim.Nodes {
+		node.Switch.Stop()
+		os.Remove(node.privValFile)
+	}
+}
+
+// Runs the simulation until every node has committed the block at height,
+// or until timeout has passed in simulated time.
+func (sim *Simulation) RunToHeight(height int, timeout time.Duration) error {
+	deadline := sim.clock.Now().Add(timeout)
+	for !sim.hasHeight(height) {
+		if !sim.clock.runNext(deadline) {
+			heights := make([]int, len(sim.Nodes))
+			for i, node := range sim.Nodes {
+				heights[i] = node.BlockStore.Height()
+			}
+			return fmt.Errorf("%v waiting for height %v. Heights: %v", ErrSimTimeout, height, heights)
+		}
+		sim.step()
+	}
+	return nil
+}
+
+func (sim *Simulation) hasHeight(height int) bool {
+	for _, node := range sim.Nodes {
+		if node.BlockStore.Height() < height {
+			return false
+		}
+	}
+	return true
+}
+
+// The simulated time.
+func (sim *Simulation) Now() time.Time {
+	return sim.clock.Now()
+}
+
+// Runs after each timeout, gossip or delivery, which may have changed any node.
+func (sim *Simulation) step() {
+	for _, node := range sim.Nodes {
+		node.Reactor.broadcastPending()
+	}
+	sim.applyEvents()
+}
+
+//-----------------------------------------------------------------------------
+// Network
+
+// Disconnects the given validators from the rest.
+// Any previous partition is replaced.
+func (sim *Simulation) Partition(isolated ...int) {
+	for i := range sim.partition {
+		sim.partition[i] = 0
+	}
+	for _, i := range isolated {
+		sim.partition[i] = 1
+	}
+	if sim.running {
+		sim.reconnect()
+	}
+}
+
+func (sim *Simulation) Heal() {
+	sim.Partition()
+}
+
+// Sets the probability that a message is dropped.
+func (sim *Simulation) SetDropRate(dropRate float64) {
+	sim.dropRate = dropRate
+}
+
+// Each message is delayed by a uniformly random duration in [minDelay, maxDelay].
+// Messages on the same link are still delivered in order.
+func (sim *Simulation) SetDelay(minDelay, maxDelay time.Duration) {
+	sim.minDelay = minDelay
+	sim.maxDelay = maxDelay
+}
+
+func (sim *Simulation) connected(i, j int) bool {
+	return i != j && sim.partition[i] == sim.partition[j]
+}
+
+type simLink struct {
+	rand     *rand.Rand
+	lastTime time.Time          // Delivery time of the last message.
+	peer     *p2p.Peer          // The peer dst in the switch of src, nil if disconnected.
+	conn     *p2p.MemConnection // peer's connection, which receives messages from dst.
+	epoch    int                // Incremented on connect and disconnect.
+}
+
+// Connects and disconnects the switches to match the partition.
+func (sim *Simulation) reconnect() {
+	for i := range sim.Nodes {
+		for j := i + 1; j < len(sim.Nodes); j++ {
+			isConnected := sim.links[i][j].peer != nil
+			if sim.connected(i, j) && !isConnected {
+				sim.connect(i, j)
+			} else if !sim.connected(i, j) && isConnected {
+				sim.disconnect(i, j)
+			}
+		}
+	}
+}
+
+func (sim *Simulation) connect(i, j int) {
+	sim.links[i][j].epoch++
+	sim.links[j][i].epoch++
+	sim.addPeer(i, j, true)
+	sim.addPeer(j, i, false)
+}
+
+// Adds dst as a peer of src.
+func (sim *Simulation) addPeer(src, dst int, outbound bool) {
+	link := sim.links[src][dst]
+	epoch := link.epoch
+	peer, conn, err := sim.Nodes[src].Switch.AddMemPeer(sim.Nodes[dst].nodeInfo, outbound,
+		func(chId byte, msgBytes []byte) {
+			sim.send(src, dst, epoch, chId, msgBytes)
+		})
+	if err != nil {
+		// SANITY CHECK
+		panic(Fmt("Simulation failed to connect %v to %v: %v", src, dst, err))
+	}
+	link.peer, link.conn = peer, conn
+}
+
+func (sim *Simulation) disconnect(i, j int) {
+	for _, pair := range [][2]int{{i, j}, {j, i}} {
+		link := sim.links[pair[0]][pair[1]]
+		link.epoch++
+		sim.Nodes[pair[0]].Switch.StopPeerForError(link.peer, "Simulated disconnect")
+		link.peer, link.conn = nil, nil
+	}
+}
+
+// Delivers msgBytes from src to dst later, unless src and dst were
+// disconnected since the message was sent in epoch.
+// Messages on a link are delivered in order.
+func (sim *Simulation) send(src, dst int, epoch int, chId byte, msgBytes []byte) {
+	link := sim.links[src][dst]
+	if link.epoch != epoch {
+		return
+	}
+	if sim.dropRate > 0 && link.rand.Float64() < sim.dropRate {
+		sim.breakConnection(src, dst)
+		return
+	}
+	delay := sim.minDelay
+	if sim.maxDelay > sim.minDelay {
+		delay += time.Duration(link.rand.Int63n(int64(sim.maxDelay - sim.minDelay + 1)))
+	}
+	now := sim.clock.Now()
+	deliverTime := now.Add(delay)
+	if deliverTime.Before(link.lastTime) {
+		deliverTime = link.lastTime
+	}
+	link.lastTime = deliverTime
+
+	sim.clock.AfterFunc(deliverTime.Sub(now), func() {
+		if link.epoch == epoch {
+			sim.deliver(src, dst, chId, msgBytes)
+		}
+	})
+}
+
+// Loses the messages in flight between src and dst, then disconnects
+// and later reconnects them. Called from sends, so the switches are
+// changed in later functions on the clock.
+func (sim *Simulation) breakConnection(src, dst int) {
+	sim.links[src][dst].epoch++
+	sim.links[dst][src].epoch++
+	epoch := sim.links[src][dst].epoch
+	sim.clock.AfterFunc(0, func() {
+		if sim.links[src][dst].epoch != epoch {
+			return // Already disconnected.
+		}
+		sim.disconnect(src, dst)
+		sim.clock.AfterFunc(simReconnectDelay, func() {
+			if sim.running {
+				sim.reconnect()
+			}
+		})
+	})
+}
+
+// Passes msgBytes from src to the reactor of chId in dst, if connected.
+func (sim *Simulation) deliver(src, dst int, chId byte, msgBytes []byte) {
+	if conn := sim.links[dst][src].conn; conn != nil {
+		conn.Receive(chId, msgBytes)
+	}
+}
+
+//-----------------------------------------------------------------------------
+// Clock
+
+// Implements Clock in simulated time. Functions run one at a time, in
+// order of their time and then of the AfterFunc calls, when runNext is
+// called.
+type simClock struct {
+	mtx   sync.Mutex
+	now   time.Time
+	seq   int
+	tasks simTasks
+}
+
+type simTask struct {
+	time time.Time
+	seq  int
+	f    func()
+}
+
+func newSimClock(now time.Time) *simClock {
+	return &simClock{now: now}
+}
+
+func (clock *simClock) Now() time.Time {
+	clock.mtx.Lock()
+	defer clock.mtx.Unlock()
+	return clock.now
+}
+
+func (clock *simClock) AfterFunc(d time.Duration, f func()) {
+	clock.mtx.Lock()
+	defer clock.mtx.Unlock()
+	if d < 0 {
+		d = 0
+	}
+	heap.Push(&clock.tasks, &simTask{clock.now.Add(d), clock.seq, f})
+	clock.seq++
+}
+
+// Advances the time to that of the next function and runs it.
+// Returns false if there is none until deadline.
+func (clock *simClock) runNext(deadline time.Time) bool {
+	clock.mtx.Lock()
+	if len(clock.tasks) == 0 || clock.tasks[0].time.After(deadline) {
+		clock.mtx.Unlock()
+		return false
+	}
+	task := heap.Pop(&clock.tasks).(*simTask)
+	clock.now = task.time
+	clock.mtx.Unlock()
+
+	task.f()
+	return true
+}
+
+// Implements heap.Interface
+type simTasks []*simTask
+
+func (tasks simTasks) Len() int { return len(tasks) }
+
+func (tasks simTasks) Less(i, j int) bool {
+	if tasks[i].time.Equal(tasks[j].time) {
+		return tasks[i].seq < tasks[j].seq
+	}
+	return tasks[i].time.Before(tasks[j].time)
+}
+
+func (tasks simTasks) Swap(i, j int) { tasks[i], tasks[j] = tasks[j], tasks[i] }
+
+func (tasks *simTasks) Push(x interface{}) { *tasks = append(*tasks, x.(*simTask)) }
+
+func (tasks *simTasks) Pop() interface{} {
+	old := *tasks
+	task := old[len(old)-1]
+	*tasks = old[:len(old)-1]
+	return task
+}
+
+//-----------------------------------------------------------------------------
+// Scenarios
+
+type SimEvent struct {
+	Height int
+	Round  int
+	Line   string
+	apply  func(sim *Simulation)
+}
+
+// Parses a scenario and adds its events to the simulation.
+// Must be called before Start.
+func (sim *Simulation) LoadScenario(scenario string) error {
+	for i, line := range strings.Split(scenario, "\n") {
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		event, err := sim.parseEvent(line)
+		if err != nil {
+			return fmt.Errorf("Error in scenario line %v: %v", i+1, err)
+		}
+		sim.scenario = append(sim.scenario, event)
+	}
+	return nil
+}
+
+func (sim *Simulation) parseEvent(line string) (*SimEvent, error) {
+	event := &SimEvent{Line: line}
+	fields := strings.Fields(line)
+	for i, field := range fields {
+		if field == "at" {
+			if err := parseSimTrigger(event, fields[i+1:]); err != nil {
+				return nil, err
+			}
+			fields = fields[:i]
+			break
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil, errors.New("Missing action")
+	}
+	args := fields[1:]
+	switch fields[0] {
+	case "partition":
+		if len(args) < 2 || (args[0] != "validator" && args[0] != "validators") {
+			return nil, errors.New("Expected \"partition validators <index>,...\"")
+		}
+		var isolated []int
+		for _, arg := range args[1:] {
+			for _, s := range strings.Split(arg, ",") {
+				if s == "" {
+					continue
+				}
+				index, err := strconv.Atoi(s)
+				if err != nil || index < 0 || index >= len(sim.Nodes) {
+					return nil, fmt.Errorf("Invalid validator index %v", s)
+				}
+				isolated = append(isolated, index)
+			}
+		}
+		event.apply = func(sim *Simulation) { sim.Partition(isolated...) }
+	case "heal":
+		if len(args) != 0 {
+			return nil, errors.New("Expected \"heal\"")
+		}
+		event.apply = func(sim *Simulation) { sim.Heal() }
+	case "drop":
+		if len(args) != 1 {
+			return nil, errors.New("Expected \"drop <rate>\"")
+		}
+		dropRate, err := strconv.ParseFloat(args[0], 64)
+		if err != nil || dropRate < 0 || dropRate > 1 {
+			return nil, fmt.Errorf("Invalid drop rate %v", args[0])
+		}
+		event.apply = func(sim *Simulation) { sim.SetDropRate(dropRate) }
+	case "delay":
+		if len(args) != 1 && len(args) != 2 {
+			return nil, errors.New("Expected \"delay <min> [<max>]\"")
+		}
+		minDelay, err := time.ParseDuration(args[0])
+		if err != nil {
+			return nil, err
+		}
+		maxDelay := minDelay
+		if len(args) == 2 {
+			if maxDelay, err = time.ParseDuration(args[1]); err != nil {
+				return nil, err
+			}
+		}
+		if minDelay < 0 || maxDelay < minDelay {
+			return nil, fmt.Errorf("Invalid delay range %v %v", minDelay, maxDelay)
+		}
+		event.apply = func(sim *Simulation) { sim.SetDelay(minDelay, maxDelay) }
+	default:
+		return nil, fmt.Errorf("Unknown action %v", fields[0])
+	}
+	return event, nil
+}
+
+// Parses "height <H> [round <R>]".
+func parseSimTrigger(event *SimEvent, fields []string) (err error) {
+	if len(fields) != 2 && len(fields) != 4 {
+		return errors.New("Expected \"at height <height> [round <round>]\"")
+	}
+	if fields[0] != "height" {
+		return fmt.Errorf("Expected \"height\", got %v", fields[0])
+	}
+	if event.Height, err = strconv.Atoi(fields[1]); err != nil || event.Height < 1 {
+		return fmt.Errorf("Invalid height %v", fields[1])
+	}
+	if len(fields) == 4 {
+		if fields[2] != "round" {
+			return fmt.Errorf("Expected \"round\", got %v", fields[2])
+		}
+		if event.Round, err = strconv.Atoi(fields[3]); err != nil || event.Round < 0 {
+			return fmt.Errorf("Invalid round %v", fields[3])
+		}
+	}
+	return nil
+}
+
+// Applies the events whose height and round some node reached.
+func (sim *Simulation) applyEvents() {
+	height, round := sim.maxHeightRound()
+	for len(sim.scenario) > 0 {
+		event := sim.scenario[0]
+		if event.Height > height || (event.Height == height && event.Round > round) {
+			return
+		}
+		log.Info("Simulation event", "height", height, "round", round, "event", event.Line)
+		event.apply(sim)
+		sim.scenario = sim.scenario[1:]
+	}
+}
+
+// Returns the highest height/round among all nodes.
+func (sim *Simulation) maxHeightRound() (height int, round int) {
+	for _, node := range sim.Nodes {
+		rs := node.State.GetRoundState()
+		if rs.Height > height || (rs.Height == height && rs.Round > round) {
+			height, round = rs.Height, rs.Round
+		}
+	}
+	return
+}
+
+//-----------------------------------------------------------------------------
+// Checks
+
+// Checks that no two nodes committed different blocks at the same height.
+func (sim *Simulation) CheckAgreement() error {
+	for height := 1; ; height++ {
+		var hash []byte
+		var found bool
+		for _, node := range sim.Nodes {
+			if node.BlockStore.Height() < height {
+				continue
+			}
+			found = true
+			meta := node.BlockStore.LoadBlockMeta(height)
+			if hash == nil {
+				hash = meta.Hash
+			} else if !bytes.Equal(hash, meta.Hash) {
+				return fmt.Errorf("Validators committed different blocks at height %v: %X vs %X",
+					height, hash, meta.Hash)
+			}
+		}
+		if !found {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package consensus
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	acm "github.com/tendermint/tendermint/account"
+	"github.com/tendermint/tendermint/binary"
+	bc "github.com/tendermint/tendermint/blockchain"
+	_ "github.com/tendermint/tendermint/config/tendermint_test"
+	dbm "github.com/tendermint/tendermint/db"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestSimulationPartition(t *testing.T) {
+	sim := NewSimulation(4, 1)
+	err := sim.LoadScenario(`
+		delay 1ms 10ms
+		partition validator 3 at height 2   # 3 of 4 can still commit
+		heal at height 4
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sim.Start()
+	defer sim.Stop()
+
+	if err := sim.RunToHeight(5, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := sim.CheckAgreement(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Runs with the same seed commit the same blocks at the same times.
+func TestSimulationReproducible(t *testing.T) {
+	run := func() (hashes [][]byte, end time.Time) {
+		sim := NewSimulation(4, 7)
+		err := sim.LoadScenario(`
+			delay 1ms 500ms
+			drop 0.1
+			partition validator 2 at height 2
+			heal at height 3
+		`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sim.Start()
+		defer sim.Stop()
+		if err := sim.RunToHeight(4, 10*time.Minute); err != nil {
+			t.Fatal(err)
+		}
+		if err := sim.CheckAgreement(); err != nil {
+			t.Fatal(err)
+		}
+		for height := 1; height <= 4; height++ {
+			hashes = append(hashes, sim.Nodes[0].BlockStore.LoadBlockMeta(height).Hash)
+		}
+		return hashes, sim.Now()
+	}
+	hashes1, end1 := run()
+	hashes2, end2 := run()
+	for i := range hashes1 {
+		if !bytes.Equal(hashes1[i], hashes2[i]) {
+			t.Errorf("Expected the same block at height %v, got %X and %X", i+1, hashes1[i], hashes2[i])
+		}
+	}
+	if !end1.Equal(end2) {
+		t.Errorf("Expected the runs to end at the same time, got %v and %v", end1, end2)
+	}
+}
+
+func TestSimulationScenarioErrors(t *testing.T) {
+	sim := NewSimulation(4, 1)
+	bad := []string{
+		"partition validator 4",
+		"partition 1",
+		"heal now",
+		"drop 2",
+		"delay 10ms 5ms",
+		"heal at round 1",
+		"heal at height 0",
+		"explode at height 3",
+		"at height 3",
+	}
+	for _, line := range bad {
+		if err := sim.LoadScenario(line); err == nil {
+			t.Errorf("Expected error for scenario %q", line)
+		}
+	}
+	if len(sim.scenario) != 0 {
+		t.Errorf("Expected no events, got %v", len(sim.scenario))
+	}
+}
+
+// Adds evidence that validator index signed conflicting prevotes
+// to the mempool of each node.
+func addSimDupeoutTx(t *testing.T, sim *Simulation, index int) *types.DupeoutTx {
+	chainID := sim.Nodes[0].State.GetState().ChainID
+	accused := sim.Nodes[index].PrivValidator
+	voteA := &types.Vote{Height: 1, Round: 0, Type: types.VoteTypePrevote, BlockHash: []byte("block_a")}
+	voteB := &types.Vote{Height: 1, Round: 0, Type: types.VoteTypePrevote, BlockHash: []byte("block_b")}
+	accused.SignVoteUnsafe(chainID, voteA)
+	accused.SignVoteUnsafe(chainID, voteB)
+	tx := &types.DupeoutTx{Address: accused.Address, VoteA: *voteA, VoteB: *voteB}
+	for _, node := range sim.Nodes {
+		if err := node.State.mempoolReactor.Mempool.AddTx(tx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return tx
+}
+
+// Evidence in the mempool is committed, and the accused validator unbonded.
+func TestSimulationDupeout(t *testing.T) {
+	sim := NewSimulation(4, 1)
+	accused := sim.Nodes[3].PrivValidator
+	tx := addSimDupeoutTx(t, sim, 3)
+	sim.Start()
+	defer sim.Stop()
+
+	if err := sim.RunToHeight(2, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := sim.CheckAgreement(); err != nil {
+		t.Fatal(err)
+	}
+	block := sim.Nodes[0].BlockStore.LoadBlock(1)
+	if len(block.Txs) != 1 || !bytes.Equal(types.TxId(block.ChainID, block.Txs[0]), types.TxId(block.ChainID, tx)) {
+		t.Fatalf("Expected the DupeoutTx in block 1, got %v", block.Txs)
+	}
+	for _, node := range sim.Nodes {
+		if _, val := node.State.GetState().BondedValidators.GetByAddress(accused.Address); val != nil {
+			t.Errorf("Expected node %v to have unbonded the accused validator", node.Index)
+		}
+	}
+}
+
+// A conflicting proposal is turned into evidence, which is committed,
+// and the proposer unbonded.
+func TestSimulationDupeProposal(t *testing.T) {
+	sim := NewSimulation(4, 1)
+	sim.Start()
+	defer sim.Stop()
+	chainID := sim.Nodes[0].State.GetState().ChainID
+	proposerIndex, _ := sim.Nodes[0].State.GetRoundState().Validators.GetByAddress(
+		sim.Nodes[0].State.GetRoundState().Validators.Proposer().Address)
+	proposer := sim.Nodes[proposerIndex].PrivValidator
+	dst := (proposerIndex + 1) % len(sim.Nodes)
+
+	// Run until another node has the proposal of height 1.
+	deadline := sim.Now().Add(time.Minute)
+	for sim.Nodes[dst].State.GetRoundState().Proposal == nil {
+		if !sim.clock.runNext(deadline) {
+			t.Fatal("Expected a proposal for height 1")
+		}
+		sim.step()
+	}
+	proposal := *sim.Nodes[dst].State.GetRoundState().Proposal
+	// A valid header, so that the reactor accepts the proposal.
+	proposal.BlockPartsHeader.Hash = bytes.Repeat([]byte{0x01}, len(proposal.BlockPartsHeader.Hash))
+	proposal.Signature = proposer.PrivKey.Sign(acm.SignBytes(chainID, &proposal)).(acm.SignatureEd25519)
+	sim.deliver(proposerIndex, dst, DataChannel, binary.BinaryBytes(&ProposalMessage{Proposal: &proposal}))
+
+	if err := sim.RunToHeight(3, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := sim.CheckAgreement(); err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for height := 1; height <= 3; height++ {
+		for _, tx := range sim.Nodes[0].BlockStore.LoadBlock(height).Txs {
+			if evidence, ok := tx.(*types.DupeProposalTx); ok && bytes.Equal(evidence.Address, proposer.Address) {
+				found = true
+			}
+		}
+	}
+	if !found {
+		t.Fatal("Expected a DupeProposalTx for the proposer to be committed")
+	}
+	for _, node := range sim.Nodes {
+		if _, val := node.State.GetState().BondedValidators.GetByAddress(proposer.Address); val != nil {
+			t.Errorf("Expected node %v to have unbonded the proposer", node.Index)
+		}
+	}
+}
+
+// Rolling back a block removes its txs from the tx index.
+func TestSimulationRollbackTxIndex(t *testing.T) {
+	sim := NewSimulation(4, 1)
+	// The accused validator is unbonded by block 1, so it may roll back.
+	node := sim.Nodes[3]
+	tx := addSimDupeoutTx(t, sim, 3)
+	txIndex := bc.NewTxIndex(dbm.NewMemDB())
+	node.State.SetTxIndex(txIndex)
+	sim.Start()
+	defer sim.Stop()
+
+	if err := sim.RunToHeight(1, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if node.BlockStore.Height() != 1 {
+		t.Fatalf("Expected node 3 at height 1, got %v", node.BlockStore.Height())
+	}
+	txId := types.TxId(node.State.GetState().ChainID, tx)
+	if txIndex.Get(txId) == nil {
+		t.Fatal("Expected the DupeoutTx to be indexed")
+	}
+	if err := node.State.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if txIndex.Get(txId) != nil {
+		t.Error("Expected the DupeoutTx to be unindexed by the rollback")
+	}
+}
+
+// The trace of a block has the arrival times of its txs, and when they were indexed.
+func TestSimulationBlockTrace(t *testing.T) {
+	sim := NewSimulation(4, 1)
+	txIndex := bc.NewTxIndex(dbm.NewMemDB())
+	sim.Nodes[0].State.SetTxIndex(txIndex)
+	tx := addSimDupeoutTx(t, sim, 3)
+	sim.Start()
+	defer sim.Stop()
+
+	if err := sim.RunToHeight(1, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	trace := sim.Nodes[0].State.GetBlockTrace(1)
+	if len(trace.TxArrivals) != 1 || trace.TxArrivals[0].IsZero() || !trace.FirstTx.Equal(trace.TxArrivals[0]) {
+		t.Errorf("Expected the arrival of the block's tx, got %v and first tx %v", trace.TxArrivals, trace.FirstTx)
+	}
+	if trace.Indexed.IsZero() || trace.Indexed.Before(trace.Saved) {
+		t.Errorf("Expected the block to be indexed after it was saved, got %v", trace.Indexed)
+	}
+	if entry := txIndex.Get(types.TxId(sim.Nodes[0].State.GetState().ChainID, tx)); entry == nil || entry.Height != 1 {
+		t.Errorf("Expected the tx to be indexed at height 1, got %v", entry)
+	}
+}
@@ -155,7 +155,6 @@ import (
 	"errors"
 	"fmt"
 	"sync"
-	"sync/atomic"
 	"time"
 
 	"github.com/tendermint/tendermint/account"
@@ -181,8 +180,32 @@ var (
 var (
 	ErrInvalidProposalSignature = errors.New("Error invalid proposal signature")
 	ErrInvalidProposalPOLRound  = errors.New("Error invalid proposal POL round")
+	ErrRollbackNotRunning       = errors.New("Error cannot roll back unless consensus is running")
+	ErrRollbackHeightMismatch   = errors.New("Error cannot roll back, block store and state heights differ")
+	ErrRollbackValidator        = errors.New("Error cannot roll back a bonded validator")
 )
 
+// The proposer signed both proposals for the same height and round.
+type ErrConflictingProposal struct {
+	Address   []byte
+	ProposalA *Proposal
+	ProposalB *Proposal
+}
+
+func (err *ErrConflictingProposal) Error() string {
+	return "Conflicting proposal signature"
+}
+
+// The evidence to slash the proposer with. There is no evidence pool, so
+// it is broadcast through the mempool like any other tx.
+func (err *ErrConflictingProposal) EvidenceTx() *types.DupeProposalTx {
+	return &types.DupeProposalTx{
+		Address:   err.Address,
+		ProposalA: err.ProposalA.Signed(),
+		ProposalB: err.ProposalB.Signed(),
+	}
+}
+
 //-----------------------------------------------------------------------------
 // RoundStepType enum type
 
@@ -285,14 +308,18 @@ func (rs *RoundState) StringShort() string {
 
 // Tracks consensus state across block heights and rounds.
 type ConsensusState struct {
-	started uint32
-	stopped uint32
-	quit    chan struct{}
+	*BaseService
 
 	blockStore     *bc.BlockStore
 	mempoolReactor *mempl.MempoolReactor
 	privValidator  *sm.PrivValidator
+	signingGate    *SigningGate    // nil unless set
+	signingMonitor *signingMonitor // nil unless set, see SetMissedSigningAlert
+	chaosVoteDelay time.Duration   // 0 unless set, see SetChaosVoteDelay
+	txIndex        *bc.TxIndex     // nil unless set
+	clock          Clock
 	newStepCh      chan *RoundState
+	ownVoteCh      chan *VoteMessage
 
 	mtx sync.Mutex
 	RoundState
@@ -302,15 +329,21 @@ type ConsensusState struct {
 
 	evsw events.Fireable
 	evc  *events.EventCache // set in stageBlock and passed into state
+
+	tracer    *blockTracer
+	partSetGC PartSetGCStats
 }
 
 func NewConsensusState(state *sm.State, blockStore *bc.BlockStore, mempoolReactor *mempl.MempoolReactor) *ConsensusState {
 	cs := &ConsensusState{
-		quit:           make(chan struct{}),
 		blockStore:     blockStore,
 		mempoolReactor: mempoolReactor,
 		newStepCh:      make(chan *RoundState, 10),
+		ownVoteCh:      make(chan *VoteMessage, 10),
+		clock:          realClock{},
+		tracer:         newBlockTracer(),
 	}
+	cs.BaseService = NewBaseService(log, "ConsensusState", cs)
 	cs.updateToState(state, true)
 	// Don't call scheduleRound0 yet.
 	// We do that upon Start().
@@ -360,34 +393,40 @@ func (cs *ConsensusState) NewStepCh() chan *RoundState {
 	return cs.newStepCh
 }
 
-func (cs *ConsensusState) Start() {
-	if atomic.CompareAndSwapUint32(&cs.started, 0, 1) {
-		log.Info("Starting ConsensusState")
-		cs.scheduleRound0(cs.Height)
-	}
+// Receives the votes we sign, so they can be pushed to peers
+// without waiting for gossip. Votes are dropped if the channel is full.
+func (cs *ConsensusState) OwnVoteCh() chan *VoteMessage {
+	return cs.ownVoteCh
+}
+
+// Implements Service
+func (cs *ConsensusState) OnStart() error {
+	cs.BaseService.OnStart()
+	cs.scheduleRound0(cs.Height)
+	return nil
 }
 
 // EnterNewRound(height, 0) at cs.StartTime.
 func (cs *ConsensusState) scheduleRound0(height int) {
-	//log.Debug("scheduleRound0", "now", time.Now(), "startTime", cs.StartTime)
-	sleepDuration := cs.StartTime.Sub(time.Now())
-	go func() {
-		if 0 < sleepDuration {
-			time.Sleep(sleepDuration)
-		}
+	//log.Debug("scheduleRound0", "now", cs.clock.Now(), "startTime", cs.StartTime)
+	sleepDuration := cs.StartTime.Sub(cs.clock.Now())
+	cs.clock.AfterFunc(sleepDuration, func() {
 		cs.EnterNewRound(height, 0)
-	}()
+	})
 }
 
-func (cs *ConsensusState) Stop() {
-	if atomic.CompareAndSwapUint32(&cs.stopped, 0, 1) {
-		log.Info("Stopping ConsensusState")
-		close(cs.quit)
-	}
+// Implements Service
+// Waits for any in-progress FinalizeCommit, which checks IsStopped() under
+// cs.mtx, so that no block is written after OnStop() returns.
+func (cs *ConsensusState) OnStop() {
+	cs.BaseService.OnStop()
+	cs.mtx.Lock()
+	cs.mtx.Unlock()
 }
 
-func (cs *ConsensusState) IsStopped() bool {
-	return atomic.LoadUint32(&cs.stopped) == 1
+// Resolves the ambiguity between RoundState.String and BaseService.String.
+func (cs *ConsensusState) String() string {
+	return cs.RoundState.String()
 }
 
 // Updates ConsensusState and increments height to match that of state.
@@ -421,7 +460,7 @@ func (cs *ConsensusState) updateToState(state *sm.State, contiguous bool) {
 		// to be gathered for the first block.
 		// And alternative solution that relies on clocks:
 		//  cs.StartTime = state.LastBlockTime.Add(timeoutCommit)
-		cs.StartTime = time.Now().Add(timeoutCommit)
+		cs.StartTime = cs.clock.Now().Add(timeoutCommit)
 	} else {
 		cs.StartTime = cs.CommitTime.Add(timeoutCommit)
 	}
@@ -434,6 +473,7 @@ func (cs *ConsensusState) updateToState(state *sm.State, contiguous bool) {
 	cs.LockedBlock = nil
 	cs.LockedBlockParts = nil
 	cs.Votes = NewHeightVoteSet(height, validators)
+	cs.Votes.SetFireable(cs.evsw)
 	cs.LastCommit = lastPrecommits
 	cs.LastValidators = state.LastBondedValidators
 
@@ -454,7 +494,7 @@ func (cs *ConsensusState) maybeRebond() {
 		Address: cs.privValidator.Address,
 		Height:  cs.Height,
 	}
-	err := cs.privValidator.SignRebondTx(cs.state.ChainID, rebondTx)
+	err := cs.privValidator.SignRebondTx(cs.state.ChainID, cs.state.TxSignVersion(), rebondTx)
 	if err == nil {
 		err := cs.mempoolReactor.BroadcastTx(rebondTx)
 		if err != nil {
@@ -475,6 +515,13 @@ func (cs *ConsensusState) SetPrivValidator(priv *sm.PrivValidator) {
 	cs.privValidator = priv
 }
 
+// Committed blocks are indexed in txIndex.
+func (cs *ConsensusState) SetTxIndex(txIndex *bc.TxIndex) {
+	cs.mtx.Lock()
+	defer cs.mtx.Unlock()
+	cs.txIndex = txIndex
+}
+
 //-----------------------------------------------------------------------------
 
 // Enter: +2/3 precommits for nil at (height,round-1)
@@ -485,10 +532,14 @@ func (cs *ConsensusState) EnterNewRound(height int, round int) {
 	cs.mtx.Lock()
 	defer cs.mtx.Unlock()
 	if cs.Height != height || round < cs.Round || (cs.Round == round && cs.Step != RoundStepNewHeight) {
-		log.Debug(Fmt("EnterNewRound(%v/%v): Invalid args. Current step: %v/%v/%v", height, round, cs.Height, cs.Round, cs.Step))
+		log.Debug("EnterNewRound: Invalid args", "height", height, "round", round, "current", Fmt("%v/%v/%v", cs.Height, cs.Round, cs.Step))
 		return
 	}
-	if now := time.Now(); cs.StartTime.After(now) {
+	if cs.state.IsHalted(height) {
+		log.Warn("EnterNewRound: Chain halted by EmergencyHaltTx", "height", height, "round", round, "haltHeight", cs.state.HaltHeight)
+		return
+	}
+	if now := cs.clock.Now(); cs.StartTime.After(now) {
 		log.Warn("Need to set a buffer and log.Warn() here for sanity.", "startTime", cs.StartTime, "now", now)
 	}
 
@@ -505,11 +556,13 @@ func (cs *ConsensusState) EnterNewRound(height int, round int) {
 	cs.Validators = validators
 	cs.Proposal = nil
 	cs.ProposalBlock = nil
+	oldParts := cs.ProposalBlockParts
 	cs.ProposalBlockParts = nil
+	cs.releasePartSets(oldParts)
 	cs.Votes.SetRound(round + 1) // also track next round (round+1) to allow round-skipping
 
 	// Immediately go to EnterPropose.
-	go cs.EnterPropose(height, round)
+	cs.clock.AfterFunc(0, func() { cs.EnterPropose(height, round) })
 }
 
 // Enter: from NewRound(height,round).
@@ -517,7 +570,7 @@ func (cs *ConsensusState) EnterPropose(height int, round int) {
 	cs.mtx.Lock()
 	defer cs.mtx.Unlock()
 	if cs.Height != height || round < cs.Round || (cs.Round == round && RoundStepPropose <= cs.Step) {
-		log.Debug(Fmt("EnterPropose(%v/%v): Invalid args. Current step: %v/%v/%v", height, round, cs.Height, cs.Round, cs.Step))
+		log.Debug("EnterPropose: Invalid args", "height", height, "round", round, "current", Fmt("%v/%v/%v", cs.Height, cs.Round, cs.Step))
 		return
 	}
 
@@ -529,15 +582,14 @@ func (cs *ConsensusState) EnterPropose(height int, round int) {
 
 		// If we already have the proposal + POL, then goto Prevote
 		if cs.isProposalComplete() {
-			go cs.EnterPrevote(height, cs.Round)
+			cs.clock.AfterFunc(0, func() { cs.EnterPrevote(height, round) })
 		}
 	}()
 
 	// This step times out after `timeoutPropose`
-	go func() {
-		time.Sleep(timeoutPropose)
+	cs.clock.AfterFunc(timeoutPropose, func() {
 		cs.EnterPrevote(height, round)
-	}()
+	})
 
 	// Nothing more to do if we're not a validator
 	if cs.privValidator == nil {
@@ -545,9 +597,9 @@ func (cs *ConsensusState) EnterPropose(height int, round int) {
 	}
 
 	if !bytes.Equal(cs.Validators.Proposer().Address, cs.privValidator.Address) {
-		log.Debug("EnterPropose: Not our turn to propose", "proposer", cs.Validators.Proposer().Address, "privValidator", cs.privValidator)
+		log.Debug("EnterPropose: Not our turn to propose", "height", height, "round", round, "proposer", cs.Validators.Proposer().Address, "privValidator", cs.privValidator)
 	} else {
-		log.Debug("EnterPropose: Our turn to propose", "proposer", cs.Validators.Proposer().Address, "privValidator", cs.privValidator)
+		log.Debug("EnterPropose: Our turn to propose", "height", height, "round", round, "proposer", cs.Validators.Proposer().Address, "privValidator", cs.privValidator)
 		cs.decideProposal(height, round)
 	}
 }
@@ -571,11 +623,14 @@ func (cs *ConsensusState) decideProposal(height int, round int) {
 	err := cs.privValidator.SignProposal(cs.state.ChainID, proposal)
 	if err == nil {
 		log.Info("Signed and set proposal", "height", height, "round", round, "proposal", proposal)
-		log.Debug(Fmt("Signed and set proposal block: %v", block))
+		log.Debug("Signed and set proposal block", "height", height, "round", round, "block", block)
 		// Set fields
 		cs.Proposal = proposal
 		cs.ProposalBlock = block
+		oldParts := cs.ProposalBlockParts
 		cs.ProposalBlockParts = blockParts
+		cs.releasePartSets(oldParts)
+		cs.tracer.Mark(height, func(trace *BlockTrace) { markOnce(&trace.ProposalCreated) })
 	} else {
 		log.Warn("EnterPropose: Error signing proposal", "height", height, "round", round, "error", err)
 	}
@@ -612,11 +667,19 @@ func (cs *ConsensusState) createProposalBlock() (block *types.Block, blockParts
 		return
 	}
 	txs := cs.mempoolReactor.Mempool.GetProposalTxs()
+	if cs.state.BlockVersionAt(cs.Height) >= types.TxGasVersion {
+		txs = sm.TxsWithinGasLimit(txs, cs.state.BlockGasLimit)
+	}
+	blockTime, ok := cs.state.NextBlockTime(validation)
+	if !ok {
+		blockTime = cs.clock.Now()
+	}
 	block = &types.Block{
 		Header: &types.Header{
+			Version:        cs.state.BlockVersionAt(cs.Height),
 			ChainID:        cs.state.ChainID,
 			Height:         cs.Height,
-			Time:           time.Now(),
+			Time:           blockTime,
 			Fees:           0, // TODO fees
 			NumTxs:         len(txs),
 			LastBlockHash:  cs.state.LastBlockHash,
@@ -636,7 +699,11 @@ func (cs *ConsensusState) createProposalBlock() (block *types.Block, blockParts
 		return
 	}
 
-	blockParts = block.MakePartSet()
+	if block.Version >= types.ErasurePartSetVersion {
+		blockParts = block.MakeErasurePartSet(config.GetFloat64("block_part_parity_ratio"))
+	} else {
+		blockParts = block.MakePartSet()
+	}
 	return block, blockParts
 }
 
@@ -649,7 +716,7 @@ func (cs *ConsensusState) EnterPrevote(height int, round int) {
 	cs.mtx.Lock()
 	defer cs.mtx.Unlock()
 	if cs.Height != height || round < cs.Round || (cs.Round == round && RoundStepPrevote <= cs.Step) {
-		log.Debug(Fmt("EnterPrevote(%v/%v): Invalid args. Current step: %v/%v/%v", height, round, cs.Height, cs.Round, cs.Step))
+		log.Debug("EnterPrevote: Invalid args", "height", height, "round", round, "current", Fmt("%v/%v/%v", cs.Height, cs.Round, cs.Step))
 		return
 	}
 
@@ -669,14 +736,14 @@ func (cs *ConsensusState) EnterPrevote(height int, round int) {
 func (cs *ConsensusState) doPrevote(height int, round int) {
 	// If a block is locked, prevote that.
 	if cs.LockedBlock != nil {
-		log.Debug("EnterPrevote: Block was locked")
+		log.Debug("EnterPrevote: Block was locked", "height", height, "round", round)
 		cs.signAddVote(types.VoteTypePrevote, cs.LockedBlock.Hash(), cs.LockedBlockParts.Header())
 		return
 	}
 
 	// If ProposalBlock is nil, prevote nil.
 	if cs.ProposalBlock == nil {
-		log.Warn("EnterPrevote: ProposalBlock is nil")
+		log.Warn("EnterPrevote: ProposalBlock is nil", "height", height, "round", round)
 		cs.signAddVote(types.VoteTypePrevote, nil, types.PartSetHeader{})
 		return
 	}
@@ -685,7 +752,7 @@ func (cs *ConsensusState) doPrevote(height int, round int) {
 	err := cs.stageBlock(cs.ProposalBlock, cs.ProposalBlockParts)
 	if err != nil {
 		// ProposalBlock is invalid, prevote nil.
-		log.Warn("EnterPrevote: ProposalBlock is invalid", "error", err)
+		log.Warn("EnterPrevote: ProposalBlock is invalid", "height", height, "round", round, "error", err)
 		cs.signAddVote(types.VoteTypePrevote, nil, types.PartSetHeader{})
 		return
 	}
@@ -700,7 +767,7 @@ func (cs *ConsensusState) EnterPrevoteWait(height int, round int) {
 	cs.mtx.Lock()
 	defer cs.mtx.Unlock()
 	if cs.Height != height || round < cs.Round || (cs.Round == round && RoundStepPrevoteWait <= cs.Step) {
-		log.Debug(Fmt("EnterPrevoteWait(%v/%v): Invalid args. Current step: %v/%v/%v", height, round, cs.Height, cs.Round, cs.Step))
+		log.Debug("EnterPrevoteWait: Invalid args", "height", height, "round", round, "current", Fmt("%v/%v/%v", cs.Height, cs.Round, cs.Step))
 		return
 	}
 	if !cs.Votes.Prevotes(round).HasTwoThirdsAny() {
@@ -713,10 +780,9 @@ func (cs *ConsensusState) EnterPrevoteWait(height int, round int) {
 	cs.newStepCh <- cs.getRoundState()
 
 	// After `timeoutPrevote0+timeoutPrevoteDelta*round`, EnterPrecommit()
-	go func() {
-		time.Sleep(timeoutPrevote0 + timeoutPrevote0*time.Duration(round))
+	cs.clock.AfterFunc(timeoutPrevote0+timeoutPrevote0*time.Duration(round), func() {
 		cs.EnterPrecommit(height, round)
-	}()
+	})
 }
 
 // Enter: +2/3 precomits for block or nil.
@@ -729,7 +795,7 @@ func (cs *ConsensusState) EnterPrecommit(height int, round int) {
 	cs.mtx.Lock()
 	defer cs.mtx.Unlock()
 	if cs.Height != height || round < cs.Round || (cs.Round == round && RoundStepPrecommit <= cs.Step) {
-		log.Debug(Fmt("EnterPrecommit(%v/%v): Invalid args. Current step: %v/%v/%v", height, round, cs.Height, cs.Round, cs.Step))
+		log.Debug("EnterPrecommit: Invalid args", "height", height, "round", round, "current", Fmt("%v/%v/%v", cs.Height, cs.Round, cs.Step))
 		return
 	}
 
@@ -749,10 +815,10 @@ func (cs *ConsensusState) EnterPrecommit(height int, round int) {
 	// If we don't have two thirds of prevotes, just precommit locked block or nil
 	if !ok {
 		if cs.LockedBlock != nil {
-			log.Info("EnterPrecommit: No +2/3 prevotes during EnterPrecommit. Precommitting lock.")
+			log.Info("EnterPrecommit: No +2/3 prevotes during EnterPrecommit. Precommitting lock.", "height", height, "round", round)
 			cs.signAddVote(types.VoteTypePrecommit, cs.LockedBlock.Hash(), cs.LockedBlockParts.Header())
 		} else {
-			log.Info("EnterPrecommit: No +2/3 prevotes during EnterPrecommit. Precommitting nil.")
+			log.Info("EnterPrecommit: No +2/3 prevotes during EnterPrecommit. Precommitting nil.", "height", height, "round", round)
 			cs.signAddVote(types.VoteTypePrecommit, nil, types.PartSetHeader{})
 		}
 		return
@@ -761,12 +827,14 @@ func (cs *ConsensusState) EnterPrecommit(height int, round int) {
 	// +2/3 prevoted nil. Unlock and precommit nil.
 	if len(hash) == 0 {
 		if cs.LockedBlock == nil {
-			log.Info("EnterPrecommit: +2/3 prevoted for nil.")
+			log.Info("EnterPrecommit: +2/3 prevoted for nil.", "height", height, "round", round)
 		} else {
-			log.Info("EnterPrecommit: +2/3 prevoted for nil. Unlocking")
+			log.Info("EnterPrecommit: +2/3 prevoted for nil. Unlocking", "height", height, "round", round)
+			oldLockedParts := cs.LockedBlockParts
 			cs.LockedRound = 0
 			cs.LockedBlock = nil
 			cs.LockedBlockParts = nil
+			cs.releasePartSets(oldLockedParts)
 		}
 		cs.signAddVote(types.VoteTypePrecommit, nil, types.PartSetHeader{})
 		return
@@ -776,21 +844,23 @@ func (cs *ConsensusState) EnterPrecommit(height int, round int) {
 
 	// If +2/3 prevoted for already locked block, precommit it.
 	if cs.LockedBlock.HashesTo(hash) {
-		log.Info("EnterPrecommit: +2/3 prevoted locked block.")
+		log.Info("EnterPrecommit: +2/3 prevoted locked block.", "height", height, "round", round)
 		cs.signAddVote(types.VoteTypePrecommit, hash, partsHeader)
 		return
 	}
 
 	// If +2/3 prevoted for proposal block, stage and precommit it
 	if cs.ProposalBlock.HashesTo(hash) {
-		log.Info("EnterPrecommit: +2/3 prevoted proposal block.")
+		log.Info("EnterPrecommit: +2/3 prevoted proposal block.", "height", height, "round", round)
 		// Validate the block.
 		if err := cs.stageBlock(cs.ProposalBlock, cs.ProposalBlockParts); err != nil {
 			panic(Fmt("EnterPrecommit: +2/3 prevoted for an invalid block: %v", err))
 		}
+		oldLockedParts := cs.LockedBlockParts
 		cs.LockedRound = round
 		cs.LockedBlock = cs.ProposalBlock
 		cs.LockedBlockParts = cs.ProposalBlockParts
+		cs.releasePartSets(oldLockedParts)
 		cs.signAddVote(types.VoteTypePrecommit, hash, partsHeader)
 		return
 	}
@@ -801,6 +871,7 @@ func (cs *ConsensusState) EnterPrecommit(height int, round int) {
 	if cs.Votes.POLRound() < round {
 		panic(Fmt("This POLRound shold be %v but got %", round, cs.Votes.POLRound()))
 	}
+	oldParts, oldLockedParts := cs.ProposalBlockParts, cs.LockedBlockParts
 	cs.LockedRound = 0
 	cs.LockedBlock = nil
 	cs.LockedBlockParts = nil
@@ -808,6 +879,7 @@ func (cs *ConsensusState) EnterPrecommit(height int, round int) {
 		cs.ProposalBlock = nil
 		cs.ProposalBlockParts = types.NewPartSetFromHeader(partsHeader)
 	}
+	cs.releasePartSets(oldParts, oldLockedParts)
 	cs.signAddVote(types.VoteTypePrecommit, nil, types.PartSetHeader{})
 	return
 }
@@ -817,7 +889,7 @@ func (cs *ConsensusState) EnterPrecommitWait(height int, round int) {
 	cs.mtx.Lock()
 	defer cs.mtx.Unlock()
 	if cs.Height != height || round < cs.Round || (cs.Round == round && RoundStepPrecommitWait <= cs.Step) {
-		log.Debug(Fmt("EnterPrecommitWait(%v/%v): Invalid args. Current step: %v/%v/%v", height, round, cs.Height, cs.Round, cs.Step))
+		log.Debug("EnterPrecommitWait: Invalid args", "height", height, "round", round, "current", Fmt("%v/%v/%v", cs.Height, cs.Round, cs.Step))
 		return
 	}
 	if !cs.Votes.Precommits(round).HasTwoThirdsAny() {
@@ -830,14 +902,13 @@ func (cs *ConsensusState) EnterPrecommitWait(height int, round int) {
 	cs.newStepCh <- cs.getRoundState()
 
 	// After `timeoutPrecommit0+timeoutPrecommitDelta*round`, EnterNewRound()
-	go func() {
-		time.Sleep(timeoutPrecommit0 + timeoutPrecommitDelta*time.Duration(round))
+	cs.clock.AfterFunc(timeoutPrecommit0+timeoutPrecommitDelta*time.Duration(round), func() {
 		// If we have +2/3 of precommits for a particular block (or nil),
 		// we already entered commit (or the next round).
 		// So just try to transition to the next round,
 		// which is what we'd do otherwise.
 		cs.EnterNewRound(height, round+1)
-	}()
+	})
 }
 
 // Enter: +2/3 precommits for block
@@ -845,7 +916,7 @@ func (cs *ConsensusState) EnterCommit(height int) {
 	cs.mtx.Lock()
 	defer cs.mtx.Unlock()
 	if cs.Height != height || RoundStepCommit <= cs.Step {
-		log.Debug(Fmt("EnterCommit(%v): Invalid args. Current step: %v/%v/%v", height, cs.Height, cs.Round, cs.Step))
+		log.Debug("EnterCommit: Invalid args", "height", height, "current", Fmt("%v/%v/%v", cs.Height, cs.Round, cs.Step))
 		return
 	}
 
@@ -866,9 +937,12 @@ func (cs *ConsensusState) EnterCommit(height int) {
 	}
 	// END SANITY CHECK
 
+	cs.tracer.Mark(height, func(trace *BlockTrace) { markOnce(&trace.Precommit23) })
+
 	// The Locked* fields no longer matter.
 	// Move them over to ProposalBlock if they match the commit hash,
 	// otherwise they can now be cleared.
+	oldParts, oldLockedParts := cs.ProposalBlockParts, cs.LockedBlockParts
 	if cs.LockedBlock.HashesTo(hash) {
 		cs.ProposalBlock = cs.LockedBlock
 		cs.ProposalBlockParts = cs.LockedBlockParts
@@ -892,6 +966,7 @@ func (cs *ConsensusState) EnterCommit(height int) {
 			// We just need to keep waiting.
 		}
 	}
+	cs.releasePartSets(oldParts, oldLockedParts)
 }
 
 // If we have the block AND +2/3 commits for it, finalize.
@@ -909,7 +984,7 @@ func (cs *ConsensusState) tryFinalizeCommit(height int) {
 	if !cs.ProposalBlock.HashesTo(hash) {
 		return // We don't have the commit block.
 	}
-	go cs.FinalizeCommit(height)
+	cs.clock.AfterFunc(0, func() { cs.FinalizeCommit(height) })
 }
 
 // Increment height and goto RoundStepNewHeight
@@ -918,7 +993,11 @@ func (cs *ConsensusState) FinalizeCommit(height int) {
 	defer cs.mtx.Unlock()
 
 	if cs.Height != height || cs.Step != RoundStepCommit {
-		log.Debug(Fmt("FinalizeCommit(%v): Invalid args. Current step: %v/%v/%v", height, cs.Height, cs.Round, cs.Step))
+		log.Debug("FinalizeCommit: Invalid args", "height", height, "current", Fmt("%v/%v/%v", cs.Height, cs.Round, cs.Step))
+		return
+	}
+	if cs.IsStopped() {
+		log.Info("FinalizeCommit: Stopped, not saving block", "height", height)
 		return
 	}
 
@@ -939,14 +1018,15 @@ func (cs *ConsensusState) FinalizeCommit(height int) {
 	}
 	// END SANITY CHECK
 
-	log.Debug(Fmt("Finalizing commit of block: %v", cs.ProposalBlock))
+	log.Debug("Finalizing commit of block", "height", height, "block", cs.ProposalBlock)
+	cs.tracer.Mark(height, func(trace *BlockTrace) { markOnce(&trace.Commit) })
 	// We have the block, so stage/save/commit-vote.
 	cs.saveBlock(cs.ProposalBlock, cs.ProposalBlockParts, cs.Votes.Precommits(cs.Round))
 	// Increment height.
 	cs.updateToState(cs.stagedState, true)
 	// cs.StartTime is already set.
 	// Schedule Round0 to start soon.
-	go cs.scheduleRound0(height + 1)
+	cs.scheduleRound0(height + 1)
 	// If we're unbonded, broadcast RebondTx.
 	cs.maybeRebond()
 
@@ -957,6 +1037,45 @@ func (cs *ConsensusState) FinalizeCommit(height int) {
 	return
 }
 
+// Reverts the state and block store by one height, e.g. to recover from
+// a state hash divergence, and restarts consensus at the reverted height
+// so that the block is fetched and executed again.
+// Only for nodes that aren't validators: the privValidator won't sign
+// again at heights it has already signed, so a bonded validator would
+// stall at the reverted height. Returns ErrRollbackValidator for them.
+func (cs *ConsensusState) Rollback() error {
+	cs.mtx.Lock()
+	defer cs.mtx.Unlock()
+
+	if cs.privValidator != nil && cs.Validators.HasAddress(cs.privValidator.Address) {
+		return ErrRollbackValidator
+	}
+	if !cs.IsRunning() {
+		return ErrRollbackNotRunning
+	}
+	if cs.blockStore.Height() != cs.state.LastBlockHeight {
+		return ErrRollbackHeightMismatch
+	}
+	block := cs.blockStore.LoadBlock(cs.blockStore.Height())
+	state, err := sm.RollbackState(cs.state.DB)
+	if err != nil {
+		return err
+	}
+	cs.blockStore.RollbackBlock()
+	if cs.txIndex != nil {
+		cs.txIndex.UnindexBlock(block)
+	}
+	log.Warn("Rolled back one block", "height", state.LastBlockHeight)
+
+	oldParts, oldLockedParts := cs.ProposalBlockParts, cs.LockedBlockParts
+	cs.updateToState(state, false)
+	cs.releasePartSets(oldParts, oldLockedParts)
+	cs.reconstructLastCommit(state)
+	cs.mempoolReactor.Mempool.ResetToState(state)
+	cs.scheduleRound0(cs.Height)
+	return nil
+}
+
 //-----------------------------------------------------------------------------
 
 func (cs *ConsensusState) SetProposal(proposal *Proposal) error {
@@ -965,7 +1084,7 @@ func (cs *ConsensusState) SetProposal(proposal *Proposal) error {
 
 	// Already have one
 	if cs.Proposal != nil {
-		return nil
+		return cs.checkConflictingProposal(proposal)
 	}
 
 	// Does not apply
@@ -984,16 +1103,47 @@ func (cs *ConsensusState) SetProposal(proposal *Proposal) error {
 		return ErrInvalidProposalPOLRound
 	}
 
+	// Erasure coded parts must be in effect at the height.
+	if proposal.BlockPartsHeader.DataParts > 0 &&
+		cs.state.BlockVersionAt(proposal.Height) < types.ErasurePartSetVersion {
+		return ErrInvalidProposalParts
+	}
+
 	// Verify signature
 	if !cs.Validators.Proposer().PubKey.VerifyBytes(account.SignBytes(cs.state.ChainID, proposal), proposal.Signature) {
 		return ErrInvalidProposalSignature
 	}
 
 	cs.Proposal = proposal
+	oldParts := cs.ProposalBlockParts
 	cs.ProposalBlockParts = types.NewPartSetFromHeader(proposal.BlockPartsHeader)
+	cs.releasePartSets(oldParts)
+	cs.tracer.Mark(proposal.Height, func(trace *BlockTrace) { markOnce(&trace.ProposalSet) })
 	return nil
 }
 
+// Returns ErrConflictingProposal if proposal is another proposal signed
+// by the proposer of cs.Proposal.
+func (cs *ConsensusState) checkConflictingProposal(proposal *Proposal) error {
+	if proposal.Height != cs.Proposal.Height || proposal.Round != cs.Proposal.Round ||
+		proposal.Round != cs.Round {
+		return nil
+	}
+	signBytes := account.SignBytes(cs.state.ChainID, proposal)
+	if bytes.Equal(signBytes, account.SignBytes(cs.state.ChainID, cs.Proposal)) {
+		return nil
+	}
+	proposer := cs.Validators.Proposer()
+	if !proposer.PubKey.VerifyBytes(signBytes, proposal.Signature) {
+		return ErrInvalidProposalSignature
+	}
+	return &ErrConflictingProposal{
+		Address:   proposer.Address,
+		ProposalA: cs.Proposal,
+		ProposalB: proposal,
+	}
+}
+
 // NOTE: block is not necessarily valid.
 func (cs *ConsensusState) AddProposalBlockPart(height int, part *types.Part) (added bool, err error) {
 	cs.mtx.Lock()
@@ -1018,10 +1168,11 @@ func (cs *ConsensusState) AddProposalBlockPart(height int, part *types.Part) (ad
 		var n int64
 		var err error
 		cs.ProposalBlock = binary.ReadBinary(&types.Block{}, cs.ProposalBlockParts.GetReader(), &n, &err).(*types.Block)
-		log.Debug("Received complete proposal", "hash", cs.ProposalBlock.Hash())
+		log.Debug("Received complete proposal", "height", height, "hash", cs.ProposalBlock.Hash())
 		if cs.Step == RoundStepPropose && cs.isProposalComplete() {
 			// Move onto the next step
-			go cs.EnterPrevote(height, cs.Round)
+			round := cs.Round
+			cs.clock.AfterFunc(0, func() { cs.EnterPrevote(height, round) })
 		} else if cs.Step == RoundStepCommit {
 			// If we're waiting on the proposal block...
 			cs.tryFinalizeCommit(height)
@@ -1041,11 +1192,16 @@ func (cs *ConsensusState) AddVote(address []byte, vote *types.Vote, peerKey stri
 //-----------------------------------------------------------------------------
 
 func (cs *ConsensusState) addVote(address []byte, vote *types.Vote, peerKey string) (added bool, index int, err error) {
+	// Votes are in the format of the block version at their height.
+	if vote.Version != cs.state.BlockVersionAt(vote.Height) {
+		return false, 0, types.ErrVoteInvalidVersion
+	}
+
 	// A precommit for the previous height?
 	if vote.Height+1 == cs.Height && vote.Type == types.VoteTypePrecommit {
 		added, index, err = cs.LastCommit.AddByAddress(address, vote)
 		if added {
-			log.Debug(Fmt("Added to lastPrecommits: %v", cs.LastCommit.StringShort()))
+			log.Debug("Added to lastPrecommits", "height", vote.Height, "round", vote.Round, "lastPrecommits", cs.LastCommit.StringShort())
 		}
 		return
 	}
@@ -1058,7 +1214,10 @@ func (cs *ConsensusState) addVote(address []byte, vote *types.Vote, peerKey stri
 			switch vote.Type {
 			case types.VoteTypePrevote:
 				prevotes := cs.Votes.Prevotes(vote.Round)
-				log.Debug(Fmt("Added to prevotes: %v", prevotes.StringShort()))
+				log.Debug("Added to prevotes", "height", vote.Height, "round", vote.Round, "prevotes", prevotes.StringShort())
+				if prevotes.HasTwoThirdsMajority() {
+					cs.tracer.Mark(height, func(trace *BlockTrace) { markOnce(&trace.Prevote23) })
+				}
 				// First, unlock if prevotes is a valid POL.
 				// >> lockRound < POLRound <= unlockOrChangeLockRound (see spec)
 				// NOTE: If (lockRound < POLRound) but !(POLRound <= unlockOrChangeLockRound),
@@ -1067,15 +1226,17 @@ func (cs *ConsensusState) addVote(address []byte, vote *types.Vote, peerKey stri
 				if (cs.LockedBlock != nil) && (cs.LockedRound < vote.Round) && (vote.Round <= cs.Round) {
 					hash, _, ok := prevotes.TwoThirdsMajority()
 					if ok && !cs.LockedBlock.HashesTo(hash) {
-						log.Info("Unlocking because of POL.", "lockedRound", cs.LockedRound, "POLRound", vote.Round)
+						log.Info("Unlocking because of POL.", "height", height, "lockedRound", cs.LockedRound, "POLRound", vote.Round)
+						oldLockedParts := cs.LockedBlockParts
 						cs.LockedRound = 0
 						cs.LockedBlock = nil
 						cs.LockedBlockParts = nil
+						cs.releasePartSets(oldLockedParts)
 					}
 				}
 				if cs.Round <= vote.Round && prevotes.HasTwoThirdsAny() {
 					// Round-skip over to PrevoteWait or goto Precommit.
-					go func() {
+					cs.clock.AfterFunc(0, func() {
 						if cs.Round < vote.Round {
 							cs.EnterNewRound(height, vote.Round)
 						}
@@ -1085,18 +1246,19 @@ func (cs *ConsensusState) addVote(address []byte, vote *types.Vote, peerKey stri
 							cs.EnterPrevote(height, vote.Round)
 							cs.EnterPrevoteWait(height, vote.Round)
 						}
-					}()
+					})
 				} else if cs.Proposal != nil && 0 <= cs.Proposal.POLRound && cs.Proposal.POLRound == vote.Round {
 					// If the proposal is now complete, enter prevote of cs.Round.
 					if cs.isProposalComplete() {
-						go cs.EnterPrevote(height, cs.Round)
+						round := cs.Round
+						cs.clock.AfterFunc(0, func() { cs.EnterPrevote(height, round) })
 					}
 				}
 			case types.VoteTypePrecommit:
 				precommits := cs.Votes.Precommits(vote.Round)
-				log.Debug(Fmt("Added to precommit: %v", precommits.StringShort()))
+				log.Debug("Added to precommits", "height", vote.Height, "round", vote.Round, "precommits", precommits.StringShort())
 				if cs.Round <= vote.Round && precommits.HasTwoThirdsAny() {
-					go func() {
+					cs.clock.AfterFunc(0, func() {
 						hash, _, ok := precommits.TwoThirdsMajority()
 						if ok && len(hash) == 0 {
 							cs.EnterNewRound(height, vote.Round+1)
@@ -1110,7 +1272,7 @@ func (cs *ConsensusState) addVote(address []byte, vote *types.Vote, peerKey stri
 							cs.EnterPrecommit(height, vote.Round)
 							cs.EnterPrecommitWait(height, vote.Round)
 						}
-					}()
+					})
 				}
 			default:
 				panic(Fmt("Unexpected vote type %X", vote.Type)) // Should not happen.
@@ -1152,21 +1314,53 @@ func (cs *ConsensusState) stageBlock(block *types.Block, blockParts *types.PartS
 	}
 }
 
+// Vote timestamps are after the block being voted on, so that the
+// median time of the next block is after it too.
+// See State.NextBlockTime.
+func (cs *ConsensusState) voteTime() time.Time {
+	now := cs.clock.Now()
+	minTime := cs.state.LastBlockTime
+	if cs.LockedBlock != nil {
+		minTime = cs.LockedBlock.Time
+	} else if cs.ProposalBlock != nil {
+		minTime = cs.ProposalBlock.Time
+	}
+	minTime = minTime.Add(time.Millisecond)
+	if now.After(minTime) {
+		return now
+	}
+	return minTime
+}
+
 func (cs *ConsensusState) signAddVote(type_ byte, hash []byte, header types.PartSetHeader) *types.Vote {
 	if cs.privValidator == nil || !cs.Validators.HasAddress(cs.privValidator.Address) {
 		return nil
 	}
+	if cs.signingGate != nil {
+		if status := cs.signingGate.Status(); !status.CanSign {
+			log.Warn("Not signing vote, too few peers", "height", cs.Height, "round", cs.Round,
+				"peers", status.Peers, "minPeers", status.MinPeers, "missingSentries", status.MissingSentries)
+			return nil
+		}
+	}
 	vote := &types.Vote{
+		Version:    cs.state.BlockVersionAt(cs.Height),
 		Height:     cs.Height,
 		Round:      cs.Round,
 		Type:       type_,
 		BlockHash:  hash,
 		BlockParts: header,
 	}
+	if vote.Version >= types.VoteTimestampVersion {
+		vote.Timestamp = cs.voteTime().UnixNano()
+	}
 	err := cs.privValidator.SignVote(cs.state.ChainID, vote)
 	if err == nil {
-		_, _, err := cs.addVote(cs.privValidator.Address, vote, "")
-		log.Info("Signed and added vote", "height", cs.Height, "round", cs.Round, "vote", vote, "error", err)
+		if cs.chaosVoteDelay > 0 {
+			cs.addOwnVoteLater(vote)
+		} else {
+			cs.addOwnVote(vote)
+		}
 		return vote
 	} else {
 		log.Warn("Error signing vote", "height", cs.Height, "round", cs.Round, "vote", vote, "error", err)
@@ -1174,6 +1368,20 @@ func (cs *ConsensusState) signAddVote(type_ byte, hash []byte, header types.Part
 	}
 }
 
+func (cs *ConsensusState) addOwnVote(vote *types.Vote) {
+	_, _, err := cs.addVote(cs.privValidator.Address, vote, "")
+	log.Info("Signed and added vote", "height", cs.Height, "round", cs.Round, "vote", vote, "error", err)
+	// A delayed vote from an earlier height is left to gossip.
+	if err == nil && vote.Height == cs.Height {
+		index, _ := cs.Validators.GetByAddress(cs.privValidator.Address)
+		select {
+		case cs.ownVoteCh <- &VoteMessage{ValidatorIndex: index, Vote: vote}:
+		default:
+			// Gossip will deliver it.
+		}
+	}
+}
+
 // Save Block, save the +2/3 Commits we've seen
 func (cs *ConsensusState) saveBlock(block *types.Block, blockParts *types.PartSet, commits *VoteSet) {
 
@@ -1188,21 +1396,69 @@ func (cs *ConsensusState) saveBlock(block *types.Block, blockParts *types.PartSe
 		cs.blockStore.SaveBlock(block, blockParts, seenValidation)
 	}
 
+	// Only blocks committed while the validator is bonded count.
+	if cs.signingMonitor != nil && cs.privValidator != nil {
+		address := cs.privValidator.Address
+		bonded := cs.Validators.HasAddress(address)
+		cs.signingMonitor.saveBlock(address, block.Height, bonded,
+			bonded && cs.Votes.HasPrecommitByAddress(address), cs.LastCommit)
+	}
+
 	// Save the state.
 	cs.stagedState.Save()
 
+	// Txs we didn't receive before the block have the zero time.
+	txTimes := cs.mempoolReactor.Mempool.GetTxTimes(block.Txs)
+	cs.tracer.Mark(block.Height, func(trace *BlockTrace) {
+		trace.TxArrivals = txTimes
+		for _, txTime := range txTimes {
+			if !txTime.IsZero() && (trace.FirstTx.IsZero() || txTime.Before(trace.FirstTx)) {
+				trace.FirstTx = txTime
+			}
+		}
+		markOnce(&trace.Saved)
+	})
+
+	// Index the txs, with the gas they used.
+	if cs.txIndex != nil {
+		cs.txIndex.IndexBlock(block, cs.stagedState.LastBlockGasUsed)
+		cs.tracer.Mark(block.Height, func(trace *BlockTrace) {
+			markOnce(&trace.Indexed)
+		})
+	}
+
 	// Update mempool.
 	cs.mempoolReactor.Mempool.ResetForBlockAndState(block, cs.stagedState)
 
 	// Fire off event
 	go func(block *types.Block) {
 		cs.evsw.FireEvent(types.EventStringNewBlock(), block)
+		for i, tx := range block.Data.Txs {
+			txId := types.TxId(block.ChainID, tx)
+			cs.evsw.FireEvent(types.EventStringTxCommitted(txId), &types.EventMsgTxCommitted{
+				TxId:   txId,
+				Height: block.Height,
+				Index:  i,
+			})
+		}
 		cs.evc.Flush()
 	}(block)
 
 }
 
+// Returns the lifecycle trace of the block at height,
+// or nil if height is not one of the recent heights.
+func (cs *ConsensusState) GetBlockTrace(height int) *BlockTrace {
+	return cs.tracer.Get(height)
+}
+
+// Records the first receipt of a proposal for height from a peer.
+func (cs *ConsensusState) TraceProposalReceived(height int, peerKey string) {
+	cs.tracer.MarkProposalReceived(height, peerKey)
+}
+
 // implements events.Eventable
 func (cs *ConsensusState) SetFireable(evsw events.Fireable) {
 	cs.evsw = evsw
+	cs.Votes.SetFireable(evsw)
 }
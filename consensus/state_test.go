@@ -1,9 +1,16 @@
 package consensus
 
 import (
+	"bytes"
 	"testing"
+	"time"
 
+	"github.com/tendermint/tendermint/account"
 	_ "github.com/tendermint/tendermint/config/tendermint_test"
+	. "github.com/tendermint/tendermint/consensus/types"
+	"github.com/tendermint/tendermint/p2p"
+	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/types"
 )
 
 func TestEnterProposeNoPrivValidator(t *testing.T) {
@@ -35,4 +42,165 @@ func TestEnterPropose(t *testing.T) {
 	}
 }
 
+func TestReleaseOrphanedParts(t *testing.T) {
+	cs, privValidators := randConsensusState()
+	cs.SetPrivValidator(privValidators[0])
+
+	cs.EnterPropose(1, 0)
+	parts := cs.GetRoundState().ProposalBlockParts
+	size := parts.ByteSize()
+	if size == 0 {
+		t.Fatal("Expected a proposal with parts")
+	}
+
+	// The round changes before the proposal commits.
+	cs.EnterNewRound(1, 1)
+	stats := cs.GetPartSetGCStats()
+	if stats.PartSets != 1 || stats.Incomplete != 0 || stats.Parts != parts.Total() || stats.Bytes != size {
+		t.Errorf("Unexpected stats after round change: %v", stats)
+	}
+	if parts.Count() != 0 || parts.ByteSize() != 0 || parts.GetPart(0) != nil {
+		t.Error("Expected the orphaned parts to be released")
+	}
+
+	// A partially received PartSet counts as incomplete.
+	full := types.NewPartSetFromData(make([]byte, 5000)) // 2 parts
+	partial := types.NewPartSetFromHeader(full.Header())
+	if _, err := partial.AddPart(full.GetPart(0)); err != nil {
+		t.Fatal(err)
+	}
+	cs.mtx.Lock()
+	cs.releasePartSets(partial, partial, cs.ProposalBlockParts)
+	cs.mtx.Unlock()
+	stats = cs.GetPartSetGCStats()
+	if stats.PartSets != 2 || stats.Incomplete != 1 || stats.Bytes != size+4096 {
+		t.Errorf("Unexpected stats after releasing a partial PartSet: %v", stats)
+	}
+}
+
+func TestConflictingProposal(t *testing.T) {
+	cs, privValidators := randConsensusState()
+	var proposer *sm.PrivValidator
+	for _, privVal := range privValidators {
+		if bytes.Equal(privVal.Address, cs.Validators.Proposer().Address) {
+			proposer = privVal
+		}
+	}
+	sign := func(p *Proposal, privVal *sm.PrivValidator) {
+		p.Signature = privVal.PrivKey.Sign(account.SignBytes(cs.state.ChainID, p)).(account.SignatureEd25519)
+	}
+	proposalA := NewProposal(1, 0, types.PartSetHeader{Total: 1, Hash: []byte("parts_a")}, -1)
+	sign(proposalA, proposer)
+	if err := cs.SetProposal(proposalA); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.SetProposal(proposalA); err != nil {
+		t.Errorf("Expected the same proposal again to be ignored, got %v", err)
+	}
+
+	proposalB := NewProposal(1, 0, types.PartSetHeader{Total: 1, Hash: []byte("parts_b")}, -1)
+	for _, privVal := range privValidators {
+		if privVal != proposer {
+			sign(proposalB, privVal)
+			break
+		}
+	}
+	if err := cs.SetProposal(proposalB); err != ErrInvalidProposalSignature {
+		t.Errorf("Expected ErrInvalidProposalSignature, got %v", err)
+	}
+
+	sign(proposalB, proposer)
+	errDupe, ok := cs.SetProposal(proposalB).(*ErrConflictingProposal)
+	if !ok {
+		t.Fatal("Expected ErrConflictingProposal")
+	}
+	if !bytes.Equal(errDupe.Address, proposer.Address) || errDupe.ProposalA != proposalA || errDupe.ProposalB != proposalB {
+		t.Errorf("Unexpected evidence %v", errDupe)
+	}
+	if cs.GetRoundState().Proposal != proposalA {
+		t.Error("Expected the first proposal to be kept")
+	}
+}
+
 // TODO write better consensus state tests
+
+func TestOwnVoteCh(t *testing.T) {
+	cs, privValidators := randConsensusState()
+	cs.SetPrivValidator(privValidators[0])
+
+	cs.EnterPrevote(1, 0)
+	select {
+	case msg := <-cs.OwnVoteCh():
+		index, _ := cs.Validators.GetByAddress(privValidators[0].Address)
+		if msg.ValidatorIndex != index {
+			t.Errorf("Expected validator index %v, got %v", index, msg.ValidatorIndex)
+		}
+		if msg.Vote.Type != types.VoteTypePrevote || msg.Vote.Height != 1 || msg.Vote.Round != 0 {
+			t.Errorf("Unexpected vote %v", msg.Vote)
+		}
+	default:
+		t.Fatal("Expected our prevote on OwnVoteCh")
+	}
+}
+
+func TestSigningGate(t *testing.T) {
+	cs, privValidators := randConsensusState()
+	cs.SetPrivValidator(privValidators[0])
+	cs.SetSigningGate(NewSigningGate(p2p.NewSwitch(), 1, []string{"127.0.0.1"}))
+
+	status := cs.GetSigningGateStatus()
+	if status.CanSign || status.Peers != 0 || len(status.MissingSentries) != 1 {
+		t.Errorf("Expected a closed gate with no peers and a missing sentry, got %v", status)
+	}
+	cs.EnterPrevote(1, 0)
+	if cs.Votes.Prevotes(0).GetByAddress(privValidators[0].Address) != nil {
+		t.Error("Expected no prevote without peers")
+	}
+
+	cs, privValidators = randConsensusState()
+	cs.SetPrivValidator(privValidators[0])
+	cs.SetSigningGate(NewSigningGate(p2p.NewSwitch(), 0, nil))
+	cs.EnterPrevote(1, 0)
+	if cs.Votes.Prevotes(0).GetByAddress(privValidators[0].Address) == nil {
+		t.Error("Expected a prevote through an open gate")
+	}
+}
+
+func TestChaosVoteDelay(t *testing.T) {
+	cs, privValidators := randConsensusState()
+	cs.SetPrivValidator(privValidators[0])
+	cs.SetChaosVoteDelay(20 * time.Millisecond)
+	cs.EnterPrevote(1, 0)
+	time.Sleep(100 * time.Millisecond)
+	if cs.GetRoundState().Votes.Prevotes(0).GetByAddress(privValidators[0].Address) == nil {
+		t.Error("Expected the delayed prevote to be added")
+	}
+}
+
+func TestVoteVersion(t *testing.T) {
+	cs, privValidators := randConsensusState()
+	cs.state.ProtocolUpgrades = []types.ProtocolUpgrade{{Height: 1, Version: types.VoteTimestampVersion}}
+	cs.SetPrivValidator(privValidators[0])
+
+	// Our votes have the version of the height, and a timestamp from it on.
+	cs.EnterPrevote(1, 0)
+	prevote := cs.Votes.Prevotes(0).GetByAddress(privValidators[0].Address)
+	if prevote == nil || prevote.Version != types.VoteTimestampVersion || prevote.Timestamp == 0 {
+		t.Fatalf("Expected a timestamped version %v prevote, got %v", types.VoteTimestampVersion, prevote)
+	}
+
+	// Votes of another version are rejected.
+	vote := &types.Vote{Height: 1, Round: 0, Type: types.VoteTypePrevote}
+	privValidators[1].SignVote(cs.state.ChainID, vote)
+	if _, _, err := cs.AddVote(privValidators[1].Address, vote, "peer"); err != types.ErrVoteInvalidVersion {
+		t.Errorf("Expected ErrVoteInvalidVersion, got %v", err)
+	}
+}
+
+func TestRollbackValidator(t *testing.T) {
+	cs, privValidators := randConsensusState()
+	cs.SetPrivValidator(privValidators[0])
+	if err := cs.Rollback(); err != ErrRollbackValidator {
+		t.Errorf("Expected ErrRollbackValidator, got %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package consensus
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	maxBlockTraces = 100 // Number of recent heights to keep traces for.
+)
+
+// BlockTrace records when a block passed each stage of its lifecycle,
+// as seen by this node. Stages that didn't happen have the zero time.
+type BlockTrace struct {
+	Height           int         `json:"height"`
+	FirstTx          time.Time   `json:"first_tx"`          // Earliest of TxArrivals.
+	TxArrivals       []time.Time `json:"tx_arrivals"`       // Arrival of each tx of the block in our mempool.
+	ProposalCreated  time.Time   `json:"proposal_created"`  // We signed a proposal.
+	ProposalSet      time.Time   `json:"proposal_set"`      // We accepted a proposal.
+	ProposalReceived []*PeerTime `json:"proposal_received"` // First proposal receipt from each peer.
+	Prevote23        time.Time   `json:"prevote_23"`        // First +2/3 prevotes for a block or nil.
+	Precommit23      time.Time   `json:"precommit_23"`      // +2/3 precommits for the block.
+	Commit           time.Time   `json:"commit"`            // Block finalized.
+	Saved            time.Time   `json:"saved"`             // Block and state saved.
+	Indexed          time.Time   `json:"indexed"`           // Txs indexed, see SetTxIndex.
+}
+
+type PeerTime struct {
+	PeerKey string    `json:"peer_key"`
+	Time    time.Time `json:"time"`
+}
+
+func (bt *BlockTrace) Copy() *BlockTrace {
+	btCopy := *bt
+	btCopy.ProposalReceived = make([]*PeerTime, len(bt.ProposalReceived))
+	for i, pt := range bt.ProposalReceived {
+		ptCopy := *pt
+		btCopy.ProposalReceived[i] = &ptCopy
+	}
+	return &btCopy
+}
+
+// Keeps BlockTraces for the most recent heights.
+type blockTracer struct {
+	mtx    sync.Mutex
+	traces map[int]*BlockTrace
+}
+
+func newBlockTracer() *blockTracer {
+	return &blockTracer{
+		traces: make(map[int]*BlockTrace),
+	}
+}
+
+func (tr *blockTracer) Get(height int) *BlockTrace {
+	tr.mtx.Lock()
+	defer tr.mtx.Unlock()
+	trace := tr.traces[height]
+	if trace == nil {
+		return nil
+	}
+	return trace.Copy()
+}
+
+// Calls mark with the trace for height, creating it if needed.
+func (tr *blockTracer) Mark(height int, mark func(*BlockTrace)) {
+	tr.mtx.Lock()
+	defer tr.mtx.Unlock()
+	trace := tr.traces[height]
+	if trace == nil {
+		trace = &BlockTrace{Height: height}
+		tr.traces[height] = trace
+		for h := range tr.traces {
+			if h <= height-maxBlockTraces {
+				delete(tr.traces, h)
+			}
+		}
+	}
+	mark(trace)
+}
+
+// Sets *t to now, unless it is already set.
+func markOnce(t *time.Time) {
+	if t.IsZero() {
+		*t = time.Now()
+	}
+}
+
+func (tr *blockTracer) MarkProposalReceived(height int, peerKey string) {
+	tr.Mark(height, func(trace *BlockTrace) {
+		for _, pt := range trace.ProposalReceived {
+			if pt.PeerKey == peerKey {
+				return
+			}
+		}
+		trace.ProposalReceived = append(trace.ProposalReceived, &PeerTime{peerKey, time.Now()})
+	})
+}
@@ -5,15 +5,19 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/tendermint/tendermint/Godeps/_workspace/src/github.com/tendermint/ed25519"
 	"github.com/tendermint/tendermint/account"
-	"github.com/tendermint/tendermint/binary"
-	. "github.com/tendermint/tendermint/common"
 	"github.com/tendermint/tendermint/types"
 )
 
 var (
 	ErrInvalidBlockPartSignature = errors.New("Error invalid block part signature")
 	ErrInvalidBlockPartHash      = errors.New("Error invalid block part hash")
+	ErrInvalidProposalHeight     = errors.New("Error invalid proposal height")
+	ErrInvalidProposalRound      = errors.New("Error invalid proposal round")
+	ErrInvalidProposalParts      = errors.New("Error invalid proposal block parts header")
+	ErrInvalidProposalPOLRange   = errors.New("Error invalid proposal POL round range")
+	ErrInvalidProposalSigSize    = errors.New("Error invalid proposal signature size")
 )
 
 type Proposal struct {
@@ -33,15 +37,45 @@ func NewProposal(height int, round int, blockPartsHeader types.PartSetHeader, po
 	}
 }
 
+// Basic validation that doesn't involve state data.
+func (p *Proposal) ValidateBasic() error {
+	if p.Height <= 0 {
+		return ErrInvalidProposalHeight
+	}
+	if p.Round < 0 {
+		return ErrInvalidProposalRound
+	}
+	if p.POLRound < -1 || p.POLRound >= p.Round {
+		return ErrInvalidProposalPOLRange
+	}
+	if p.BlockPartsHeader.IsZero() {
+		return ErrInvalidProposalParts
+	}
+	if err := p.BlockPartsHeader.ValidateBasic(); err != nil {
+		return err
+	}
+	if len(p.Signature) != ed25519.SignatureSize {
+		return ErrInvalidProposalSigSize
+	}
+	return nil
+}
+
 func (p *Proposal) String() string {
 	return fmt.Sprintf("Proposal{%v/%v %v %v %v}", p.Height, p.Round,
 		p.BlockPartsHeader, p.POLRound, p.Signature)
 }
 
 func (p *Proposal) WriteSignBytes(chainID string, w io.Writer, n *int64, err *error) {
-	binary.WriteTo([]byte(Fmt(`{"chain_id":"%s"`, chainID)), w, n, err)
-	binary.WriteTo([]byte(`,"proposal":{"block_parts_header":`), w, n, err)
-	p.BlockPartsHeader.WriteSignBytes(w, n, err)
-	binary.WriteTo([]byte(Fmt(`,"height":%v,"pol_round":%v`, p.Height, p.POLRound)), w, n, err)
-	binary.WriteTo([]byte(Fmt(`,"round":%v}}`, p.Round)), w, n, err)
+	types.WriteProposalSignBytes(chainID, p.Height, p.Round, p.BlockPartsHeader, p.POLRound, w, n, err)
+}
+
+// For evidence, see types.DupeProposalTx.
+func (p *Proposal) Signed() types.SignedProposal {
+	return types.SignedProposal{
+		Height:           p.Height,
+		Round:            p.Round,
+		BlockPartsHeader: p.BlockPartsHeader,
+		POLRound:         p.POLRound,
+		Signature:        p.Signature,
+	}
 }
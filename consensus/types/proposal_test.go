@@ -13,7 +13,7 @@ func TestProposalSignable(t *testing.T) {
 	proposal := &Proposal{
 		Height:           12345,
 		Round:            23456,
-		BlockPartsHeader: types.PartSetHeader{111, []byte("blockparts")},
+		BlockPartsHeader: types.PartSetHeader{Total: 111, Hash: []byte("blockparts")},
 		POLRound:         -1,
 		Signature:        nil,
 	}
@@ -26,3 +26,29 @@ func TestProposalSignable(t *testing.T) {
 		t.Errorf("Got unexpected sign string for SendTx. Expected:\n%v\nGot:\n%v", expected, signStr)
 	}
 }
+
+func TestProposalValidateBasic(t *testing.T) {
+	proposal := &Proposal{
+		Height:           1,
+		Round:            2,
+		BlockPartsHeader: types.PartSetHeader{Total: 111, Hash: CRandBytes(32)},
+		POLRound:         -1,
+		Signature:        CRandBytes(64),
+	}
+	if err := proposal.ValidateBasic(); err != nil {
+		t.Errorf("Proposal should be valid, error: %v", err)
+	}
+
+	// POLRound must be less than Round
+	proposal.POLRound = 2
+	if err := proposal.ValidateBasic(); err != ErrInvalidProposalPOLRange {
+		t.Errorf("Expected ErrInvalidProposalPOLRange, got %v", err)
+	}
+	proposal.POLRound = -1
+
+	// Signature must be the right size
+	proposal.Signature = CRandBytes(65)
+	if err := proposal.ValidateBasic(); err != ErrInvalidProposalSigSize {
+		t.Errorf("Expected ErrInvalidProposalSigSize, got %v", err)
+	}
+}
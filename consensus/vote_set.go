@@ -3,6 +3,7 @@ package consensus
 import (
 	"bytes"
 	"fmt"
+	"math/rand"
 	"strings"
 	"sync"
 
@@ -122,6 +123,11 @@ func (voteSet *VoteSet) addVote(val *sm.Validator, valIndex int, vote *types.Vot
 		return false, 0, types.ErrVoteUnexpectedStep
 	}
 
+	// Reject malformed votes before checking the signature.
+	if err := vote.ValidateBasic(); err != nil {
+		return false, 0, err
+	}
+
 	// Check signature.
 	if !val.PubKey.VerifyBytes(account.SignBytes(config.GetString("chain_id"), vote), vote.Signature) {
 		// Bad signature.
@@ -168,6 +174,26 @@ func (voteSet *VoteSet) BitArray() *BitArray {
 	return voteSet.votesBitArray.Copy()
 }
 
+// Returns a random vote that we have and the peer doesn't,
+// given the peer's announced votes. peerVotes may be nil.
+// The pick is drawn from r.
+func (voteSet *VoteSet) PickVoteToSend(peerVotes *BitArray, r *rand.Rand) (vote *types.Vote, index int, ok bool) {
+	if voteSet == nil {
+		return nil, 0, false
+	}
+	voteSet.mtx.Lock()
+	defer voteSet.mtx.Unlock()
+	missing := voteSet.votesBitArray.Copy()
+	if peerVotes != nil {
+		missing = missing.Sub(peerVotes.Copy())
+	}
+	index, ok = missing.PickRandomFrom(r)
+	if !ok {
+		return nil, 0, false
+	}
+	return voteSet.votes[index], index, true
+}
+
 func (voteSet *VoteSet) GetByIndex(valIndex int) *types.Vote {
 	voteSet.mtx.Lock()
 	defer voteSet.mtx.Unlock()
@@ -202,6 +228,39 @@ func (voteSet *VoteSet) HasTwoThirdsAny() bool {
 	return voteSet.totalVotes > voteSet.valSet.TotalVotingPower()*2/3
 }
 
+// Returns the blocks, excluding nil, that have more than 1/3 of the voting power,
+// ordered by the lowest index of a validator that voted for them.
+func (voteSet *VoteSet) OneThirdBlocks() []*types.BlockPower {
+	if voteSet == nil {
+		return nil
+	}
+	voteSet.mtx.Lock()
+	defer voteSet.mtx.Unlock()
+	var blocks []*types.BlockPower
+	blocksByKey := make(map[string]*types.BlockPower)
+	for valIndex, vote := range voteSet.votes {
+		if vote == nil || len(vote.BlockHash) == 0 {
+			continue
+		}
+		_, val := voteSet.valSet.GetByIndex(valIndex)
+		blockKey := string(vote.BlockHash) + string(binary.BinaryBytes(vote.BlockParts))
+		block, ok := blocksByKey[blockKey]
+		if !ok {
+			block = &types.BlockPower{Hash: vote.BlockHash, Parts: vote.BlockParts}
+			blocksByKey[blockKey] = block
+			blocks = append(blocks, block)
+		}
+		block.Power += val.VotingPower
+	}
+	oneThirds := blocks[:0]
+	for _, block := range blocks {
+		if block.Power > voteSet.valSet.TotalVotingPower()*1/3 {
+			oneThirds = append(oneThirds, block)
+		}
+	}
+	return oneThirds
+}
+
 // Returns either a blockhash (or nil) that received +2/3 majority.
 // If there exists no such majority, returns (nil, false).
 func (voteSet *VoteSet) TwoThirdsMajority() (hash []byte, parts types.PartSetHeader, ok bool) {
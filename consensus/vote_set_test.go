@@ -2,6 +2,7 @@ package consensus
 
 import (
 	"bytes"
+	"math/rand"
 
 	. "github.com/tendermint/tendermint/common"
 	. "github.com/tendermint/tendermint/common/test"
@@ -88,6 +89,45 @@ func TestAddVote(t *testing.T) {
 	}
 }
 
+func TestPickVoteToSend(t *testing.T) {
+	height, round := 1, 0
+	voteSet, valSet, privValidators := randVoteSet(height, round, types.VoteTypePrevote, 10, 1)
+	r := rand.New(rand.NewSource(1))
+
+	if _, _, ok := voteSet.PickVoteToSend(nil, r); ok {
+		t.Errorf("Expected no vote to send from an empty VoteSet")
+	}
+
+	peerVotes := NewBitArray(10)
+	for i := 0; i < 3; i++ {
+		vote := &types.Vote{Height: height, Round: round, Type: types.VoteTypePrevote, BlockHash: nil}
+		signAddVote(privValidators[i], vote, voteSet)
+		if i < 2 {
+			index, _ := valSet.GetByAddress(privValidators[i].Address)
+			peerVotes.SetIndex(index, true)
+		}
+	}
+
+	// The peer is only missing the third vote.
+	missingIndex, _ := valSet.GetByAddress(privValidators[2].Address)
+	for i := 0; i < 10; i++ {
+		vote, index, ok := voteSet.PickVoteToSend(peerVotes, r)
+		if !ok || index != missingIndex || vote != voteSet.GetByIndex(missingIndex) {
+			t.Fatalf("Expected the missing vote at %v, got %v at %v", missingIndex, vote, index)
+		}
+	}
+
+	// Nothing to send once the peer has it.
+	peerVotes.SetIndex(missingIndex, true)
+	if _, _, ok := voteSet.PickVoteToSend(peerVotes, r); ok {
+		t.Errorf("Expected no vote to send to a peer with all our votes")
+	}
+	// A peer that announced nothing is missing everything we have.
+	if _, _, ok := voteSet.PickVoteToSend(nil, r); !ok {
+		t.Errorf("Expected a vote to send to a peer with no votes")
+	}
+}
+
 func Test2_3Majority(t *testing.T) {
 	height, round := 1, 0
 	voteSet, _, privValidators := randVoteSet(height, round, types.VoteTypePrevote, 10, 1)
@@ -128,7 +168,7 @@ func Test2_3MajorityRedux(t *testing.T) {
 
 	blockHash := CRandBytes(32)
 	blockPartsTotal := 123
-	blockParts := types.PartSetHeader{blockPartsTotal, CRandBytes(32)}
+	blockParts := types.PartSetHeader{Total: blockPartsTotal, Hash: CRandBytes(32)}
 
 	vote := &types.Vote{Height: height, Round: round, Type: types.VoteTypePrevote, BlockHash: blockHash, BlockParts: blockParts}
 
@@ -152,7 +192,7 @@ func Test2_3MajorityRedux(t *testing.T) {
 
 	// 68th validator voted for a different BlockParts PartSetHeader
 	{
-		blockParts := types.PartSetHeader{blockPartsTotal, CRandBytes(32)}
+		blockParts := types.PartSetHeader{Total: blockPartsTotal, Hash: CRandBytes(32)}
 		signAddVote(privValidators[67], withBlockParts(vote, blockParts), voteSet)
 		hash, header, ok = voteSet.TwoThirdsMajority()
 		if hash != nil || !header.IsZero() || ok {
@@ -162,7 +202,7 @@ func Test2_3MajorityRedux(t *testing.T) {
 
 	// 69th validator voted for different BlockParts Total
 	{
-		blockParts := types.PartSetHeader{blockPartsTotal + 1, blockParts.Hash}
+		blockParts := types.PartSetHeader{Total: blockPartsTotal + 1, Hash: blockParts.Hash}
 		signAddVote(privValidators[68], withBlockParts(vote, blockParts), voteSet)
 		hash, header, ok = voteSet.TwoThirdsMajority()
 		if hash != nil || !header.IsZero() || ok {
@@ -223,12 +263,18 @@ func TestBadVotes(t *testing.T) {
 	if added {
 		t.Errorf("Expected VoteSet.Add to fail, wrong type")
 	}
+
+	// val4 votes for a malformed block hash.
+	added, err = signAddVote(privValidators[4], withBlockHash(vote, RandBytes(33)), voteSet)
+	if added || err != types.ErrVoteInvalidBlockHash {
+		t.Errorf("Expected VoteSet.Add to fail, invalid block hash")
+	}
 }
 
 func TestMakeValidation(t *testing.T) {
 	height, round := 1, 0
 	voteSet, _, privValidators := randVoteSet(height, round, types.VoteTypePrecommit, 10, 1)
-	blockHash, blockParts := CRandBytes(32), types.PartSetHeader{123, CRandBytes(32)}
+	blockHash, blockParts := CRandBytes(32), types.PartSetHeader{Total: 123, Hash: CRandBytes(32)}
 
 	vote := &types.Vote{Height: height, Round: round, Type: types.VoteTypePrecommit,
 		BlockHash: blockHash, BlockParts: blockParts}
@@ -244,7 +290,7 @@ func TestMakeValidation(t *testing.T) {
 	// 7th voted for some other block.
 	{
 		vote := withBlockHash(vote, RandBytes(32))
-		vote = withBlockParts(vote, types.PartSetHeader{123, RandBytes(32)})
+		vote = withBlockParts(vote, types.PartSetHeader{Total: 123, Hash: RandBytes(32)})
 		signAddVote(privValidators[6], vote, voteSet)
 	}
 
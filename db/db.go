@@ -12,6 +12,9 @@ type DB interface {
 	SetSync([]byte, []byte)
 	Delete([]byte)
 	DeleteSync([]byte)
+	// Deletes the keys from start up to but excluding end, in byte order.
+	// A nil end deletes to the last key.
+	DeleteRange(start, end []byte)
 	Close()
 
 	// For debugging
@@ -27,21 +30,28 @@ const DBBackendLevelDB = "leveldb"
 var dbs = NewCMap()
 
 func GetDB(name string) DB {
-	db := dbs.Get(name)
+	return GetDBAt(name, config.GetString("db_dir"))
+}
+
+// Like GetDB, but for a database under dir instead of db_dir,
+// e.g. on a separate, slower disk.
+func GetDBAt(name string, dir string) DB {
+	dbPath := path.Join(dir, name+".db")
+	db := dbs.Get(dbPath)
 	if db != nil {
 		return db.(DB)
 	}
 	switch config.GetString("db_backend") {
 	case DBBackendMemDB:
 		db := NewMemDB()
-		dbs.Set(name, db)
+		dbs.Set(dbPath, db)
 		return db
 	case DBBackendLevelDB:
-		db, err := NewLevelDB(path.Join(config.GetString("db_dir"), name+".db"))
+		db, err := NewLevelDB(dbPath)
 		if err != nil {
 			panic(err)
 		}
-		dbs.Set(name, db)
+		dbs.Set(dbPath, db)
 		return db
 	default:
 		panic(Fmt("Unknown DB backend: %v", config.GetString("db_backend")))
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/tendermint/tendermint/Godeps/_workspace/src/github.com/syndtr/goleveldb/leveldb/util"
+)
+
+func testDeleteRange(t *testing.T, db DB) {
+	for i := 0; i < 30; i++ {
+		db.Set([]byte(fmt.Sprintf("k:%02d", i)), []byte("v"))
+	}
+	db.Set([]byte("l"), []byte("v"))
+
+	checkKeys := func(from, to int, exist bool) {
+		for i := from; i < to; i++ {
+			if key := fmt.Sprintf("k:%02d", i); (db.Get([]byte(key)) != nil) != exist {
+				t.Errorf("Expected %v to exist: %v", key, exist)
+			}
+		}
+	}
+	db.DeleteRange([]byte("k:05"), []byte("k:25"))
+	checkKeys(0, 5, true)
+	checkKeys(5, 25, false)
+	checkKeys(25, 30, true)
+
+	db.DeleteRange([]byte("k:"), nil)
+	checkKeys(0, 30, false)
+	if db.Get([]byte("l")) != nil {
+		t.Error("Expected a nil end to delete to the last key")
+	}
+}
+
+func TestDeleteRange(t *testing.T) {
+	testDeleteRange(t, NewMemDB())
+
+	dir, err := ioutil.TempDir("", "deleterange")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	db, err := NewLevelDB(path.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	testDeleteRange(t, db)
+}
+
+func TestMergeRanges(t *testing.T) {
+	r := mergeRanges(nil, util.Range{Start: []byte("b"), Limit: []byte("c")})
+	r = mergeRanges(r, util.Range{Start: []byte("a"), Limit: []byte("b")})
+	if string(r.Start) != "a" || string(r.Limit) != "c" {
+		t.Errorf("Expected [a, c), got [%s, %s)", r.Start, r.Limit)
+	}
+	r = mergeRanges(r, util.Range{Start: []byte("d"), Limit: nil})
+	if string(r.Start) != "a" || r.Limit != nil {
+		t.Errorf("Expected [a, end), got [%s, %s)", r.Start, r.Limit)
+	}
+}
@@ -1,15 +1,26 @@
 package db
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/tendermint/tendermint/Godeps/_workspace/src/github.com/syndtr/goleveldb/leveldb"
 	"github.com/tendermint/tendermint/Godeps/_workspace/src/github.com/syndtr/goleveldb/leveldb/errors"
 	"github.com/tendermint/tendermint/Godeps/_workspace/src/github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/tendermint/tendermint/Godeps/_workspace/src/github.com/syndtr/goleveldb/leveldb/util"
 	"path"
+	"sync"
 )
 
+// Deletes are written in batches of this many keys, so that a large
+// DeleteRange doesn't hold all its keys in memory.
+const levelDBDeleteBatchSize = 10000
+
 type LevelDB struct {
 	db *leveldb.DB
+
+	compactMtx     sync.Mutex
+	compacting     bool
+	compactPending *util.Range // The ranges deleted while compacting, merged
 }
 
 func NewLevelDB(name string) (*LevelDB, error) {
@@ -62,6 +73,77 @@ func (db *LevelDB) DeleteSync(key []byte) {
 	}
 }
 
+// Deletes leave tombstones until their range is compacted, which would
+// slow reads over it, so the range is compacted in the background.
+func (db *LevelDB) DeleteRange(start, end []byte) {
+	r := util.Range{Start: start, Limit: end}
+	iter := db.db.NewIterator(&r, nil)
+	defer iter.Release()
+	batch := new(leveldb.Batch)
+	for iter.Next() {
+		batch.Delete(iter.Key())
+		if batch.Len() == levelDBDeleteBatchSize {
+			db.writeBatch(batch)
+			batch.Reset()
+		}
+	}
+	if err := iter.Error(); err != nil {
+		panic(err)
+	}
+	db.writeBatch(batch)
+	db.compact(r)
+}
+
+// At most one compaction runs at a time. Ranges deleted meanwhile are
+// merged, and compacted once it's done.
+func (db *LevelDB) compact(r util.Range) {
+	db.compactMtx.Lock()
+	defer db.compactMtx.Unlock()
+	if db.compacting {
+		db.compactPending = mergeRanges(db.compactPending, r)
+		return
+	}
+	db.compacting = true
+	go func() {
+		for {
+			db.db.CompactRange(r)
+			db.compactMtx.Lock()
+			if db.compactPending == nil {
+				db.compacting = false
+				db.compactMtx.Unlock()
+				return
+			}
+			r, db.compactPending = *db.compactPending, nil
+			db.compactMtx.Unlock()
+		}
+	}()
+}
+
+// Returns the smallest range that covers a and b. A nil a is empty.
+func mergeRanges(a *util.Range, b util.Range) *util.Range {
+	if a == nil {
+		return &b
+	}
+	merged := *a
+	if bytes.Compare(b.Start, merged.Start) < 0 {
+		merged.Start = b.Start
+	}
+	if merged.Limit != nil && (b.Limit == nil || bytes.Compare(b.Limit, merged.Limit) > 0) {
+		merged.Limit = b.Limit
+	}
+	return &merged
+}
+
+func (db *LevelDB) writeBatch(batch *leveldb.Batch) {
+	if batch.Len() == 0 {
+		return
+	}
+	err := db.db.Write(batch, nil)
+	if err != nil {
+		panic(err)
+	}
+}
+
 func (db *LevelDB) DB() *leveldb.DB {
 	return db.db
 }
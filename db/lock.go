@@ -0,0 +1,119 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+
+	. "github.com/tendermint/tendermint/common"
+)
+
+/*
+A DirLock keeps two node processes from opening the databases of a
+directory at once, which corrupts them, and for a validator can lead to
+double-signing.
+
+The lock is a file in the directory, locked by the OS where it can be (see
+lockFile), and holding the pid and host of its process. A lock left by a
+crashed process is stale, and is taken over. A lock that can't be told
+stale, e.g. one from another host on a network disk, can be removed with
+ForceUnlock once its process is known to be gone.
+*/
+
+var ErrLockHeld = errors.New("Error lock is held by a running process")
+
+type DirLock struct {
+	file  *os.File
+	Stale *LockHolder // The holder of a stale lock that was taken over, or nil.
+}
+
+type LockHolder struct {
+	Pid  int       `json:"pid"`
+	Host string    `json:"host"`
+	Time time.Time `json:"time"`
+}
+
+const lockFileName = "tendermint.lock"
+
+func LockDir(dir string) (*DirLock, error) {
+	if err := EnsureDir(dir); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path.Join(dir, lockFileName), os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	holder := readLockHolder(file)
+	if err := lockFile(file, holder); err != nil {
+		file.Close()
+		if holder != nil {
+			return nil, fmt.Errorf("Error %v is locked by process %v on %v since %v: %v",
+				dir, holder.Pid, holder.Host, holder.Time, err)
+		}
+		return nil, fmt.Errorf("Error %v is locked: %v", dir, err)
+	}
+	host, _ := os.Hostname()
+	bytes, _ := json.Marshal(&LockHolder{Pid: os.Getpid(), Host: host, Time: time.Now()})
+	if err := writeLockFile(file, bytes); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &DirLock{file: file, Stale: holder}, nil
+}
+
+// Returns nil if the lock file is empty, e.g. it's new or was unlocked.
+func readLockHolder(file *os.File) *LockHolder {
+	bytes, err := ioutil.ReadAll(file)
+	if err != nil || len(bytes) == 0 {
+		return nil
+	}
+	holder := &LockHolder{}
+	if err := json.Unmarshal(bytes, holder); err != nil {
+		// Left half written by a crash.
+		return &LockHolder{}
+	}
+	return holder
+}
+
+func writeLockFile(file *os.File, bytes []byte) error {
+	if err := file.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := file.WriteAt(bytes, 0); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// Empties the lock file before releasing it, so that the next
+// LockDir doesn't take it for stale.
+func (l *DirLock) Unlock() {
+	writeLockFile(l.file, nil)
+	l.file.Close()
+}
+
+// Removes the lock of dir, for when its process is known to be gone but
+// the lock can't be told stale. Returns ErrLockHeld instead if a running
+// process is seen to hold the lock.
+func ForceUnlock(dir string) error {
+	file, err := os.OpenFile(path.Join(dir, lockFileName), os.O_RDWR, 0600)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	held := lockHeld(file, readLockHolder(file))
+	file.Close()
+	if held {
+		return ErrLockHeld
+	}
+	err = os.Remove(path.Join(dir, lockFileName))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+func TestDirLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dirlock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	lock, err := LockDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lock.Stale != nil {
+		t.Errorf("Expected a new lock, got stale %v", lock.Stale)
+	}
+	if _, err := LockDir(dir); err == nil {
+		t.Fatal("Expected the directory to be locked")
+	}
+	lock.Unlock()
+
+	// Unlocked, the lock can be taken again, and isn't stale.
+	lock, err = LockDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lock.Stale != nil {
+		t.Errorf("Expected an unlocked lock not to be stale, got %v", lock.Stale)
+	}
+
+	// A forced unlock is refused while the lock is held.
+	if err := ForceUnlock(dir); err != ErrLockHeld {
+		t.Fatalf("Expected ErrLockHeld, got %v", err)
+	}
+	lock.Unlock()
+
+	// A crashed process leaves its lock file behind.
+	lockFile := path.Join(dir, lockFileName)
+	host, _ := os.Hostname()
+	stale, _ := json.Marshal(&LockHolder{Pid: 999999999, Host: host, Time: time.Now()})
+	if err := ioutil.WriteFile(lockFile, stale, 0600); err != nil {
+		t.Fatal(err)
+	}
+	lock, err = LockDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lock.Stale == nil || lock.Stale.Pid != 999999999 {
+		t.Errorf("Expected to take over the stale lock, got %v", lock.Stale)
+	}
+	lock.Unlock()
+
+	// A lock file that isn't held is removed by a forced unlock.
+	if err := ioutil.WriteFile(lockFile, stale, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ForceUnlock(dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(lockFile); !os.IsNotExist(err) {
+		t.Errorf("Expected the lock file to be removed, got %v", err)
+	}
+}
@@ -0,0 +1,26 @@
+//go:build !windows
+// +build !windows
+
+package db
+
+import (
+	"os"
+	"syscall"
+)
+
+// Takes an flock on file, which the OS releases when the process exits,
+// so a lock is stale just when it can be taken.
+func lockFile(file *os.File, holder *LockHolder) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+}
+
+// Tries the flock, which fails with EWOULDBLOCK just while it is held.
+// Other failures, e.g. a filesystem without flock, can't tell.
+func lockHeld(file *os.File, holder *LockHolder) bool {
+	err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err == nil {
+		syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+		return false
+	}
+	return err == syscall.EWOULDBLOCK
+}
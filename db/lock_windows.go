@@ -0,0 +1,36 @@
+//go:build windows
+// +build windows
+
+package db
+
+import (
+	"os"
+)
+
+// Without flock, a lock is stale if its process is gone from this host.
+// Locks from other hosts, or from a process whose pid was reused, need
+// ForceUnlock.
+func lockFile(file *os.File, holder *LockHolder) error {
+	if holder == nil {
+		return nil
+	}
+	host, _ := os.Hostname()
+	if holder.Host != host || holder.Pid == os.Getpid() {
+		return ErrLockHeld
+	}
+	// FindProcess fails on windows if the process doesn't exist.
+	if process, err := os.FindProcess(holder.Pid); err == nil {
+		process.Release()
+		return ErrLockHeld
+	}
+	return nil
+}
+
+// Only a lock from a running process of this host is known to be held.
+func lockHeld(file *os.File, holder *LockHolder) bool {
+	host, _ := os.Hostname()
+	if holder == nil || holder.Host != host {
+		return false
+	}
+	return lockFile(file, holder) == ErrLockHeld
+}
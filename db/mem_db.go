@@ -1,6 +1,7 @@
 package db
 
 import (
+	"bytes"
 	"fmt"
 )
 
@@ -33,6 +34,14 @@ func (db *MemDB) DeleteSync(key []byte) {
 	delete(db.db, string(key))
 }
 
+func (db *MemDB) DeleteRange(start, end []byte) {
+	for key := range db.db {
+		if bytes.Compare([]byte(key), start) >= 0 && (end == nil || bytes.Compare([]byte(key), end) < 0) {
+			delete(db.db, key)
+		}
+	}
+}
+
 func (db *MemDB) Close() {
 	db = nil
 }
@@ -0,0 +1,109 @@
+/*
+Package faucet sends coins from a funded key to anyone who asks,
+so that testnet users don't need an external faucet service.
+It must only be enabled on test chains, see types.IsTestChainID.
+*/
+package faucet
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/tendermint/tendermint/account"
+	. "github.com/tendermint/tendermint/common"
+	mempl "github.com/tendermint/tendermint/mempool"
+	"github.com/tendermint/tendermint/types"
+)
+
+var (
+	ErrFaucetInvalidAmount = errors.New("Error invalid faucet amount")
+	ErrFaucetRateLimited   = errors.New("Error faucet rate limited, try again later")
+)
+
+type Faucet struct {
+	chainID        string
+	privAccount    *account.PrivAccount
+	mempoolReactor *mempl.MempoolReactor
+	maxAmount      int64         // Per send.
+	interval       time.Duration // Per recipient.
+	maxSends       int           // Across all recipients, per interval.
+
+	mtx      sync.Mutex
+	lastSend map[string]time.Time // Recipient address -> time of last send.
+	sends    []time.Time          // Times of sends within the last interval.
+}
+
+func NewFaucet(chainID string, privAccount *account.PrivAccount, mempoolReactor *mempl.MempoolReactor,
+	maxAmount int64, interval time.Duration, maxSends int) *Faucet {
+	return &Faucet{
+		chainID:        chainID,
+		privAccount:    privAccount,
+		mempoolReactor: mempoolReactor,
+		maxAmount:      maxAmount,
+		interval:       interval,
+		maxSends:       maxSends,
+		lastSend:       make(map[string]time.Time),
+	}
+}
+
+func (f *Faucet) Address() []byte {
+	return f.privAccount.Address
+}
+
+// Signs and broadcasts a SendTx of amount to address.
+// Each address may receive once per interval, and the faucet sends
+// at most maxSends times per interval overall.
+func (f *Faucet) Send(address []byte, amount int64) (*types.SendTx, error) {
+	if len(address) != 20 {
+		return nil, types.ErrTxInvalidAddress
+	}
+	if amount <= 0 || amount > f.maxAmount {
+		return nil, ErrFaucetInvalidAmount
+	}
+
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	now := time.Now()
+	f.pruneSends(now)
+	if last, ok := f.lastSend[string(address)]; ok && now.Sub(last) < f.interval {
+		return nil, ErrFaucetRateLimited
+	}
+	if f.maxSends > 0 && len(f.sends) >= f.maxSends {
+		return nil, ErrFaucetRateLimited
+	}
+
+	// The mempool's accounts include our unconfirmed sends,
+	// so the sequence is right for back to back sends.
+	tx := types.NewSendTx()
+	err := tx.AddInput(f.mempoolReactor.Mempool, f.privAccount.PubKey, amount)
+	if err != nil {
+		return nil, err
+	}
+	tx.AddOutput(address, amount)
+	tx.SignInput(f.chainID, f.mempoolReactor.Mempool.GetState().TxSignVersion(), 0, f.privAccount)
+	if err := f.mempoolReactor.BroadcastTx(tx); err != nil {
+		return nil, err
+	}
+
+	f.lastSend[string(address)] = now
+	f.sends = append(f.sends, now)
+	log.Info(Fmt("Faucet sent %v to %X", amount, address))
+	return tx, nil
+}
+
+// Forgets sends older than the interval.
+// CONTRACT: f.mtx is held.
+func (f *Faucet) pruneSends(now time.Time) {
+	for addr, last := range f.lastSend {
+		if now.Sub(last) >= f.interval {
+			delete(f.lastSend, addr)
+		}
+	}
+	i := 0
+	for i < len(f.sends) && now.Sub(f.sends[i]) >= f.interval {
+		i++
+	}
+	f.sends = f.sends[i:]
+}
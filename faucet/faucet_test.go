@@ -0,0 +1,75 @@
+package faucet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tendermint/tendermint/account"
+	_ "github.com/tendermint/tendermint/config/tendermint_test"
+	mempl "github.com/tendermint/tendermint/mempool"
+	"github.com/tendermint/tendermint/p2p"
+	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+)
+
+func newTestFaucet(interval time.Duration, maxSends int, upgrades ...types.ProtocolUpgrade) (*Faucet, *mempl.Mempool) {
+	state, privAccounts, _ := sm.RandGenesisState(1, false, 1000000, 1, false, 1000)
+	state.ProtocolUpgrades = upgrades
+	mempool := mempl.NewMempool(state.Copy())
+	mempoolReactor := mempl.NewMempoolReactor(mempool)
+	sw := p2p.NewSwitch()
+	sw.AddReactor("MEMPOOL", mempoolReactor)
+	return NewFaucet(state.ChainID, privAccounts[0], mempoolReactor, 1000, interval, maxSends), mempool
+}
+
+func TestFaucetSend(t *testing.T) {
+	fct, mempool := newTestFaucet(time.Hour, 0)
+	addrA, addrB := account.GenPrivAccount().Address, account.GenPrivAccount().Address
+
+	if _, err := fct.Send(addrA, 1001); err != ErrFaucetInvalidAmount {
+		t.Errorf("Expected ErrFaucetInvalidAmount, got %v", err)
+	}
+	if _, err := fct.Send(addrA, 1000); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fct.Send(addrA, 1); err != ErrFaucetRateLimited {
+		t.Errorf("Expected ErrFaucetRateLimited, got %v", err)
+	}
+	// Back to back sends to different addresses use consecutive sequences.
+	if _, err := fct.Send(addrB, 500); err != nil {
+		t.Fatal(err)
+	}
+	if acc := mempool.GetAccount(addrA); acc == nil || acc.Balance != 1000 {
+		t.Errorf("Expected %X to have 1000, got %v", addrA, acc)
+	}
+	if acc := mempool.GetAccount(addrB); acc == nil || acc.Balance != 500 {
+		t.Errorf("Expected %X to have 500, got %v", addrB, acc)
+	}
+	if len(mempool.GetProposalTxs()) != 2 {
+		t.Errorf("Expected 2 txs in the mempool, got %v", len(mempool.GetProposalTxs()))
+	}
+}
+
+func TestFaucetMaxSends(t *testing.T) {
+	fct, _ := newTestFaucet(50*time.Millisecond, 1)
+	if _, err := fct.Send(account.GenPrivAccount().Address, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fct.Send(account.GenPrivAccount().Address, 1); err != ErrFaucetRateLimited {
+		t.Errorf("Expected ErrFaucetRateLimited, got %v", err)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if _, err := fct.Send(account.GenPrivAccount().Address, 1); err != nil {
+		t.Errorf("Expected send after the interval to succeed, got %v", err)
+	}
+}
+
+func TestFaucetSendAfterUpgrade(t *testing.T) {
+	fct, mempool := newTestFaucet(time.Hour, 0, types.ProtocolUpgrade{Height: 1, Version: types.TxGasVersion})
+	if _, err := fct.Send(account.GenPrivAccount().Address, 1); err != nil {
+		t.Fatal(err)
+	}
+	if len(mempool.GetProposalTxs()) != 1 {
+		t.Errorf("Expected 1 tx in the mempool, got %v", len(mempool.GetProposalTxs()))
+	}
+}
@@ -0,0 +1,7 @@
+package faucet
+
+import (
+	"github.com/tendermint/tendermint/logger"
+)
+
+var log = logger.New("module", "faucet")
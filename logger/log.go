@@ -1,14 +1,29 @@
 package logger
 
 import (
+	"errors"
 	"os"
+	"strings"
+	"sync"
 
 	"github.com/tendermint/tendermint/Godeps/_workspace/src/github.com/tendermint/log15"
 	. "github.com/tendermint/tendermint/common"
 )
 
+var (
+	ErrInvalidModuleLevels = errors.New("Error invalid module levels, expected module:level,...")
+)
+
 var rootHandler log15.Handler
 
+// Levels are checked per record against its "module" context value,
+// so they can be changed at runtime without recreating loggers.
+var (
+	levelsMtx    sync.RWMutex
+	defaultLevel log15.Lvl
+	moduleLevels map[string]log15.Lvl
+)
+
 func init() {
 	Reset()
 }
@@ -16,28 +31,37 @@ func init() {
 // You might want to call this after resetting tendermint/config.
 func Reset() {
 
-	var logLevel string = "debug"
+	var logLevel, logFormat, logModuleLevels string = "debug", "terminal", ""
 	if config != nil {
 		logLevel = config.GetString("log_level")
+		logFormat = config.GetString("log_format")
+		logModuleLevels = config.GetString("log_module_levels")
+	}
+
+	// binary is very noisy at debug.
+	levels := map[string]log15.Lvl{"binary": log15.LvlWarn}
+	configLevels, err := parseModuleLevels(logModuleLevels)
+	if err != nil {
+		Exit(Fmt("Invalid log_module_levels %v: %v", logModuleLevels, err))
+	}
+	for module, lvl := range configLevels {
+		levels[module] = lvl
 	}
+	levelsMtx.Lock()
+	defaultLevel = getLevel(logLevel)
+	moduleLevels = levels
+	levelsMtx.Unlock()
 
 	// stdout handler
-	//handlers := []log15.Handler{}
-	stdoutHandler := log15.LvlFilterHandler(
-		getLevel(logLevel),
-		log15.StreamHandler(os.Stdout, log15.TerminalFormat()),
+	rootHandler = log15.FilterHandler(
+		isEnabled,
+		log15.StreamHandler(os.Stdout, getFormat(logFormat)),
 	)
-	//handlers = append(handlers, stdoutHandler)
-
-	// Set rootHandler.
-	//rootHandler = log15.MultiHandler(handlers...)
-	rootHandler = stdoutHandler
 
 	// By setting handlers on the root, we handle events from all loggers.
 	log15.Root().SetHandler(rootHandler)
 }
 
-// See binary/log for an example of usage.
 func RootHandler() log15.Handler {
 	return rootHandler
 }
@@ -46,6 +70,77 @@ func New(ctx ...interface{}) log15.Logger {
 	return log15.Root().New(ctx...)
 }
 
+// Sets the level of module, or the default level if module is "".
+func SetLevel(module string, lvlString string) error {
+	lvl, err := log15.LvlFromString(lvlString)
+	if err != nil {
+		return err
+	}
+	levelsMtx.Lock()
+	defer levelsMtx.Unlock()
+	if module == "" {
+		defaultLevel = lvl
+		return nil
+	}
+	levels := make(map[string]log15.Lvl, len(moduleLevels)+1)
+	for m, l := range moduleLevels {
+		levels[m] = l
+	}
+	levels[module] = lvl
+	moduleLevels = levels
+	return nil
+}
+
+// Returns the level of each module with its own level,
+// and the default level under "".
+func Levels() map[string]string {
+	levelsMtx.RLock()
+	defer levelsMtx.RUnlock()
+	levels := map[string]string{"": defaultLevel.String()}
+	for module, lvl := range moduleLevels {
+		levels[module] = lvl.String()
+	}
+	return levels
+}
+
+func isEnabled(r *log15.Record) bool {
+	module := ""
+	for i := 0; i+1 < len(r.Ctx); i += 2 {
+		if r.Ctx[i] == "module" {
+			module, _ = r.Ctx[i+1].(string)
+			break
+		}
+	}
+	levelsMtx.RLock()
+	defer levelsMtx.RUnlock()
+	lvl, ok := moduleLevels[module]
+	if !ok {
+		lvl = defaultLevel
+	}
+	return r.Lvl <= lvl
+}
+
+// Parses e.g. "consensus:info,p2p:warn".
+func parseModuleLevels(str string) (map[string]log15.Lvl, error) {
+	levels := make(map[string]log15.Lvl)
+	for _, item := range strings.Split(str, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.Split(item, ":")
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, ErrInvalidModuleLevels
+		}
+		lvl, err := log15.LvlFromString(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		levels[parts[0]] = lvl
+	}
+	return levels, nil
+}
+
 func getLevel(lvlString string) log15.Lvl {
 	lvl, err := log15.LvlFromString(lvlString)
 	if err != nil {
@@ -53,3 +148,17 @@ func getLevel(lvlString string) log15.Lvl {
 	}
 	return lvl
 }
+
+func getFormat(formatString string) log15.Format {
+	switch formatString {
+	case "terminal", "":
+		return log15.TerminalFormat()
+	case "json":
+		return log15.JsonFormat()
+	case "logfmt":
+		return log15.LogfmtFormat()
+	default:
+		Exit(Fmt("Invalid log format %v, expected terminal, json or logfmt", formatString))
+		return nil
+	}
+}
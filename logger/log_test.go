@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/tendermint/tendermint/Godeps/_workspace/src/github.com/tendermint/log15"
+)
+
+func TestModuleLevels(t *testing.T) {
+	levels, err := parseModuleLevels(" consensus:info, p2p:warn,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(levels) != 2 || levels["consensus"] != log15.LvlInfo || levels["p2p"] != log15.LvlWarn {
+		t.Errorf("Unexpected levels %v", levels)
+	}
+	for _, str := range []string{"consensus", ":info", "consensus:loud"} {
+		if _, err := parseModuleLevels(str); err == nil {
+			t.Errorf("Expected an error parsing %q", str)
+		}
+	}
+
+	defer Reset()
+	if err := SetLevel("", "info"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetLevel("p2p", "debug"); err != nil {
+		t.Fatal(err)
+	}
+	record := func(module string, lvl log15.Lvl) *log15.Record {
+		return &log15.Record{Lvl: lvl, Ctx: []interface{}{"module", module, "height", 1}}
+	}
+	if !isEnabled(record("p2p", log15.LvlDebug)) {
+		t.Error("Expected p2p debug logs to be enabled")
+	}
+	if isEnabled(record("consensus", log15.LvlDebug)) || !isEnabled(record("consensus", log15.LvlInfo)) {
+		t.Error("Expected consensus to use the default level")
+	}
+	if isEnabled(record("binary", log15.LvlInfo)) {
+		t.Error("Expected binary to log warnings only")
+	}
+	if Levels()["p2p"] != "dbug" || Levels()[""] != "info" {
+		t.Errorf("Unexpected levels %v", Levels())
+	}
+}
@@ -0,0 +1,136 @@
+package mempool
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+// Audit events
+const (
+	AuditAccepted  = "accepted"  // added to the mempool
+	AuditRejected  = "rejected"  // not added, see Code
+	AuditCommitted = "committed" // removed because it is in the block at Height
+	AuditEvicted   = "evicted"   // removed without being committed, see Code
+)
+
+// Audit codes, for rejected and evicted txs
+const (
+	AuditCodeInvalidAddress    = "invalid_address"
+	AuditCodeDuplicateAddress  = "duplicate_address"
+	AuditCodeInvalidAmount     = "invalid_amount"
+	AuditCodeInsufficientFunds = "insufficient_funds"
+	AuditCodeInsufficientGas   = "insufficient_gas"
+	AuditCodeInvalidPubKey     = "invalid_pubkey"
+	AuditCodeInvalidSignature  = "invalid_signature"
+	AuditCodeInvalidSequence   = "invalid_sequence"
+	AuditCodeInvalidString     = "invalid_string"
+	AuditCodeNameNotFound      = "name_not_found"
+	AuditCodeIncorrectOwner    = "incorrect_owner"
+	AuditCodeNoLongerValid     = "no_longer_valid" // evicted: invalid after a new block, see Reason
+	AuditCodeFlushed           = "flushed"         // evicted: the mempool was flushed
+	AuditCodeOther             = "other"           // see Reason
+)
+
+// AuditEntry records one admission decision about a tx,
+// so it can be explained even if the tx never made it into a block.
+type AuditEntry struct {
+	TxId   []byte    `json:"tx_id"`
+	Time   time.Time `json:"time"`
+	Event  string    `json:"event"`
+	Code   string    `json:"code"`   // If rejected or evicted
+	Reason string    `json:"reason"` // Error message, if any
+	Height int       `json:"height"` // If committed
+}
+
+func auditCode(err error) string {
+	if _, ok := err.(types.ErrTxInvalidSequence); ok {
+		return AuditCodeInvalidSequence
+	}
+	switch err {
+	case types.ErrTxInvalidAddress:
+		return AuditCodeInvalidAddress
+	case types.ErrTxDuplicateAddress:
+		return AuditCodeDuplicateAddress
+	case types.ErrTxInvalidAmount:
+		return AuditCodeInvalidAmount
+	case types.ErrTxInsufficientFunds:
+		return AuditCodeInsufficientFunds
+	case types.ErrTxInsufficientGasPrice, types.ErrTxInsufficientGas:
+		return AuditCodeInsufficientGas
+	case types.ErrTxUnknownPubKey, types.ErrTxInvalidPubKey:
+		return AuditCodeInvalidPubKey
+	case types.ErrTxInvalidSignature:
+		return AuditCodeInvalidSignature
+	case types.ErrTxInvalidString:
+		return AuditCodeInvalidString
+	case types.ErrTxNameNotFound:
+		return AuditCodeNameNotFound
+	case types.ErrIncorrectOwner:
+		return AuditCodeIncorrectOwner
+	default:
+		return AuditCodeOther
+	}
+}
+
+// Keeps the most recent AuditEntries in a ring buffer.
+type auditLog struct {
+	mtx     sync.Mutex
+	chainID string
+	entries []*AuditEntry
+	next    int // index of the oldest entry once full
+}
+
+func newAuditLog(chainID string, size int) *auditLog {
+	return &auditLog{
+		chainID: chainID,
+		entries: make([]*AuditEntry, 0, size),
+	}
+}
+
+func (al *auditLog) add(entry *AuditEntry) {
+	al.mtx.Lock()
+	defer al.mtx.Unlock()
+	if len(al.entries) < cap(al.entries) {
+		al.entries = append(al.entries, entry)
+		return
+	}
+	al.entries[al.next] = entry
+	al.next = (al.next + 1) % len(al.entries)
+}
+
+// Records an event for tx. The code defaults to the code of err.
+func (al *auditLog) record(tx types.Tx, event string, code string, err error, height int) {
+	entry := &AuditEntry{
+		TxId:   types.TxId(al.chainID, tx),
+		Time:   time.Now(),
+		Event:  event,
+		Code:   code,
+		Height: height,
+	}
+	if err != nil {
+		if entry.Code == "" {
+			entry.Code = auditCode(err)
+		}
+		entry.Reason = err.Error()
+	}
+	al.add(entry)
+}
+
+// Returns the entries for txId, or all entries if txId is empty,
+// oldest first.
+func (al *auditLog) get(txId []byte) []*AuditEntry {
+	al.mtx.Lock()
+	defer al.mtx.Unlock()
+	entries := []*AuditEntry{}
+	for i := 0; i < len(al.entries); i++ {
+		entry := al.entries[(al.next+i)%len(al.entries)]
+		if len(txId) == 0 || bytes.Equal(entry.TxId, txId) {
+			entryCopy := *entry
+			entries = append(entries, &entryCopy)
+		}
+	}
+	return entries
+}
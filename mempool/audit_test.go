@@ -0,0 +1,32 @@
+package mempool
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestAuditLogDupeoutTx(t *testing.T) {
+	al := newAuditLog("test_chain", 2)
+	tx := &types.DupeoutTx{
+		Address: []byte("accused"),
+		VoteA:   types.Vote{Height: 1, Type: types.VoteTypePrevote},
+		VoteB:   types.Vote{Height: 1, Type: types.VoteTypePrevote},
+	}
+	al.record(tx, AuditRejected, "", types.ErrTxInvalidSignature, 0)
+	al.record(tx, AuditAccepted, "", nil, 0)
+	al.record(tx, AuditCommitted, "", nil, 2)
+
+	txId := types.TxId("test_chain", tx)
+	entries := al.get(txId)
+	if len(entries) != 2 {
+		t.Fatalf("Expected the 2 latest entries, got %v", len(entries))
+	}
+	if entries[0].Event != AuditAccepted || entries[1].Event != AuditCommitted || entries[1].Height != 2 {
+		t.Errorf("Unexpected entries %v %v", entries[0], entries[1])
+	}
+	if !bytes.Equal(entries[0].TxId, txId) {
+		t.Errorf("Expected TxId %X, got %X", txId, entries[0].TxId)
+	}
+}
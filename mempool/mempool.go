@@ -10,7 +10,9 @@ package mempool
 
 import (
 	"sync"
+	"time"
 
+	acm "github.com/tendermint/tendermint/account"
 	"github.com/tendermint/tendermint/binary"
 	sm "github.com/tendermint/tendermint/state"
 	"github.com/tendermint/tendermint/types"
@@ -21,6 +23,9 @@ type Mempool struct {
 	state *sm.State
 	cache *sm.BlockCache
 	txs   []types.Tx
+	times []time.Time // arrival time of each tx in txs
+
+	auditLog *auditLog // nil unless enabled
 }
 
 func NewMempool(state *sm.State) *Mempool {
@@ -30,6 +35,31 @@ func NewMempool(state *sm.State) *Mempool {
 	}
 }
 
+// Records the last size admission decisions, see GetAuditEntries.
+// Not goroutine safe.
+func (mem *Mempool) EnableAuditLog(size int) {
+	mem.auditLog = newAuditLog(mem.state.ChainID, size)
+}
+
+func (mem *Mempool) AuditLogEnabled() bool {
+	return mem.auditLog != nil
+}
+
+// Returns the recorded admission decisions about the tx with txId,
+// or all of them if txId is empty, oldest first.
+func (mem *Mempool) GetAuditEntries(txId []byte) []*AuditEntry {
+	if mem.auditLog == nil {
+		return nil
+	}
+	return mem.auditLog.get(txId)
+}
+
+func (mem *Mempool) audit(tx types.Tx, event string, code string, err error, height int) {
+	if mem.auditLog != nil {
+		mem.auditLog.record(tx, event, code, err, height)
+	}
+}
+
 func (mem *Mempool) GetState() *sm.State {
 	return mem.state
 }
@@ -38,6 +68,14 @@ func (mem *Mempool) GetCache() *sm.BlockCache {
 	return mem.cache
 }
 
+// Returns the account with the mempool's txs applied, or nil.
+// Unlike GetCache().GetAccount, it may be called concurrently with AddTx.
+func (mem *Mempool) GetAccount(address []byte) *acm.Account {
+	mem.mtx.Lock()
+	defer mem.mtx.Unlock()
+	return mem.cache.GetAccount(address)
+}
+
 // Apply tx to the state and remember it.
 func (mem *Mempool) AddTx(tx types.Tx) (err error) {
 	mem.mtx.Lock()
@@ -45,14 +83,40 @@ func (mem *Mempool) AddTx(tx types.Tx) (err error) {
 	err = sm.ExecTx(mem.cache, tx, false, nil)
 	if err != nil {
 		log.Debug("AddTx() error", "tx", tx, "error", err)
+		mem.audit(tx, AuditRejected, "", err, 0)
 		return err
 	} else {
 		log.Debug("AddTx() success", "tx", tx)
+		mem.audit(tx, AuditAccepted, "", nil, 0)
 		mem.txs = append(mem.txs, tx)
+		mem.times = append(mem.times, time.Now())
 		return nil
 	}
 }
 
+// Like AddTx for each tx, in order, but under a single lock.
+// Returns the error for each tx, nil if it was added.
+func (mem *Mempool) AddTxs(txs []types.Tx) []error {
+	mem.mtx.Lock()
+	defer mem.mtx.Unlock()
+	errs := make([]error, len(txs))
+	now := time.Now()
+	for i, tx := range txs {
+		err := sm.ExecTx(mem.cache, tx, false, nil)
+		if err != nil {
+			log.Debug("AddTxs() error", "tx", tx, "error", err)
+			mem.audit(tx, AuditRejected, "", err, 0)
+			errs[i] = err
+			continue
+		}
+		mem.audit(tx, AuditAccepted, "", nil, 0)
+		mem.txs = append(mem.txs, tx)
+		mem.times = append(mem.times, now)
+	}
+	log.Debug("AddTxs() done", "txs", len(txs))
+	return errs
+}
+
 func (mem *Mempool) GetProposalTxs() []types.Tx {
 	mem.mtx.Lock()
 	defer mem.mtx.Unlock()
@@ -60,6 +124,51 @@ func (mem *Mempool) GetProposalTxs() []types.Tx {
 	return mem.txs
 }
 
+// Returns the arrival time of each of txs,
+// or the zero time for txs that aren't in the mempool.
+func (mem *Mempool) GetTxTimes(txs []types.Tx) []time.Time {
+	mem.mtx.Lock()
+	defer mem.mtx.Unlock()
+	memTimes := make(map[string]time.Time, len(mem.txs))
+	for i, tx := range mem.txs {
+		memTimes[string(binary.BinarySha256(tx))] = mem.times[i]
+	}
+	times := make([]time.Time, len(txs))
+	for i, tx := range txs {
+		times[i] = memTimes[string(binary.BinarySha256(tx))]
+	}
+	return times
+}
+
+// Drops all txs and returns how many there were.
+func (mem *Mempool) Flush() int {
+	mem.mtx.Lock()
+	defer mem.mtx.Unlock()
+	n := len(mem.txs)
+	mem.resetToState(mem.state)
+	return n
+}
+
+// Drops all txs and applies new txs to state instead.
+// Unlike ResetForBlockAndState, state needn't follow the current state.
+func (mem *Mempool) ResetToState(state *sm.State) {
+	mem.mtx.Lock()
+	defer mem.mtx.Unlock()
+	mem.resetToState(state.Copy())
+}
+
+// CONTRACT: mem.mtx is held.
+func (mem *Mempool) resetToState(state *sm.State) {
+	log.Info("Flushing mempool", "txs", len(mem.txs))
+	for _, tx := range mem.txs {
+		mem.audit(tx, AuditEvicted, AuditCodeFlushed, nil, 0)
+	}
+	mem.state = state
+	mem.cache = sm.NewBlockCache(state)
+	mem.txs = nil
+	mem.times = nil
+}
+
 // "block" is the new block being committed.
 // "state" is the result of state.AppendBlock("block").
 // Txs that are present in "block" are discarded from mempool.
@@ -78,32 +187,37 @@ func (mem *Mempool) ResetForBlockAndState(block *types.Block, state *sm.State) {
 	}
 
 	// Next, filter all txs from mem.txs that are in blockTxsMap
-	txs := []types.Tx{}
-	for _, tx := range mem.txs {
+	txs, times := []types.Tx{}, []time.Time{}
+	for i, tx := range mem.txs {
 		txHash := binary.BinarySha256(tx)
 		if _, ok := blockTxsMap[string(txHash)]; ok {
 			log.Debug("Filter out, already committed", "tx", tx, "txHash", txHash)
+			mem.audit(tx, AuditCommitted, "", nil, block.Height)
 			continue
 		} else {
 			log.Debug("Filter in, still new", "tx", tx, "txHash", txHash)
 			txs = append(txs, tx)
+			times = append(times, mem.times[i])
 		}
 	}
 
 	// Next, filter all txs that aren't valid given new state.
-	validTxs := []types.Tx{}
-	for _, tx := range txs {
+	validTxs, validTimes := []types.Tx{}, []time.Time{}
+	for i, tx := range txs {
 		err := sm.ExecTx(mem.cache, tx, false, nil)
 		if err == nil {
 			log.Debug("Filter in, valid", "tx", tx)
 			validTxs = append(validTxs, tx)
+			validTimes = append(validTimes, times[i])
 		} else {
 			// tx is no longer valid.
 			log.Debug("Filter out, no longer valid", "tx", tx, "error", err)
+			mem.audit(tx, AuditEvicted, AuditCodeNoLongerValid, err, 0)
 		}
 	}
 
 	// We're done!
 	log.Debug("New txs", "txs", validTxs, "oldTxs", mem.txs)
 	mem.txs = validTxs
+	mem.times = validTimes
 }
@@ -2,9 +2,10 @@ package mempool
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"math/rand"
 	"reflect"
-	"sync/atomic"
 
 	"github.com/tendermint/tendermint/binary"
 	. "github.com/tendermint/tendermint/common"
@@ -15,44 +16,34 @@ import (
 
 var (
 	MempoolChannel = byte(0x30)
+
+	ErrTxsBatchTooLarge = errors.New("Error too many txs in batch")
 )
 
+// Default for SetMaxBatchTxs.
+const DefaultMaxBatchTxs = 1000
+
 // MempoolReactor handles mempool tx broadcasting amongst peers.
 type MempoolReactor struct {
-	sw      *p2p.Switch
-	quit    chan struct{}
-	started uint32
-	stopped uint32
+	*p2p.BaseReactor
 
 	Mempool *Mempool
 
+	chaosDropRate float64 // 0 unless set, see SetChaosDropRate
+	maxBatchTxs   int     // see SetMaxBatchTxs
+
 	evsw events.Fireable
 }
 
 func NewMempoolReactor(mempool *Mempool) *MempoolReactor {
 	memR := &MempoolReactor{
-		quit:    make(chan struct{}),
-		Mempool: mempool,
+		Mempool:     mempool,
+		maxBatchTxs: DefaultMaxBatchTxs,
 	}
+	memR.BaseReactor = p2p.NewBaseReactor(log, "MempoolReactor", memR)
 	return memR
 }
 
-// Implements Reactor
-func (memR *MempoolReactor) Start(sw *p2p.Switch) {
-	if atomic.CompareAndSwapUint32(&memR.started, 0, 1) {
-		memR.sw = sw
-		log.Info("Starting MempoolReactor")
-	}
-}
-
-// Implements Reactor
-func (memR *MempoolReactor) Stop() {
-	if atomic.CompareAndSwapUint32(&memR.stopped, 0, 1) {
-		log.Info("Stopping MempoolReactor")
-		close(memR.quit)
-	}
-}
-
 // Implements Reactor
 func (memR *MempoolReactor) GetChannels() []*p2p.ChannelDescriptor {
 	return []*p2p.ChannelDescriptor{
@@ -75,31 +66,54 @@ func (pexR *MempoolReactor) RemovePeer(peer *p2p.Peer, reason interface{}) {
 func (memR *MempoolReactor) Receive(chId byte, src *p2p.Peer, msgBytes []byte) {
 	_, msg_, err := DecodeMessage(msgBytes)
 	if err != nil {
-		log.Warn("Error decoding message", "error", err)
+		log.Warn("Error decoding message", "peer", src, "error", err)
+		return
+	}
+	log.Info("MempoolReactor received message", "peer", src, "msg", msg_)
+	if memR.chaosDropRate > 0 && rand.Float64() < memR.chaosDropRate {
+		log.Debug("Chaos: dropped mempool message", "peer", src)
 		return
 	}
-	log.Info("MempoolReactor received message", "msg", msg_)
 
 	switch msg := msg_.(type) {
 	case *TxMessage:
 		err := memR.Mempool.AddTx(msg.Tx)
 		if err != nil {
 			// Bad, seen, or conflicting tx.
-			log.Debug("Could not add tx", "tx", msg.Tx)
+			log.Debug("Could not add tx", "peer", src, "tx", msg.Tx, "error", err)
 			return
 		} else {
-			log.Debug("Added valid tx", "tx", msg.Tx)
+			log.Debug("Added valid tx", "peer", src, "tx", msg.Tx)
 		}
 		// Share tx.
 		// We use a simple shotgun approach for now.
 		// TODO: improve efficiency
-		for _, peer := range memR.sw.Peers().List() {
+		for _, peer := range memR.Switch.Peers().List() {
 			if peer.Key == src.Key {
 				continue
 			}
 			peer.TrySend(MempoolChannel, msg)
 		}
 
+	case *TxsMessage:
+		if len(msg.Txs) > memR.maxBatchTxs {
+			log.Warn("Peer sent too many txs in batch", "peer", src, "txs", len(msg.Txs), "max", memR.maxBatchTxs)
+			memR.Switch.StopPeerForError(src, ErrTxsBatchTooLarge)
+			return
+		}
+		added := addedTxs(msg.Txs, memR.Mempool.AddTxs(msg.Txs))
+		log.Debug("Added valid txs", "peer", src, "txs", len(added), "of", len(msg.Txs))
+		if len(added) == 0 {
+			return
+		}
+		// Share only the txs we added, still as one message.
+		for _, peer := range memR.Switch.Peers().List() {
+			if peer.Key == src.Key {
+				continue
+			}
+			peer.TrySend(MempoolChannel, &TxsMessage{Txs: added})
+		}
+
 	default:
 		log.Warn(Fmt("Unknown message type %v", reflect.TypeOf(msg)))
 	}
@@ -111,10 +125,48 @@ func (memR *MempoolReactor) BroadcastTx(tx types.Tx) error {
 		return err
 	}
 	msg := &TxMessage{Tx: tx}
-	memR.sw.Broadcast(MempoolChannel, msg)
+	memR.Switch.Broadcast(MempoolChannel, msg)
 	return nil
 }
 
+// Adds txs to the mempool as a batch and gossips the ones that were
+// added in a single message. Returns the error for each tx, nil if added,
+// or ErrTxsBatchTooLarge without adding any if there are too many txs.
+func (memR *MempoolReactor) BroadcastTxs(txs []types.Tx) ([]error, error) {
+	if len(txs) > memR.maxBatchTxs {
+		return nil, ErrTxsBatchTooLarge
+	}
+	errs := memR.Mempool.AddTxs(txs)
+	added := addedTxs(txs, errs)
+	if len(added) > 0 {
+		msg := &TxsMessage{Txs: added}
+		memR.Switch.Broadcast(MempoolChannel, msg)
+	}
+	return errs, nil
+}
+
+func addedTxs(txs []types.Tx, errs []error) []types.Tx {
+	added := make([]types.Tx, 0, len(txs))
+	for i, tx := range txs {
+		if errs[i] == nil {
+			added = append(added, tx)
+		}
+	}
+	return added
+}
+
+// Drops incoming mempool messages at rate, between 0 and 1, to exercise
+// tx gossip and recovery. Test chains only, see types.IsTestChainID.
+func (memR *MempoolReactor) SetChaosDropRate(rate float64) {
+	memR.chaosDropRate = rate
+}
+
+// Sets the most txs accepted in one batch, from BroadcastTxs or a peer.
+// Peers that send more are stopped.
+func (memR *MempoolReactor) SetMaxBatchTxs(max int) {
+	memR.maxBatchTxs = max
+}
+
 // implements events.Eventable
 func (memR *MempoolReactor) SetFireable(evsw events.Fireable) {
 	memR.evsw = evsw
@@ -124,7 +176,8 @@ func (memR *MempoolReactor) SetFireable(evsw events.Fireable) {
 // Messages
 
 const (
-	msgTypeTx = byte(0x01)
+	msgTypeTx  = byte(0x01)
+	msgTypeTxs = byte(0x02)
 )
 
 type MempoolMessage interface{}
@@ -132,6 +185,7 @@ type MempoolMessage interface{}
 var _ = binary.RegisterInterface(
 	struct{ MempoolMessage }{},
 	binary.ConcreteType{&TxMessage{}, msgTypeTx},
+	binary.ConcreteType{&TxsMessage{}, msgTypeTxs},
 )
 
 func DecodeMessage(bz []byte) (msgType byte, msg MempoolMessage, err error) {
@@ -151,3 +205,13 @@ type TxMessage struct {
 func (m *TxMessage) String() string {
 	return fmt.Sprintf("[TxMessage %v]", m.Tx)
 }
+
+//-------------------------------------
+
+type TxsMessage struct {
+	Txs []types.Tx
+}
+
+func (m *TxsMessage) String() string {
+	return fmt.Sprintf("[TxsMessage %v]", len(m.Txs))
+}
@@ -0,0 +1,97 @@
+package mempool
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/tendermint/tendermint/account"
+	_ "github.com/tendermint/tendermint/config/tendermint_test"
+	"github.com/tendermint/tendermint/p2p"
+	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+)
+
+func newTestReactor(moniker string, state *sm.State) (*MempoolReactor, *p2p.Switch) {
+	memR := NewMempoolReactor(NewMempool(state.Copy()))
+	sw := p2p.NewSwitch()
+	sw.AddReactor("MEMPOOL", memR)
+	sw.SetNodeInfo(&types.NodeInfo{
+		Moniker: moniker,
+		ChainID: state.ChainID,
+		Version: "123.123.123",
+	})
+	return memR, sw
+}
+
+func sendTxs(chainID string, privAccount *account.PrivAccount, n int) []types.Tx {
+	txs := make([]types.Tx, n)
+	for i := range txs {
+		tx := types.NewSendTx()
+		tx.AddInputWithNonce(privAccount.PubKey, 1, i+1)
+		tx.AddOutput(account.GenPrivAccount().Address, 1)
+		tx.SignInput(chainID, 0, 0, privAccount)
+		txs[i] = tx
+	}
+	return txs
+}
+
+func TestBroadcastTxsTooLarge(t *testing.T) {
+	state, privAccounts, _ := sm.RandGenesisState(1, false, 1000000, 1, false, 1000)
+	memR, _ := newTestReactor("node", state)
+	memR.SetMaxBatchTxs(2)
+
+	if _, err := memR.BroadcastTxs(sendTxs(state.ChainID, privAccounts[0], 3)); err != ErrTxsBatchTooLarge {
+		t.Errorf("Expected ErrTxsBatchTooLarge, got %v", err)
+	}
+	if n := len(memR.Mempool.GetProposalTxs()); n != 0 {
+		t.Errorf("Expected no txs in the mempool, got %v", n)
+	}
+	errs, err := memR.BroadcastTxs(sendTxs(state.ChainID, privAccounts[0], 2))
+	if err != nil || errs[0] != nil || errs[1] != nil {
+		t.Fatalf("Expected the batch to be added, got %v %v", err, errs)
+	}
+}
+
+func TestReceiveTxsTooLarge(t *testing.T) {
+	state, privAccounts, _ := sm.RandGenesisState(1, false, 1000000, 1, false, 1000)
+	memR1, sw1 := newTestReactor("node1", state)
+	_, sw2 := newTestReactor("node2", state)
+	memR1.SetMaxBatchTxs(2)
+	sw1.Start()
+	defer sw1.Stop()
+	sw2.Start()
+	defer sw2.Stop()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		if conn, err := l.Accept(); err == nil {
+			sw1.AddPeerWithConnection(conn, false)
+		}
+	}()
+	conn2, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	peer, err := sw2.AddPeerWithConnection(conn2, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if sw1.Peers().Size() != 1 {
+		t.Fatalf("Expected node1 to have 1 peer, got %v", sw1.Peers().Size())
+	}
+
+	peer.Send(MempoolChannel, &TxsMessage{Txs: sendTxs(state.ChainID, privAccounts[0], 3)})
+	time.Sleep(100 * time.Millisecond)
+	if sw1.Peers().Size() != 0 {
+		t.Errorf("Expected node1 to stop the peer that sent too many txs")
+	}
+	if n := len(memR1.Mempool.GetProposalTxs()); n != 0 {
+		t.Errorf("Expected no txs in the mempool, got %v", n)
+	}
+}
@@ -0,0 +1,174 @@
+package merkle
+
+import (
+	"bytes"
+	"errors"
+)
+
+var (
+	ErrIAVLNodeInvalid    = errors.New("Error invalid IAVL node")
+	ErrIAVLNodeMissing    = errors.New("Error missing IAVL node")
+	ErrIAVLTooManyChanges = errors.New("Error too many IAVL nodes changed")
+)
+
+// The nodes of a tree in key order, from which subtrees are popped whole
+// or expanded into their children.
+type iavlFrontier struct {
+	t     *IAVLTree
+	nodes []*IAVLNode // The next node is last.
+}
+
+func newIAVLFrontier(t *IAVLTree) *iavlFrontier {
+	f := &iavlFrontier{t: t}
+	if t.root != nil {
+		f.nodes = []*IAVLNode{t.root}
+	}
+	return f
+}
+
+func (f *iavlFrontier) next() *IAVLNode {
+	if len(f.nodes) == 0 {
+		return nil
+	}
+	return f.nodes[len(f.nodes)-1]
+}
+
+func (f *iavlFrontier) pop() *IAVLNode {
+	node := f.next()
+	f.nodes = f.nodes[:len(f.nodes)-1]
+	return node
+}
+
+func (f *iavlFrontier) expand() *IAVLNode {
+	node := f.pop()
+	f.nodes = append(f.nodes, node.getRightNode(f.t), node.getLeftNode(f.t))
+	return node
+}
+
+// Returns the persisted nodes of t that aren't in from, e.g. to send t to
+// a node that has from. Both trees are walked in key order, skipping the
+// subtrees they share, so this is about O(changes * log(size)).
+// A few shared nodes may be included when the trees' shapes differ.
+// leafFn, if not nil, is called with the key and value of each new leaf.
+// Stops with ErrIAVLTooManyChanges after maxNodes nodes, unless maxNodes is -1.
+// NOTE: Both trees must be saved.
+func (t *IAVLTree) NewNodes(from *IAVLTree, maxNodes int, leafFn func(key interface{}, value interface{})) ([][]byte, error) {
+	nodes := [][]byte{}
+	add := func(node *IAVLNode) error {
+		if len(nodes) == maxNodes {
+			return ErrIAVLTooManyChanges
+		}
+		if node.height == 0 && leafFn != nil {
+			leafFn(node.key, node.value)
+		}
+		buf := new(bytes.Buffer)
+		if _, err := node.writePersistBytes(t, buf); err != nil {
+			return err
+		}
+		nodes = append(nodes, buf.Bytes())
+		return nil
+	}
+	old, new_ := newIAVLFrontier(from), newIAVLFrontier(t)
+	for new_.next() != nil {
+		oldNode, newNode := old.next(), new_.next()
+		var err error
+		switch {
+		case oldNode == nil:
+			if newNode.height > 0 {
+				err = add(new_.expand())
+			} else {
+				err = add(new_.pop())
+			}
+		case bytes.Equal(oldNode.hash, newNode.hash):
+			old.pop()
+			new_.pop()
+		case newNode.height > 0 && newNode.height >= oldNode.height:
+			err = add(new_.expand())
+		case oldNode.height > 0:
+			old.expand()
+		default:
+			// Both are leaves.
+			cmp := t.keyCodec.Compare(oldNode.key, newNode.key)
+			if cmp <= 0 {
+				old.pop()
+			}
+			if cmp >= 0 {
+				err = add(new_.pop())
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nodes, nil
+}
+
+// Saves nodes, e.g. from NewNodes, and loads the tree at root.
+// Each node is saved by its hash, and nodes are only saved once they and
+// the nodes already saved make up the whole tree at root.
+func (t *IAVLTree) ImportNodes(nodes [][]byte, root []byte) (err error) {
+	defer func() {
+		// Undecodable nodes panic in ReadIAVLNode.
+		if r := recover(); r != nil {
+			err = ErrIAVLNodeInvalid
+		}
+	}()
+	imported := make(map[string]*IAVLNode, len(nodes))
+	for _, nodeBytes := range nodes {
+		var n int64
+		var err error
+		node := ReadIAVLNode(t, bytes.NewReader(nodeBytes), &n, &err)
+		buf := new(bytes.Buffer)
+		if _, err := node.writePersistBytes(t, buf); err != nil || !bytes.Equal(buf.Bytes(), nodeBytes) {
+			return ErrIAVLNodeInvalid
+		}
+		node.hash, _ = node.hashWithCount(t)
+		node.persisted = true
+		imported[string(node.hash)] = node
+	}
+	getNode := func(hash []byte) *IAVLNode {
+		if node, ok := imported[string(hash)]; ok {
+			return node
+		}
+		if t.ndb.db.Get(hash) == nil {
+			return nil
+		}
+		return t.ndb.GetNode(t, hash)
+	}
+	// Returns the leftmost or rightmost leaf under hash.
+	edgeLeaf := func(hash []byte, left bool) *IAVLNode {
+		node := getNode(hash)
+		for node != nil && node.height > 0 {
+			if left {
+				node = getNode(node.leftHash)
+			} else {
+				node = getNode(node.rightHash)
+			}
+		}
+		return node
+	}
+	if len(root) > 0 && getNode(root) == nil {
+		return ErrIAVLNodeMissing
+	}
+	for _, node := range imported {
+		if node.height == 0 {
+			continue
+		}
+		// Inner keys aren't hashed, so check that each splits its
+		// children's keys.
+		leftLeaf, rightLeaf := edgeLeaf(node.leftHash, false), edgeLeaf(node.rightHash, true)
+		if leftLeaf == nil || rightLeaf == nil {
+			return ErrIAVLNodeMissing
+		}
+		if t.keyCodec.Compare(leftLeaf.key, node.key) >= 0 || t.keyCodec.Compare(node.key, rightLeaf.key) > 0 {
+			return ErrIAVLNodeInvalid
+		}
+	}
+	for hash, node := range imported {
+		buf := new(bytes.Buffer)
+		node.writePersistBytes(t, buf)
+		t.ndb.db.Set([]byte(hash), buf.Bytes())
+	}
+	t.Load(root)
+	return nil
+}
@@ -10,12 +10,18 @@ import (
 
 // Node
 
+/*
+A nil hash marks a node as dirty. Nodes are immutable once hashed:
+set/remove/rotate _copy() each node on the path to the root, leaving the
+copies unhashed and every untouched subtree with its cached hash. So
+hashWithCount only visits dirty nodes, O(updates * log(size)) per commit.
+*/
 type IAVLNode struct {
 	key       interface{}
 	value     interface{}
 	height    int8
 	size      int
-	hash      []byte
+	hash      []byte // nil if dirty
 	leftHash  []byte
 	leftNode  *IAVLNode
 	rightHash []byte
@@ -421,6 +427,24 @@ func (node *IAVLNode) traverse(t *IAVLTree, cb func(*IAVLNode) bool) bool {
 	return false
 }
 
+// Calls cb with the leaves from the one at index.
+func (node *IAVLNode) traverseFrom(t *IAVLTree, index int, cb func(*IAVLNode) bool) bool {
+	if node.height == 0 {
+		if index > 0 {
+			return false
+		}
+		return cb(node)
+	}
+	leftNode := node.getLeftNode(t)
+	if index < leftNode.size {
+		if leftNode.traverseFrom(t, index, cb) {
+			return true
+		}
+		index = leftNode.size
+	}
+	return node.getRightNode(t).traverseFrom(t, index-leftNode.size, cb)
+}
+
 // Only used in testing...
 func (node *IAVLNode) lmd(t *IAVLTree) *IAVLNode {
 	if node.height == 0 {
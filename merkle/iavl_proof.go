@@ -47,7 +47,8 @@ func (branch IAVLProofInnerNode) Hash(childHash []byte) []byte {
 	n, err := int64(0), error(nil)
 	binary.WriteInt8(branch.Height, buf, &n, &err)
 	binary.WriteVarint(branch.Size, buf, &n, &err)
-	if branch.Left == nil {
+	// Left is empty, not nil, once decoded from JSON.
+	if len(branch.Left) == 0 {
 		binary.WriteByteSlice(childHash, buf, &n, &err)
 		binary.WriteByteSlice(branch.Right, buf, &n, &err)
 	} else {
@@ -332,3 +332,180 @@ func BenchmarkImmutableAvlTree(b *testing.B) {
 		t.Remove(ri)
 	}
 }
+
+func TestIAVLIncrementalHash(t *testing.T) {
+	tree := NewIAVLTree(binary.BasicCodec, binary.BasicCodec, 0, nil)
+	keys := []string{}
+	for i := 0; i < 1000; i++ {
+		key := randstr(20)
+		keys = append(keys, key)
+		tree.Set(key, randstr(20))
+	}
+	tree.Hash()
+
+	// Nothing is dirty after hashing.
+	if _, count := tree.HashWithCount(); count != 0 {
+		t.Fatalf("Expected no new hashes, got %v", count)
+	}
+
+	// Updating a value only dirties the path to the root.
+	tree.Set(keys[500], randstr(20))
+	hash, count := tree.HashWithCount()
+	if count == 0 || count > int(tree.Height())+1 {
+		t.Errorf("Expected at most %v new hashes, got %v", tree.Height()+1, count)
+	}
+
+	// Recomputing every hash from scratch agrees.
+	tree.root.traverse(tree, func(node *IAVLNode) bool {
+		node.hash = nil
+		return false
+	})
+	fullHash, fullCount := tree.HashWithCount()
+	if !bytes.Equal(hash, fullHash) {
+		t.Errorf("Expected hash %X, got %X", fullHash, hash)
+	}
+	if fullCount != 2*tree.Size()-1 {
+		t.Errorf("Expected %v hashes for a full recompute, got %v", 2*tree.Size()-1, fullCount)
+	}
+}
+
+// Updates `updates` random keys of a tree with `size` keys, then hashes.
+// If full, all cached hashes are cleared first, as if the root were
+// recomputed from scratch at each commit.
+func benchmarkIAVLHash(b *testing.B, size, updates int, full bool) {
+	b.StopTimer()
+	tree := NewIAVLTree(binary.BasicCodec, binary.BasicCodec, 0, nil)
+	for i := 0; i < size; i++ {
+		tree.Set(RandInt64(), randstr(20))
+	}
+	tree.Hash()
+	runtime.GC()
+
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < updates; j++ {
+			tree.Set(RandInt64(), randstr(20))
+		}
+		if full {
+			tree.root.traverse(tree, func(node *IAVLNode) bool {
+				node.hash = nil
+				return false
+			})
+		}
+		b.StartTimer()
+		tree.Hash()
+		b.StopTimer()
+	}
+}
+
+func BenchmarkIAVLHashIncremental(b *testing.B) {
+	benchmarkIAVLHash(b, 100000, 100, false)
+}
+
+func BenchmarkIAVLHashFull(b *testing.B) {
+	benchmarkIAVLHash(b, 100000, 100, true)
+}
+
+func TestIAVLNewNodes(t *testing.T) {
+	// The same old tree in two dbs
+	db1, db2, db3 := db.NewMemDB(), db.NewMemDB(), db.NewMemDB()
+	keys := []string{}
+	for i := 0; i < 1000; i++ {
+		keys = append(keys, randstr(20))
+	}
+	trees := []*IAVLTree{}
+	for _, d := range []db.DB{db1, db2, db3} {
+		tree := NewIAVLTree(binary.BasicCodec, binary.BasicCodec, 0, d)
+		for _, key := range keys {
+			tree.Set(key, key)
+		}
+		tree.Save()
+		trees = append(trees, tree)
+	}
+	oldTree := trees[0]
+
+	// Change it in the first
+	newTree := oldTree.Copy().(*IAVLTree)
+	for i := 0; i < 10; i++ {
+		newTree.Set(randstr(20), randstr(20))
+		newTree.Set(keys[i], randstr(20))
+		newTree.Remove(keys[i+100])
+	}
+	root := newTree.Save()
+
+	leaves := 0
+	nodes, err := newTree.NewNodes(oldTree, 1000, func(key, value interface{}) { leaves++ })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leaves != 20 || len(nodes) > 20*2*int(newTree.Height()) {
+		t.Errorf("Expected 20 new leaves and few nodes, got %v leaves and %v nodes", leaves, len(nodes))
+	}
+	if _, err := newTree.NewNodes(oldTree, 10, nil); err != ErrIAVLTooManyChanges {
+		t.Errorf("Expected ErrIAVLTooManyChanges, got %v", err)
+	}
+
+	// An inner key isn't hashed, so a wrong one must be caught.
+	var n int64
+	node := ReadIAVLNode(newTree, bytes.NewReader(nodes[0]), &n, &err)
+	node.key = "wrong"
+	buf := new(bytes.Buffer)
+	node.writePersistBytes(newTree, buf)
+	badNodes := append([][]byte{buf.Bytes()}, nodes[1:]...)
+	if err := trees[2].ImportNodes(badNodes, root); err != ErrIAVLNodeInvalid {
+		t.Errorf("Expected ErrIAVLNodeInvalid, got %v", err)
+	}
+	if err := trees[2].ImportNodes(nodes[1:], root); err != ErrIAVLNodeMissing {
+		t.Errorf("Expected ErrIAVLNodeMissing, got %v", err)
+	}
+
+	// Import the new nodes into the second
+	if err := trees[1].ImportNodes(nodes, root); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(trees[1].Hash(), root) {
+		t.Fatalf("Expected the imported tree to have hash %X, got %X", root, trees[1].Hash())
+	}
+	newTree.Iterate(func(key, value interface{}) bool {
+		if _, value2 := trees[1].Get(key); value2 != value {
+			t.Fatalf("Expected %v at %v, got %v", value, key, value2)
+		}
+		return false
+	})
+	if trees[1].Size() != newTree.Size() {
+		t.Errorf("Expected size %v, got %v", newTree.Size(), trees[1].Size())
+	}
+}
+
+func TestIAVLIterateFrom(t *testing.T) {
+	tree := NewIAVLTree(binary.BasicCodec, binary.BasicCodec, 0, db.NewMemDB())
+	for i := 0; i < 100; i++ {
+		tree.Set(randstr(20), "")
+	}
+	tree.Save()
+	// From a loaded tree, so that skipped subtrees aren't in memory.
+	loaded := NewIAVLTree(binary.BasicCodec, binary.BasicCodec, 0, tree.ndb.db)
+	loaded.Load(tree.Hash())
+
+	for _, from := range []int{0, 1, 37, 99, 100, 200} {
+		index := from
+		loaded.IterateFrom(from, func(key interface{}, value interface{}) bool {
+			if expected, _ := tree.GetByIndex(index); key != expected {
+				t.Fatalf("Expected key %v at %v, got %v", expected, index, key)
+			}
+			index++
+			return false
+		})
+		if index != MaxInt(from, 100) {
+			t.Errorf("Expected to iterate from %v to the end, stopped at %v", from, index)
+		}
+	}
+	// Stops when fn returns true.
+	count := 0
+	loaded.IterateFrom(10, func(key interface{}, value interface{}) bool {
+		count++
+		return count == 5
+	})
+	if count != 5 {
+		t.Errorf("Expected to stop after 5 keys, got %v", count)
+	}
+}
@@ -173,6 +173,17 @@ func (t *IAVLTree) Iterate(fn func(key interface{}, value interface{}) bool) (st
 	})
 }
 
+// Like Iterate, but starts at the leaf at index, skipping the subtrees
+// before it.
+func (t *IAVLTree) IterateFrom(index int, fn func(key interface{}, value interface{}) bool) (stopped bool) {
+	if t.root == nil {
+		return false
+	}
+	return t.root.traverseFrom(t, index, func(node *IAVLNode) bool {
+		return fn(node.key, node.value)
+	})
+}
+
 //-----------------------------------------------------------------------------
 
 type nodeElement struct {
@@ -0,0 +1,80 @@
+package node
+
+import (
+	"math/rand"
+	"time"
+
+	. "github.com/tendermint/tendermint/common"
+	"github.com/tendermint/tendermint/consensus"
+	mempl "github.com/tendermint/tendermint/mempool"
+	"github.com/tendermint/tendermint/types"
+)
+
+// Lets the peer see the disconnect before we redial.
+const chaosRedialDelay = 2 * time.Second
+
+/*
+The chaos flags make a node misbehave, so that a staging network
+continuously exercises its timeout and recovery paths:
+
+    chaos_vote_delay              delays our votes by up to this many ms
+    chaos_mempool_drop_percent    drops this percent of incoming mempool messages
+    chaos_peer_restart_interval   every this many seconds, disconnects a random
+                                  peer, redialing it if outbound, so that each
+                                  reactor drops and restarts its routines for it
+
+They are refused on chains that aren't test chains, see types.IsTestChainID.
+Returns the peer restart interval, or 0.
+*/
+func configureChaos(chainID string, consensusState *consensus.ConsensusState, mempoolReactor *mempl.MempoolReactor) time.Duration {
+	voteDelay := time.Duration(config.GetInt("chaos_vote_delay")) * time.Millisecond
+	dropPercent := config.GetFloat64("chaos_mempool_drop_percent")
+	restartInterval := time.Duration(config.GetInt("chaos_peer_restart_interval")) * time.Second
+	if voteDelay <= 0 && dropPercent <= 0 && restartInterval <= 0 {
+		return 0
+	}
+	if !types.IsTestChainID(chainID) {
+		Exit(Fmt("Refusing to enable chaos flags on chain %v, which is not a test chain", chainID))
+	}
+	if voteDelay > 0 {
+		consensusState.SetChaosVoteDelay(voteDelay)
+	}
+	if dropPercent > 0 {
+		mempoolReactor.SetChaosDropRate(dropPercent / 100)
+	}
+	log.Warn("Enabled chaos flags", "voteDelay", voteDelay, "mempoolDropPercent", dropPercent,
+		"peerRestartInterval", restartInterval)
+	if restartInterval < 0 {
+		return 0
+	}
+	return restartInterval
+}
+
+func (n *Node) chaosPeerRestartRoutine(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-n.Quit:
+			return
+		case <-ticker.C:
+		}
+		peers := n.sw.Peers().List()
+		if len(peers) == 0 {
+			continue
+		}
+		peer := peers[rand.Intn(len(peers))]
+		log.Info("Chaos: restarting peer", "peer", peer)
+		n.sw.StopPeerGracefully(peer)
+		if !peer.IsOutbound() {
+			// Inbound peers redial us themselves.
+			continue
+		}
+		addr := peer.Connection().RemoteAddress
+		time.AfterFunc(chaosRedialDelay, func() {
+			if _, err := n.sw.DialPeerWithAddress(addr); err != nil {
+				log.Warn("Chaos: error redialing peer", "address", addr, "error", err)
+			}
+		})
+	}
+}
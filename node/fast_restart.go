@@ -0,0 +1,80 @@
+package node
+
+import (
+	"bytes"
+	"errors"
+
+	bc "github.com/tendermint/tendermint/blockchain"
+	"github.com/tendermint/tendermint/rpc/core_client"
+	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+)
+
+var (
+	ErrFastRestartTooFar    = errors.New("Error fast restart peer is too far ahead")
+	ErrFastRestartBadParts  = errors.New("Error fast restart block doesn't match its parts header")
+	ErrFastRestartBadBlocks = errors.New("Error fast restart blocks don't match the block store")
+)
+
+// Fast restart is for short downtimes, since the diff must be committed by
+// +2/3 of the validators the node last knew.
+const maxFastRestartBlocks = 1000
+
+// Brings state up to date with the state diff of the trusted node at the
+// RPC address peer, see sm.State.ApplyDiff, and saves the missed blocks
+// without executing them.
+func fastRestart(peer string, state *sm.State, blockStore *bc.BlockStore) (*sm.State, error) {
+	client := core_client.NewClient(peer, "JSONRPC")
+	res, err := client.StateDiff(state.Roots())
+	if err != nil {
+		return nil, err
+	}
+	if res.Height <= state.LastBlockHeight {
+		return state, nil
+	}
+	if res.Height-state.LastBlockHeight > maxFastRestartBlocks || blockStore.Height() > res.Height {
+		return nil, ErrFastRestartTooFar
+	}
+	blocks := []*types.Block{}
+	for height := state.LastBlockHeight + 1; height <= res.Height; height++ {
+		blockRes, err := client.GetBlock(uint(height))
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, blockRes.Block)
+	}
+	newState, err := state.ApplyDiff(res.Diff, blocks, res.Validation)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check all the blocks before saving any.
+	partSets := make([]*types.PartSet, len(blocks))
+	for i, block := range blocks {
+		header := newState.LastBlockParts
+		if i+1 < len(blocks) {
+			header = blocks[i+1].LastBlockParts
+		}
+		partSets[i] = block.MakePartSetFor(header)
+		if !partSets[i].Header().Equals(header) {
+			return nil, ErrFastRestartBadParts
+		}
+		// Blocks may have been saved before a crash, before the state was.
+		if block.Height <= blockStore.Height() && !bytes.Equal(blockStore.LoadBlockMeta(block.Height).Hash, block.Hash()) {
+			return nil, ErrFastRestartBadBlocks
+		}
+	}
+	for i, block := range blocks {
+		if block.Height <= blockStore.Height() {
+			continue
+		}
+		seenValidation := res.Validation
+		if i+1 < len(blocks) {
+			seenValidation = blocks[i+1].LastValidation
+		}
+		blockStore.SaveBlock(block, partSets[i], seenValidation)
+	}
+	newState.Save()
+	log.Info("Fast restarted from state diff", "peer", peer, "height", newState.LastBlockHeight)
+	return newState, nil
+}
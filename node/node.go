@@ -1,7 +1,9 @@
 package node
 
 import (
+	"crypto/tls"
 	"fmt"
+	"io/ioutil"
 	"math/rand"
 	"net"
 	"net/http"
@@ -10,11 +12,14 @@ import (
 	"strings"
 	"time"
 
+	"github.com/tendermint/tendermint/account"
+	"github.com/tendermint/tendermint/binary"
 	bc "github.com/tendermint/tendermint/blockchain"
 	. "github.com/tendermint/tendermint/common"
 	"github.com/tendermint/tendermint/consensus"
 	dbm "github.com/tendermint/tendermint/db"
 	"github.com/tendermint/tendermint/events"
+	"github.com/tendermint/tendermint/faucet"
 	mempl "github.com/tendermint/tendermint/mempool"
 	"github.com/tendermint/tendermint/p2p"
 	"github.com/tendermint/tendermint/rpc/core"
@@ -32,6 +37,8 @@ func init() {
 }
 
 type Node struct {
+	*BaseService
+
 	sw               *p2p.Switch
 	evsw             *events.EventSwitch
 	book             *p2p.AddrBook
@@ -42,12 +49,40 @@ type Node struct {
 	consensusState   *consensus.ConsensusState
 	consensusReactor *consensus.ConsensusReactor
 	privValidator    *sm.PrivValidator
+	faucet           *faucet.Faucet // nil unless faucet_file is set
+	chaosRestart     time.Duration  // 0 unless chaos_peer_restart_interval is set
+	blockStoreDB     dbm.DB
+	outboxDB         dbm.DB      // nil unless outbox is set
+	txIndex          *bc.TxIndex // nil unless tx_index is set
+	txIndexDB        dbm.DB      // nil unless tx_index is set
+	stateDB          dbm.DB
+	dirLocks         []*dbm.DirLock // none with the memdb backend
+	rpcListener      net.Listener
 }
 
 func NewNode() *Node {
+	dirLocks := lockDBDirs()
+
 	// Get BlockStore
 	blockStoreDB := dbm.GetDB("blockstore")
-	blockStore := bc.NewBlockStore(blockStoreDB)
+	var blockStore *bc.BlockStore
+	if coldDir := config.GetString("block_store_cold_dir"); coldDir != "" {
+		coldDB := dbm.GetDBAt("blockstore", coldDir)
+		blockStore = bc.NewTieredBlockStore(blockStoreDB, coldDB, config.GetInt("block_store_hot_heights"))
+	} else {
+		blockStore = bc.NewBlockStore(blockStoreDB)
+	}
+	var outboxDB dbm.DB
+	if config.GetBool("outbox") {
+		outboxDB = dbm.GetDB("outbox")
+		blockStore.SetOutbox(bc.NewOutbox(outboxDB, blockStore))
+	}
+	var txIndex *bc.TxIndex
+	var txIndexDB dbm.DB
+	if config.GetBool("tx_index") {
+		txIndexDB = dbm.GetDB("txindex")
+		txIndex = bc.NewTxIndex(txIndexDB)
+	}
 
 	// Get State
 	stateDB := dbm.GetDB("state")
@@ -56,6 +91,13 @@ func NewNode() *Node {
 		state = sm.MakeGenesisStateFromFile(stateDB, config.GetString("genesis_file"))
 		state.Save()
 	}
+	if peer := config.GetString("fast_restart_peer"); peer != "" {
+		if newState, err := fastRestart(peer, state, blockStore); err != nil {
+			log.Warn("Fast restart failed, syncing blocks instead", "peer", peer, "error", err)
+		} else {
+			state = newState
+		}
+	}
 	// add the chainid to the global config
 	config.Set("chain_id", state.ChainID)
 
@@ -82,10 +124,17 @@ func NewNode() *Node {
 
 	// Get BlockchainReactor
 	bcReactor := bc.NewBlockchainReactor(state, blockStore, config.GetBool("fast_sync"))
+	if txIndex != nil {
+		bcReactor.SetTxIndex(txIndex)
+	}
 
 	// Get MempoolReactor
 	mempool := mempl.NewMempool(state.Copy())
+	if size := config.GetInt("mempool_audit_size"); size > 0 {
+		mempool.EnableAuditLog(size)
+	}
 	mempoolReactor := mempl.NewMempoolReactor(mempool)
+	mempoolReactor.SetMaxBatchTxs(config.GetInt("mempool_max_batch_txs"))
 
 	// Get ConsensusReactor
 	consensusState := consensus.NewConsensusState(state, blockStore, mempoolReactor)
@@ -93,8 +142,27 @@ func NewNode() *Node {
 	if privValidator != nil {
 		consensusReactor.SetPrivValidator(privValidator)
 	}
+	if txIndex != nil {
+		consensusState.SetTxIndex(txIndex)
+	}
+
+	// Get Faucet
+	var fct *faucet.Faucet
+	if faucetFile := config.GetString("faucet_file"); faucetFile != "" {
+		fct = makeFaucet(faucetFile, state.ChainID, mempoolReactor)
+	}
+
+	// Chaos flags, test chains only
+	chaosRestart := configureChaos(state.ChainID, consensusState, mempoolReactor)
 
 	sw := p2p.NewSwitch()
+	sw.SetAllowSameIPPeers(config.GetBool("allow_same_ip_peers"))
+	if minPeers, sentries := config.GetInt("min_signing_peers"), configList("signing_sentries"); minPeers > 0 || len(sentries) > 0 {
+		consensusState.SetSigningGate(consensus.NewSigningGate(sw, minPeers, sentries))
+	}
+	if maxMissed := config.GetInt("alert_missed_blocks"); maxMissed > 0 {
+		consensusState.SetMissedSigningAlert(maxMissed)
+	}
 	sw.AddReactor("PEX", pexReactor)
 	sw.AddReactor("MEMPOOL", mempoolReactor)
 	sw.AddReactor("BLOCKCHAIN", bcReactor)
@@ -104,7 +172,7 @@ func NewNode() *Node {
 	// they should all satisfy events.Eventable
 	SetFireable(eventSwitch, pexReactor, bcReactor, mempoolReactor, consensusReactor)
 
-	return &Node{
+	node := &Node{
 		sw:               sw,
 		evsw:             eventSwitch,
 		book:             book,
@@ -115,23 +183,106 @@ func NewNode() *Node {
 		consensusState:   consensusState,
 		consensusReactor: consensusReactor,
 		privValidator:    privValidator,
+		faucet:           fct,
+		chaosRestart:     chaosRestart,
+		blockStoreDB:     blockStoreDB,
+		outboxDB:         outboxDB,
+		txIndex:          txIndex,
+		txIndexDB:        txIndexDB,
+		stateDB:          stateDB,
+		dirLocks:         dirLocks,
+	}
+	node.BaseService = NewBaseService(log, "Node", node)
+	return node
+}
+
+// Locks db_dir, and block_store_cold_dir if set, so that no other node
+// process opens their databases. With force_unlock their locks are removed
+// first, e.g. a lock from another host that can't be told stale.
+func lockDBDirs() []*dbm.DirLock {
+	if config.GetString("db_backend") == dbm.DBBackendMemDB {
+		return nil
+	}
+	dirs := []string{config.GetString("db_dir")}
+	if coldDir := config.GetString("block_store_cold_dir"); coldDir != "" {
+		dirs = append(dirs, coldDir)
+	}
+	locks := []*dbm.DirLock{}
+	for _, dir := range dirs {
+		if config.GetBool("force_unlock") {
+			log.Warn("Forcing unlock of data directory", "dir", dir)
+			if err := dbm.ForceUnlock(dir); err != nil {
+				Exit(Fmt("Failed to unlock %v: %v", dir, err))
+			}
+		}
+		lock, err := dbm.LockDir(dir)
+		if err != nil {
+			Exit(err.Error())
+		}
+		if lock.Stale != nil {
+			log.Warn("Took over the stale lock of a crashed process", "dir", dir,
+				"pid", lock.Stale.Pid, "host", lock.Stale.Host, "since", lock.Stale.Time)
+		}
+		locks = append(locks, lock)
+	}
+	return locks
+}
+
+// The faucet holds a funded key, so it is refused on production chains.
+func makeFaucet(faucetFile, chainID string, mempoolReactor *mempl.MempoolReactor) *faucet.Faucet {
+	if !types.IsTestChainID(chainID) {
+		Exit(Fmt("Refusing to enable the faucet on chain %v, which is not a test chain", chainID))
+	}
+	privAccountJSONBytes, err := ioutil.ReadFile(faucetFile)
+	if err != nil {
+		Exit(err.Error())
 	}
+	privAccount := binary.ReadJSON(&account.PrivAccount{}, privAccountJSONBytes, &err).(*account.PrivAccount)
+	if err != nil {
+		Exit(Fmt("Error reading faucet PrivAccount from %v: %v\n", faucetFile, err))
+	}
+	fct := faucet.NewFaucet(chainID, privAccount, mempoolReactor,
+		int64(config.GetInt("faucet_max_amount")),
+		time.Duration(config.GetInt("faucet_interval"))*time.Second,
+		config.GetInt("faucet_max_sends"))
+	log.Info("Enabled faucet", "address", Fmt("%X", fct.Address()))
+	return fct
 }
 
 // Call Start() after adding the listeners.
-func (n *Node) Start() {
-	log.Info("Starting Node")
+func (n *Node) OnStart() error {
+	n.BaseService.OnStart()
 	n.book.Start()
 	nodeInfo := makeNodeInfo(n.sw)
 	n.sw.SetNodeInfo(nodeInfo)
-	n.sw.Start()
+	_, err := n.sw.Start()
+	if err == nil && n.chaosRestart > 0 {
+		go n.chaosPeerRestartRoutine(n.chaosRestart)
+	}
+	return err
 }
 
-func (n *Node) Stop() {
-	log.Info("Stopping Node")
-	// TODO: gracefully disconnect from peers.
+// Shuts down in dependency order: stop taking RPC requests, then stop gossip
+// and consensus (which waits for any block being saved), then close the DBs.
+func (n *Node) OnStop() {
+	n.BaseService.OnStop()
+	if n.rpcListener != nil {
+		n.rpcListener.Close()
+	}
 	n.sw.Stop()
 	n.book.Stop()
+	n.evsw.Stop()
+	n.blockStoreDB.Close()
+	if n.outboxDB != nil {
+		n.outboxDB.Close()
+	}
+	if n.txIndexDB != nil {
+		n.txIndexDB.Close()
+	}
+	n.stateDB.Close()
+	for _, lock := range n.dirLocks {
+		lock.Unlock()
+	}
 }
 
 // Add the event switch to reactors, mempool, etc.
@@ -177,19 +328,73 @@ func (n *Node) dialSeed(addr *p2p.NetAddress) {
 	}
 }
 
-func (n *Node) StartRPC() {
+func (n *Node) StartRPC() error {
 	core.SetBlockStore(n.blockStore)
+	core.SetBlockchainReactor(n.bcReactor)
 	core.SetConsensusState(n.consensusState)
 	core.SetConsensusReactor(n.consensusReactor)
 	core.SetMempoolReactor(n.mempoolReactor)
 	core.SetSwitch(n.sw)
 	core.SetPrivValidator(n.privValidator)
+	core.SetFaucet(n.faucet)
+	core.SetTxIndex(n.txIndex)
 
 	listenAddr := config.GetString("rpc_laddr")
 	mux := http.NewServeMux()
 	rpcserver.RegisterEventsHandler(mux, n.evsw)
-	rpcserver.RegisterRPCFuncs(mux, core.Routes)
-	rpcserver.StartHTTPServer(listenAddr, mux)
+	rpcserver.RegisterRPCFuncs(mux, core.RoutesFor(config.GetBool("rpc_unsafe")))
+	rpcserver.RegisterStreamFunc(mux, "stream_state", core.StreamState, []string{"height", "offset"})
+	middlewareConfig := rpcMiddlewareConfig()
+	handler := rpcserver.NewMiddleware(mux, middlewareConfig)
+
+	var listener net.Listener
+	var err error
+	certFile, keyFile := config.GetString("rpc_tls_cert_file"), config.GetString("rpc_tls_key_file")
+	if certFile != "" || keyFile != "" {
+		var tlsConfig *tls.Config
+		tlsConfig, err = rpcserver.NewTLSConfig(certFile, keyFile)
+		if err != nil {
+			return err
+		}
+		listener, err = rpcserver.StartHTTPSServer(listenAddr, handler, tlsConfig)
+	} else {
+		if len(middlewareConfig.AdminFingerprints) > 0 {
+			return fmt.Errorf("rpc_admin_client_fingerprints requires rpc_tls_cert_file and rpc_tls_key_file")
+		}
+		listener, err = rpcserver.StartHTTPServer(listenAddr, handler)
+	}
+	if err != nil {
+		return err
+	}
+	n.rpcListener = listener
+	return nil
+}
+
+func rpcMiddlewareConfig() rpcserver.MiddlewareConfig {
+	return rpcserver.MiddlewareConfig{
+		RateLimit:    config.GetFloat64("rpc_rate_limit"),
+		RateBurst:    config.GetInt("rpc_rate_burst"),
+		MaxBodyBytes: int64(config.GetInt("rpc_max_body_bytes")),
+		CORSOrigins:  configList("rpc_cors_origins"),
+		AuthToken:    config.GetString("rpc_auth_token"),
+		AuthUser:     config.GetString("rpc_auth_user"),
+		AuthPassword: config.GetString("rpc_auth_password"),
+		IsProtected:  core.IsWriteRoute,
+
+		AdminFingerprints: configList("rpc_admin_client_fingerprints"),
+		IsAdmin:           core.IsAdminRoute,
+	}
+}
+
+// Returns the non-empty items of a comma separated config value.
+func configList(key string) []string {
+	items := []string{}
+	for _, item := range strings.Split(config.GetString(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
 
 func (n *Node) Switch() *p2p.Switch {
@@ -217,6 +422,8 @@ func makeNodeInfo(sw *p2p.Switch) *types.NodeInfo {
 		ChainID: config.GetString("chain_id"),
 		Moniker: config.GetString("moniker"),
 		Version: config.GetString("version"),
+
+		Protocol: types.CurrentProtocolVersion(),
 	}
 	if !sw.IsListening() {
 		return nodeInfo
@@ -246,7 +453,9 @@ func RunNode() {
 	n := NewNode()
 	l := p2p.NewDefaultListener("tcp", config.GetString("node_laddr"), false)
 	n.AddListener(l)
-	n.Start()
+	if _, err := n.Start(); err != nil {
+		Exit(Fmt("Failed to start node: %v", err))
+	}
 
 	// If seedNode is provided by config, dial out.
 	if len(config.GetString("seeds")) > 0 {
@@ -255,10 +464,12 @@ func RunNode() {
 
 	// Run the RPC server.
 	if config.GetString("rpc_laddr") != "" {
-		n.StartRPC()
+		if err := n.StartRPC(); err != nil {
+			Exit(Fmt("Failed to start RPC server: %v", err))
+		}
 	}
 
-	// Sleep forever and then...
+	// Sleep forever and then shut down cleanly on SIGINT/SIGTERM.
 	TrapSignal(func() {
 		n.Stop()
 	})
@@ -144,7 +144,7 @@ func (a *AddrBook) Stop() {
 func (a *AddrBook) AddOurAddress(addr *NetAddress) {
 	a.mtx.Lock()
 	defer a.mtx.Unlock()
-	log.Debug("Add our address to book", "addr", addr)
+	log.Debug("Add our address to book", "address", addr)
 	a.ourAddrs[addr.String()] = addr
 }
 
@@ -159,7 +159,7 @@ func (a *AddrBook) OurAddresses() []*NetAddress {
 func (a *AddrBook) AddAddress(addr *NetAddress, src *NetAddress) {
 	a.mtx.Lock()
 	defer a.mtx.Unlock()
-	log.Debug("Add address to book", "addr", addr, "src", src)
+	log.Debug("Add address to book", "address", addr, "src", src)
 	a.addAddress(addr, src)
 }
 
@@ -321,7 +321,7 @@ func (a *AddrBook) saveToFile(filePath string) {
 
 	jsonBytes, err := json.MarshalIndent(aJSON, "", "\t")
 	if err != nil {
-		log.Error("Failed to save AddrBook to file", "err", err)
+		log.Error("Failed to save AddrBook to file", "error", err)
 		return
 	}
 	err = WriteFileAtomic(filePath, jsonBytes)
@@ -2,6 +2,7 @@ package p2p
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -16,18 +17,19 @@ import (
 )
 
 const (
-	numBatchMsgPackets        = 10
-	minReadBufferSize         = 1024
-	minWriteBufferSize        = 1024
-	flushThrottleMS           = 50
-	idleTimeoutMinutes        = 5
-	updateStatsSeconds        = 2
-	pingTimeoutMinutes        = 2
-	defaultSendRate           = 51200 // 5Kb/s
-	defaultRecvRate           = 51200 // 5Kb/s
-	defaultSendQueueCapacity  = 1
-	defaultRecvBufferCapacity = 4096
-	defaultSendTimeoutSeconds = 10
+	numBatchMsgPackets         = 10
+	minReadBufferSize          = 1024
+	minWriteBufferSize         = 1024
+	flushThrottleMS            = 50
+	idleTimeoutMinutes         = 5
+	updateStatsSeconds         = 2
+	pingTimeoutMinutes         = 2
+	defaultSendRate            = 51200 // 5Kb/s
+	defaultRecvRate            = 51200 // 5Kb/s
+	defaultSendQueueCapacity   = 1
+	defaultRecvBufferCapacity  = 4096
+	defaultRecvMessageCapacity = 22020096 // 21MB
+	defaultSendTimeoutSeconds  = 10
 )
 
 type receiveCbFunc func(chId byte, msgBytes []byte)
@@ -416,7 +418,14 @@ FOR_LOOP:
 			if !ok || channel == nil {
 				panic(Fmt("Unknown channel %X", pkt.ChannelId))
 			}
-			msgBytes := channel.recvMsgPacket(pkt)
+			msgBytes, err_ := channel.recvMsgPacket(pkt)
+			if err_ != nil {
+				if atomic.LoadUint32(&c.stopped) != 1 {
+					log.Warn("Connection failed @ recvRoutine", "connection", c, "error", err_)
+					c.stopForError(err_)
+				}
+				break FOR_LOOP
+			}
 			if msgBytes != nil {
 				//log.Debug("Received bytes", "chId", pkt.ChannelId, "msgBytes", msgBytes)
 				c.onReceive(pkt.ChannelId, msgBytes)
@@ -440,10 +449,11 @@ FOR_LOOP:
 //-----------------------------------------------------------------------------
 
 type ChannelDescriptor struct {
-	Id                 byte
-	Priority           int
-	SendQueueCapacity  int
-	RecvBufferCapacity int
+	Id                  byte
+	Priority            int
+	SendQueueCapacity   int
+	RecvBufferCapacity  int
+	RecvMessageCapacity int
 }
 
 func (chDesc *ChannelDescriptor) FillDefaults() {
@@ -453,6 +463,9 @@ func (chDesc *ChannelDescriptor) FillDefaults() {
 	if chDesc.RecvBufferCapacity == 0 {
 		chDesc.RecvBufferCapacity = defaultRecvBufferCapacity
 	}
+	if chDesc.RecvMessageCapacity == 0 {
+		chDesc.RecvMessageCapacity = defaultRecvMessageCapacity
+	}
 }
 
 // TODO: lowercase.
@@ -567,14 +580,20 @@ func (ch *Channel) writeMsgPacketTo(w io.Writer) (n int64, err error) {
 
 // Handles incoming msgPackets. Returns a msg bytes if msg is complete.
 // Not goroutine-safe
-func (ch *Channel) recvMsgPacket(pkt msgPacket) []byte {
+func (ch *Channel) recvMsgPacket(pkt msgPacket) ([]byte, error) {
+	if len(pkt.Bytes) > maxMsgPacketSize {
+		return nil, errors.New("Received msgPacket exceeds maxMsgPacketSize")
+	}
+	if len(ch.recving)+len(pkt.Bytes) > ch.desc.RecvMessageCapacity {
+		return nil, errors.New("Received message exceeds channel RecvMessageCapacity")
+	}
 	ch.recving = append(ch.recving, pkt.Bytes...)
 	if pkt.EOF == byte(0x01) {
 		msgBytes := ch.recving
 		ch.recving = make([]byte, 0, defaultRecvBufferCapacity)
-		return msgBytes
+		return msgBytes, nil
 	}
-	return nil
+	return nil, nil
 }
 
 // Call this periodically to update stats for throttling purposes.
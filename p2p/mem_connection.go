@@ -0,0 +1,75 @@
+package p2p
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/tendermint/tendermint/binary"
+)
+
+/*
+MemConnection connects to a peer in the same process, e.g. in a
+simulation, in place of an MConnection.
+
+Messages sent on it are encoded and handed to the send function given to
+Switch.AddMemPeer, which passes them to the other side's MemConnection
+with Receive, right away, later or never. Sends never block.
+*/
+type MemConnection struct {
+	remote    string
+	send      func(chId byte, msgBytes []byte)
+	onReceive receiveCbFunc
+	started   uint32
+	stopped   uint32
+}
+
+func newMemConnection(remote string, send func(chId byte, msgBytes []byte), onReceive receiveCbFunc) *MemConnection {
+	return &MemConnection{
+		remote:    remote,
+		send:      send,
+		onReceive: onReceive,
+	}
+}
+
+func (c *MemConnection) Start() {
+	atomic.StoreUint32(&c.started, 1)
+}
+
+func (c *MemConnection) Stop() {
+	atomic.StoreUint32(&c.stopped, 1)
+}
+
+func (c *MemConnection) IsRunning() bool {
+	return atomic.LoadUint32(&c.started) == 1 && atomic.LoadUint32(&c.stopped) == 0
+}
+
+func (c *MemConnection) String() string {
+	return fmt.Sprintf("MemConn{%v}", c.remote)
+}
+
+func (c *MemConnection) Send(chId byte, msg interface{}) bool {
+	if !c.IsRunning() {
+		return false
+	}
+	log.Debug("Send", "channel", chId, "connection", c, "msg", msg)
+	c.send(chId, binary.BinaryBytes(msg))
+	return true
+}
+
+func (c *MemConnection) TrySend(chId byte, msg interface{}) bool {
+	return c.Send(chId, msg)
+}
+
+func (c *MemConnection) CanSend(chId byte) bool {
+	return c.IsRunning()
+}
+
+// Hands msgBytes from the peer to the reactor of chId,
+// as an MConnection does with the messages it reads.
+// Messages for a stopped connection are dropped.
+func (c *MemConnection) Receive(chId byte, msgBytes []byte) {
+	if !c.IsRunning() {
+		return
+	}
+	c.onReceive(chId, msgBytes)
+}
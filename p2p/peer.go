@@ -14,12 +14,13 @@ import (
 
 type Peer struct {
 	outbound bool
-	mconn    *MConnection
+	conn     peerConn
 	running  uint32
 
 	*types.NodeInfo
-	Key  string
-	Data *CMap // User data.
+	Key                string
+	Data               *CMap                 // User data.
+	NegotiatedProtocol types.ProtocolVersion // See types.NegotiateProtocol
 }
 
 func peerHandshake(conn net.Conn, ourNodeInfo *types.NodeInfo) (*types.NodeInfo, error) {
@@ -49,42 +50,68 @@ func peerHandshake(conn net.Conn, ourNodeInfo *types.NodeInfo) (*types.NodeInfo,
 	return peerNodeInfo, nil
 }
 
+// The connection a Peer sends on and receives from.
+type peerConn interface {
+	Start()
+	Stop()
+	Send(chId byte, msg interface{}) bool
+	TrySend(chId byte, msg interface{}) bool
+	CanSend(chId byte) bool
+	String() string
+}
+
 func newPeer(conn net.Conn, peerNodeInfo *types.NodeInfo, outbound bool, reactorsByCh map[byte]Reactor, chDescs []*ChannelDescriptor, onPeerError func(*Peer, interface{})) *Peer {
-	var p *Peer
-	onReceive := func(chId byte, msgBytes []byte) {
-		reactor := reactorsByCh[chId]
-		if reactor == nil {
-			panic(Fmt("Unknown channel %X", chId))
-		}
-		reactor.Receive(chId, p, msgBytes)
+	p := &Peer{
+		outbound: outbound,
+		running:  0,
+		NodeInfo: peerNodeInfo,
+		Data:     NewCMap(),
 	}
 	onError := func(r interface{}) {
 		p.stop()
 		onPeerError(p, r)
 	}
-	mconn := NewMConnection(conn, chDescs, onReceive, onError)
-	p = &Peer{
+	mconn := NewMConnection(conn, chDescs, p.receiveFunc(reactorsByCh), onError)
+	p.conn = mconn
+	p.Key = mconn.RemoteAddress.IP.String()
+	return p
+}
+
+// Like newPeer, but connected in memory, see Switch.AddMemPeer.
+func newMemPeer(peerNodeInfo *types.NodeInfo, outbound bool, reactorsByCh map[byte]Reactor, send func(chId byte, msgBytes []byte)) (*Peer, *MemConnection) {
+	p := &Peer{
 		outbound: outbound,
-		mconn:    mconn,
 		running:  0,
 		NodeInfo: peerNodeInfo,
-		Key:      mconn.RemoteAddress.IP.String(),
+		Key:      peerNodeInfo.Host,
 		Data:     NewCMap(),
 	}
-	return p
+	memConn := newMemConnection(peerNodeInfo.Host, send, p.receiveFunc(reactorsByCh))
+	p.conn = memConn
+	return p, memConn
+}
+
+func (p *Peer) receiveFunc(reactorsByCh map[byte]Reactor) receiveCbFunc {
+	return func(chId byte, msgBytes []byte) {
+		reactor := reactorsByCh[chId]
+		if reactor == nil {
+			panic(Fmt("Unknown channel %X", chId))
+		}
+		reactor.Receive(chId, p, msgBytes)
+	}
 }
 
 func (p *Peer) start() {
 	if atomic.CompareAndSwapUint32(&p.running, 0, 1) {
 		log.Debug("Starting Peer", "peer", p)
-		p.mconn.Start()
+		p.conn.Start()
 	}
 }
 
 func (p *Peer) stop() {
 	if atomic.CompareAndSwapUint32(&p.running, 1, 0) {
 		log.Debug("Stopping Peer", "peer", p)
-		p.mconn.Stop()
+		p.conn.Stop()
 	}
 }
 
@@ -92,8 +119,10 @@ func (p *Peer) IsRunning() bool {
 	return atomic.LoadUint32(&p.running) == 1
 }
 
+// Returns nil for peers connected in memory.
 func (p *Peer) Connection() *MConnection {
-	return p.mconn
+	mconn, _ := p.conn.(*MConnection)
+	return mconn
 }
 
 func (p *Peer) IsOutbound() bool {
@@ -104,21 +133,21 @@ func (p *Peer) Send(chId byte, msg interface{}) bool {
 	if atomic.LoadUint32(&p.running) == 0 {
 		return false
 	}
-	return p.mconn.Send(chId, msg)
+	return p.conn.Send(chId, msg)
 }
 
 func (p *Peer) TrySend(chId byte, msg interface{}) bool {
 	if atomic.LoadUint32(&p.running) == 0 {
 		return false
 	}
-	return p.mconn.TrySend(chId, msg)
+	return p.conn.TrySend(chId, msg)
 }
 
 func (p *Peer) CanSend(chId byte) bool {
 	if atomic.LoadUint32(&p.running) == 0 {
 		return false
 	}
-	return p.mconn.CanSend(chId)
+	return p.conn.CanSend(chId)
 }
 
 func (p *Peer) WriteTo(w io.Writer) (n int64, err error) {
@@ -128,9 +157,9 @@ func (p *Peer) WriteTo(w io.Writer) (n int64, err error) {
 
 func (p *Peer) String() string {
 	if p.outbound {
-		return fmt.Sprintf("Peer{->%v}", p.mconn)
+		return fmt.Sprintf("Peer{->%v}", p.conn)
 	} else {
-		return fmt.Sprintf("Peer{%v->}", p.mconn)
+		return fmt.Sprintf("Peer{%v->}", p.conn)
 	}
 }
 
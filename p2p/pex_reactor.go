@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"math/rand"
 	"reflect"
-	"sync/atomic"
 	"time"
 
 	"github.com/tendermint/tendermint/binary"
@@ -28,10 +27,7 @@ PEXReactor handles PEX (peer exchange) and ensures that an
 adequate number of peers are connected to the switch.
 */
 type PEXReactor struct {
-	sw      *Switch
-	quit    chan struct{}
-	started uint32
-	stopped uint32
+	*BaseReactor
 
 	book *AddrBook
 
@@ -40,27 +36,17 @@ type PEXReactor struct {
 
 func NewPEXReactor(book *AddrBook) *PEXReactor {
 	pexR := &PEXReactor{
-		quit: make(chan struct{}),
 		book: book,
 	}
+	pexR.BaseReactor = NewBaseReactor(log, "PEXReactor", pexR)
 	return pexR
 }
 
 // Implements Reactor
-func (pexR *PEXReactor) Start(sw *Switch) {
-	if atomic.CompareAndSwapUint32(&pexR.started, 0, 1) {
-		log.Info("Starting PEXReactor")
-		pexR.sw = sw
-		go pexR.ensurePeersRoutine()
-	}
-}
-
-// Implements Reactor
-func (pexR *PEXReactor) Stop() {
-	if atomic.CompareAndSwapUint32(&pexR.stopped, 0, 1) {
-		log.Info("Stopping PEXReactor")
-		close(pexR.quit)
-	}
+func (pexR *PEXReactor) OnStart() error {
+	pexR.BaseReactor.OnStart()
+	go pexR.ensurePeersRoutine()
+	return nil
 }
 
 // Implements Reactor
@@ -143,7 +129,7 @@ FOR_LOOP:
 		select {
 		case <-timer.Ch:
 			pexR.ensurePeers()
-		case <-pexR.quit:
+		case <-pexR.Quit:
 			break FOR_LOOP
 		}
 	}
@@ -154,7 +140,7 @@ FOR_LOOP:
 
 // Ensures that sufficient peers are connected. (once)
 func (pexR *PEXReactor) ensurePeers() {
-	numOutPeers, _, numDialing := pexR.sw.NumPeers()
+	numOutPeers, _, numDialing := pexR.Switch.NumPeers()
 	numToDial := minNumOutboundPeers - (numOutPeers + numDialing)
 	log.Debug("Ensure peers", "numOutPeers", numOutPeers, "numDialing", numDialing, "numToDial", numToDial)
 	if numToDial <= 0 {
@@ -175,18 +161,18 @@ func (pexR *PEXReactor) ensurePeers() {
 				break
 			}
 			alreadySelected := toDial.Has(try.IP.String())
-			alreadyDialing := pexR.sw.IsDialing(try)
-			alreadyConnected := pexR.sw.Peers().Has(try.IP.String())
+			alreadyDialing := pexR.Switch.IsDialing(try)
+			alreadyConnected := pexR.Switch.Peers().Has(try.IP.String())
 			if alreadySelected || alreadyDialing || alreadyConnected {
 				/*
-					log.Debug("Cannot dial address", "addr", try,
+					log.Debug("Cannot dial address", "address", try,
 						"alreadySelected", alreadySelected,
 						"alreadyDialing", alreadyDialing,
 						"alreadyConnected", alreadyConnected)
 				*/
 				continue
 			} else {
-				log.Debug("Will dial address", "addr", try)
+				log.Debug("Will dial address", "address", try)
 				picked = try
 				break
 			}
@@ -200,7 +186,7 @@ func (pexR *PEXReactor) ensurePeers() {
 	// Dial picked addresses
 	for _, item := range toDial.Values() {
 		go func(picked *NetAddress) {
-			_, err := pexR.sw.DialPeerWithAddress(picked)
+			_, err := pexR.Switch.DialPeerWithAddress(picked)
 			if err != nil {
 				pexR.book.MarkAttempt(picked)
 			}
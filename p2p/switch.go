@@ -5,16 +5,17 @@ import (
 	"fmt"
 	"net"
 	"strconv"
-	"sync/atomic"
 	"time"
 
+	"github.com/tendermint/tendermint/Godeps/_workspace/src/github.com/tendermint/log15"
 	. "github.com/tendermint/tendermint/common"
 	"github.com/tendermint/tendermint/types"
 )
 
 type Reactor interface {
-	Start(sw *Switch)
-	Stop()
+	Service // Start, Stop
+
+	SetSwitch(*Switch)
 	GetChannels() []*ChannelDescriptor
 	AddPeer(peer *Peer)
 	RemovePeer(peer *Peer, reason interface{})
@@ -23,14 +24,25 @@ type Reactor interface {
 
 //--------------------------------------
 
-type BaseReactor struct{}
+type BaseReactor struct {
+	*BaseService // Provides Start, Stop, .Quit
+	Switch       *Switch
+}
+
+func NewBaseReactor(log log15.Logger, name string, impl Reactor) *BaseReactor {
+	return &BaseReactor{
+		BaseService: NewBaseService(log, name, impl),
+		Switch:      nil,
+	}
+}
 
-func (_ BaseReactor) Start(sw *Switch)                               {}
-func (_ BaseReactor) Stop()                                          {}
-func (_ BaseReactor) GetChannels() []*ChannelDescriptor              { return nil }
-func (_ BaseReactor) AddPeer(peer *Peer)                             {}
-func (_ BaseReactor) RemovePeer(peer *Peer, reason interface{})      {}
-func (_ BaseReactor) Receive(chId byte, peer *Peer, msgBytes []byte) {}
+func (br *BaseReactor) SetSwitch(sw *Switch) {
+	br.Switch = sw
+}
+func (_ *BaseReactor) GetChannels() []*ChannelDescriptor              { return nil }
+func (_ *BaseReactor) AddPeer(peer *Peer)                             {}
+func (_ *BaseReactor) RemovePeer(peer *Peer, reason interface{})      {}
+func (_ *BaseReactor) Receive(chId byte, peer *Peer, msgBytes []byte) {}
 
 //-----------------------------------------------------------------------------
 
@@ -41,14 +53,17 @@ or more `Channels`.  So while sending outgoing messages is typically performed o
 incoming messages are received on the reactor.
 */
 type Switch struct {
+	*BaseService
+
 	listeners    []Listener
 	reactors     map[string]Reactor
 	chDescs      []*ChannelDescriptor
 	reactorsByCh map[byte]Reactor
 	peers        *PeerSet
 	dialing      *CMap
-	running      uint32
 	nodeInfo     *types.NodeInfo // our node info
+
+	allowSameIPPeers bool
 }
 
 var (
@@ -66,9 +81,9 @@ func NewSwitch() *Switch {
 		reactorsByCh: make(map[byte]Reactor),
 		peers:        NewPeerSet(),
 		dialing:      NewCMap(),
-		running:      0,
 		nodeInfo:     nil,
 	}
+	sw.BaseService = NewBaseService(log, "P2P Switch", sw)
 	return sw
 }
 
@@ -86,6 +101,7 @@ func (sw *Switch) AddReactor(name string, reactor Reactor) Reactor {
 		sw.reactorsByCh[chId] = reactor
 	}
 	sw.reactors[name] = reactor
+	reactor.SetSwitch(sw)
 	return reactor
 }
 
@@ -119,39 +135,50 @@ func (sw *Switch) SetNodeInfo(nodeInfo *types.NodeInfo) {
 	sw.nodeInfo = nodeInfo
 }
 
-func (sw *Switch) Start() {
-	if atomic.CompareAndSwapUint32(&sw.running, 0, 1) {
-		// Start reactors
-		for _, reactor := range sw.reactors {
-			reactor.Start(sw)
-		}
-		// Start peers
-		for _, peer := range sw.peers.List() {
-			sw.startInitPeer(peer)
-		}
-		// Start listeners
-		for _, listener := range sw.listeners {
-			go sw.listenerRoutine(listener)
+// By default there is one peer per IP. If allowed, peers are told apart
+// by their listen port too, e.g. to run several nodes on one host.
+// Not goroutine safe.
+func (sw *Switch) SetAllowSameIPPeers(allow bool) {
+	sw.allowSameIPPeers = allow
+}
+
+// Implements Service
+func (sw *Switch) OnStart() error {
+	sw.BaseService.OnStart()
+	// Start reactors
+	for _, reactor := range sw.reactors {
+		if _, err := reactor.Start(); err != nil {
+			return err
 		}
 	}
+	// Start peers
+	for _, peer := range sw.peers.List() {
+		sw.startInitPeer(peer)
+	}
+	// Start listeners
+	for _, listener := range sw.listeners {
+		go sw.listenerRoutine(listener)
+	}
+	return nil
 }
 
-func (sw *Switch) Stop() {
-	if atomic.CompareAndSwapUint32(&sw.running, 1, 0) {
-		// Stop listeners
-		for _, listener := range sw.listeners {
-			listener.Stop()
-		}
-		sw.listeners = nil
-		// Stop peers
-		for _, peer := range sw.peers.List() {
-			peer.stop()
-		}
-		sw.peers = NewPeerSet()
-		// Stop reactors
-		for _, reactor := range sw.reactors {
-			reactor.Stop()
-		}
+// Implements Service
+// Listeners are closed first so no new peers arrive while the rest shuts down.
+func (sw *Switch) OnStop() {
+	sw.BaseService.OnStop()
+	// Stop listeners
+	for _, listener := range sw.listeners {
+		listener.Stop()
+	}
+	sw.listeners = nil
+	// Stop peers
+	for _, peer := range sw.peers.List() {
+		peer.stop()
+	}
+	sw.peers = NewPeerSet()
+	// Stop reactors
+	for _, reactor := range sw.reactors {
+		reactor.Stop()
 	}
 }
 
@@ -165,6 +192,7 @@ func (sw *Switch) AddPeerWithConnection(conn net.Conn, outbound bool) (*Peer, er
 	if err := sw.nodeInfo.CompatibleWith(peerNodeInfo); err != nil {
 		return nil, err
 	}
+	protocol, _ := types.NegotiateProtocol(sw.nodeInfo.Protocol, peerNodeInfo.Protocol)
 
 	// the peerNodeInfo is not verified,
 	// so we overwrite the IP with that from the conn
@@ -177,6 +205,10 @@ func (sw *Switch) AddPeerWithConnection(conn net.Conn, outbound bool) (*Peer, er
 		peerNodeInfo.P2PPort = uint16(porti)
 	}
 	peer := newPeer(conn, peerNodeInfo, outbound, sw.reactorsByCh, sw.chDescs, sw.StopPeerForError)
+	peer.NegotiatedProtocol = protocol
+	if sw.allowSameIPPeers {
+		peer.Key = net.JoinHostPort(ip, strconv.Itoa(int(peerNodeInfo.P2PPort)))
+	}
 
 	// Add the peer to .peers
 	if sw.peers.Add(peer) {
@@ -186,12 +218,30 @@ func (sw *Switch) AddPeerWithConnection(conn net.Conn, outbound bool) (*Peer, er
 		return nil, ErrSwitchDuplicatePeer
 	}
 
-	if atomic.LoadUint32(&sw.running) == 1 {
+	if sw.IsRunning() {
 		sw.startInitPeer(peer)
 	}
 	return peer, nil
 }
 
+// Adds a peer connected in memory, without a handshake, keyed by
+// peerNodeInfo.Host. Messages to the peer are passed to send, see MemConnection.
+// Messages from the peer are to be passed to the returned MemConnection's Receive.
+func (sw *Switch) AddMemPeer(peerNodeInfo *types.NodeInfo, outbound bool, send func(chId byte, msgBytes []byte)) (*Peer, *MemConnection, error) {
+	peer, memConn := newMemPeer(peerNodeInfo, outbound, sw.reactorsByCh, send)
+	if sw.peers.Add(peer) {
+		log.Info("Added peer", "peer", peer)
+	} else {
+		log.Info("Ignoring duplicate peer", "peer", peer)
+		return nil, nil, ErrSwitchDuplicatePeer
+	}
+
+	if sw.IsRunning() {
+		sw.startInitPeer(peer)
+	}
+	return peer, memConn, nil
+}
+
 func (sw *Switch) startInitPeer(peer *Peer) {
 	peer.start()
 	sw.addPeerToReactors(peer)
@@ -226,6 +276,11 @@ func (sw *Switch) Broadcast(chId byte, msg interface{}) chan bool {
 	successChan := make(chan bool, len(sw.peers.List()))
 	log.Debug("Broadcast", "channel", chId, "msg", msg)
 	for _, peer := range sw.peers.List() {
+		// Sends on in-memory connections don't block, and keep their order.
+		if _, ok := peer.conn.(*MemConnection); ok {
+			successChan <- peer.Send(chId, msg)
+			continue
+		}
 		go func(peer *Peer) {
 			success := peer.Send(chId, msg)
 			successChan <- success
@@ -290,9 +345,9 @@ func (sw *Switch) listenerRoutine(l Listener) {
 			break
 		}
 		// New inbound connection!
-		peer, err := sw.AddPeerWithConnection(inConn, false)
+		_, err := sw.AddPeerWithConnection(inConn, false)
 		if err != nil {
-			log.Info(Fmt("Ignoring error from inbound connection: %v\n%v", peer, err))
+			log.Info("Ignoring error from inbound connection", "address", inConn.RemoteAddr(), "error", err)
 			continue
 		}
 		// NOTE: We don't yet have the external address of the
@@ -18,6 +18,8 @@ type PeerMessage struct {
 }
 
 type TestReactor struct {
+	*BaseReactor
+
 	mtx          sync.Mutex
 	channels     []*ChannelDescriptor
 	peersAdded   []*Peer
@@ -28,17 +30,13 @@ type TestReactor struct {
 }
 
 func NewTestReactor(channels []*ChannelDescriptor, logMessages bool) *TestReactor {
-	return &TestReactor{
+	tr := &TestReactor{
 		channels:     channels,
 		logMessages:  logMessages,
 		msgsReceived: make(map[byte][]PeerMessage),
 	}
-}
-
-func (tr *TestReactor) Start(sw *Switch) {
-}
-
-func (tr *TestReactor) Stop() {
+	tr.BaseReactor = NewBaseReactor(log, "TestReactor", tr)
+	return tr
 }
 
 func (tr *TestReactor) GetChannels() []*ChannelDescriptor {
@@ -124,11 +122,11 @@ func TestSwitches(t *testing.T) {
 		sw.AddReactor("foo", NewTestReactor([]*ChannelDescriptor{
 			&ChannelDescriptor{Id: byte(0x00), Priority: 10},
 			&ChannelDescriptor{Id: byte(0x01), Priority: 10},
-		}, true)).Start(sw) // Start the reactor
+		}, true))
 		sw.AddReactor("bar", NewTestReactor([]*ChannelDescriptor{
 			&ChannelDescriptor{Id: byte(0x02), Priority: 10},
 			&ChannelDescriptor{Id: byte(0x03), Priority: 10},
-		}, true)).Start(sw) // Start the reactor
+		}, true))
 		return sw
 	})
 	defer s1.Stop()
@@ -182,6 +180,54 @@ func TestSwitches(t *testing.T) {
 
 }
 
+func TestSwitchMemPeers(t *testing.T) {
+	makeSwitch := func(moniker string) *Switch {
+		sw := NewSwitch()
+		sw.SetNodeInfo(&types.NodeInfo{Moniker: moniker, ChainID: "testing", Host: moniker})
+		sw.AddReactor("foo", NewTestReactor([]*ChannelDescriptor{
+			&ChannelDescriptor{Id: byte(0x00), Priority: 10},
+		}, true))
+		sw.Start()
+		return sw
+	}
+	s1, s2 := makeSwitch("switch1"), makeSwitch("switch2")
+	defer s1.Stop()
+	defer s2.Stop()
+
+	// Deliver synchronously, like a network without delays.
+	var conn1, conn2 *MemConnection
+	_, conn1, err := s1.AddMemPeer(&types.NodeInfo{Moniker: "switch2", Host: "switch2"}, true,
+		func(chId byte, msgBytes []byte) { conn2.Receive(chId, msgBytes) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	peer1, conn2, err := s2.AddMemPeer(&types.NodeInfo{Moniker: "switch1", Host: "switch1"}, false,
+		func(chId byte, msgBytes []byte) { conn1.Receive(chId, msgBytes) })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s1.Broadcast(byte(0x00), "first")
+	s1.Broadcast(byte(0x00), "second")
+	msgs := s2.Reactor("foo").(*TestReactor).msgsReceived[byte(0x00)]
+	if len(msgs) != 2 {
+		t.Fatalf("Expected to have received 2 messages, got %v", len(msgs))
+	}
+	if msgs[0].PeerKey != "switch1" || !bytes.Equal(msgs[1].Bytes, binary.BinaryBytes("second")) {
+		t.Errorf("Unexpected messages: %v", msgs)
+	}
+
+	// Messages to a stopped peer are dropped.
+	s2.StopPeerForError(peer1, "test")
+	s1.Broadcast(byte(0x00), "third")
+	if msgs := s2.Reactor("foo").(*TestReactor).msgsReceived[byte(0x00)]; len(msgs) != 2 {
+		t.Errorf("Expected no more messages after the peer stopped, got %v", len(msgs))
+	}
+	if _, _, err := s1.AddMemPeer(&types.NodeInfo{Host: "switch2"}, true, nil); err != ErrSwitchDuplicatePeer {
+		t.Errorf("Expected ErrSwitchDuplicatePeer, got %v", err)
+	}
+}
+
 func BenchmarkSwitches(b *testing.B) {
 
 	b.StopTimer()
@@ -5,6 +5,7 @@ import (
 	acm "github.com/tendermint/tendermint/account"
 	. "github.com/tendermint/tendermint/common"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	sm "github.com/tendermint/tendermint/state"
 )
 
 func GenPrivAccount() (*acm.PrivAccount, error) {
@@ -12,8 +13,7 @@ func GenPrivAccount() (*acm.PrivAccount, error) {
 }
 
 func GetAccount(address []byte) (*acm.Account, error) {
-	cache := mempoolReactor.Mempool.GetCache()
-	account := cache.GetAccount(address)
+	account := mempoolReactor.Mempool.GetAccount(address)
 	if account == nil {
 		account = &acm.Account{
 			Address:     address,
@@ -43,6 +43,81 @@ func GetStorage(address, key []byte) (*ctypes.ResponseGetStorage, error) {
 	return &ctypes.ResponseGetStorage{key, value.([]byte)}, nil
 }
 
+// Proves the account at path "accounts/<address>", see Prove.
+// Unlike GetAccount, reads the committed state, not the mempool's.
+func GetAccountProof(address []byte) (*ctypes.ResponseGetAccountProof, error) {
+	state := consensusState.GetState()
+	account := state.GetAccount(address)
+	if account == nil {
+		return nil, fmt.Errorf("Unknown address: %X", address)
+	}
+	proof, err := proveLatest(state, Fmt("accounts/%X", address))
+	if err != nil {
+		return nil, err
+	}
+	return &ctypes.ResponseGetAccountProof{
+		Account: account,
+		Proof:   proof,
+	}, nil
+}
+
+// Proves the storage value at path "accounts/<address>/storage/<key>",
+// see Prove.
+func GetStorageProof(address, key []byte) (*ctypes.ResponseGetStorageProof, error) {
+	state := consensusState.GetState()
+	key = LeftPadWord256(key).Bytes()
+	proof, err := proveLatest(state, Fmt("accounts/%X/storage/%X", address, key))
+	if err == sm.ErrProofNoValue {
+		return nil, fmt.Errorf("Unknown address or storage key: %X %X", address, key)
+	} else if err != nil {
+		return nil, err
+	}
+	account := state.GetAccount(address)
+	_, value := state.LoadStorage(account.StorageRoot).Get(key)
+	return &ctypes.ResponseGetStorageProof{
+		Key:   key,
+		Value: value.([]byte),
+		Proof: proof,
+	}, nil
+}
+
+func proveLatest(state *sm.State, path string) (*sm.Proof, error) {
+	block := blockStore.LoadBlock(state.LastBlockHeight)
+	if block == nil {
+		return nil, fmt.Errorf("No block at height %v", state.LastBlockHeight)
+	}
+	return sm.Prove(state, block, path)
+}
+
+// Proves the value at path, see sm.Prove. A height of 0 is the latest.
+// Verify with sm.VerifyProof.
+func Prove(path string, height int) (*sm.Proof, error) {
+	state := consensusState.GetState()
+	if height == 0 {
+		return proveLatest(state, path)
+	}
+	block := blockStore.LoadBlock(height)
+	if block == nil {
+		return nil, fmt.Errorf("No block at height %v", height)
+	}
+	return sm.Prove(state, block, path)
+}
+
+// Returns the diff from the trees at from to the latest state,
+// see sm.State.ApplyDiff, along with the seen validation of its block.
+func StateDiff(from *sm.StateRoots) (*ctypes.ResponseStateDiff, error) {
+	state := consensusState.GetState()
+	diff, err := state.Diff(from)
+	if err != nil {
+		return nil, err
+	}
+	return &ctypes.ResponseStateDiff{
+		Height:     state.LastBlockHeight,
+		Diff:       diff,
+		Validation: blockStore.LoadSeenValidation(state.LastBlockHeight),
+	}, nil
+}
+
 func ListAccounts() (*ctypes.ResponseListAccounts, error) {
 	var blockHeight int
 	var accounts []*acm.Account
@@ -2,6 +2,7 @@ package core
 
 import (
 	"fmt"
+	bc "github.com/tendermint/tendermint/blockchain"
 	. "github.com/tendermint/tendermint/common"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
 	"github.com/tendermint/tendermint/types"
@@ -43,3 +44,58 @@ func GetBlock(height int) (*ctypes.ResponseGetBlock, error) {
 	block := blockStore.LoadBlock(height)
 	return &ctypes.ResponseGetBlock{blockMeta, block}, nil
 }
+
+//-----------------------------------------------------------------------------
+
+// Returns fast sync progress: our height, the network's, and an ETA.
+func SyncProgress() (*bc.SyncProgress, error) {
+	return bcReactor.GetSyncProgress(), nil
+}
+
+//-----------------------------------------------------------------------------
+
+// Returns the height and index of the committed tx with txId,
+// and the gas it used.
+func GetTx(txId []byte) (*bc.TxIndexEntry, error) {
+	if txIndex == nil {
+		return nil, fmt.Errorf("Tx index is disabled. See tx_index")
+	}
+	entry := txIndex.Get(txId)
+	if entry == nil {
+		return nil, fmt.Errorf("Tx %X is not indexed", txId)
+	}
+	return entry, nil
+}
+
+//-----------------------------------------------------------------------------
+
+// Returns up to limit (at most 100) outbox events after the last ack.
+// Events are returned again until acked with outbox_ack, an operator
+// route (see UnsafeRoutes) since acks prune the events.
+func OutboxEvents(limit int) (*ctypes.ResponseOutboxEvents, error) {
+	outbox := blockStore.Outbox()
+	if outbox == nil {
+		return nil, fmt.Errorf("Outbox is disabled. See outbox")
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+	height, acked := outbox.Offsets()
+	return &ctypes.ResponseOutboxEvents{
+		Height: height,
+		Acked:  acked,
+		Events: outbox.Pending(limit),
+	}, nil
+}
+
+// Acknowledges the outbox events up to offset.
+func OutboxAck(offset int) (*ctypes.ResponseOutboxAck, error) {
+	outbox := blockStore.Outbox()
+	if outbox == nil {
+		return nil, fmt.Errorf("Outbox is disabled. See outbox")
+	}
+	if err := outbox.Ack(offset); err != nil {
+		return nil, err
+	}
+	return &ctypes.ResponseOutboxAck{Acked: offset}, nil
+}
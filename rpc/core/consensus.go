@@ -1,7 +1,10 @@
 package core
 
 import (
+	"fmt"
+
 	"github.com/tendermint/tendermint/binary"
+	. "github.com/tendermint/tendermint/common"
 	cm "github.com/tendermint/tendermint/consensus"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
 	sm "github.com/tendermint/tendermint/state"
@@ -26,6 +29,31 @@ func ListValidators() (*ctypes.ResponseListValidators, error) {
 	return &ctypes.ResponseListValidators{blockHeight, bondedValidators, unbondingValidators}, nil
 }
 
+// Returns when the block at height passed each stage of its lifecycle.
+// Only recent heights are traced.
+func BlockTrace(height int) (*cm.BlockTrace, error) {
+	trace := consensusState.GetBlockTrace(height)
+	if trace == nil {
+		return nil, fmt.Errorf("No trace for height %v", height)
+	}
+	return trace, nil
+}
+
+// Returns counts of block PartSets that were released without committing.
+func PartSetGCStats() (*cm.PartSetGCStats, error) {
+	stats := consensusState.GetPartSetGCStats()
+	return &stats, nil
+}
+
+// Reverts the state and block store by one height.
+// Consensus then fetches and executes the block again.
+func Rollback() (*ctypes.ResponseRollback, error) {
+	if err := consensusState.Rollback(); err != nil {
+		return nil, err
+	}
+	return &ctypes.ResponseRollback{LastBlockHeight: blockStore.Height()}, nil
+}
+
 func DumpConsensusState() (*ctypes.ResponseDumpConsensusState, error) {
 	roundState := consensusState.GetRoundState()
 	peerRoundStates := []string{}
@@ -38,3 +66,18 @@ func DumpConsensusState() (*ctypes.ResponseDumpConsensusState, error) {
 	}
 	return &ctypes.ResponseDumpConsensusState{roundState.String(), peerRoundStates}, nil
 }
+
+// Merges precommits exported out-of-band (see consensus.OfflinePrecommit)
+// into the consensus state, for recovery when p2p is down.
+func ImportPrecommits(precommits []*cm.OfflinePrecommit) (*ctypes.ResponseImportPrecommits, error) {
+	added, errs := 0, []string{}
+	for _, precommit := range precommits {
+		ok, err := consensusState.AddOfflinePrecommit(precommit)
+		if err != nil {
+			errs = append(errs, Fmt("%X: %v", precommit.Address, err))
+		} else if ok {
+			added += 1
+		}
+	}
+	return &ctypes.ResponseImportPrecommits{Added: added, Errors: errs}, nil
+}
@@ -0,0 +1,21 @@
+package core
+
+import (
+	"fmt"
+
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+func FaucetSend(address []byte, amount int64) (*ctypes.Receipt, error) {
+	if fct == nil {
+		return nil, fmt.Errorf("Faucet is not enabled on this node")
+	}
+	tx, err := fct.Send(address, amount)
+	if err != nil {
+		return nil, fmt.Errorf("Error sending from faucet: %v", err)
+	}
+	mempoolState := mempoolReactor.Mempool.GetState()
+	txHash := types.TxId(mempoolState.ChainID, tx)
+	return &ctypes.Receipt{TxHash: txHash, GasUsed: mempoolState.TxIntrinsicGas(tx)}, nil
+}
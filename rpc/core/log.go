@@ -1,7 +1,22 @@
 package core
 
 import (
-	"github.com/tendermint/tendermint/Godeps/_workspace/src/github.com/tendermint/log15"
+	"github.com/tendermint/tendermint/logger"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
 )
 
-var log = log15.New("module", "rpc")
+var log = logger.New("module", "rpc")
+
+//-----------------------------------------------------------------------------
+
+// Sets the log level of module, or the default level if module is "".
+func SetLogLevel(module, level string) (*ctypes.ResponseLogLevels, error) {
+	if err := logger.SetLevel(module, level); err != nil {
+		return nil, err
+	}
+	return &ctypes.ResponseLogLevels{Levels: logger.Levels()}, nil
+}
+
+func LogLevels() (*ctypes.ResponseLogLevels, error) {
+	return &ctypes.ResponseLogLevels{Levels: logger.Levels()}, nil
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"fmt"
+	mempl "github.com/tendermint/tendermint/mempool"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
 	"github.com/tendermint/tendermint/state"
 	"github.com/tendermint/tendermint/types"
@@ -15,8 +16,31 @@ func BroadcastTx(tx types.Tx) (*ctypes.Receipt, error) {
 	if err != nil {
 		return nil, fmt.Errorf("Error broadcasting transaction: %v", err)
 	}
+	return makeReceipt(tx), nil
+}
+
+// Note: txs must be signed
+// Txs are added in order, so a tx may depend on an earlier one in txs.
+// Rejected txs don't fail the batch, but more than mempool_max_batch_txs do.
+func BroadcastTxs(txs []types.Tx) (*ctypes.ResponseBroadcastTxs, error) {
+	errs, err := mempoolReactor.BroadcastTxs(txs)
+	if err != nil {
+		return nil, fmt.Errorf("Error broadcasting transactions: %v", err)
+	}
+	results := make([]*ctypes.TxResult, len(txs))
+	for i, tx := range txs {
+		if errs[i] != nil {
+			results[i] = &ctypes.TxResult{Error: fmt.Sprintf("Error broadcasting transaction: %v", errs[i])}
+		} else {
+			results[i] = &ctypes.TxResult{Receipt: makeReceipt(tx)}
+		}
+	}
+	return &ctypes.ResponseBroadcastTxs{Results: results}, nil
+}
 
-	txHash := types.TxId(mempoolReactor.Mempool.GetState().ChainID, tx)
+func makeReceipt(tx types.Tx) *ctypes.Receipt {
+	mempoolState := mempoolReactor.Mempool.GetState()
+	txHash := types.TxId(mempoolState.ChainID, tx)
 	var createsContract uint8
 	var contractAddr []byte
 	// check if creates new contract
@@ -26,9 +50,28 @@ func BroadcastTx(tx types.Tx) (*ctypes.Receipt, error) {
 			contractAddr = state.NewContractAddress(callTx.Input.Address, callTx.Input.Sequence)
 		}
 	}
-	return &ctypes.Receipt{txHash, createsContract, contractAddr}, nil
+	// CallTxs aren't run until they're in a block,
+	// so for them this is only the intrinsic gas. See get_tx.
+	gasUsed := mempoolState.TxIntrinsicGas(tx)
+	return &ctypes.Receipt{txHash, createsContract, contractAddr, gasUsed}
+}
+
+// Returns the recorded admission decisions about the tx with txId,
+// e.g. why it was rejected or evicted, or all of them if txId is empty.
+// Only recent decisions are kept, see mempool_audit_size.
+func MempoolAudit(txId []byte) ([]*mempl.AuditEntry, error) {
+	if !mempoolReactor.Mempool.AuditLogEnabled() {
+		return nil, fmt.Errorf("Mempool audit log is disabled. See mempool_audit_size")
+	}
+	return mempoolReactor.Mempool.GetAuditEntries(txId), nil
 }
 
 func ListUnconfirmedTxs() ([]types.Tx, error) {
 	return mempoolReactor.Mempool.GetProposalTxs(), nil
 }
+
+// Drops all unconfirmed txs.
+func FlushMempool() (*ctypes.ResponseFlushMempool, error) {
+	flushed := mempoolReactor.Mempool.Flush()
+	return &ctypes.ResponseFlushMempool{Flushed: flushed}, nil
+}
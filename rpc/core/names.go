@@ -1,6 +1,7 @@
 package core
 
 import (
+	"bytes"
 	"fmt"
 
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
@@ -27,3 +28,43 @@ func ListNames() (*ctypes.ResponseListNames, error) {
 	})
 	return &ctypes.ResponseListNames{blockHeight, names}, nil
 }
+
+func NameExpiration(name string) (*ctypes.ResponseNameExpiration, error) {
+	state := consensusState.GetState()
+	entry := state.GetNameRegEntry(name)
+	if entry == nil {
+		return nil, fmt.Errorf("Name %s not found", name)
+	}
+	return &ctypes.ResponseNameExpiration{
+		Name:        entry.Name,
+		Expires:     entry.Expires,
+		BlockHeight: state.LastBlockHeight,
+	}, nil
+}
+
+// Lists names owned by owner, ordered by name.
+// A limit of 0 returns all names after offset.
+func ListNamesByOwner(owner []byte, offset, limit int) (*ctypes.ResponseListNamesByOwner, error) {
+	if offset < 0 || limit < 0 {
+		return nil, fmt.Errorf("Invalid offset %d or limit %d", offset, limit)
+	}
+	var total int
+	names := []*types.NameRegEntry{}
+	state := consensusState.GetState()
+	state.GetNames().Iterate(func(key interface{}, value interface{}) bool {
+		entry := value.(*types.NameRegEntry)
+		if !bytes.Equal(entry.Owner, owner) {
+			return false
+		}
+		if total >= offset && (limit == 0 || len(names) < limit) {
+			names = append(names, entry)
+		}
+		total += 1
+		return false
+	})
+	return &ctypes.ResponseListNamesByOwner{
+		BlockHeight: state.LastBlockHeight,
+		Total:       total,
+		Names:       names,
+	}, nil
+}
@@ -1,10 +1,11 @@
 package core
 
 import (
+	"fmt"
 	"io/ioutil"
 
-	"github.com/tendermint/tendermint/binary"
 	dbm "github.com/tendermint/tendermint/db"
+	"github.com/tendermint/tendermint/p2p"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
 	sm "github.com/tendermint/tendermint/state"
 	"github.com/tendermint/tendermint/types"
@@ -36,7 +37,8 @@ func Status() (*ctypes.ResponseStatus, error) {
 		PubKey:            privValidator.PubKey,
 		LatestBlockHash:   latestBlockHash,
 		LatestBlockHeight: latestHeight,
-		LatestBlockTime:   latestBlockTime}, nil
+		LatestBlockTime:   latestBlockTime,
+		SigningGate:       consensusState.GetSigningGateStatus()}, nil
 }
 
 //-----------------------------------------------------------------------------
@@ -61,6 +63,39 @@ func NetInfo() (*ctypes.ResponseNetInfo, error) {
 	}, nil
 }
 
+// Dials the peers (host:port) in the background.
+func DialPeers(peers []string) (*ctypes.ResponseDialPeers, error) {
+	addrs := make([]*p2p.NetAddress, len(peers))
+	for i, peer := range peers {
+		addr, err := parseNetAddress(peer)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid peer address %v: %v", peer, err)
+		}
+		addrs[i] = addr
+	}
+	for _, addr := range addrs {
+		go func(addr *p2p.NetAddress) {
+			peer, err := p2pSwitch.DialPeerWithAddress(addr)
+			if err != nil {
+				log.Warn("Error dialing peer", "addr", addr, "error", err)
+			} else {
+				log.Info("Dialed peer", "peer", peer)
+			}
+		}(addr)
+	}
+	return &ctypes.ResponseDialPeers{Dialing: peers}, nil
+}
+
+// p2p.NewNetAddressString panics on invalid input.
+func parseNetAddress(addr string) (netAddr *p2p.NetAddress, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return p2p.NewNetAddressString(addr), nil
+}
+
 //-----------------------------------------------------------------------------
 
 // cache the genesis structure
@@ -72,7 +107,7 @@ func Genesis() (*sm.GenesisDoc, error) {
 		if err != nil {
 			return nil, err
 		}
-		binary.ReadJSON(&genDoc, b, &err)
+		genDoc, err = sm.ParseGenesisDoc(b)
 		if err != nil {
 			return nil, err
 		}
@@ -3,22 +3,30 @@ package core
 import (
 	bc "github.com/tendermint/tendermint/blockchain"
 	"github.com/tendermint/tendermint/consensus"
+	"github.com/tendermint/tendermint/faucet"
 	mempl "github.com/tendermint/tendermint/mempool"
 	"github.com/tendermint/tendermint/p2p"
 	"github.com/tendermint/tendermint/state"
 )
 
 var blockStore *bc.BlockStore
+var bcReactor *bc.BlockchainReactor
 var consensusState *consensus.ConsensusState
 var consensusReactor *consensus.ConsensusReactor
 var mempoolReactor *mempl.MempoolReactor
 var p2pSwitch *p2p.Switch
 var privValidator *state.PrivValidator
+var fct *faucet.Faucet
+var txIndex *bc.TxIndex
 
 func SetBlockStore(bs *bc.BlockStore) {
 	blockStore = bs
 }
 
+func SetBlockchainReactor(bcr *bc.BlockchainReactor) {
+	bcReactor = bcr
+}
+
 func SetConsensusState(cs *consensus.ConsensusState) {
 	consensusState = cs
 }
@@ -38,3 +46,11 @@ func SetSwitch(sw *p2p.Switch) {
 func SetPrivValidator(pv *state.PrivValidator) {
 	privValidator = pv
 }
+
+func SetFaucet(f *faucet.Faucet) {
+	fct = f
+}
+
+func SetTxIndex(ti *bc.TxIndex) {
+	txIndex = ti
+}
@@ -1,6 +1,8 @@
 package core
 
 import (
+	"strings"
+
 	rpc "github.com/tendermint/tendermint/rpc/server"
 )
 
@@ -10,18 +12,74 @@ var Routes = map[string]*rpc.RPCFunc{
 	"blockchain":              rpc.NewRPCFunc(BlockchainInfo, []string{"minHeight", "maxHeight"}),
 	"genesis":                 rpc.NewRPCFunc(Genesis, []string{}),
 	"get_block":               rpc.NewRPCFunc(GetBlock, []string{"height"}),
+	"get_tx":                  rpc.NewRPCFunc(GetTx, []string{"txId"}),
+	"outbox_events":           rpc.NewRPCFunc(OutboxEvents, []string{"limit"}),
+	"sync_progress":           rpc.NewRPCFunc(SyncProgress, []string{}),
 	"get_account":             rpc.NewRPCFunc(GetAccount, []string{"address"}),
 	"get_storage":             rpc.NewRPCFunc(GetStorage, []string{"address", "key"}),
+	"get_account_proof":       rpc.NewRPCFunc(GetAccountProof, []string{"address"}),
+	"get_storage_proof":       rpc.NewRPCFunc(GetStorageProof, []string{"address", "key"}),
+	"prove":                   rpc.NewRPCFunc(Prove, []string{"path", "height"}),
+	"state_diff":              rpc.NewRPCFunc(StateDiff, []string{"from"}),
 	"call":                    rpc.NewRPCFunc(Call, []string{"address", "data"}),
 	"call_code":               rpc.NewRPCFunc(CallCode, []string{"code", "data"}),
 	"list_validators":         rpc.NewRPCFunc(ListValidators, []string{}),
 	"dump_consensus_state":    rpc.NewRPCFunc(DumpConsensusState, []string{}),
+	"block_trace":             rpc.NewRPCFunc(BlockTrace, []string{"height"}),
+	"part_set_gc_stats":       rpc.NewRPCFunc(PartSetGCStats, []string{}),
 	"dump_storage":            rpc.NewRPCFunc(DumpStorage, []string{"address"}),
-	"broadcast_tx":            rpc.NewRPCFunc(BroadcastTx, []string{"tx"}),
+	"broadcast_tx":            rpc.NewWriteRPCFunc(BroadcastTx, []string{"tx"}),
+	"broadcast_txs":           rpc.NewWriteRPCFunc(BroadcastTxs, []string{"txs"}),
 	"list_unconfirmed_txs":    rpc.NewRPCFunc(ListUnconfirmedTxs, []string{}),
+	"mempool_audit":           rpc.NewRPCFunc(MempoolAudit, []string{"txId"}),
 	"list_accounts":           rpc.NewRPCFunc(ListAccounts, []string{}),
 	"get_name":                rpc.NewRPCFunc(GetName, []string{"name"}),
 	"list_names":              rpc.NewRPCFunc(ListNames, []string{}),
-	"unsafe/gen_priv_account": rpc.NewRPCFunc(GenPrivAccount, []string{}),
-	"unsafe/sign_tx":          rpc.NewRPCFunc(SignTx, []string{"tx", "privAccounts"}),
+	"name_expiration":         rpc.NewRPCFunc(NameExpiration, []string{"name"}),
+	"list_names_by_owner":     rpc.NewRPCFunc(ListNamesByOwner, []string{"owner", "offset", "limit"}),
+	"faucet_send":             rpc.NewWriteRPCFunc(FaucetSend, []string{"address", "amount"}),
+	"log_levels":              rpc.NewRPCFunc(LogLevels, []string{}),
+	"unsafe/gen_priv_account": rpc.NewWriteRPCFunc(GenPrivAccount, []string{}),
+	"unsafe/sign_tx":          rpc.NewWriteRPCFunc(SignTx, []string{"tx", "privAccounts"}),
+}
+
+// Operator routes, only served when rpc_unsafe is set.
+var UnsafeRoutes = map[string]*rpc.RPCFunc{
+	"dial_peers":               rpc.NewWriteRPCFunc(DialPeers, []string{"peers"}),
+	"unsafe/import_precommits": rpc.NewWriteRPCFunc(ImportPrecommits, []string{"precommits"}),
+	"unsafe_flush_mempool":     rpc.NewWriteRPCFunc(FlushMempool, []string{}),
+	"unsafe_rollback":          rpc.NewWriteRPCFunc(Rollback, []string{}),
+	"outbox_ack":               rpc.NewWriteRPCFunc(OutboxAck, []string{"offset"}), // prunes the acked events for good
+	"unsafe/set_log_level":     rpc.NewWriteRPCFunc(SetLogLevel, []string{"module", "level"}),
+}
+
+// Returns Routes, plus UnsafeRoutes if unsafe is true.
+func RoutesFor(unsafe bool) map[string]*rpc.RPCFunc {
+	if !unsafe {
+		return Routes
+	}
+	routes := make(map[string]*rpc.RPCFunc, len(Routes)+len(UnsafeRoutes))
+	for name, f := range Routes {
+		routes[name] = f
+	}
+	for name, f := range UnsafeRoutes {
+		routes[name] = f
+	}
+	return routes
+}
+
+// Routes that change node or chain state, see rpc.NewWriteRPCFunc.
+// These require authentication when rpc auth is configured.
+func IsWriteRoute(name string) bool {
+	rpcFunc := Routes[name]
+	if rpcFunc == nil {
+		rpcFunc = UnsafeRoutes[name]
+	}
+	return rpcFunc != nil && rpcFunc.IsWrite()
+}
+
+// Routes that manage the node itself: UnsafeRoutes, and the unsafe/ routes.
+// These require a pinned TLS client certificate when rpc_admin_client_fingerprints is configured.
+func IsAdminRoute(name string) bool {
+	return UnsafeRoutes[name] != nil || strings.HasPrefix(name, "unsafe/")
 }
@@ -0,0 +1,41 @@
+package core
+
+import (
+	"testing"
+)
+
+func TestWriteRoutes(t *testing.T) {
+	for _, name := range []string{"broadcast_tx", "faucet_send", "outbox_ack"} {
+		if !IsWriteRoute(name) {
+			t.Errorf("Expected %v to be a write route", name)
+		}
+	}
+	for _, name := range []string{"status", "get_tx"} {
+		if IsWriteRoute(name) {
+			t.Errorf("Expected %v not to be a write route", name)
+		}
+	}
+}
+
+func TestAdminRoutes(t *testing.T) {
+	for _, name := range []string{"dial_peers", "unsafe_flush_mempool", "unsafe_rollback", "outbox_ack", "unsafe/sign_tx"} {
+		if !IsAdminRoute(name) {
+			t.Errorf("Expected %v to be an admin route", name)
+		}
+	}
+	if IsAdminRoute("broadcast_tx") {
+		t.Error("Expected broadcast_tx not to be an admin route")
+	}
+}
+
+func TestUnsafeRoutes(t *testing.T) {
+	if Routes["outbox_ack"] != nil || RoutesFor(false)["outbox_ack"] != nil {
+		t.Error("Expected outbox_ack to be served only with rpc_unsafe")
+	}
+	if Routes["unsafe/set_log_level"] != nil || RoutesFor(false)["unsafe/set_log_level"] != nil {
+		t.Error("Expected unsafe/set_log_level to be served only with rpc_unsafe")
+	}
+	if RoutesFor(true)["unsafe/set_log_level"] == nil {
+		t.Error("Expected unsafe/set_log_level to be served with rpc_unsafe")
+	}
+}
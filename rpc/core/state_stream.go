@@ -0,0 +1,94 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	acm "github.com/tendermint/tendermint/account"
+	"github.com/tendermint/tendermint/binary"
+	"github.com/tendermint/tendermint/merkle"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	sm "github.com/tendermint/tendermint/state"
+)
+
+// Streamed states are kept by height, so that a stream can be resumed at
+// the same height after the chain moved on. Only the latest state is
+// saved, so streams start at the latest height.
+const maxStreamedStates = 4
+
+var (
+	streamedStatesMtx sync.Mutex
+	streamedStates    = make(map[int]*sm.State)
+)
+
+func getStreamedState(height int) (*sm.State, error) {
+	streamedStatesMtx.Lock()
+	defer streamedStatesMtx.Unlock()
+	if state, ok := streamedStates[height]; ok {
+		return state, nil
+	}
+	state := consensusState.GetState()
+	if height != 0 && height != state.LastBlockHeight {
+		return nil, fmt.Errorf("State at height %v is no longer available, stream from height 0", height)
+	}
+	if _, ok := streamedStates[state.LastBlockHeight]; !ok {
+		streamedStates[state.LastBlockHeight] = state
+		heights := []int{}
+		for height := range streamedStates {
+			heights = append(heights, height)
+		}
+		sort.Ints(heights)
+		for len(heights) > maxStreamedStates {
+			delete(streamedStates, heights[0])
+			heights = heights[1:]
+		}
+	}
+	return streamedStates[state.LastBlockHeight], nil
+}
+
+// Writes the accounts and storage of the state at height, or at the
+// latest height if 0, from the account at offset, as newline-delimited
+// ctypes.StateStreamItems. To resume, stream the same height from the
+// offset after that of the last account line received.
+func StreamState(w io.Writer, height int, offset int) error {
+	if offset < 0 {
+		return fmt.Errorf("Invalid offset %v", offset)
+	}
+	state, err := getStreamedState(height)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriterSize(w, 64*1024)
+	writeItem := func(item *ctypes.StateStreamItem) bool {
+		item.Height = state.LastBlockHeight
+		bw.Write(binary.JSONBytes(item))
+		_, err = bw.Write([]byte("\n"))
+		return err != nil
+	}
+	state.GetAccounts().(*merkle.IAVLTree).IterateFrom(offset, func(key interface{}, value interface{}) bool {
+		acc := value.(*acm.Account)
+		stopped := state.LoadStorage(acc.StorageRoot).Iterate(func(key interface{}, value interface{}) bool {
+			return writeItem(&ctypes.StateStreamItem{
+				Offset:  offset,
+				Address: acc.Address,
+				Storage: &ctypes.StorageItem{Key: key.([]byte), Value: value.([]byte)},
+			})
+		})
+		if stopped || writeItem(&ctypes.StateStreamItem{Offset: offset, Address: acc.Address, Account: acc}) {
+			return true
+		}
+		offset++
+		return false
+	})
+	if err != nil {
+		return err
+	}
+	writeItem(&ctypes.StateStreamItem{Offset: offset, End: true})
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
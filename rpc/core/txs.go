@@ -86,32 +86,37 @@ func SignTx(tx types.Tx, privAccounts []*account.PrivAccount) (types.Tx, error)
 			return nil, fmt.Errorf("Invalid (empty) privAccount @%v", i)
 		}
 	}
+	chainID := config.GetString("chain_id")
+	signVersion := mempoolReactor.Mempool.GetState().TxSignVersion()
 	switch tx.(type) {
 	case *types.SendTx:
 		sendTx := tx.(*types.SendTx)
 		for i, input := range sendTx.Inputs {
 			input.PubKey = privAccounts[i].PubKey
-			input.Signature = privAccounts[i].Sign(config.GetString("chain_id"), sendTx)
+			input.Signature = privAccounts[i].SignVersion(signVersion, chainID, sendTx)
 		}
 	case *types.CallTx:
 		callTx := tx.(*types.CallTx)
 		callTx.Input.PubKey = privAccounts[0].PubKey
-		callTx.Input.Signature = privAccounts[0].Sign(config.GetString("chain_id"), callTx)
+		callTx.Input.Signature = privAccounts[0].SignVersion(signVersion, chainID, callTx)
 	case *types.BondTx:
 		bondTx := tx.(*types.BondTx)
 		// the first privaccount corresponds to the BondTx pub key.
 		// the rest to the inputs
-		bondTx.Signature = privAccounts[0].Sign(config.GetString("chain_id"), bondTx).(account.SignatureEd25519)
+		bondTx.Signature = privAccounts[0].SignVersion(signVersion, chainID, bondTx).(account.SignatureEd25519)
 		for i, input := range bondTx.Inputs {
 			input.PubKey = privAccounts[i+1].PubKey
-			input.Signature = privAccounts[i+1].Sign(config.GetString("chain_id"), bondTx)
+			input.Signature = privAccounts[i+1].SignVersion(signVersion, chainID, bondTx)
 		}
 	case *types.UnbondTx:
 		unbondTx := tx.(*types.UnbondTx)
-		unbondTx.Signature = privAccounts[0].Sign(config.GetString("chain_id"), unbondTx).(account.SignatureEd25519)
+		unbondTx.Signature = privAccounts[0].SignVersion(signVersion, chainID, unbondTx).(account.SignatureEd25519)
 	case *types.RebondTx:
 		rebondTx := tx.(*types.RebondTx)
-		rebondTx.Signature = privAccounts[0].Sign(config.GetString("chain_id"), rebondTx).(account.SignatureEd25519)
+		rebondTx.Signature = privAccounts[0].SignVersion(signVersion, chainID, rebondTx).(account.SignatureEd25519)
+	case *types.EmergencyHaltTx:
+		haltTx := tx.(*types.EmergencyHaltTx)
+		haltTx.Signature = privAccounts[0].SignVersion(signVersion, chainID, haltTx).(account.SignatureEd25519)
 	}
 	return tx, nil
 }
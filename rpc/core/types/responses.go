@@ -2,6 +2,8 @@ package core_types
 
 import (
 	"github.com/tendermint/tendermint/account"
+	bc "github.com/tendermint/tendermint/blockchain"
+	cm "github.com/tendermint/tendermint/consensus"
 	sm "github.com/tendermint/tendermint/state"
 	"github.com/tendermint/tendermint/types"
 )
@@ -11,6 +13,56 @@ type ResponseGetStorage struct {
 	Value []byte `json:"value"`
 }
 
+// Proof proves Account against the StateHash of the block at Proof.Height,
+// see sm.VerifyProof.
+type ResponseGetAccountProof struct {
+	Account *account.Account `json:"account"`
+	Proof   *sm.Proof        `json:"proof"`
+}
+
+// Proof proves Value against the StateHash of the block at Proof.Height,
+// see sm.VerifyProof.
+type ResponseGetStorageProof struct {
+	Key   []byte    `json:"key"`
+	Value []byte    `json:"value"`
+	Proof *sm.Proof `json:"proof"`
+}
+
+// Diff brings a node's state to the state after the block at Height,
+// which Validation commits.
+type ResponseStateDiff struct {
+	Height     int               `json:"height"`
+	Diff       *sm.StateDiff     `json:"diff"`
+	Validation *types.Validation `json:"validation"`
+}
+
+type ResponseDialPeers struct {
+	Dialing []string `json:"dialing"`
+}
+
+type ResponseFlushMempool struct {
+	Flushed int `json:"flushed"` // Number of txs dropped.
+}
+
+type ResponseRollback struct {
+	LastBlockHeight int `json:"last_block_height"` // After the rollback.
+}
+
+type ResponseOutboxEvents struct {
+	Height int               `json:"height"` // Offset of the last event.
+	Acked  int               `json:"acked"`  // Offset of the last ack.
+	Events []*bc.OutboxEvent `json:"events"`
+}
+
+type ResponseOutboxAck struct {
+	Acked int `json:"acked"`
+}
+
+// Log levels by module. The default level is under "".
+type ResponseLogLevels struct {
+	Levels map[string]string `json:"levels"`
+}
+
 type ResponseCall struct {
 	Return  []byte `json:"return"`
 	GasUsed int64  `json:"gas_used"`
@@ -32,6 +84,17 @@ type ResponseDumpStorage struct {
 	StorageItems []StorageItem `json:"storage_items"`
 }
 
+// A line of the stream_state stream. The storage of the account at
+// Offset is sent before the account, and the last line has End set.
+type StateStreamItem struct {
+	Height  int              `json:"height"`
+	Offset  int              `json:"offset"` // Of the account, in address order
+	Address []byte           `json:"address"`
+	Storage *StorageItem     `json:"storage"`
+	Account *account.Account `json:"account"`
+	End     bool             `json:"end"`
+}
+
 type ResponseBlockchainInfo struct {
 	LastHeight int                `json:"last_height"`
 	BlockMetas []*types.BlockMeta `json:"block_metas"`
@@ -46,6 +109,19 @@ type Receipt struct {
 	TxHash          []byte `json:"tx_hash"`
 	CreatesContract uint8  `json:"creates_contract"`
 	ContractAddr    []byte `json:"contract_addr"`
+	GasUsed         int64  `json:"gas_used"` // Intrinsic gas only for CallTxs; see EventMsgCallTx.
+}
+
+// One entry per tx, in order. Error is set if the tx was rejected.
+// Subscribe to types.EventStringTxCommitted(Receipt.TxHash) over
+// the websocket to be notified when the tx is committed.
+type ResponseBroadcastTxs struct {
+	Results []*TxResult `json:"results"`
+}
+
+type TxResult struct {
+	Receipt *Receipt `json:"receipt"`
+	Error   string   `json:"error"`
 }
 
 type ResponseStatus struct {
@@ -57,6 +133,8 @@ type ResponseStatus struct {
 	LatestBlockHash   []byte         `json:"latest_block_hash"`
 	LatestBlockHeight int            `json:"latest_block_height"`
 	LatestBlockTime   int64          `json:"latest_block_time"` // nano
+
+	SigningGate *cm.SigningGateStatus `json:"signing_gate"` // nil unless min_signing_peers or signing_sentries is set
 }
 
 type ResponseNetInfo struct {
@@ -81,7 +159,24 @@ type ResponseDumpConsensusState struct {
 	PeerRoundStates []string `json:"peer_round_states"`
 }
 
+type ResponseImportPrecommits struct {
+	Added  int      `json:"added"`
+	Errors []string `json:"errors"`
+}
+
 type ResponseListNames struct {
 	BlockHeight int                   `json:"block_height"`
 	Names       []*types.NameRegEntry `json:"names"`
 }
+
+type ResponseNameExpiration struct {
+	Name        string `json:"name"`
+	Expires     int    `json:"expires"`
+	BlockHeight int    `json:"block_height"`
+}
+
+type ResponseListNamesByOwner struct {
+	BlockHeight int                   `json:"block_height"`
+	Total       int                   `json:"total"`
+	Names       []*types.NameRegEntry `json:"names"`
+}
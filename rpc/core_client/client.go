@@ -21,20 +21,41 @@ var reverseFuncMap = map[string]string{
 	"BlockchainInfo":     "blockchain",
 	"Genesis":            "genesis",
 	"GetBlock":           "get_block",
+	"GetTx":              "get_tx",
+	"OutboxEvents":       "outbox_events",
+	"SyncProgress":       "sync_progress",
 	"GetAccount":         "get_account",
 	"GetStorage":         "get_storage",
+	"GetAccountProof":    "get_account_proof",
+	"GetStorageProof":    "get_storage_proof",
+	"Prove":              "prove",
+	"StateDiff":          "state_diff",
 	"Call":               "call",
 	"CallCode":           "call_code",
 	"ListValidators":     "list_validators",
 	"DumpConsensusState": "dump_consensus_state",
+	"BlockTrace":         "block_trace",
+	"PartSetGCStats":     "part_set_gc_stats",
 	"DumpStorage":        "dump_storage",
 	"BroadcastTx":        "broadcast_tx",
+	"BroadcastTxs":       "broadcast_txs",
 	"ListUnconfirmedTxs": "list_unconfirmed_txs",
+	"MempoolAudit":       "mempool_audit",
 	"ListAccounts":       "list_accounts",
 	"GetName":            "get_name",
 	"ListNames":          "list_names",
+	"NameExpiration":     "name_expiration",
+	"ListNamesByOwner":   "list_names_by_owner",
+	"FaucetSend":         "faucet_send",
+	"LogLevels":          "log_levels",
 	"GenPrivAccount":     "unsafe/gen_priv_account",
 	"SignTx":             "unsafe/sign_tx",
+	"ImportPrecommits":   "unsafe/import_precommits",
+	"SetLogLevel":        "unsafe/set_log_level",
+	"DialPeers":          "dial_peers",
+	"FlushMempool":       "unsafe_flush_mempool",
+	"Rollback":           "unsafe_rollback",
+	"OutboxAck":          "outbox_ack",
 }
 
 /*
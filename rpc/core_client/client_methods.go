@@ -7,6 +7,9 @@ import (
 	"github.com/tendermint/tendermint/account"
 	acm "github.com/tendermint/tendermint/account"
 	"github.com/tendermint/tendermint/binary"
+	bc "github.com/tendermint/tendermint/blockchain"
+	cm "github.com/tendermint/tendermint/consensus"
+	mempl "github.com/tendermint/tendermint/mempool"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
 	rpctypes "github.com/tendermint/tendermint/rpc/types"
 	sm "github.com/tendermint/tendermint/state"
@@ -16,25 +19,76 @@ import (
 )
 
 type Client interface {
+	BlockTrace(height int) (*cm.BlockTrace, error)
 	BlockchainInfo(minHeight uint, maxHeight uint) (*ctypes.ResponseBlockchainInfo, error)
 	BroadcastTx(tx types.Tx) (*ctypes.Receipt, error)
+	BroadcastTxs(txs []types.Tx) (*ctypes.ResponseBroadcastTxs, error)
 	Call(address []byte, data []byte) (*ctypes.ResponseCall, error)
 	CallCode(code []byte, data []byte) (*ctypes.ResponseCall, error)
+	DialPeers(peers []string) (*ctypes.ResponseDialPeers, error)
 	DumpConsensusState() (*ctypes.ResponseDumpConsensusState, error)
 	DumpStorage(address []byte) (*ctypes.ResponseDumpStorage, error)
+	FaucetSend(address []byte, amount int64) (*ctypes.Receipt, error)
+	FlushMempool() (*ctypes.ResponseFlushMempool, error)
 	GenPrivAccount() (*acm.PrivAccount, error)
 	Genesis() (*sm.GenesisDoc, error)
 	GetAccount(address []byte) (*acm.Account, error)
+	GetAccountProof(address []byte) (*ctypes.ResponseGetAccountProof, error)
 	GetBlock(height uint) (*ctypes.ResponseGetBlock, error)
 	GetName(name string) (*types.NameRegEntry, error)
 	GetStorage(address []byte, key []byte) (*ctypes.ResponseGetStorage, error)
+	GetStorageProof(address []byte, key []byte) (*ctypes.ResponseGetStorageProof, error)
+	GetTx(txId []byte) (*bc.TxIndexEntry, error)
+	ImportPrecommits(precommits []*cm.OfflinePrecommit) (*ctypes.ResponseImportPrecommits, error)
 	ListAccounts() (*ctypes.ResponseListAccounts, error)
 	ListNames() (*ctypes.ResponseListNames, error)
+	ListNamesByOwner(owner []byte, offset int, limit int) (*ctypes.ResponseListNamesByOwner, error)
 	ListUnconfirmedTxs() ([]types.Tx, error)
 	ListValidators() (*ctypes.ResponseListValidators, error)
+	LogLevels() (*ctypes.ResponseLogLevels, error)
+	MempoolAudit(txId []byte) ([]*mempl.AuditEntry, error)
+	NameExpiration(name string) (*ctypes.ResponseNameExpiration, error)
 	NetInfo() (*ctypes.ResponseNetInfo, error)
+	OutboxAck(offset int) (*ctypes.ResponseOutboxAck, error)
+	OutboxEvents(limit int) (*ctypes.ResponseOutboxEvents, error)
+	PartSetGCStats() (*cm.PartSetGCStats, error)
+	Prove(path string, height int) (*sm.Proof, error)
+	Rollback() (*ctypes.ResponseRollback, error)
+	SetLogLevel(module string, level string) (*ctypes.ResponseLogLevels, error)
 	SignTx(tx types.Tx, privAccounts []*account.PrivAccount) (types.Tx, error)
+	StateDiff(from *sm.StateRoots) (*ctypes.ResponseStateDiff, error)
 	Status() (*ctypes.ResponseStatus, error)
+	SyncProgress() (*bc.SyncProgress, error)
+}
+
+func (c *ClientHTTP) BlockTrace(height int) (*cm.BlockTrace, error) {
+	values, err := argsToURLValues([]string{"height"}, height)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.PostForm(c.addr+reverseFuncMap["BlockTrace"], values)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *cm.BlockTrace `json:"result"`
+		Error   string         `json:"error"`
+		Id      string         `json:"id"`
+		JSONRPC string         `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
 }
 
 func (c *ClientHTTP) BlockchainInfo(minHeight uint, maxHeight uint) (*ctypes.ResponseBlockchainInfo, error) {
@@ -97,6 +151,36 @@ func (c *ClientHTTP) BroadcastTx(tx types.Tx) (*ctypes.Receipt, error) {
 	return response.Result, nil
 }
 
+func (c *ClientHTTP) BroadcastTxs(txs []types.Tx) (*ctypes.ResponseBroadcastTxs, error) {
+	values, err := argsToURLValues([]string{"txs"}, txs)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.PostForm(c.addr+reverseFuncMap["BroadcastTxs"], values)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *ctypes.ResponseBroadcastTxs `json:"result"`
+		Error   string                       `json:"error"`
+		Id      string                       `json:"id"`
+		JSONRPC string                       `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
 func (c *ClientHTTP) Call(address []byte, data []byte) (*ctypes.ResponseCall, error) {
 	values, err := argsToURLValues([]string{"address", "data"}, address, data)
 	if err != nil {
@@ -157,6 +241,36 @@ func (c *ClientHTTP) CallCode(code []byte, data []byte) (*ctypes.ResponseCall, e
 	return response.Result, nil
 }
 
+func (c *ClientHTTP) DialPeers(peers []string) (*ctypes.ResponseDialPeers, error) {
+	values, err := argsToURLValues([]string{"peers"}, peers)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.PostForm(c.addr+reverseFuncMap["DialPeers"], values)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *ctypes.ResponseDialPeers `json:"result"`
+		Error   string                    `json:"error"`
+		Id      string                    `json:"id"`
+		JSONRPC string                    `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
 func (c *ClientHTTP) DumpConsensusState() (*ctypes.ResponseDumpConsensusState, error) {
 	values, err := argsToURLValues(nil)
 	if err != nil {
@@ -217,6 +331,66 @@ func (c *ClientHTTP) DumpStorage(address []byte) (*ctypes.ResponseDumpStorage, e
 	return response.Result, nil
 }
 
+func (c *ClientHTTP) FaucetSend(address []byte, amount int64) (*ctypes.Receipt, error) {
+	values, err := argsToURLValues([]string{"address", "amount"}, address, amount)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.PostForm(c.addr+reverseFuncMap["FaucetSend"], values)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *ctypes.Receipt `json:"result"`
+		Error   string          `json:"error"`
+		Id      string          `json:"id"`
+		JSONRPC string          `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientHTTP) FlushMempool() (*ctypes.ResponseFlushMempool, error) {
+	values, err := argsToURLValues(nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.PostForm(c.addr+reverseFuncMap["FlushMempool"], values)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *ctypes.ResponseFlushMempool `json:"result"`
+		Error   string                       `json:"error"`
+		Id      string                       `json:"id"`
+		JSONRPC string                       `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
 func (c *ClientHTTP) GenPrivAccount() (*acm.PrivAccount, error) {
 	values, err := argsToURLValues(nil)
 	if err != nil {
@@ -307,6 +481,36 @@ func (c *ClientHTTP) GetAccount(address []byte) (*acm.Account, error) {
 	return response.Result, nil
 }
 
+func (c *ClientHTTP) GetAccountProof(address []byte) (*ctypes.ResponseGetAccountProof, error) {
+	values, err := argsToURLValues([]string{"address"}, address)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.PostForm(c.addr+reverseFuncMap["GetAccountProof"], values)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *ctypes.ResponseGetAccountProof `json:"result"`
+		Error   string                          `json:"error"`
+		Id      string                          `json:"id"`
+		JSONRPC string                          `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
 func (c *ClientHTTP) GetBlock(height uint) (*ctypes.ResponseGetBlock, error) {
 	values, err := argsToURLValues([]string{"height"}, height)
 	if err != nil {
@@ -397,12 +601,12 @@ func (c *ClientHTTP) GetStorage(address []byte, key []byte) (*ctypes.ResponseGet
 	return response.Result, nil
 }
 
-func (c *ClientHTTP) ListAccounts() (*ctypes.ResponseListAccounts, error) {
-	values, err := argsToURLValues(nil)
+func (c *ClientHTTP) GetStorageProof(address []byte, key []byte) (*ctypes.ResponseGetStorageProof, error) {
+	values, err := argsToURLValues([]string{"address", "key"}, address, key)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := http.PostForm(c.addr+reverseFuncMap["ListAccounts"], values)
+	resp, err := http.PostForm(c.addr+reverseFuncMap["GetStorageProof"], values)
 	if err != nil {
 		return nil, err
 	}
@@ -412,10 +616,10 @@ func (c *ClientHTTP) ListAccounts() (*ctypes.ResponseListAccounts, error) {
 		return nil, err
 	}
 	var response struct {
-		Result  *ctypes.ResponseListAccounts `json:"result"`
-		Error   string                       `json:"error"`
-		Id      string                       `json:"id"`
-		JSONRPC string                       `json:"jsonrpc"`
+		Result  *ctypes.ResponseGetStorageProof `json:"result"`
+		Error   string                          `json:"error"`
+		Id      string                          `json:"id"`
+		JSONRPC string                          `json:"jsonrpc"`
 	}
 	binary.ReadJSON(&response, body, &err)
 	if err != nil {
@@ -427,12 +631,12 @@ func (c *ClientHTTP) ListAccounts() (*ctypes.ResponseListAccounts, error) {
 	return response.Result, nil
 }
 
-func (c *ClientHTTP) ListNames() (*ctypes.ResponseListNames, error) {
-	values, err := argsToURLValues(nil)
+func (c *ClientHTTP) GetTx(txId []byte) (*bc.TxIndexEntry, error) {
+	values, err := argsToURLValues([]string{"txId"}, txId)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := http.PostForm(c.addr+reverseFuncMap["ListNames"], values)
+	resp, err := http.PostForm(c.addr+reverseFuncMap["GetTx"], values)
 	if err != nil {
 		return nil, err
 	}
@@ -442,10 +646,10 @@ func (c *ClientHTTP) ListNames() (*ctypes.ResponseListNames, error) {
 		return nil, err
 	}
 	var response struct {
-		Result  *ctypes.ResponseListNames `json:"result"`
-		Error   string                    `json:"error"`
-		Id      string                    `json:"id"`
-		JSONRPC string                    `json:"jsonrpc"`
+		Result  *bc.TxIndexEntry `json:"result"`
+		Error   string           `json:"error"`
+		Id      string           `json:"id"`
+		JSONRPC string           `json:"jsonrpc"`
 	}
 	binary.ReadJSON(&response, body, &err)
 	if err != nil {
@@ -457,12 +661,12 @@ func (c *ClientHTTP) ListNames() (*ctypes.ResponseListNames, error) {
 	return response.Result, nil
 }
 
-func (c *ClientHTTP) ListUnconfirmedTxs() ([]types.Tx, error) {
-	values, err := argsToURLValues(nil)
+func (c *ClientHTTP) ImportPrecommits(precommits []*cm.OfflinePrecommit) (*ctypes.ResponseImportPrecommits, error) {
+	values, err := argsToURLValues([]string{"precommits"}, precommits)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := http.PostForm(c.addr+reverseFuncMap["ListUnconfirmedTxs"], values)
+	resp, err := http.PostForm(c.addr+reverseFuncMap["ImportPrecommits"], values)
 	if err != nil {
 		return nil, err
 	}
@@ -472,10 +676,10 @@ func (c *ClientHTTP) ListUnconfirmedTxs() ([]types.Tx, error) {
 		return nil, err
 	}
 	var response struct {
-		Result  []types.Tx `json:"result"`
-		Error   string     `json:"error"`
-		Id      string     `json:"id"`
-		JSONRPC string     `json:"jsonrpc"`
+		Result  *ctypes.ResponseImportPrecommits `json:"result"`
+		Error   string                           `json:"error"`
+		Id      string                           `json:"id"`
+		JSONRPC string                           `json:"jsonrpc"`
 	}
 	binary.ReadJSON(&response, body, &err)
 	if err != nil {
@@ -487,12 +691,12 @@ func (c *ClientHTTP) ListUnconfirmedTxs() ([]types.Tx, error) {
 	return response.Result, nil
 }
 
-func (c *ClientHTTP) ListValidators() (*ctypes.ResponseListValidators, error) {
+func (c *ClientHTTP) ListAccounts() (*ctypes.ResponseListAccounts, error) {
 	values, err := argsToURLValues(nil)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := http.PostForm(c.addr+reverseFuncMap["ListValidators"], values)
+	resp, err := http.PostForm(c.addr+reverseFuncMap["ListAccounts"], values)
 	if err != nil {
 		return nil, err
 	}
@@ -502,10 +706,10 @@ func (c *ClientHTTP) ListValidators() (*ctypes.ResponseListValidators, error) {
 		return nil, err
 	}
 	var response struct {
-		Result  *ctypes.ResponseListValidators `json:"result"`
-		Error   string                         `json:"error"`
-		Id      string                         `json:"id"`
-		JSONRPC string                         `json:"jsonrpc"`
+		Result  *ctypes.ResponseListAccounts `json:"result"`
+		Error   string                       `json:"error"`
+		Id      string                       `json:"id"`
+		JSONRPC string                       `json:"jsonrpc"`
 	}
 	binary.ReadJSON(&response, body, &err)
 	if err != nil {
@@ -517,12 +721,12 @@ func (c *ClientHTTP) ListValidators() (*ctypes.ResponseListValidators, error) {
 	return response.Result, nil
 }
 
-func (c *ClientHTTP) NetInfo() (*ctypes.ResponseNetInfo, error) {
+func (c *ClientHTTP) ListNames() (*ctypes.ResponseListNames, error) {
 	values, err := argsToURLValues(nil)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := http.PostForm(c.addr+reverseFuncMap["NetInfo"], values)
+	resp, err := http.PostForm(c.addr+reverseFuncMap["ListNames"], values)
 	if err != nil {
 		return nil, err
 	}
@@ -532,10 +736,10 @@ func (c *ClientHTTP) NetInfo() (*ctypes.ResponseNetInfo, error) {
 		return nil, err
 	}
 	var response struct {
-		Result  *ctypes.ResponseNetInfo `json:"result"`
-		Error   string                  `json:"error"`
-		Id      string                  `json:"id"`
-		JSONRPC string                  `json:"jsonrpc"`
+		Result  *ctypes.ResponseListNames `json:"result"`
+		Error   string                    `json:"error"`
+		Id      string                    `json:"id"`
+		JSONRPC string                    `json:"jsonrpc"`
 	}
 	binary.ReadJSON(&response, body, &err)
 	if err != nil {
@@ -547,12 +751,12 @@ func (c *ClientHTTP) NetInfo() (*ctypes.ResponseNetInfo, error) {
 	return response.Result, nil
 }
 
-func (c *ClientHTTP) SignTx(tx types.Tx, privAccounts []*account.PrivAccount) (types.Tx, error) {
-	values, err := argsToURLValues([]string{"tx", "privAccounts"}, tx, privAccounts)
+func (c *ClientHTTP) ListNamesByOwner(owner []byte, offset int, limit int) (*ctypes.ResponseListNamesByOwner, error) {
+	values, err := argsToURLValues([]string{"owner", "offset", "limit"}, owner, offset, limit)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := http.PostForm(c.addr+reverseFuncMap["SignTx"], values)
+	resp, err := http.PostForm(c.addr+reverseFuncMap["ListNamesByOwner"], values)
 	if err != nil {
 		return nil, err
 	}
@@ -562,10 +766,10 @@ func (c *ClientHTTP) SignTx(tx types.Tx, privAccounts []*account.PrivAccount) (t
 		return nil, err
 	}
 	var response struct {
-		Result  types.Tx `json:"result"`
-		Error   string   `json:"error"`
-		Id      string   `json:"id"`
-		JSONRPC string   `json:"jsonrpc"`
+		Result  *ctypes.ResponseListNamesByOwner `json:"result"`
+		Error   string                           `json:"error"`
+		Id      string                           `json:"id"`
+		JSONRPC string                           `json:"jsonrpc"`
 	}
 	binary.ReadJSON(&response, body, &err)
 	if err != nil {
@@ -577,12 +781,12 @@ func (c *ClientHTTP) SignTx(tx types.Tx, privAccounts []*account.PrivAccount) (t
 	return response.Result, nil
 }
 
-func (c *ClientHTTP) Status() (*ctypes.ResponseStatus, error) {
+func (c *ClientHTTP) ListUnconfirmedTxs() ([]types.Tx, error) {
 	values, err := argsToURLValues(nil)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := http.PostForm(c.addr+reverseFuncMap["Status"], values)
+	resp, err := http.PostForm(c.addr+reverseFuncMap["ListUnconfirmedTxs"], values)
 	if err != nil {
 		return nil, err
 	}
@@ -592,10 +796,10 @@ func (c *ClientHTTP) Status() (*ctypes.ResponseStatus, error) {
 		return nil, err
 	}
 	var response struct {
-		Result  *ctypes.ResponseStatus `json:"result"`
-		Error   string                 `json:"error"`
-		Id      string                 `json:"id"`
-		JSONRPC string                 `json:"jsonrpc"`
+		Result  []types.Tx `json:"result"`
+		Error   string     `json:"error"`
+		Id      string     `json:"id"`
+		JSONRPC string     `json:"jsonrpc"`
 	}
 	binary.ReadJSON(&response, body, &err)
 	if err != nil {
@@ -607,19 +811,22 @@ func (c *ClientHTTP) Status() (*ctypes.ResponseStatus, error) {
 	return response.Result, nil
 }
 
-func (c *ClientJSON) BlockchainInfo(minHeight uint, maxHeight uint) (*ctypes.ResponseBlockchainInfo, error) {
-	request := rpctypes.RPCRequest{
-		JSONRPC: "2.0",
-		Method:  reverseFuncMap["BlockchainInfo"],
-		Params:  []interface{}{minHeight, maxHeight},
-		Id:      0,
+func (c *ClientHTTP) ListValidators() (*ctypes.ResponseListValidators, error) {
+	values, err := argsToURLValues(nil)
+	if err != nil {
+		return nil, err
 	}
-	body, err := c.RequestResponse(request)
+	resp, err := http.PostForm(c.addr+reverseFuncMap["ListValidators"], values)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
 	var response struct {
-		Result  *ctypes.ResponseBlockchainInfo `json:"result"`
+		Result  *ctypes.ResponseListValidators `json:"result"`
 		Error   string                         `json:"error"`
 		Id      string                         `json:"id"`
 		JSONRPC string                         `json:"jsonrpc"`
@@ -634,19 +841,682 @@ func (c *ClientJSON) BlockchainInfo(minHeight uint, maxHeight uint) (*ctypes.Res
 	return response.Result, nil
 }
 
-func (c *ClientJSON) BroadcastTx(tx types.Tx) (*ctypes.Receipt, error) {
-	request := rpctypes.RPCRequest{
-		JSONRPC: "2.0",
-		Method:  reverseFuncMap["BroadcastTx"],
-		Params:  []interface{}{tx},
-		Id:      0,
+func (c *ClientHTTP) LogLevels() (*ctypes.ResponseLogLevels, error) {
+	values, err := argsToURLValues(nil)
+	if err != nil {
+		return nil, err
 	}
-	body, err := c.RequestResponse(request)
+	resp, err := http.PostForm(c.addr+reverseFuncMap["LogLevels"], values)
 	if err != nil {
 		return nil, err
 	}
-	var response struct {
-		Result  *ctypes.Receipt `json:"result"`
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *ctypes.ResponseLogLevels `json:"result"`
+		Error   string                    `json:"error"`
+		Id      string                    `json:"id"`
+		JSONRPC string                    `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientHTTP) MempoolAudit(txId []byte) ([]*mempl.AuditEntry, error) {
+	values, err := argsToURLValues([]string{"txId"}, txId)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.PostForm(c.addr+reverseFuncMap["MempoolAudit"], values)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  []*mempl.AuditEntry `json:"result"`
+		Error   string              `json:"error"`
+		Id      string              `json:"id"`
+		JSONRPC string              `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientHTTP) NameExpiration(name string) (*ctypes.ResponseNameExpiration, error) {
+	values, err := argsToURLValues([]string{"name"}, name)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.PostForm(c.addr+reverseFuncMap["NameExpiration"], values)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *ctypes.ResponseNameExpiration `json:"result"`
+		Error   string                         `json:"error"`
+		Id      string                         `json:"id"`
+		JSONRPC string                         `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientHTTP) NetInfo() (*ctypes.ResponseNetInfo, error) {
+	values, err := argsToURLValues(nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.PostForm(c.addr+reverseFuncMap["NetInfo"], values)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *ctypes.ResponseNetInfo `json:"result"`
+		Error   string                  `json:"error"`
+		Id      string                  `json:"id"`
+		JSONRPC string                  `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientHTTP) OutboxAck(offset int) (*ctypes.ResponseOutboxAck, error) {
+	values, err := argsToURLValues([]string{"offset"}, offset)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.PostForm(c.addr+reverseFuncMap["OutboxAck"], values)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *ctypes.ResponseOutboxAck `json:"result"`
+		Error   string                    `json:"error"`
+		Id      string                    `json:"id"`
+		JSONRPC string                    `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientHTTP) OutboxEvents(limit int) (*ctypes.ResponseOutboxEvents, error) {
+	values, err := argsToURLValues([]string{"limit"}, limit)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.PostForm(c.addr+reverseFuncMap["OutboxEvents"], values)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *ctypes.ResponseOutboxEvents `json:"result"`
+		Error   string                       `json:"error"`
+		Id      string                       `json:"id"`
+		JSONRPC string                       `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientHTTP) PartSetGCStats() (*cm.PartSetGCStats, error) {
+	values, err := argsToURLValues(nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.PostForm(c.addr+reverseFuncMap["PartSetGCStats"], values)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *cm.PartSetGCStats `json:"result"`
+		Error   string             `json:"error"`
+		Id      string             `json:"id"`
+		JSONRPC string             `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientHTTP) Prove(path string, height int) (*sm.Proof, error) {
+	values, err := argsToURLValues([]string{"path", "height"}, path, height)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.PostForm(c.addr+reverseFuncMap["Prove"], values)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *sm.Proof `json:"result"`
+		Error   string    `json:"error"`
+		Id      string    `json:"id"`
+		JSONRPC string    `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientHTTP) Rollback() (*ctypes.ResponseRollback, error) {
+	values, err := argsToURLValues(nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.PostForm(c.addr+reverseFuncMap["Rollback"], values)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *ctypes.ResponseRollback `json:"result"`
+		Error   string                   `json:"error"`
+		Id      string                   `json:"id"`
+		JSONRPC string                   `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientHTTP) SetLogLevel(module string, level string) (*ctypes.ResponseLogLevels, error) {
+	values, err := argsToURLValues([]string{"module", "level"}, module, level)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.PostForm(c.addr+reverseFuncMap["SetLogLevel"], values)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *ctypes.ResponseLogLevels `json:"result"`
+		Error   string                    `json:"error"`
+		Id      string                    `json:"id"`
+		JSONRPC string                    `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientHTTP) SignTx(tx types.Tx, privAccounts []*account.PrivAccount) (types.Tx, error) {
+	values, err := argsToURLValues([]string{"tx", "privAccounts"}, tx, privAccounts)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.PostForm(c.addr+reverseFuncMap["SignTx"], values)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  types.Tx `json:"result"`
+		Error   string   `json:"error"`
+		Id      string   `json:"id"`
+		JSONRPC string   `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientHTTP) StateDiff(from *sm.StateRoots) (*ctypes.ResponseStateDiff, error) {
+	values, err := argsToURLValues([]string{"from"}, from)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.PostForm(c.addr+reverseFuncMap["StateDiff"], values)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *ctypes.ResponseStateDiff `json:"result"`
+		Error   string                    `json:"error"`
+		Id      string                    `json:"id"`
+		JSONRPC string                    `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientHTTP) Status() (*ctypes.ResponseStatus, error) {
+	values, err := argsToURLValues(nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.PostForm(c.addr+reverseFuncMap["Status"], values)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *ctypes.ResponseStatus `json:"result"`
+		Error   string                 `json:"error"`
+		Id      string                 `json:"id"`
+		JSONRPC string                 `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientHTTP) SyncProgress() (*bc.SyncProgress, error) {
+	values, err := argsToURLValues(nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.PostForm(c.addr+reverseFuncMap["SyncProgress"], values)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *bc.SyncProgress `json:"result"`
+		Error   string           `json:"error"`
+		Id      string           `json:"id"`
+		JSONRPC string           `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientJSON) BlockTrace(height int) (*cm.BlockTrace, error) {
+	request := rpctypes.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  reverseFuncMap["BlockTrace"],
+		Params:  []interface{}{height},
+		Id:      0,
+	}
+	body, err := c.RequestResponse(request)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *cm.BlockTrace `json:"result"`
+		Error   string         `json:"error"`
+		Id      string         `json:"id"`
+		JSONRPC string         `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientJSON) BlockchainInfo(minHeight uint, maxHeight uint) (*ctypes.ResponseBlockchainInfo, error) {
+	request := rpctypes.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  reverseFuncMap["BlockchainInfo"],
+		Params:  []interface{}{minHeight, maxHeight},
+		Id:      0,
+	}
+	body, err := c.RequestResponse(request)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *ctypes.ResponseBlockchainInfo `json:"result"`
+		Error   string                         `json:"error"`
+		Id      string                         `json:"id"`
+		JSONRPC string                         `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientJSON) BroadcastTx(tx types.Tx) (*ctypes.Receipt, error) {
+	request := rpctypes.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  reverseFuncMap["BroadcastTx"],
+		Params:  []interface{}{tx},
+		Id:      0,
+	}
+	body, err := c.RequestResponse(request)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *ctypes.Receipt `json:"result"`
+		Error   string          `json:"error"`
+		Id      string          `json:"id"`
+		JSONRPC string          `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientJSON) BroadcastTxs(txs []types.Tx) (*ctypes.ResponseBroadcastTxs, error) {
+	request := rpctypes.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  reverseFuncMap["BroadcastTxs"],
+		Params:  []interface{}{txs},
+		Id:      0,
+	}
+	body, err := c.RequestResponse(request)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *ctypes.ResponseBroadcastTxs `json:"result"`
+		Error   string                       `json:"error"`
+		Id      string                       `json:"id"`
+		JSONRPC string                       `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientJSON) Call(address []byte, data []byte) (*ctypes.ResponseCall, error) {
+	request := rpctypes.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  reverseFuncMap["Call"],
+		Params:  []interface{}{address, data},
+		Id:      0,
+	}
+	body, err := c.RequestResponse(request)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *ctypes.ResponseCall `json:"result"`
+		Error   string               `json:"error"`
+		Id      string               `json:"id"`
+		JSONRPC string               `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientJSON) CallCode(code []byte, data []byte) (*ctypes.ResponseCall, error) {
+	request := rpctypes.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  reverseFuncMap["CallCode"],
+		Params:  []interface{}{code, data},
+		Id:      0,
+	}
+	body, err := c.RequestResponse(request)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *ctypes.ResponseCall `json:"result"`
+		Error   string               `json:"error"`
+		Id      string               `json:"id"`
+		JSONRPC string               `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientJSON) DialPeers(peers []string) (*ctypes.ResponseDialPeers, error) {
+	request := rpctypes.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  reverseFuncMap["DialPeers"],
+		Params:  []interface{}{peers},
+		Id:      0,
+	}
+	body, err := c.RequestResponse(request)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *ctypes.ResponseDialPeers `json:"result"`
+		Error   string                    `json:"error"`
+		Id      string                    `json:"id"`
+		JSONRPC string                    `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientJSON) DumpConsensusState() (*ctypes.ResponseDumpConsensusState, error) {
+	request := rpctypes.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  reverseFuncMap["DumpConsensusState"],
+		Params:  []interface{}{},
+		Id:      0,
+	}
+	body, err := c.RequestResponse(request)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *ctypes.ResponseDumpConsensusState `json:"result"`
+		Error   string                             `json:"error"`
+		Id      string                             `json:"id"`
+		JSONRPC string                             `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientJSON) DumpStorage(address []byte) (*ctypes.ResponseDumpStorage, error) {
+	request := rpctypes.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  reverseFuncMap["DumpStorage"],
+		Params:  []interface{}{address},
+		Id:      0,
+	}
+	body, err := c.RequestResponse(request)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *ctypes.ResponseDumpStorage `json:"result"`
+		Error   string                      `json:"error"`
+		Id      string                      `json:"id"`
+		JSONRPC string                      `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientJSON) FaucetSend(address []byte, amount int64) (*ctypes.Receipt, error) {
+	request := rpctypes.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  reverseFuncMap["FaucetSend"],
+		Params:  []interface{}{address, amount},
+		Id:      0,
+	}
+	body, err := c.RequestResponse(request)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *ctypes.Receipt `json:"result"`
 		Error   string          `json:"error"`
 		Id      string          `json:"id"`
 		JSONRPC string          `json:"jsonrpc"`
@@ -661,11 +1531,227 @@ func (c *ClientJSON) BroadcastTx(tx types.Tx) (*ctypes.Receipt, error) {
 	return response.Result, nil
 }
 
-func (c *ClientJSON) Call(address []byte, data []byte) (*ctypes.ResponseCall, error) {
+func (c *ClientJSON) FlushMempool() (*ctypes.ResponseFlushMempool, error) {
+	request := rpctypes.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  reverseFuncMap["FlushMempool"],
+		Params:  []interface{}{},
+		Id:      0,
+	}
+	body, err := c.RequestResponse(request)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *ctypes.ResponseFlushMempool `json:"result"`
+		Error   string                       `json:"error"`
+		Id      string                       `json:"id"`
+		JSONRPC string                       `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientJSON) GenPrivAccount() (*acm.PrivAccount, error) {
+	request := rpctypes.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  reverseFuncMap["GenPrivAccount"],
+		Params:  []interface{}{},
+		Id:      0,
+	}
+	body, err := c.RequestResponse(request)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *acm.PrivAccount `json:"result"`
+		Error   string           `json:"error"`
+		Id      string           `json:"id"`
+		JSONRPC string           `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientJSON) Genesis() (*sm.GenesisDoc, error) {
+	request := rpctypes.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  reverseFuncMap["Genesis"],
+		Params:  []interface{}{},
+		Id:      0,
+	}
+	body, err := c.RequestResponse(request)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *sm.GenesisDoc `json:"result"`
+		Error   string         `json:"error"`
+		Id      string         `json:"id"`
+		JSONRPC string         `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientJSON) GetAccount(address []byte) (*acm.Account, error) {
+	request := rpctypes.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  reverseFuncMap["GetAccount"],
+		Params:  []interface{}{address},
+		Id:      0,
+	}
+	body, err := c.RequestResponse(request)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *acm.Account `json:"result"`
+		Error   string       `json:"error"`
+		Id      string       `json:"id"`
+		JSONRPC string       `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientJSON) GetAccountProof(address []byte) (*ctypes.ResponseGetAccountProof, error) {
+	request := rpctypes.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  reverseFuncMap["GetAccountProof"],
+		Params:  []interface{}{address},
+		Id:      0,
+	}
+	body, err := c.RequestResponse(request)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *ctypes.ResponseGetAccountProof `json:"result"`
+		Error   string                          `json:"error"`
+		Id      string                          `json:"id"`
+		JSONRPC string                          `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientJSON) GetBlock(height uint) (*ctypes.ResponseGetBlock, error) {
+	request := rpctypes.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  reverseFuncMap["GetBlock"],
+		Params:  []interface{}{height},
+		Id:      0,
+	}
+	body, err := c.RequestResponse(request)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *ctypes.ResponseGetBlock `json:"result"`
+		Error   string                   `json:"error"`
+		Id      string                   `json:"id"`
+		JSONRPC string                   `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientJSON) GetName(name string) (*types.NameRegEntry, error) {
+	request := rpctypes.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  reverseFuncMap["GetName"],
+		Params:  []interface{}{name},
+		Id:      0,
+	}
+	body, err := c.RequestResponse(request)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *types.NameRegEntry `json:"result"`
+		Error   string              `json:"error"`
+		Id      string              `json:"id"`
+		JSONRPC string              `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientJSON) GetStorage(address []byte, key []byte) (*ctypes.ResponseGetStorage, error) {
+	request := rpctypes.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  reverseFuncMap["GetStorage"],
+		Params:  []interface{}{address, key},
+		Id:      0,
+	}
+	body, err := c.RequestResponse(request)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *ctypes.ResponseGetStorage `json:"result"`
+		Error   string                     `json:"error"`
+		Id      string                     `json:"id"`
+		JSONRPC string                     `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientJSON) GetStorageProof(address []byte, key []byte) (*ctypes.ResponseGetStorageProof, error) {
 	request := rpctypes.RPCRequest{
 		JSONRPC: "2.0",
-		Method:  reverseFuncMap["Call"],
-		Params:  []interface{}{address, data},
+		Method:  reverseFuncMap["GetStorageProof"],
+		Params:  []interface{}{address, key},
 		Id:      0,
 	}
 	body, err := c.RequestResponse(request)
@@ -673,10 +1759,10 @@ func (c *ClientJSON) Call(address []byte, data []byte) (*ctypes.ResponseCall, er
 		return nil, err
 	}
 	var response struct {
-		Result  *ctypes.ResponseCall `json:"result"`
-		Error   string               `json:"error"`
-		Id      string               `json:"id"`
-		JSONRPC string               `json:"jsonrpc"`
+		Result  *ctypes.ResponseGetStorageProof `json:"result"`
+		Error   string                          `json:"error"`
+		Id      string                          `json:"id"`
+		JSONRPC string                          `json:"jsonrpc"`
 	}
 	binary.ReadJSON(&response, body, &err)
 	if err != nil {
@@ -688,11 +1774,11 @@ func (c *ClientJSON) Call(address []byte, data []byte) (*ctypes.ResponseCall, er
 	return response.Result, nil
 }
 
-func (c *ClientJSON) CallCode(code []byte, data []byte) (*ctypes.ResponseCall, error) {
+func (c *ClientJSON) GetTx(txId []byte) (*bc.TxIndexEntry, error) {
 	request := rpctypes.RPCRequest{
 		JSONRPC: "2.0",
-		Method:  reverseFuncMap["CallCode"],
-		Params:  []interface{}{code, data},
+		Method:  reverseFuncMap["GetTx"],
+		Params:  []interface{}{txId},
 		Id:      0,
 	}
 	body, err := c.RequestResponse(request)
@@ -700,10 +1786,10 @@ func (c *ClientJSON) CallCode(code []byte, data []byte) (*ctypes.ResponseCall, e
 		return nil, err
 	}
 	var response struct {
-		Result  *ctypes.ResponseCall `json:"result"`
-		Error   string               `json:"error"`
-		Id      string               `json:"id"`
-		JSONRPC string               `json:"jsonrpc"`
+		Result  *bc.TxIndexEntry `json:"result"`
+		Error   string           `json:"error"`
+		Id      string           `json:"id"`
+		JSONRPC string           `json:"jsonrpc"`
 	}
 	binary.ReadJSON(&response, body, &err)
 	if err != nil {
@@ -715,11 +1801,11 @@ func (c *ClientJSON) CallCode(code []byte, data []byte) (*ctypes.ResponseCall, e
 	return response.Result, nil
 }
 
-func (c *ClientJSON) DumpConsensusState() (*ctypes.ResponseDumpConsensusState, error) {
+func (c *ClientJSON) ImportPrecommits(precommits []*cm.OfflinePrecommit) (*ctypes.ResponseImportPrecommits, error) {
 	request := rpctypes.RPCRequest{
 		JSONRPC: "2.0",
-		Method:  reverseFuncMap["DumpConsensusState"],
-		Params:  []interface{}{},
+		Method:  reverseFuncMap["ImportPrecommits"],
+		Params:  []interface{}{precommits},
 		Id:      0,
 	}
 	body, err := c.RequestResponse(request)
@@ -727,10 +1813,10 @@ func (c *ClientJSON) DumpConsensusState() (*ctypes.ResponseDumpConsensusState, e
 		return nil, err
 	}
 	var response struct {
-		Result  *ctypes.ResponseDumpConsensusState `json:"result"`
-		Error   string                             `json:"error"`
-		Id      string                             `json:"id"`
-		JSONRPC string                             `json:"jsonrpc"`
+		Result  *ctypes.ResponseImportPrecommits `json:"result"`
+		Error   string                           `json:"error"`
+		Id      string                           `json:"id"`
+		JSONRPC string                           `json:"jsonrpc"`
 	}
 	binary.ReadJSON(&response, body, &err)
 	if err != nil {
@@ -742,11 +1828,11 @@ func (c *ClientJSON) DumpConsensusState() (*ctypes.ResponseDumpConsensusState, e
 	return response.Result, nil
 }
 
-func (c *ClientJSON) DumpStorage(address []byte) (*ctypes.ResponseDumpStorage, error) {
+func (c *ClientJSON) ListAccounts() (*ctypes.ResponseListAccounts, error) {
 	request := rpctypes.RPCRequest{
 		JSONRPC: "2.0",
-		Method:  reverseFuncMap["DumpStorage"],
-		Params:  []interface{}{address},
+		Method:  reverseFuncMap["ListAccounts"],
+		Params:  []interface{}{},
 		Id:      0,
 	}
 	body, err := c.RequestResponse(request)
@@ -754,10 +1840,10 @@ func (c *ClientJSON) DumpStorage(address []byte) (*ctypes.ResponseDumpStorage, e
 		return nil, err
 	}
 	var response struct {
-		Result  *ctypes.ResponseDumpStorage `json:"result"`
-		Error   string                      `json:"error"`
-		Id      string                      `json:"id"`
-		JSONRPC string                      `json:"jsonrpc"`
+		Result  *ctypes.ResponseListAccounts `json:"result"`
+		Error   string                       `json:"error"`
+		Id      string                       `json:"id"`
+		JSONRPC string                       `json:"jsonrpc"`
 	}
 	binary.ReadJSON(&response, body, &err)
 	if err != nil {
@@ -769,10 +1855,10 @@ func (c *ClientJSON) DumpStorage(address []byte) (*ctypes.ResponseDumpStorage, e
 	return response.Result, nil
 }
 
-func (c *ClientJSON) GenPrivAccount() (*acm.PrivAccount, error) {
+func (c *ClientJSON) ListNames() (*ctypes.ResponseListNames, error) {
 	request := rpctypes.RPCRequest{
 		JSONRPC: "2.0",
-		Method:  reverseFuncMap["GenPrivAccount"],
+		Method:  reverseFuncMap["ListNames"],
 		Params:  []interface{}{},
 		Id:      0,
 	}
@@ -781,10 +1867,10 @@ func (c *ClientJSON) GenPrivAccount() (*acm.PrivAccount, error) {
 		return nil, err
 	}
 	var response struct {
-		Result  *acm.PrivAccount `json:"result"`
-		Error   string           `json:"error"`
-		Id      string           `json:"id"`
-		JSONRPC string           `json:"jsonrpc"`
+		Result  *ctypes.ResponseListNames `json:"result"`
+		Error   string                    `json:"error"`
+		Id      string                    `json:"id"`
+		JSONRPC string                    `json:"jsonrpc"`
 	}
 	binary.ReadJSON(&response, body, &err)
 	if err != nil {
@@ -796,10 +1882,37 @@ func (c *ClientJSON) GenPrivAccount() (*acm.PrivAccount, error) {
 	return response.Result, nil
 }
 
-func (c *ClientJSON) Genesis() (*sm.GenesisDoc, error) {
+func (c *ClientJSON) ListNamesByOwner(owner []byte, offset int, limit int) (*ctypes.ResponseListNamesByOwner, error) {
 	request := rpctypes.RPCRequest{
 		JSONRPC: "2.0",
-		Method:  reverseFuncMap["Genesis"],
+		Method:  reverseFuncMap["ListNamesByOwner"],
+		Params:  []interface{}{owner, offset, limit},
+		Id:      0,
+	}
+	body, err := c.RequestResponse(request)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *ctypes.ResponseListNamesByOwner `json:"result"`
+		Error   string                           `json:"error"`
+		Id      string                           `json:"id"`
+		JSONRPC string                           `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientJSON) ListUnconfirmedTxs() ([]types.Tx, error) {
+	request := rpctypes.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  reverseFuncMap["ListUnconfirmedTxs"],
 		Params:  []interface{}{},
 		Id:      0,
 	}
@@ -808,10 +1921,10 @@ func (c *ClientJSON) Genesis() (*sm.GenesisDoc, error) {
 		return nil, err
 	}
 	var response struct {
-		Result  *sm.GenesisDoc `json:"result"`
-		Error   string         `json:"error"`
-		Id      string         `json:"id"`
-		JSONRPC string         `json:"jsonrpc"`
+		Result  []types.Tx `json:"result"`
+		Error   string     `json:"error"`
+		Id      string     `json:"id"`
+		JSONRPC string     `json:"jsonrpc"`
 	}
 	binary.ReadJSON(&response, body, &err)
 	if err != nil {
@@ -823,11 +1936,11 @@ func (c *ClientJSON) Genesis() (*sm.GenesisDoc, error) {
 	return response.Result, nil
 }
 
-func (c *ClientJSON) GetAccount(address []byte) (*acm.Account, error) {
+func (c *ClientJSON) ListValidators() (*ctypes.ResponseListValidators, error) {
 	request := rpctypes.RPCRequest{
 		JSONRPC: "2.0",
-		Method:  reverseFuncMap["GetAccount"],
-		Params:  []interface{}{address},
+		Method:  reverseFuncMap["ListValidators"],
+		Params:  []interface{}{},
 		Id:      0,
 	}
 	body, err := c.RequestResponse(request)
@@ -835,10 +1948,10 @@ func (c *ClientJSON) GetAccount(address []byte) (*acm.Account, error) {
 		return nil, err
 	}
 	var response struct {
-		Result  *acm.Account `json:"result"`
-		Error   string       `json:"error"`
-		Id      string       `json:"id"`
-		JSONRPC string       `json:"jsonrpc"`
+		Result  *ctypes.ResponseListValidators `json:"result"`
+		Error   string                         `json:"error"`
+		Id      string                         `json:"id"`
+		JSONRPC string                         `json:"jsonrpc"`
 	}
 	binary.ReadJSON(&response, body, &err)
 	if err != nil {
@@ -850,11 +1963,11 @@ func (c *ClientJSON) GetAccount(address []byte) (*acm.Account, error) {
 	return response.Result, nil
 }
 
-func (c *ClientJSON) GetBlock(height uint) (*ctypes.ResponseGetBlock, error) {
+func (c *ClientJSON) LogLevels() (*ctypes.ResponseLogLevels, error) {
 	request := rpctypes.RPCRequest{
 		JSONRPC: "2.0",
-		Method:  reverseFuncMap["GetBlock"],
-		Params:  []interface{}{height},
+		Method:  reverseFuncMap["LogLevels"],
+		Params:  []interface{}{},
 		Id:      0,
 	}
 	body, err := c.RequestResponse(request)
@@ -862,10 +1975,10 @@ func (c *ClientJSON) GetBlock(height uint) (*ctypes.ResponseGetBlock, error) {
 		return nil, err
 	}
 	var response struct {
-		Result  *ctypes.ResponseGetBlock `json:"result"`
-		Error   string                   `json:"error"`
-		Id      string                   `json:"id"`
-		JSONRPC string                   `json:"jsonrpc"`
+		Result  *ctypes.ResponseLogLevels `json:"result"`
+		Error   string                    `json:"error"`
+		Id      string                    `json:"id"`
+		JSONRPC string                    `json:"jsonrpc"`
 	}
 	binary.ReadJSON(&response, body, &err)
 	if err != nil {
@@ -877,11 +1990,11 @@ func (c *ClientJSON) GetBlock(height uint) (*ctypes.ResponseGetBlock, error) {
 	return response.Result, nil
 }
 
-func (c *ClientJSON) GetName(name string) (*types.NameRegEntry, error) {
+func (c *ClientJSON) MempoolAudit(txId []byte) ([]*mempl.AuditEntry, error) {
 	request := rpctypes.RPCRequest{
 		JSONRPC: "2.0",
-		Method:  reverseFuncMap["GetName"],
-		Params:  []interface{}{name},
+		Method:  reverseFuncMap["MempoolAudit"],
+		Params:  []interface{}{txId},
 		Id:      0,
 	}
 	body, err := c.RequestResponse(request)
@@ -889,7 +2002,7 @@ func (c *ClientJSON) GetName(name string) (*types.NameRegEntry, error) {
 		return nil, err
 	}
 	var response struct {
-		Result  *types.NameRegEntry `json:"result"`
+		Result  []*mempl.AuditEntry `json:"result"`
 		Error   string              `json:"error"`
 		Id      string              `json:"id"`
 		JSONRPC string              `json:"jsonrpc"`
@@ -904,11 +2017,11 @@ func (c *ClientJSON) GetName(name string) (*types.NameRegEntry, error) {
 	return response.Result, nil
 }
 
-func (c *ClientJSON) GetStorage(address []byte, key []byte) (*ctypes.ResponseGetStorage, error) {
+func (c *ClientJSON) NameExpiration(name string) (*ctypes.ResponseNameExpiration, error) {
 	request := rpctypes.RPCRequest{
 		JSONRPC: "2.0",
-		Method:  reverseFuncMap["GetStorage"],
-		Params:  []interface{}{address, key},
+		Method:  reverseFuncMap["NameExpiration"],
+		Params:  []interface{}{name},
 		Id:      0,
 	}
 	body, err := c.RequestResponse(request)
@@ -916,10 +2029,10 @@ func (c *ClientJSON) GetStorage(address []byte, key []byte) (*ctypes.ResponseGet
 		return nil, err
 	}
 	var response struct {
-		Result  *ctypes.ResponseGetStorage `json:"result"`
-		Error   string                     `json:"error"`
-		Id      string                     `json:"id"`
-		JSONRPC string                     `json:"jsonrpc"`
+		Result  *ctypes.ResponseNameExpiration `json:"result"`
+		Error   string                         `json:"error"`
+		Id      string                         `json:"id"`
+		JSONRPC string                         `json:"jsonrpc"`
 	}
 	binary.ReadJSON(&response, body, &err)
 	if err != nil {
@@ -931,10 +2044,10 @@ func (c *ClientJSON) GetStorage(address []byte, key []byte) (*ctypes.ResponseGet
 	return response.Result, nil
 }
 
-func (c *ClientJSON) ListAccounts() (*ctypes.ResponseListAccounts, error) {
+func (c *ClientJSON) NetInfo() (*ctypes.ResponseNetInfo, error) {
 	request := rpctypes.RPCRequest{
 		JSONRPC: "2.0",
-		Method:  reverseFuncMap["ListAccounts"],
+		Method:  reverseFuncMap["NetInfo"],
 		Params:  []interface{}{},
 		Id:      0,
 	}
@@ -943,10 +2056,10 @@ func (c *ClientJSON) ListAccounts() (*ctypes.ResponseListAccounts, error) {
 		return nil, err
 	}
 	var response struct {
-		Result  *ctypes.ResponseListAccounts `json:"result"`
-		Error   string                       `json:"error"`
-		Id      string                       `json:"id"`
-		JSONRPC string                       `json:"jsonrpc"`
+		Result  *ctypes.ResponseNetInfo `json:"result"`
+		Error   string                  `json:"error"`
+		Id      string                  `json:"id"`
+		JSONRPC string                  `json:"jsonrpc"`
 	}
 	binary.ReadJSON(&response, body, &err)
 	if err != nil {
@@ -958,11 +2071,11 @@ func (c *ClientJSON) ListAccounts() (*ctypes.ResponseListAccounts, error) {
 	return response.Result, nil
 }
 
-func (c *ClientJSON) ListNames() (*ctypes.ResponseListNames, error) {
+func (c *ClientJSON) OutboxAck(offset int) (*ctypes.ResponseOutboxAck, error) {
 	request := rpctypes.RPCRequest{
 		JSONRPC: "2.0",
-		Method:  reverseFuncMap["ListNames"],
-		Params:  []interface{}{},
+		Method:  reverseFuncMap["OutboxAck"],
+		Params:  []interface{}{offset},
 		Id:      0,
 	}
 	body, err := c.RequestResponse(request)
@@ -970,7 +2083,7 @@ func (c *ClientJSON) ListNames() (*ctypes.ResponseListNames, error) {
 		return nil, err
 	}
 	var response struct {
-		Result  *ctypes.ResponseListNames `json:"result"`
+		Result  *ctypes.ResponseOutboxAck `json:"result"`
 		Error   string                    `json:"error"`
 		Id      string                    `json:"id"`
 		JSONRPC string                    `json:"jsonrpc"`
@@ -985,11 +2098,11 @@ func (c *ClientJSON) ListNames() (*ctypes.ResponseListNames, error) {
 	return response.Result, nil
 }
 
-func (c *ClientJSON) ListUnconfirmedTxs() ([]types.Tx, error) {
+func (c *ClientJSON) OutboxEvents(limit int) (*ctypes.ResponseOutboxEvents, error) {
 	request := rpctypes.RPCRequest{
 		JSONRPC: "2.0",
-		Method:  reverseFuncMap["ListUnconfirmedTxs"],
-		Params:  []interface{}{},
+		Method:  reverseFuncMap["OutboxEvents"],
+		Params:  []interface{}{limit},
 		Id:      0,
 	}
 	body, err := c.RequestResponse(request)
@@ -997,10 +2110,10 @@ func (c *ClientJSON) ListUnconfirmedTxs() ([]types.Tx, error) {
 		return nil, err
 	}
 	var response struct {
-		Result  []types.Tx `json:"result"`
-		Error   string     `json:"error"`
-		Id      string     `json:"id"`
-		JSONRPC string     `json:"jsonrpc"`
+		Result  *ctypes.ResponseOutboxEvents `json:"result"`
+		Error   string                       `json:"error"`
+		Id      string                       `json:"id"`
+		JSONRPC string                       `json:"jsonrpc"`
 	}
 	binary.ReadJSON(&response, body, &err)
 	if err != nil {
@@ -1012,10 +2125,10 @@ func (c *ClientJSON) ListUnconfirmedTxs() ([]types.Tx, error) {
 	return response.Result, nil
 }
 
-func (c *ClientJSON) ListValidators() (*ctypes.ResponseListValidators, error) {
+func (c *ClientJSON) PartSetGCStats() (*cm.PartSetGCStats, error) {
 	request := rpctypes.RPCRequest{
 		JSONRPC: "2.0",
-		Method:  reverseFuncMap["ListValidators"],
+		Method:  reverseFuncMap["PartSetGCStats"],
 		Params:  []interface{}{},
 		Id:      0,
 	}
@@ -1024,10 +2137,10 @@ func (c *ClientJSON) ListValidators() (*ctypes.ResponseListValidators, error) {
 		return nil, err
 	}
 	var response struct {
-		Result  *ctypes.ResponseListValidators `json:"result"`
-		Error   string                         `json:"error"`
-		Id      string                         `json:"id"`
-		JSONRPC string                         `json:"jsonrpc"`
+		Result  *cm.PartSetGCStats `json:"result"`
+		Error   string             `json:"error"`
+		Id      string             `json:"id"`
+		JSONRPC string             `json:"jsonrpc"`
 	}
 	binary.ReadJSON(&response, body, &err)
 	if err != nil {
@@ -1039,10 +2152,37 @@ func (c *ClientJSON) ListValidators() (*ctypes.ResponseListValidators, error) {
 	return response.Result, nil
 }
 
-func (c *ClientJSON) NetInfo() (*ctypes.ResponseNetInfo, error) {
+func (c *ClientJSON) Prove(path string, height int) (*sm.Proof, error) {
 	request := rpctypes.RPCRequest{
 		JSONRPC: "2.0",
-		Method:  reverseFuncMap["NetInfo"],
+		Method:  reverseFuncMap["Prove"],
+		Params:  []interface{}{path, height},
+		Id:      0,
+	}
+	body, err := c.RequestResponse(request)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *sm.Proof `json:"result"`
+		Error   string    `json:"error"`
+		Id      string    `json:"id"`
+		JSONRPC string    `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientJSON) Rollback() (*ctypes.ResponseRollback, error) {
+	request := rpctypes.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  reverseFuncMap["Rollback"],
 		Params:  []interface{}{},
 		Id:      0,
 	}
@@ -1051,10 +2191,37 @@ func (c *ClientJSON) NetInfo() (*ctypes.ResponseNetInfo, error) {
 		return nil, err
 	}
 	var response struct {
-		Result  *ctypes.ResponseNetInfo `json:"result"`
-		Error   string                  `json:"error"`
-		Id      string                  `json:"id"`
-		JSONRPC string                  `json:"jsonrpc"`
+		Result  *ctypes.ResponseRollback `json:"result"`
+		Error   string                   `json:"error"`
+		Id      string                   `json:"id"`
+		JSONRPC string                   `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
+func (c *ClientJSON) SetLogLevel(module string, level string) (*ctypes.ResponseLogLevels, error) {
+	request := rpctypes.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  reverseFuncMap["SetLogLevel"],
+		Params:  []interface{}{module, level},
+		Id:      0,
+	}
+	body, err := c.RequestResponse(request)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *ctypes.ResponseLogLevels `json:"result"`
+		Error   string                    `json:"error"`
+		Id      string                    `json:"id"`
+		JSONRPC string                    `json:"jsonrpc"`
 	}
 	binary.ReadJSON(&response, body, &err)
 	if err != nil {
@@ -1093,6 +2260,33 @@ func (c *ClientJSON) SignTx(tx types.Tx, privAccounts []*account.PrivAccount) (t
 	return response.Result, nil
 }
 
+func (c *ClientJSON) StateDiff(from *sm.StateRoots) (*ctypes.ResponseStateDiff, error) {
+	request := rpctypes.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  reverseFuncMap["StateDiff"],
+		Params:  []interface{}{from},
+		Id:      0,
+	}
+	body, err := c.RequestResponse(request)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *ctypes.ResponseStateDiff `json:"result"`
+		Error   string                    `json:"error"`
+		Id      string                    `json:"id"`
+		JSONRPC string                    `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
+
 func (c *ClientJSON) Status() (*ctypes.ResponseStatus, error) {
 	request := rpctypes.RPCRequest{
 		JSONRPC: "2.0",
@@ -1119,3 +2313,30 @@ func (c *ClientJSON) Status() (*ctypes.ResponseStatus, error) {
 	}
 	return response.Result, nil
 }
+
+func (c *ClientJSON) SyncProgress() (*bc.SyncProgress, error) {
+	request := rpctypes.RPCRequest{
+		JSONRPC: "2.0",
+		Method:  reverseFuncMap["SyncProgress"],
+		Params:  []interface{}{},
+		Id:      0,
+	}
+	body, err := c.RequestResponse(request)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result  *bc.SyncProgress `json:"result"`
+		Error   string           `json:"error"`
+		Id      string           `json:"id"`
+		JSONRPC string           `json:"jsonrpc"`
+	}
+	binary.ReadJSON(&response, body, &err)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf(response.Error)
+	}
+	return response.Result, nil
+}
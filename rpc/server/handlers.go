@@ -28,6 +28,15 @@ func RegisterRPCFuncs(mux *http.ServeMux, funcMap map[string]*RPCFunc) {
 	mux.HandleFunc("/", makeJSONRPCHandler(funcMap))
 }
 
+// Registers f, which writes its response to the io.Writer passed as its
+// first argument, e.g. to stream newline-delimited JSON, at "/"+name.
+// The other arguments are named by args and parsed like an RPCFunc's
+// HTTP params. f must return an error. Stream functions aren't
+// available over JSONRPC.
+func RegisterStreamFunc(mux *http.ServeMux, name string, f interface{}, args []string) {
+	mux.HandleFunc("/"+name, makeStreamHandler(NewRPCFunc(f, args)))
+}
+
 func RegisterEventsHandler(mux *http.ServeMux, evsw *events.EventSwitch) {
 	// websocket endpoint
 	wm := NewWebsocketManager(evsw)
@@ -43,6 +52,7 @@ type RPCFunc struct {
 	args     []reflect.Type // type of each function arg
 	returns  []reflect.Type // type of each return arg
 	argNames []string       // name of each argument
+	write    bool           // changes node or chain state
 }
 
 // wraps a function for quicker introspection
@@ -55,6 +65,18 @@ func NewRPCFunc(f interface{}, args []string) *RPCFunc {
 	}
 }
 
+// Like NewRPCFunc, for functions that change node or chain state,
+// e.g. to require authentication for them.
+func NewWriteRPCFunc(f interface{}, args []string) *RPCFunc {
+	rpcFunc := NewRPCFunc(f, args)
+	rpcFunc.write = true
+	return rpcFunc
+}
+
+func (rpcFunc *RPCFunc) IsWrite() bool {
+	return rpcFunc.write
+}
+
 // return a function's argument types
 func funcArgTypes(f interface{}) []reflect.Type {
 	t := reflect.TypeOf(f)
@@ -176,6 +198,48 @@ func makeHTTPHandler(rpcFunc *RPCFunc) func(http.ResponseWriter, *http.Request)
 	}
 }
 
+// The response is sent with chunked encoding, flushed as it's written.
+// An error is sent as an RPCResponse if nothing was written yet, and
+// otherwise ends the response early.
+func makeStreamHandler(rpcFunc *RPCFunc) func(http.ResponseWriter, *http.Request) {
+	params := &RPCFunc{args: rpcFunc.args[1:], argNames: rpcFunc.argNames}
+	return func(w http.ResponseWriter, r *http.Request) {
+		args, err := httpParamsToArgs(params, r)
+		if err != nil {
+			WriteRPCResponse(w, NewRPCResponse(nil, err.Error()))
+			return
+		}
+		fw := &flushWriter{w: w}
+		returns := rpcFunc.f.Call(append([]reflect.Value{reflect.ValueOf(fw)}, args...))
+		err, _ = returns[0].Interface().(error)
+		if err == nil {
+			return
+		}
+		if fw.written {
+			log.Warn("Error streaming response", "method", r.URL.Path, "error", err)
+		} else {
+			WriteRPCResponse(w, NewRPCResponse(nil, err.Error()))
+		}
+	}
+}
+
+type flushWriter struct {
+	w       http.ResponseWriter
+	written bool
+}
+
+func (fw *flushWriter) Write(p []byte) (int, error) {
+	if !fw.written {
+		fw.w.Header().Set("Content-Type", "application/x-ndjson")
+		fw.written = true
+	}
+	n, err := fw.w.Write(p)
+	if flusher, ok := fw.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return n, err
+}
+
 // Covert an http query to a list of properly typed values.
 // To be properly decoded the arg must be a concrete type from tendermint (if its an interface).
 func httpParamsToArgs(rpcFunc *RPCFunc, r *http.Request) ([]reflect.Value, error) {
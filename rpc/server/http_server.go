@@ -4,6 +4,7 @@ package rpcserver
 import (
 	"bufio"
 	"bytes"
+	"crypto/tls"
 	"fmt"
 	"net"
 	"net/http"
@@ -22,6 +23,23 @@ func StartHTTPServer(listenAddr string, handler http.Handler) (net.Listener, err
 	if err != nil {
 		return nil, fmt.Errorf("Failed to listen to %v", listenAddr)
 	}
+	serveHTTP(listener, handler)
+	return listener, nil
+}
+
+// Like StartHTTPServer, but serves HTTPS. See NewTLSConfig.
+func StartHTTPSServer(listenAddr string, handler http.Handler, tlsConfig *tls.Config) (net.Listener, error) {
+	log.Info(Fmt("Starting RPC HTTPS server on %v", listenAddr))
+	listener, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to listen to %v", listenAddr)
+	}
+	listener = tls.NewListener(listener, tlsConfig)
+	serveHTTP(listener, handler)
+	return listener, nil
+}
+
+func serveHTTP(listener net.Listener, handler http.Handler) {
 	go func() {
 		res := http.Serve(
 			listener,
@@ -29,7 +47,6 @@ func StartHTTPServer(listenAddr string, handler http.Handler) (net.Listener, err
 		)
 		log.Crit("RPC HTTP server stopped", "result", res)
 	}()
-	return listener, nil
 }
 
 func WriteRPCResponse(w http.ResponseWriter, res RPCResponse) {
@@ -56,10 +73,7 @@ func RecoverAndLogHandler(handler http.Handler) http.Handler {
 		begin := time.Now()
 
 		// Common headers
-		origin := r.Header.Get("Origin")
-		rww.Header().Set("Access-Control-Allow-Origin", origin)
-		rww.Header().Set("Access-Control-Allow-Credentials", "true")
-		rww.Header().Set("Access-Control-Expose-Headers", "X-Server-Time")
+		// NOTE: CORS headers are set by NewMiddleware.
 		rww.Header().Set("X-Server-Time", fmt.Sprintf("%v", begin.Unix()))
 
 		defer func() {
@@ -111,6 +125,13 @@ func (w *ResponseWriterWrapper) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	return w.ResponseWriter.(http.Hijacker).Hijack()
 }
 
+// implements http.Flusher, for streamed responses
+func (w *ResponseWriterWrapper) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
 // Stick it as a deferred statement in gouroutines to prevent the program from crashing.
 func Recover(daemonName string) {
 	if e := recover(); e != nil {
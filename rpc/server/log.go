@@ -1,7 +1,7 @@
 package rpcserver
 
 import (
-	"github.com/tendermint/tendermint/Godeps/_workspace/src/github.com/tendermint/log15"
+	"github.com/tendermint/tendermint/logger"
 )
 
-var log = log15.New("module", "rpcserver")
+var log = logger.New("module", "rpcserver")
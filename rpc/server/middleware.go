@@ -0,0 +1,241 @@
+package rpcserver
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tendermint/tendermint/binary"
+	. "github.com/tendermint/tendermint/rpc/types"
+)
+
+const (
+	rateLimiterGCInterval = 1 * time.Minute
+)
+
+type MiddlewareConfig struct {
+	RateLimit    float64  // Max requests per second per IP. 0 disables rate limiting.
+	RateBurst    int      // Max requests an IP may burst above RateLimit.
+	MaxBodyBytes int64    // Max request body size. 0 disables the limit.
+	CORSOrigins  []string // Allowed CORS origins. "*" allows any origin.
+
+	// If AuthToken or AuthUser is set, protected methods require
+	// "Authorization: Bearer <AuthToken>" or basic auth with AuthUser/AuthPassword.
+	AuthToken    string
+	AuthUser     string
+	AuthPassword string
+	IsProtected  func(method string) bool
+
+	// If AdminFingerprints is set, admin methods additionally require a TLS
+	// client certificate whose SHA-256 fingerprint is listed.
+	AdminFingerprints []string
+	IsAdmin           func(method string) bool
+}
+
+func (conf MiddlewareConfig) authEnabled() bool {
+	return conf.AuthToken != "" || conf.AuthUser != ""
+}
+
+func (conf MiddlewareConfig) pinningEnabled() bool {
+	return len(conf.AdminFingerprints) > 0
+}
+
+// Wraps an HTTP handler with CORS, per-IP rate limiting, a max body size,
+// authentication for protected methods and client certificate pinning for
+// admin methods.
+func NewMiddleware(handler http.Handler, conf MiddlewareConfig) http.Handler {
+	var limiter *rateLimiter
+	if conf.RateLimit > 0 {
+		limiter = newRateLimiter(conf.RateLimit, conf.RateBurst)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		setCORSHeaders(w, r, conf.CORSOrigins)
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if limiter != nil && !limiter.Allow(remoteIP(r), time.Now()) {
+			writeMiddlewareError(w, http.StatusTooManyRequests, "Rate limit exceeded")
+			return
+		}
+
+		if conf.MaxBodyBytes > 0 {
+			if r.ContentLength > conf.MaxBodyBytes {
+				writeMiddlewareError(w, http.StatusRequestEntityTooLarge, "Request body too large")
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, conf.MaxBodyBytes)
+		}
+
+		checkProtected := conf.authEnabled() && conf.IsProtected != nil
+		checkAdmin := conf.pinningEnabled() && conf.IsAdmin != nil
+		if checkProtected || checkAdmin {
+			method, err := requestMethod(r)
+			if _, ok := err.(*http.MaxBytesError); ok {
+				writeMiddlewareError(w, http.StatusRequestEntityTooLarge, "Request body too large")
+				return
+			} else if err != nil {
+				writeMiddlewareError(w, http.StatusBadRequest, fmt.Sprintf("Error reading request body: %v", err))
+				return
+			}
+			if checkAdmin && conf.IsAdmin(method) && !checkClientCert(r, conf.AdminFingerprints) {
+				writeMiddlewareError(w, http.StatusForbidden, "Client certificate required")
+				return
+			}
+			if checkProtected && conf.IsProtected(method) && !checkAuth(r, conf) {
+				if conf.AuthUser != "" {
+					w.Header().Set("WWW-Authenticate", `Basic realm="tendermint"`)
+				}
+				writeMiddlewareError(w, http.StatusUnauthorized, "Unauthorized")
+				return
+			}
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+func setCORSHeaders(w http.ResponseWriter, r *http.Request, origins []string) {
+	origin := r.Header.Get("Origin")
+	if origin == "" || !originAllowed(origin, origins) {
+		return
+	}
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	w.Header().Set("Access-Control-Allow-Credentials", "true")
+	w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+	w.Header().Set("Access-Control-Expose-Headers", "X-Server-Time")
+}
+
+func originAllowed(origin string, origins []string) bool {
+	for _, allowed := range origins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the RPC method name of the request.
+// For JSONRPC requests the body is read and then restored.
+func requestMethod(r *http.Request) (string, error) {
+	if r.URL.Path != "/" {
+		return strings.TrimPrefix(r.URL.Path, "/"), nil
+	}
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return "", err
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(b))
+	var request RPCRequest
+	json.Unmarshal(b, &request) // malformed requests fail in the handler.
+	return request.Method, nil
+}
+
+func checkAuth(r *http.Request, conf MiddlewareConfig) bool {
+	if conf.AuthToken != "" {
+		auth := r.Header.Get("Authorization")
+		if strings.HasPrefix(auth, "Bearer ") &&
+			secureCompare(strings.TrimPrefix(auth, "Bearer "), conf.AuthToken) {
+			return true
+		}
+	}
+	if conf.AuthUser != "" {
+		user, password, ok := r.BasicAuth()
+		if ok && secureCompare(user, conf.AuthUser) && secureCompare(password, conf.AuthPassword) {
+			return true
+		}
+	}
+	return false
+}
+
+func secureCompare(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func writeMiddlewareError(w http.ResponseWriter, status int, msg string) {
+	buf, n, err := new(bytes.Buffer), new(int64), new(error)
+	binary.WriteJSON(NewRPCResponse(nil, msg), buf, n, err)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
+
+//-----------------------------------------------------------------------------
+
+// Token bucket rate limiter keyed by IP.
+type rateLimiter struct {
+	mtx     sync.Mutex
+	rate    float64
+	burst   float64
+	buckets map[string]*tokenBucket
+	lastGC  time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+		lastGC:  time.Now(),
+	}
+}
+
+func (rl *rateLimiter) Allow(ip string, now time.Time) bool {
+	rl.mtx.Lock()
+	defer rl.mtx.Unlock()
+
+	if now.Sub(rl.lastGC) > rateLimiterGCInterval {
+		rl.gc(now)
+	}
+
+	bucket, ok := rl.buckets[ip]
+	if !ok {
+		bucket = &tokenBucket{tokens: rl.burst, last: now}
+		rl.buckets[ip] = bucket
+	}
+	bucket.tokens += now.Sub(bucket.last).Seconds() * rl.rate
+	if bucket.tokens > rl.burst {
+		bucket.tokens = rl.burst
+	}
+	bucket.last = now
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens -= 1
+	return true
+}
+
+// Forget IPs whose buckets have refilled.
+// CONTRACT: rl.mtx is held.
+func (rl *rateLimiter) gc(now time.Time) {
+	for ip, bucket := range rl.buckets {
+		if bucket.tokens+now.Sub(bucket.last).Seconds()*rl.rate >= rl.burst {
+			delete(rl.buckets, ip)
+		}
+	}
+	rl.lastGC = now
+}
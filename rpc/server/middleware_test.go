@@ -0,0 +1,112 @@
+package rpcserver
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	rl := newRateLimiter(1, 2)
+	now := time.Now()
+
+	// Burst of 2 is allowed, the third is not.
+	if !rl.Allow("a", now) || !rl.Allow("a", now) {
+		t.Errorf("Expected burst to be allowed")
+	}
+	if rl.Allow("a", now) {
+		t.Errorf("Expected request to be rate limited")
+	}
+	// Other IPs have their own bucket.
+	if !rl.Allow("b", now) {
+		t.Errorf("Expected other IP to be allowed")
+	}
+	// One token refills after a second.
+	now = now.Add(time.Second)
+	if !rl.Allow("a", now) {
+		t.Errorf("Expected request to be allowed after refill")
+	}
+	if rl.Allow("a", now) {
+		t.Errorf("Expected request to be rate limited")
+	}
+	// Refilled buckets are forgotten.
+	rl.gc(now.Add(time.Minute))
+	if len(rl.buckets) != 0 {
+		t.Errorf("Expected buckets to be collected, got %v", len(rl.buckets))
+	}
+}
+
+func TestMiddlewareAdminFingerprints(t *testing.T) {
+	pinned := &x509.Certificate{Raw: []byte("pinned")}
+	other := &x509.Certificate{Raw: []byte("other")}
+	// Pins are matched with or without colons, in either case.
+	fingerprint := strings.ToLower(CertFingerprint(pinned))
+	fingerprint = fingerprint[:2] + ":" + fingerprint[2:]
+
+	handler := NewMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), MiddlewareConfig{
+		AdminFingerprints: []string{fingerprint},
+		IsAdmin: func(method string) bool {
+			return strings.HasPrefix(method, "unsafe/")
+		},
+	})
+	request := func(path string, cert *x509.Certificate) int {
+		r, _ := http.NewRequest("GET", "http://localhost"+path, nil)
+		if cert != nil {
+			r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	if code := request("/status", nil); code != http.StatusOK {
+		t.Errorf("Expected non-admin method without cert to be allowed, got %v", code)
+	}
+	if code := request("/unsafe/gen_priv_account", nil); code != http.StatusForbidden {
+		t.Errorf("Expected admin method without cert to be forbidden, got %v", code)
+	}
+	if code := request("/unsafe/gen_priv_account", other); code != http.StatusForbidden {
+		t.Errorf("Expected admin method with unpinned cert to be forbidden, got %v", code)
+	}
+	if code := request("/unsafe/gen_priv_account", pinned); code != http.StatusOK {
+		t.Errorf("Expected admin method with pinned cert to be allowed, got %v", code)
+	}
+}
+
+type errReader struct{}
+
+func (errReader) Read(p []byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestMiddlewareBodyErrors(t *testing.T) {
+	handler := NewMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), MiddlewareConfig{
+		MaxBodyBytes: 16,
+		AuthToken:    "token",
+		IsProtected:  func(method string) bool { return true },
+	})
+	request := func(body io.Reader) int {
+		r, _ := http.NewRequest("POST", "http://localhost/", body)
+		r.ContentLength = -1 // unknown, so the limit is hit while reading
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	if code := request(strings.NewReader(strings.Repeat("x", 17))); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected a body over the limit to be too large, got %v", code)
+	}
+	if code := request(errReader{}); code != http.StatusBadRequest {
+		t.Errorf("Expected a failed read to be a bad request, got %v", code)
+	}
+}
@@ -20,6 +20,10 @@ func TestHTTPGetAccount(t *testing.T) {
 	testGetAccount(t, "HTTP")
 }
 
+func TestHTTPGetAccountProof(t *testing.T) {
+	testGetAccountProof(t, "HTTP")
+}
+
 func TestHTTPSignedTx(t *testing.T) {
 	testSignedTx(t, "HTTP")
 }
@@ -28,10 +32,38 @@ func TestHTTPBroadcastTx(t *testing.T) {
 	testBroadcastTx(t, "HTTP")
 }
 
+func TestHTTPBroadcastTxs(t *testing.T) {
+	testBroadcastTxs(t, "HTTP")
+}
+
+func TestHTTPMempoolAudit(t *testing.T) {
+	testMempoolAudit(t, "HTTP")
+}
+
+func TestHTTPFlushMempool(t *testing.T) {
+	testFlushMempool(t, "HTTP")
+}
+
+func TestHTTPOutbox(t *testing.T) {
+	testOutbox(t, "HTTP")
+}
+
 func TestHTTPGetStorage(t *testing.T) {
 	testGetStorage(t, "HTTP")
 }
 
+func TestHTTPProve(t *testing.T) {
+	testProve(t, "HTTP")
+}
+
+func TestHTTPStateDiff(t *testing.T) {
+	testStateDiff(t, "HTTP")
+}
+
+func TestHTTPStreamState(t *testing.T) {
+	testStreamState(t)
+}
+
 func TestHTTPCallCode(t *testing.T) {
 	testCallCode(t, "HTTP")
 }
@@ -59,6 +91,10 @@ func TestJSONGetAccount(t *testing.T) {
 	testGetAccount(t, "JSONRPC")
 }
 
+func TestJSONGetAccountProof(t *testing.T) {
+	testGetAccountProof(t, "JSONRPC")
+}
+
 func TestJSONSignedTx(t *testing.T) {
 	testSignedTx(t, "JSONRPC")
 }
@@ -67,10 +103,34 @@ func TestJSONBroadcastTx(t *testing.T) {
 	testBroadcastTx(t, "JSONRPC")
 }
 
+func TestJSONBroadcastTxs(t *testing.T) {
+	testBroadcastTxs(t, "JSONRPC")
+}
+
+func TestJSONMempoolAudit(t *testing.T) {
+	testMempoolAudit(t, "JSONRPC")
+}
+
+func TestJSONFlushMempool(t *testing.T) {
+	testFlushMempool(t, "JSONRPC")
+}
+
+func TestJSONOutbox(t *testing.T) {
+	testOutbox(t, "JSONRPC")
+}
+
 func TestJSONGetStorage(t *testing.T) {
 	testGetStorage(t, "JSONRPC")
 }
 
+func TestJSONProve(t *testing.T) {
+	testProve(t, "JSONRPC")
+}
+
+func TestJSONStateDiff(t *testing.T) {
+	testStateDiff(t, "JSONRPC")
+}
+
 func TestJSONCallCode(t *testing.T) {
 	testCallCode(t, "JSONRPC")
 }
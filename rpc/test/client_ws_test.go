@@ -70,6 +70,28 @@ func TestWSSend(t *testing.T) {
 	waitForEvent(t, con, eidOutput, true, func() {}, unmarshalValidateSend(amt, toAddr))
 }
 
+// broadcast a batch of txs and wait for each to be committed
+func TestWSBroadcastTxs(t *testing.T) {
+	txs := makeSendTxsSigned(t, 2, user[1].Address, 100)
+	con := newWSCon(t)
+	eids := make([]string, len(txs))
+	for i, tx := range txs {
+		eids[i] = types.EventStringTxCommitted(types.TxId(chainID, tx))
+		subscribe(t, con, eids[i])
+	}
+	defer func() {
+		for _, eid := range eids {
+			unsubscribe(t, con, eid)
+		}
+		con.Close()
+	}()
+	waitForEvent(t, con, eids[0], true, func() {
+		if _, err := clients[wsTyp].BroadcastTxs(txs); err != nil {
+			t.Fatal(err)
+		}
+	}, unmarshalValidateTxCommitted(txs[0]))
+}
+
 // ensure events are only fired once for a given transaction
 func TestWSDoubleFire(t *testing.T) {
 	con := newWSCon(t)
@@ -98,21 +98,21 @@ func makeDefaultSendTx(t *testing.T, typ string, addr []byte, amt int64) *types.
 
 func makeDefaultSendTxSigned(t *testing.T, typ string, addr []byte, amt int64) *types.SendTx {
 	tx := makeDefaultSendTx(t, typ, addr, amt)
-	tx.SignInput(chainID, 0, user[0])
+	tx.SignInput(chainID, 0, 0, user[0])
 	return tx
 }
 
 func makeDefaultCallTx(t *testing.T, typ string, addr, code []byte, amt, gasLim, fee int64) *types.CallTx {
 	nonce := getNonce(t, typ, user[0].Address)
 	tx := types.NewCallTxWithNonce(user[0].PubKey, addr, code, amt, gasLim, fee, nonce+1)
-	tx.Sign(chainID, user[0])
+	tx.Sign(chainID, 0, user[0])
 	return tx
 }
 
 func makeDefaultNameTx(t *testing.T, typ string, name, value string, amt, fee int64) *types.NameTx {
 	nonce := getNonce(t, typ, user[0].Address)
 	tx := types.NewNameTxWithNonce(user[0].PubKey, name, value, amt, fee, nonce+1)
-	tx.Sign(chainID, user[0])
+	tx.Sign(chainID, 0, user[0])
 	return tx
 }
 
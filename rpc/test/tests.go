@@ -1,11 +1,18 @@
 package rpctest
 
 import (
+	"bufio"
 	"bytes"
 	"fmt"
 	"github.com/tendermint/tendermint/account"
+	"github.com/tendermint/tendermint/binary"
 	. "github.com/tendermint/tendermint/common"
+	mempl "github.com/tendermint/tendermint/mempool"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	sm "github.com/tendermint/tendermint/state"
 	"github.com/tendermint/tendermint/types"
+	"io/ioutil"
+	"net/http"
 	"testing"
 )
 
@@ -44,6 +51,22 @@ func testGetAccount(t *testing.T, typ string) {
 	}
 }
 
+func testGetAccountProof(t *testing.T, typ string) {
+	resp, err := clients[typ].GetAccountProof(user[0].Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Compare(resp.Account.Address, user[0].Address) != 0 {
+		t.Fatalf("Failed to get correct account. Got %x, expected %x", resp.Account.Address, user[0].Address)
+	}
+	if err := sm.VerifyProof(resp.Proof, resp.Proof.Root); err != nil {
+		t.Fatal("Account proof failed to verify:", err)
+	}
+	if !bytes.Equal(resp.Proof.Value, binary.BinaryBytes(resp.Account)) {
+		t.Fatal("Expected the proof to prove the account")
+	}
+}
+
 func testSignedTx(t *testing.T, typ string) {
 	amt := int64(100)
 	toAddr := user[1].Address
@@ -60,7 +83,7 @@ func testOneSignTx(t *testing.T, typ string, addr []byte, amt int64) {
 	tx := makeDefaultSendTx(t, typ, addr, amt)
 	tx2 := signTx(t, typ, tx, user[0])
 	tx2hash := account.HashSignBytes(chainID, tx2)
-	tx.SignInput(chainID, 0, user[0])
+	tx.SignInput(chainID, 0, 0, user[0])
 	txhash := account.HashSignBytes(chainID, tx)
 	if bytes.Compare(txhash, tx2hash) != 0 {
 		t.Fatal("Got different signatures for signing via rpc vs tx_utils")
@@ -97,6 +120,121 @@ func testBroadcastTx(t *testing.T, typ string) {
 	}
 }
 
+// Signed SendTxs from user[0] that follow the txs already in the mempool.
+func makeSendTxsSigned(t *testing.T, n int, addr []byte, amt int64) []types.Tx {
+	nonce := node.MempoolReactor().Mempool.GetCache().GetAccount(user[0].Address).Sequence
+	txs := make([]types.Tx, n)
+	for i := 0; i < n; i++ {
+		tx := types.NewSendTx()
+		tx.AddInputWithNonce(user[0].PubKey, amt, nonce+1+i)
+		tx.AddOutput(addr, amt)
+		tx.SignInput(chainID, 0, 0, user[0])
+		txs[i] = tx
+	}
+	return txs
+}
+
+func testBroadcastTxs(t *testing.T, typ string) {
+	client := clients[typ]
+	txs := makeSendTxsSigned(t, 2, user[1].Address, 100)
+	txs = append(txs, txs[0]) // replayed, so rejected
+	resp, err := client.BroadcastTxs(txs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Results) != len(txs) {
+		t.Fatalf("Expected %d results, got %d", len(txs), len(resp.Results))
+	}
+	for i, result := range resp.Results[:2] {
+		if result.Error != "" || result.Receipt == nil {
+			t.Fatalf("Expected a receipt for tx %d, got error %v", i, result.Error)
+		}
+		if !bytes.Equal(result.Receipt.TxHash, types.TxId(chainID, txs[i])) {
+			t.Errorf("Unexpected tx hash for tx %d", i)
+		}
+	}
+	if resp.Results[2].Error == "" || resp.Results[2].Receipt != nil {
+		t.Errorf("Expected the replayed tx to be rejected")
+	}
+	mempoolCount += 2
+}
+
+func testMempoolAudit(t *testing.T, typ string) {
+	client := clients[typ]
+	txs := makeSendTxsSigned(t, 1, user[1].Address, 100)
+	txs = append(txs, txs[0]) // replayed, so rejected
+	if _, err := client.BroadcastTxs(txs); err != nil {
+		t.Fatal(err)
+	}
+	mempoolCount += 1
+	entries, err := client.MempoolAudit(types.TxId(chainID, txs[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The tx may be committed too, so only check the first two.
+	if len(entries) < 2 {
+		t.Fatalf("Expected at least 2 audit entries, got %d", len(entries))
+	}
+	if entries[0].Event != mempl.AuditAccepted {
+		t.Errorf("Expected the tx to be accepted, got %v", entries[0].Event)
+	}
+	if entries[1].Event != mempl.AuditRejected || entries[1].Code != mempl.AuditCodeInvalidSequence {
+		t.Errorf("Expected the replay to be rejected with %v, got %v %v",
+			mempl.AuditCodeInvalidSequence, entries[1].Event, entries[1].Code)
+	}
+}
+
+func testOutbox(t *testing.T, typ string) {
+	client := clients[typ]
+	con := newWSCon(t)
+	eid := types.EventStringNewBlock()
+	subscribe(t, con, eid)
+	defer func() {
+		unsubscribe(t, con, eid)
+		con.Close()
+	}()
+	waitForEvent(t, con, eid, true, func() {}, doNothing)
+
+	resp, err := client.OutboxEvents(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Events) == 0 {
+		t.Fatal("Expected outbox events")
+	}
+	last := resp.Events[len(resp.Events)-1]
+	if last.Offset != last.BlockMeta.Header.Height {
+		t.Fatalf("Expected offset %v to be the block height, got %v", last.Offset, last.BlockMeta.Header.Height)
+	}
+	if _, err := client.OutboxAck(last.Offset); err != nil {
+		t.Fatal(err)
+	}
+	resp, err = client.OutboxEvents(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Acked != last.Offset || (len(resp.Events) > 0 && resp.Events[0].Offset != last.Offset+1) {
+		t.Fatalf("Expected events after %v, got acked %v", last.Offset, resp.Acked)
+	}
+	if _, err := client.OutboxAck(last.Offset - 1); err == nil {
+		t.Fatal("Expected acking an earlier offset to fail")
+	}
+}
+
+func testFlushMempool(t *testing.T, typ string) {
+	client := clients[typ]
+	tx := makeDefaultSendTxSigned(t, typ, user[1].Address, 100)
+	broadcastTx(t, typ, tx)
+	// The tx may be committed first, so don't check resp.Flushed.
+	if _, err := client.FlushMempool(); err != nil {
+		t.Fatal(err)
+	}
+	mempoolCount = 0
+	if txs := node.MempoolReactor().Mempool.GetProposalTxs(); len(txs) != 0 {
+		t.Errorf("Expected an empty mempool, got %d txs", len(txs))
+	}
+}
+
 func testGetStorage(t *testing.T, typ string) {
 	con := newWSCon(t)
 	eid := types.EventStringNewBlock()
@@ -133,6 +271,146 @@ func testGetStorage(t *testing.T, typ string) {
 	}
 }
 
+func testProve(t *testing.T, typ string) {
+	client := clients[typ]
+	con := newWSCon(t)
+	eid := types.EventStringNewBlock()
+	subscribe(t, con, eid)
+	defer func() {
+		unsubscribe(t, con, eid)
+		con.Close()
+	}()
+
+	// Commit a tx to prove.
+	committedSequence := func() int {
+		resp, err := client.GetAccountProof(user[0].Address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.Account.Sequence
+	}
+	sequence := committedSequence()
+	broadcastTx(t, typ, makeDefaultSendTxSigned(t, typ, user[1].Address, 100))
+	for i := 0; committedSequence() == sequence; i++ {
+		if i == 5 {
+			t.Fatal("Expected the tx to be committed")
+		}
+		waitForEvent(t, con, eid, true, func() {}, doNothing)
+	}
+	mempoolCount = 0
+
+	proof, err := client.Prove(Fmt("accounts/%X", user[0].Address), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.GetBlock(uint(proof.Height))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.VerifyProof(proof, resp.BlockMeta.Header.StateHash); err != nil {
+		t.Fatalf("Account proof failed to verify: %v", err)
+	}
+
+	// Prove the first tx of the latest block with txs.
+	for height := proof.Height; height > 0; height-- {
+		resp, err := client.GetBlock(uint(height))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Block.NumTxs == 0 {
+			continue
+		}
+		txProof, err := client.Prove("txs/0", height)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sm.VerifyProof(txProof, resp.BlockMeta.Hash); err != nil {
+			t.Fatalf("Tx proof failed to verify: %v", err)
+		}
+		return
+	}
+	t.Fatal("Expected a block with txs")
+}
+
+func testStateDiff(t *testing.T, typ string) {
+	client := clients[typ]
+	// A diff from empty trees has the whole state.
+	resp, err := client.StateDiff(&sm.StateRoots{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Diff.State) == 0 || len(resp.Diff.Accounts) == 0 {
+		t.Fatal("Expected the state and its accounts in the diff")
+	}
+	if resp.Height > 0 && resp.Validation == nil {
+		t.Fatal("Expected the validation of the diff's block")
+	}
+	if _, err := client.StateDiff(&sm.StateRoots{Accounts: []byte("unknown")}); err == nil {
+		t.Fatal("Expected an error for unknown roots")
+	}
+}
+
+// Returns the lines of stream_state, which is served over HTTP only.
+func streamState(t *testing.T, height, offset int) []*ctypes.StateStreamItem {
+	resp, err := http.Get(Fmt("%vstream_state?height=%v&offset=%v", requestAddr, height, offset))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	items := []*ctypes.StateStreamItem{}
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var err error
+		item := binary.ReadJSON(&ctypes.StateStreamItem{}, scanner.Bytes(), &err).(*ctypes.StateStreamItem)
+		if err != nil {
+			t.Fatalf("Error reading %s: %v", scanner.Bytes(), err)
+		}
+		items = append(items, item)
+	}
+	if len(items) == 0 || !items[len(items)-1].End {
+		t.Fatalf("Expected the stream to end with an end line, got %v", items)
+	}
+	return items
+}
+
+func testStreamState(t *testing.T) {
+	items := streamState(t, 0, 0)
+	height := items[0].Height
+	accounts := []*ctypes.StateStreamItem{}
+	for _, item := range items {
+		if item.Height != height {
+			t.Fatalf("Expected all lines at height %v, got %v", height, item)
+		}
+		if item.Account != nil {
+			accounts = append(accounts, item)
+		}
+	}
+	if len(accounts) < len(user) || items[len(items)-1].Offset != len(accounts) {
+		t.Fatalf("Expected the genesis accounts and a final offset of %v, got %v", len(accounts), items[len(items)-1])
+	}
+
+	// Resume after the first account, at the same height.
+	resumed := streamState(t, height, 1)
+	if resumed[0].Height != height || resumed[0].Offset != 1 {
+		t.Fatalf("Expected to resume at offset 1 of height %v, got %v", height, resumed[0])
+	}
+	for _, item := range resumed {
+		if item.Account != nil && !bytes.Equal(item.Address, accounts[item.Offset].Address) {
+			t.Fatalf("Expected account %X at offset %v, got %X", accounts[item.Offset].Address, item.Offset, item.Address)
+		}
+	}
+
+	resp, err := http.Get(Fmt("%vstream_state?height=%v&offset=0", requestAddr, height+1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	if !bytes.Contains(body, []byte("no longer available")) {
+		t.Fatalf("Expected an error for an unavailable height, got %s", body)
+	}
+}
+
 func testCallCode(t *testing.T, typ string) {
 	client := clients[typ]
 
@@ -238,7 +516,7 @@ func testNameReg(t *testing.T, typ string) {
 	nonce := getNonce(t, typ, user[1].Address)
 	data2 := "this is not my beautiful house"
 	tx = types.NewNameTxWithNonce(user[1].PubKey, name, data2, amt, fee, nonce+1)
-	tx.Sign(chainID, user[1])
+	tx.Sign(chainID, 0, user[1])
 	_, err := client.BroadcastTx(tx)
 	if err == nil {
 		t.Fatal("Expected error on NameTx")
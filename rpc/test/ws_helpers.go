@@ -186,6 +186,35 @@ func unmarshalValidateSend(amt int64, toAddr []byte) func(string, []byte) error
 	}
 }
 
+func unmarshalValidateTxCommitted(tx types.Tx) func(string, []byte) error {
+	return func(eid string, b []byte) error {
+		var response struct {
+			Event string                    `json:"event"`
+			Data  types.EventMsgTxCommitted `json:"data"`
+			Error string                    `json:"error"`
+		}
+		var err error
+		binary.ReadJSON(&response, b, &err)
+		if err != nil {
+			return err
+		}
+		if response.Error != "" {
+			return fmt.Errorf(response.Error)
+		}
+		if eid != response.Event {
+			return fmt.Errorf("Eventid is not correct. Got %s, expected %s", response.Event, eid)
+		}
+		if !bytes.Equal(response.Data.TxId, types.TxId(chainID, tx)) {
+			return fmt.Errorf("TxIds do not match up! Got %X, expected %X", response.Data.TxId, types.TxId(chainID, tx))
+		}
+		block := node.BlockStore().LoadBlock(response.Data.Height)
+		if block == nil || response.Data.Index >= len(block.Data.Txs) {
+			return fmt.Errorf("Tx not found in block %d at index %d", response.Data.Height, response.Data.Index)
+		}
+		return nil
+	}
+}
+
 func unmarshalValidateCall(amt int64, returnCode []byte) func(string, []byte) error {
 	return func(eid string, b []byte) error {
 		// unmarshall and assert somethings
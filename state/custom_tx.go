@@ -0,0 +1,150 @@
+package state
+
+import (
+	"reflect"
+
+	"github.com/tendermint/tendermint/account"
+	. "github.com/tendermint/tendermint/common"
+	"github.com/tendermint/tendermint/events"
+	"github.com/tendermint/tendermint/types"
+)
+
+/*
+Applications can add their own Tx types without changing the types and
+state packages. A custom Tx is registered with a type byte, for the binary
+and JSON codecs, and a TxHandler that ExecTx calls for it, both when
+checking the tx in the mempool and when executing it in a block.
+
+See state/kvtx for an example.
+*/
+
+type TxHandler interface {
+	// Checks the tx without the state, e.g. its sizes and amounts.
+	ValidateBasic(tx types.Tx) error
+
+	// Checks the tx against the state and applies it through ctx.
+	// If it returns an error, nothing it did through ctx is kept.
+	Exec(ctx *TxContext, tx types.Tx) error
+
+	// Gas for the tx on top of GasTxBase, see IntrinsicGas.
+	Gas(tx types.Tx) int64
+}
+
+var txHandlers = make(map[reflect.Type]TxHandler)
+
+// Registers tx, e.g. &MyTx{}, with typeByte, which must be at least
+// types.TxTypeCustomMin. Call it from an init function.
+func RegisterTxType(typeByte byte, tx types.Tx, handler TxHandler) {
+	types.RegisterTxType(typeByte, tx)
+	txHandlers[reflect.TypeOf(tx)] = handler
+}
+
+func getTxHandler(tx types.Tx) TxHandler {
+	return txHandlers[reflect.TypeOf(tx)]
+}
+
+// The state a TxHandler sees while executing a tx. Changes are kept in
+// the context until the handler returns without an error.
+type TxContext struct {
+	ChainID   string
+	Height    int    // Of the block that the tx is in
+	SignBytes []byte // To verify the tx's signatures against
+	RunCall   bool   // False when checking the tx, e.g. in the mempool
+
+	cache    *BlockCache
+	accounts map[string]*account.Account
+	storages map[Tuple256]Word256
+	events   []txEvent
+}
+
+type txEvent struct {
+	event string
+	data  interface{}
+}
+
+// Returns nil if there's no account at addr.
+func (ctx *TxContext) GetAccount(addr []byte) *account.Account {
+	if acc, ok := ctx.accounts[string(addr)]; ok {
+		return acc
+	}
+	acc := ctx.cache.GetAccount(addr)
+	if acc != nil {
+		acc = acc.Copy()
+	}
+	return acc
+}
+
+func (ctx *TxContext) UpdateAccount(acc *account.Account) {
+	ctx.accounts[string(acc.Address)] = acc
+}
+
+func (ctx *TxContext) GetStorage(addr []byte, key Word256) Word256 {
+	if value, ok := ctx.storages[Tuple256{First: LeftPadWord256(addr), Second: key}]; ok {
+		return value
+	}
+	return ctx.cache.GetStorage(LeftPadWord256(addr), key)
+}
+
+// The account at addr must exist.
+func (ctx *TxContext) SetStorage(addr []byte, key Word256, value Word256) error {
+	if ctx.GetAccount(addr) == nil {
+		return types.ErrTxInvalidAddress
+	}
+	ctx.storages[Tuple256{First: LeftPadWord256(addr), Second: key}] = value
+	return nil
+}
+
+// Checks the pubkeys, signatures, sequences and balances of ins, then
+// takes their amounts and increments their sequences. Returns the total.
+func (ctx *TxContext) SpendInputs(ins []*types.TxInput) (int64, error) {
+	accounts, err := getOrMakeAccounts(ctx, ins, nil)
+	if err != nil {
+		return 0, err
+	}
+	total, err := validateInputs(accounts, ctx.SignBytes, ins)
+	if err != nil {
+		return 0, err
+	}
+	adjustByInputs(accounts, ins)
+	for _, acc := range accounts {
+		ctx.UpdateAccount(acc)
+	}
+	return total, nil
+}
+
+// Events are fired once the tx is applied.
+func (ctx *TxContext) FireEvent(event string, data interface{}) {
+	ctx.events = append(ctx.events, txEvent{event: event, data: data})
+}
+
+func execCustomTx(blockCache *BlockCache, handler TxHandler, tx types.Tx, signVersion int, runCall bool, evc events.Fireable) error {
+	if err := handler.ValidateBasic(tx); err != nil {
+		return err
+	}
+	_s := blockCache.State()
+	ctx := &TxContext{
+		ChainID:   _s.ChainID,
+		Height:    _s.LastBlockHeight + 1,
+		SignBytes: account.SignBytesVersion(signVersion, _s.ChainID, tx),
+		RunCall:   runCall,
+		cache:     blockCache,
+		accounts:  make(map[string]*account.Account),
+		storages:  make(map[Tuple256]Word256),
+	}
+	if err := handler.Exec(ctx, tx); err != nil {
+		return err
+	}
+	for _, acc := range ctx.accounts {
+		blockCache.UpdateAccount(acc)
+	}
+	for key, value := range ctx.storages {
+		addr, key := Tuple256Split(key)
+		blockCache.SetStorage(addr, key, value)
+	}
+	if evc != nil {
+		for _, e := range ctx.events {
+			evc.FireEvent(e.event, e.data)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package state
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/tendermint/tendermint/account"
+	"github.com/tendermint/tendermint/binary"
+	. "github.com/tendermint/tendermint/common"
+	"github.com/tendermint/tendermint/merkle"
+	"github.com/tendermint/tendermint/types"
+)
+
+/*
+A StateDiff brings a node that was down briefly from its state to the
+latest state, without executing the blocks it missed.
+
+The node sends the roots of its trees to a peer it trusts, which replies
+with its latest state and the tree nodes that aren't under those roots.
+IAVL trees depend on the order of their updates, so the nodes are sent
+rather than the changed keys. Nodes are saved by hash, so a bad one can't
+overwrite a good one, and the new state must hash to the StateHash of a
+block committed by the node's validators.
+*/
+
+var (
+	ErrStateDiffUnknownRoots = errors.New("Error state diff from unknown tree roots")
+	ErrStateDiffTooLarge     = errors.New("Error state diff is too large")
+	ErrStateDiffInvalid      = errors.New("Error invalid state diff")
+	ErrStateDiffNotCommitted = errors.New("Error state diff isn't committed by +2/3 of the bonded validators")
+)
+
+// Bounds the work of serving a diff, e.g. from old roots.
+const maxStateDiffNodes = 100000
+
+type StateRoots struct {
+	Accounts       []byte `json:"accounts"`
+	ValidatorInfos []byte `json:"validator_infos"`
+	NameReg        []byte `json:"name_reg"`
+	NameExpiry     []byte `json:"name_expiry"` // Not in diffs, see rebuildNameExpiry.
+}
+
+// The trees must be saved.
+func (s *State) Roots() *StateRoots {
+	return &StateRoots{
+		Accounts:       s.accounts.Hash(),
+		ValidatorInfos: s.validatorInfos.Hash(),
+		NameReg:        s.nameReg.Hash(),
+	}
+}
+
+type StateDiff struct {
+	State          []byte         `json:"state"`           // As saved by State.Save
+	Accounts       [][]byte       `json:"accounts"`        // New IAVL nodes of each tree
+	ValidatorInfos [][]byte       `json:"validator_infos"` //
+	NameReg        [][]byte       `json:"name_reg"`        //
+	Storage        []*StorageDiff `json:"storage"`         // Of accounts whose StorageRoot changed
+}
+
+type StorageDiff struct {
+	Root  []byte   `json:"root"`
+	Nodes [][]byte `json:"nodes"`
+}
+
+func hasTree(s *State, root []byte) bool {
+	return len(root) == 0 || s.DB.Get(root) != nil
+}
+
+// Returns the diff from the trees at from, e.g. the Roots() of an earlier
+// state, to s. The trees of s must be saved.
+func (s *State) Diff(from *StateRoots) (*StateDiff, error) {
+	if !hasTree(s, from.Accounts) || !hasTree(s, from.ValidatorInfos) || !hasTree(s, from.NameReg) {
+		return nil, ErrStateDiffUnknownRoots
+	}
+	fromState := &State{DB: s.DB}
+	fromState.loadTrees(from)
+
+	budget := maxStateDiffNodes
+	newNodes := func(t, from merkle.Tree, leafFn func(key, value interface{})) ([][]byte, error) {
+		nodes, err := t.(*merkle.IAVLTree).NewNodes(from.(*merkle.IAVLTree), budget, leafFn)
+		if err == merkle.ErrIAVLTooManyChanges {
+			return nil, ErrStateDiffTooLarge
+		}
+		budget -= len(nodes)
+		return nodes, err
+	}
+	diff := &StateDiff{State: s.bytes()}
+	changed := []*account.Account{}
+	var err error
+	diff.Accounts, err = newNodes(s.accounts, fromState.accounts, func(key, value interface{}) {
+		changed = append(changed, value.(*account.Account))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if diff.ValidatorInfos, err = newNodes(s.validatorInfos, fromState.validatorInfos, nil); err != nil {
+		return nil, err
+	}
+	if diff.NameReg, err = newNodes(s.nameReg, fromState.nameReg, nil); err != nil {
+		return nil, err
+	}
+	for _, acc := range changed {
+		var fromRoot []byte
+		if fromAcc := fromState.GetAccount(acc.Address); fromAcc != nil {
+			fromRoot = fromAcc.StorageRoot
+		}
+		if len(acc.StorageRoot) == 0 || bytes.Equal(acc.StorageRoot, fromRoot) {
+			continue
+		}
+		nodes, err := newNodes(s.LoadStorage(acc.StorageRoot), s.LoadStorage(fromRoot), nil)
+		if err != nil {
+			return nil, err
+		}
+		diff.Storage = append(diff.Storage, &StorageDiff{Root: acc.StorageRoot, Nodes: nodes})
+	}
+	return diff, nil
+}
+
+// Returns the state after blocks, from diff, where blocks follow s and
+// validation commits the last of them. The blocks aren't executed.
+// Instead the state must hash to the last block's StateHash, and the
+// validation must be signed by validators of s.BondedValidators with +2/3
+// of its voting power, as well as by +2/3 of the validators of the block.
+// Saves the nodes of the diff, but not the state.
+func (s *State) ApplyDiff(diff *StateDiff, blocks []*types.Block, validation *types.Validation) (newState *State, err error) {
+	defer func() {
+		// Bad binary panics in readState and the trees.
+		if r := recover(); r != nil {
+			log.Warn("Invalid state diff", "error", r)
+			newState, err = nil, ErrStateDiffInvalid
+		}
+	}()
+	if len(blocks) == 0 || validation == nil || validation.FirstPrecommit() == nil {
+		return nil, ErrStateDiffInvalid
+	}
+	last := blocks[len(blocks)-1]
+	lastParts := validation.FirstPrecommit().BlockParts
+	lastBlockHash, lastBlockParts, lastBlockTime := s.LastBlockHash, s.LastBlockParts, s.LastBlockTime
+	for i, block := range blocks {
+		// The parts header of each block is in the next block, or in the validation.
+		parts := lastParts
+		if i+1 < len(blocks) {
+			parts = blocks[i+1].LastBlockParts
+		}
+		err := block.ValidateBasic(s.ChainID, s.LastBlockHeight+i, lastBlockHash, lastBlockParts, lastBlockTime)
+		if err != nil {
+			log.Warn("Invalid state diff block", "height", block.Height, "error", err)
+			return nil, ErrStateDiffInvalid
+		}
+		lastBlockHash, lastBlockParts, lastBlockTime = block.Hash(), parts, block.Time
+	}
+
+	newState, roots, err := readState(s.DB, diff.State)
+	if err != nil {
+		return nil, ErrStateDiffInvalid
+	}
+	if newState.ChainID != s.ChainID ||
+		newState.LastBlockHeight != last.Height ||
+		!bytes.Equal(newState.LastBlockHash, lastBlockHash) ||
+		!newState.LastBlockParts.Equals(lastBlockParts) ||
+		!newState.LastBlockTime.Equal(lastBlockTime) ||
+		// From genesis, so not in the StateHash.
+		!bytes.Equal(binary.BinaryBytes(newState.ProtocolUpgrades), binary.BinaryBytes(s.ProtocolUpgrades)) ||
+		newState.ValidatorChangeLimit != s.ValidatorChangeLimit ||
+		newState.BlockGasLimit != s.BlockGasLimit {
+		return nil, ErrStateDiffInvalid
+	}
+	err = newState.LastBondedValidators.VerifyValidation(s.ChainID, lastBlockHash, lastBlockParts, last.Height, validation)
+	if err != nil {
+		log.Warn("Invalid state diff validation", "error", err)
+		return nil, ErrStateDiffNotCommitted
+	}
+	if !s.BondedValidators.hasTrustedPower(newState.LastBondedValidators, lastBlockHash, lastBlockParts, validation) {
+		return nil, ErrStateDiffNotCommitted
+	}
+
+	// Import the nodes, storage first so that the accounts are complete.
+	for _, storageDiff := range diff.Storage {
+		if err := s.LoadStorage(nil).(*merkle.IAVLTree).ImportNodes(storageDiff.Nodes, storageDiff.Root); err != nil {
+			return nil, err
+		}
+	}
+	newState.loadTrees(&StateRoots{})
+	imports := []struct {
+		tree  merkle.Tree
+		nodes [][]byte
+		root  []byte
+	}{
+		{newState.accounts, diff.Accounts, roots.Accounts},
+		{newState.validatorInfos, diff.ValidatorInfos, roots.ValidatorInfos},
+		{newState.nameReg, diff.NameReg, roots.NameReg},
+	}
+	for _, imp := range imports {
+		if err := imp.tree.(*merkle.IAVLTree).ImportNodes(imp.nodes, imp.root); err != nil {
+			return nil, err
+		}
+	}
+	if !bytes.Equal(newState.Hash(), last.StateHash) {
+		return nil, ErrStateDiffInvalid
+	}
+	newState.rebuildNameExpiry()
+	// The StorageRoot of each changed account must be complete too.
+	missingStorage := false
+	newState.accounts.(*merkle.IAVLTree).NewNodes(s.accounts.(*merkle.IAVLTree), -1, func(key, value interface{}) {
+		missingStorage = missingStorage || !hasTree(s, value.(*account.Account).StorageRoot)
+	})
+	if missingStorage {
+		return nil, merkle.ErrIAVLNodeMissing
+	}
+	return newState, nil
+}
+
+// Returns whether the validators of valSet with +2/3 of its voting power
+// precommitted hash in v, whose precommits are by the validators of
+// vSet, e.g. a later validator set.
+func (valSet *ValidatorSet) hasTrustedPower(vSet *ValidatorSet, hash []byte, parts types.PartSetHeader, v *types.Validation) bool {
+	power := int64(0)
+	for idx, precommit := range v.Precommits {
+		if precommit == nil || !bytes.Equal(precommit.BlockHash, hash) || !parts.Equals(precommit.BlockParts) {
+			continue
+		}
+		_, signer := vSet.GetByIndex(idx)
+		_, val := valSet.GetByAddress(signer.Address)
+		// VerifyValidation checked the signature with signer's key.
+		if val != nil && bytes.Equal(binary.BinaryBytes(val.PubKey), binary.BinaryBytes(signer.PubKey)) {
+			power += val.VotingPower
+		}
+	}
+	if power <= valSet.TotalVotingPower()*2/3 {
+		log.Warn(Fmt("State diff validation has %v of %v trusted voting power", power, valSet.TotalVotingPower()))
+		return false
+	}
+	return true
+}
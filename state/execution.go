@@ -37,6 +37,26 @@ func execBlock(s *State, block *types.Block, blockPartsHeader types.PartSetHeade
 		return err
 	}
 
+	// Validate block version against the upgrade schedule.
+	version := s.BlockVersionAt(block.Height)
+	if version > types.MaxBlockVersion {
+		return errors.New(Fmt("Block version %v at height %v is not supported by this software. Please upgrade.", version, block.Height))
+	}
+	if block.Version != version {
+		return errors.New(Fmt("Wrong Block.Header.Version. Expected %v, got %v", version, block.Version))
+	}
+	if blockPartsHeader.DataParts > 0 && version < types.ErasurePartSetVersion {
+		return errors.New(Fmt("Erasure coded block parts are not allowed at block version %v", version))
+	}
+	if version >= types.TxGasVersion && len(TxsWithinGasLimit(block.Txs, s.BlockGasLimit)) != len(block.Txs) {
+		return errors.New(Fmt("Block txs exceed the block gas limit %v", s.BlockGasLimit))
+	}
+
+	// Validators voted to halt the chain.
+	if s.IsHalted(block.Height) {
+		return errors.New(Fmt("Chain halted after height %v by EmergencyHaltTx", s.HaltHeight))
+	}
+
 	// Validate block LastValidation.
 	if block.Height == 1 {
 		if len(block.LastValidation.Precommits) != 0 {
@@ -47,6 +67,12 @@ func execBlock(s *State, block *types.Block, blockPartsHeader types.PartSetHeade
 			return errors.New(Fmt("Invalid block validation size. Expected %v, got %v",
 				s.LastBondedValidators.Size(), len(block.LastValidation.Precommits)))
 		}
+		lastVersion := s.BlockVersionAt(block.Height - 1)
+		for _, precommit := range block.LastValidation.Precommits {
+			if precommit != nil && precommit.Version != lastVersion {
+				return types.ErrVoteInvalidVersion
+			}
+		}
 		err := s.LastBondedValidators.VerifyValidation(
 			s.ChainID, s.LastBlockHash, s.LastBlockParts, block.Height-1, block.LastValidation)
 		if err != nil {
@@ -54,6 +80,11 @@ func execBlock(s *State, block *types.Block, blockPartsHeader types.PartSetHeade
 		}
 	}
 
+	// Validate block time against the LastValidation timestamps.
+	if blockTime, ok := s.NextBlockTime(block.LastValidation); ok && !block.Time.Equal(blockTime) {
+		return errors.New(Fmt("Wrong Block.Header.Time. Expected %v, got %v", blockTime, block.Time))
+	}
+
 	// Update Validator.LastCommitHeight as necessary.
 	// If we panic in here, something has gone horribly wrong
 	for i, precommit := range block.LastValidation.Precommits {
@@ -76,28 +107,38 @@ func execBlock(s *State, block *types.Block, blockPartsHeader types.PartSetHeade
 			if !updated {
 				panic("Failed to update unbonding validator LastCommitHeight")
 			}
-		} else {
+		} else if valInfo := s.GetValidatorInfo(val.Address); valInfo == nil || valInfo.DestroyedHeight != block.Height-1 {
 			panic("Could not find validator")
 		}
+		// Else it was destroyed by evidence in the last block.
 	}
 
 	// Remember LastBondedValidators
 	s.LastBondedValidators = s.BondedValidators.Copy()
+	validatorChangeBudget := s.validatorChangeBudget()
 
 	// Create BlockCache to cache changes to state.
 	blockCache := NewBlockCache(s)
 
 	// Execute each tx
-	for _, tx := range block.Data.Txs {
-		err := ExecTx(blockCache, tx, true, s.evc)
+	txsGasUsed := make([]int64, len(block.Data.Txs))
+	for i, tx := range block.Data.Txs {
+		err := execTx(blockCache, tx, true, s.evc, &txsGasUsed[i])
 		if err != nil {
 			return InvalidTxError{tx, err}
 		}
 	}
+	s.LastBlockGasUsed = txsGasUsed
 
 	// Now sync the BlockCache to the backend.
 	blockCache.Sync()
 
+	// Garbage collect names that expire with this block.
+	s.removeExpiredNameRegEntries(block.Height)
+
+	// Drop halt votes that can no longer reach quorum in time.
+	s.pruneHaltVotes(block.Height)
+
 	// If any unbonding periods are over,
 	// reward account with bonded coins.
 	toRelease := []*Validator{}
@@ -123,7 +164,18 @@ func execBlock(s *State, block *types.Block, blockPartsHeader types.PartSetHeade
 		return false
 	})
 	for _, val := range toTimeout {
-		s.unbondValidator(val)
+		if s.validatorChangeLimited() {
+			s.queueValidatorChange(ValidatorChangeUnbond, val)
+		} else {
+			s.unbondValidator(val)
+		}
+	}
+
+	// Apply queued validator changes. Until now, only destroyed
+	// validators left BondedValidators.
+	if s.validatorChangeLimited() {
+		destroyed := s.LastBondedValidators.TotalVotingPower() - s.BondedValidators.TotalVotingPower()
+		s.applyValidatorQueue(validatorChangeBudget, destroyed)
 	}
 
 	// Increment validator AccumPowers
@@ -275,6 +327,13 @@ func adjustByOutputs(accounts map[string]*account.Account, outs []*types.TxOutpu
 // If the tx is invalid, an error will be returned.
 // Unlike ExecBlock(), state will not be altered.
 func ExecTx(blockCache *BlockCache, tx_ types.Tx, runCall bool, evc events.Fireable) error {
+	var gasUsed int64
+	return execTx(blockCache, tx_, runCall, evc, &gasUsed)
+}
+
+// Like ExecTx, and sets gasUsed to the gas used by the tx:
+// its intrinsic gas, plus for CallTxs the gas used in the VM.
+func execTx(blockCache *BlockCache, tx_ types.Tx, runCall bool, evc events.Fireable, gasUsed *int64) error {
 
 	defer func() {
 		if r := recover(); r != nil {
@@ -287,6 +346,9 @@ func ExecTx(blockCache *BlockCache, tx_ types.Tx, runCall bool, evc events.Firea
 	// TODO: do something with fees
 	fees := int64(0)
 	_s := blockCache.State() // hack to access validators and block height
+	// Txs are signed with the sign-bytes version of the block they're in.
+	signVersion := _s.TxSignVersion()
+	*gasUsed = _s.TxIntrinsicGas(tx_)
 
 	// Exec tx
 	switch tx := tx_.(type) {
@@ -295,7 +357,7 @@ func ExecTx(blockCache *BlockCache, tx_ types.Tx, runCall bool, evc events.Firea
 		if err != nil {
 			return err
 		}
-		signBytes := account.SignBytes(_s.ChainID, tx)
+		signBytes := account.SignBytesVersion(signVersion, _s.ChainID, tx)
 		inTotal, err := validateInputs(accounts, signBytes, tx.Inputs)
 		if err != nil {
 			return err
@@ -343,7 +405,7 @@ func ExecTx(blockCache *BlockCache, tx_ types.Tx, runCall bool, evc events.Firea
 			log.Debug(Fmt("Can't find pubkey for %X", tx.Input.Address))
 			return err
 		}
-		signBytes := account.SignBytes(_s.ChainID, tx)
+		signBytes := account.SignBytesVersion(signVersion, _s.ChainID, tx)
 		err := validateInput(inAcc, signBytes, tx.Input)
 		if err != nil {
 			log.Debug(Fmt("validateInput failed on %X: %v", tx.Input.Address, err))
@@ -353,6 +415,11 @@ func ExecTx(blockCache *BlockCache, tx_ types.Tx, runCall bool, evc events.Firea
 			log.Debug(Fmt("Sender did not send enough to cover the fee %X", tx.Input.Address))
 			return types.ErrTxInsufficientFunds
 		}
+		intrinsicGas := *gasUsed
+		if tx.GasLimit < intrinsicGas {
+			log.Debug(Fmt("Gas limit %v does not cover intrinsic gas %v", tx.GasLimit, intrinsicGas))
+			return types.ErrTxInsufficientGas
+		}
 
 		createAccount := len(tx.Address) == 0
 		if !createAccount {
@@ -376,7 +443,7 @@ func ExecTx(blockCache *BlockCache, tx_ types.Tx, runCall bool, evc events.Firea
 		if runCall {
 
 			var (
-				gas     int64       = tx.GasLimit
+				gas     int64       = tx.GasLimit - intrinsicGas
 				err     error       = nil
 				caller  *vm.Account = toVMAccount(inAcc)
 				callee  *vm.Account = nil
@@ -428,6 +495,7 @@ func ExecTx(blockCache *BlockCache, tx_ types.Tx, runCall bool, evc events.Firea
 			// NOTE: Call() transfers the value from caller to callee iff call succeeds.
 
 			ret, err := vmach.Call(caller, callee, code, tx.Data, value, &gas)
+			*gasUsed = tx.GasLimit - gas
 			exception := ""
 			if err != nil {
 				exception = err.Error()
@@ -446,13 +514,13 @@ func ExecTx(blockCache *BlockCache, tx_ types.Tx, runCall bool, evc events.Firea
 				txCache.Sync()
 			}
 			// Create a receipt from the ret and whether errored.
-			log.Info("VM call complete", "caller", caller, "callee", callee, "return", ret, "err", err)
+			log.Info("VM call complete", "caller", caller, "callee", callee, "return", ret, "gasUsed", *gasUsed, "err", err)
 
 			// Fire Events for sender and receiver
 			// a separate event will be fired from vm for each additional call
 			if evc != nil {
-				evc.FireEvent(types.EventStringAccInput(tx.Input.Address), types.EventMsgCallTx{tx, ret, exception})
-				evc.FireEvent(types.EventStringAccOutput(tx.Address), types.EventMsgCallTx{tx, ret, exception})
+				evc.FireEvent(types.EventStringAccInput(tx.Input.Address), types.EventMsgCallTx{tx, ret, exception, *gasUsed})
+				evc.FireEvent(types.EventStringAccOutput(tx.Address), types.EventMsgCallTx{tx, ret, exception, *gasUsed})
 			}
 		} else {
 			// The mempool does not call txs until
@@ -482,7 +550,7 @@ func ExecTx(blockCache *BlockCache, tx_ types.Tx, runCall bool, evc events.Firea
 			log.Debug(Fmt("Can't find pubkey for %X", tx.Input.Address))
 			return err
 		}
-		signBytes := account.SignBytes(_s.ChainID, tx)
+		signBytes := account.SignBytesVersion(signVersion, _s.ChainID, tx)
 		err := validateInput(inAcc, signBytes, tx.Input)
 		if err != nil {
 			log.Debug(Fmt("validateInput failed on %X: %v", tx.Input.Address, err))
@@ -582,6 +650,58 @@ func ExecTx(blockCache *BlockCache, tx_ types.Tx, runCall bool, evc events.Firea
 
 		return nil
 
+	case *types.NameTransferTx:
+		var inAcc *account.Account
+
+		// Validate input
+		inAcc = blockCache.GetAccount(tx.Input.Address)
+		if inAcc == nil {
+			log.Debug(Fmt("Can't find in account %X", tx.Input.Address))
+			return types.ErrTxInvalidAddress
+		}
+		// pubKey should be present in either "inAcc" or "tx.Input"
+		if err := checkInputPubKey(inAcc, tx.Input); err != nil {
+			log.Debug(Fmt("Can't find pubkey for %X", tx.Input.Address))
+			return err
+		}
+		signBytes := account.SignBytesVersion(signVersion, _s.ChainID, tx)
+		err := validateInput(inAcc, signBytes, tx.Input)
+		if err != nil {
+			log.Debug(Fmt("validateInput failed on %X: %v", tx.Input.Address, err))
+			return err
+		}
+		if tx.Input.Amount < tx.Fee {
+			log.Debug(Fmt("Sender did not send enough to cover the fee %X", tx.Input.Address))
+			return types.ErrTxInsufficientFunds
+		}
+		// The input amount is all fee, so a larger one would be lost.
+		if tx.Input.Amount > tx.Fee {
+			return types.ErrTxInvalidAmount
+		}
+		if len(tx.NewOwner) != 20 {
+			return types.ErrTxInvalidAddress
+		}
+
+		// Only the owner of an unexpired name may transfer it.
+		entry := blockCache.GetNameRegEntry(tx.Name)
+		if entry == nil || entry.Expires <= _s.LastBlockHeight {
+			return types.ErrTxNameNotFound
+		}
+		if !bytes.Equal(entry.Owner, tx.Input.Address) {
+			log.Debug(Fmt("Sender %X is trying to transfer a name (%s) for which he is not owner", tx.Input.Address, tx.Name))
+			return types.ErrIncorrectOwner
+		}
+		entry.Owner = tx.NewOwner
+		blockCache.UpdateNameRegEntry(entry)
+		log.Debug("Transferred namereg entry", "name", entry.Name, "owner", entry.Owner)
+
+		// Good!
+		inAcc.Sequence += 1
+		inAcc.Balance -= tx.Input.Amount
+		blockCache.UpdateAccount(inAcc)
+
+		return nil
+
 	case *types.BondTx:
 		valInfo := blockCache.State().GetValidatorInfo(tx.PubKey.Address())
 		if valInfo != nil {
@@ -594,7 +714,7 @@ func ExecTx(blockCache *BlockCache, tx_ types.Tx, runCall bool, evc events.Firea
 			return err
 		}
 
-		signBytes := account.SignBytes(_s.ChainID, tx)
+		signBytes := account.SignBytesVersion(signVersion, _s.ChainID, tx)
 		inTotal, err := validateInputs(accounts, signBytes, tx.Inputs)
 		if err != nil {
 			return err
@@ -629,14 +749,16 @@ func ExecTx(blockCache *BlockCache, tx_ types.Tx, runCall bool, evc events.Firea
 			FirstBondAmount: outTotal,
 		})
 		// Add Validator
-		added := _s.BondedValidators.Add(&Validator{
+		val := &Validator{
 			Address:     tx.PubKey.Address(),
 			PubKey:      tx.PubKey,
 			BondHeight:  _s.LastBlockHeight + 1,
 			VotingPower: outTotal,
 			Accum:       0,
-		})
-		if !added {
+		}
+		if _s.validatorChangeLimited() {
+			_s.queueValidatorChange(ValidatorChangeBond, val)
+		} else if !_s.BondedValidators.Add(val) {
 			// SOMETHING HAS GONE HORRIBLY WRONG
 			panic("Failed to add validator")
 		}
@@ -653,7 +775,7 @@ func ExecTx(blockCache *BlockCache, tx_ types.Tx, runCall bool, evc events.Firea
 		}
 
 		// Verify the signature
-		signBytes := account.SignBytes(_s.ChainID, tx)
+		signBytes := account.SignBytesVersion(signVersion, _s.ChainID, tx)
 		if !val.PubKey.VerifyBytes(signBytes, tx.Signature) {
 			return types.ErrTxInvalidSignature
 		}
@@ -664,7 +786,11 @@ func ExecTx(blockCache *BlockCache, tx_ types.Tx, runCall bool, evc events.Firea
 		}
 
 		// Good!
-		_s.unbondValidator(val)
+		if _s.validatorChangeLimited() {
+			_s.queueValidatorChange(ValidatorChangeUnbond, val)
+		} else {
+			_s.unbondValidator(val)
+		}
 		if evc != nil {
 			evc.FireEvent(types.EventStringUnbond(), tx)
 		}
@@ -678,7 +804,7 @@ func ExecTx(blockCache *BlockCache, tx_ types.Tx, runCall bool, evc events.Firea
 		}
 
 		// Verify the signature
-		signBytes := account.SignBytes(_s.ChainID, tx)
+		signBytes := account.SignBytesVersion(signVersion, _s.ChainID, tx)
 		if !val.PubKey.VerifyBytes(signBytes, tx.Signature) {
 			return types.ErrTxInvalidSignature
 		}
@@ -689,7 +815,11 @@ func ExecTx(blockCache *BlockCache, tx_ types.Tx, runCall bool, evc events.Firea
 		}
 
 		// Good!
-		_s.rebondValidator(val)
+		if _s.validatorChangeLimited() {
+			_s.queueValidatorChange(ValidatorChangeRebond, val)
+		} else {
+			_s.rebondValidator(val)
+		}
 		if evc != nil {
 			evc.FireEvent(types.EventStringRebond(), tx)
 		}
@@ -734,7 +864,78 @@ func ExecTx(blockCache *BlockCache, tx_ types.Tx, runCall bool, evc events.Firea
 		}
 		return nil
 
+	case *types.DupeProposalTx:
+		// Verify the signatures
+		_, accused := _s.BondedValidators.GetByAddress(tx.Address)
+		if accused == nil {
+			_, accused = _s.UnbondingValidators.GetByAddress(tx.Address)
+			if accused == nil {
+				return types.ErrTxInvalidAddress
+			}
+		}
+		proposalASignBytes := account.SignBytes(_s.ChainID, &tx.ProposalA)
+		proposalBSignBytes := account.SignBytes(_s.ChainID, &tx.ProposalB)
+		if !accused.PubKey.VerifyBytes(proposalASignBytes, tx.ProposalA.Signature) ||
+			!accused.PubKey.VerifyBytes(proposalBSignBytes, tx.ProposalB.Signature) {
+			return types.ErrTxInvalidSignature
+		}
+
+		// Verify equivocation
+		if tx.ProposalA.Height != tx.ProposalB.Height {
+			return errors.New("DupeProposalTx heights don't match")
+		}
+		if tx.ProposalA.Round != tx.ProposalB.Round {
+			return errors.New("DupeProposalTx rounds don't match")
+		}
+		if bytes.Equal(proposalASignBytes, proposalBSignBytes) {
+			return errors.New("DupeProposalTx proposals shouldn't match")
+		}
+
+		// Good! (Bad proposer!)
+		_s.destroyValidator(accused)
+		if evc != nil {
+			evc.FireEvent(types.EventStringDupeout(), tx)
+		}
+		return nil
+
+	case *types.EmergencyHaltTx:
+		// The validator must be active
+		_, val := _s.BondedValidators.GetByAddress(tx.Address)
+		if val == nil {
+			return types.ErrTxInvalidAddress
+		}
+
+		// Verify the signature
+		signBytes := account.SignBytesVersion(signVersion, _s.ChainID, tx)
+		if !val.PubKey.VerifyBytes(signBytes, tx.Signature) {
+			return types.ErrTxInvalidSignature
+		}
+
+		// tx.Height must be within the vote window, and the halt after it.
+		nextHeight := _s.LastBlockHeight + 1
+		if tx.Height > nextHeight || tx.Height+haltVoteWindowBlocks < nextHeight {
+			return ErrHaltVoteExpired
+		}
+		if tx.HaltHeight <= tx.Height || tx.HaltHeight < nextHeight {
+			return ErrHaltHeightTooLow
+		}
+		if _s.HaltHeight != 0 {
+			return ErrHaltAlreadyScheduled
+		}
+
+		// Good!
+		if _s.addHaltVote(HaltVote{tx.Address, tx.Height, tx.HaltHeight}) {
+			log.Warn("Emergency halt scheduled", "haltHeight", tx.HaltHeight, "height", nextHeight)
+		}
+		if evc != nil {
+			evc.FireEvent(types.EventStringEmergencyHalt(), tx)
+		}
+		return nil
+
 	default:
+		if handler := getTxHandler(tx); handler != nil {
+			return execCustomTx(blockCache, handler, tx, signVersion, runCall, evc)
+		}
 		// SANITY CHECK (binary decoding should catch bad tx types
 		// before they get here
 		panic("Unknown Tx type")
@@ -0,0 +1,75 @@
+package state
+
+import (
+	"github.com/tendermint/tendermint/types"
+)
+
+// Gas charged for a tx before any VM execution.
+// The VM charges its own gas for CallTxs, see vm/gas.go.
+const (
+	GasTxBase     int64 = 100 // Every tx.
+	GasTxSig      int64 = 50  // Per signature to verify.
+	GasTxOutput   int64 = 20  // Per output or unbond_to.
+	GasTxDataByte int64 = 1   // Per byte of call data, name or name data.
+)
+
+// Returns the gas a tx consumes outside of the VM.
+func IntrinsicGas(tx types.Tx) int64 {
+	gas := GasTxBase
+	switch tx := tx.(type) {
+	case *types.SendTx:
+		gas += int64(len(tx.Inputs))*GasTxSig + int64(len(tx.Outputs))*GasTxOutput
+	case *types.CallTx:
+		gas += GasTxSig + int64(len(tx.Data))*GasTxDataByte
+	case *types.NameTx:
+		gas += GasTxSig + int64(len(tx.Name)+len(tx.Data))*GasTxDataByte
+	case *types.NameTransferTx:
+		gas += GasTxSig
+	case *types.BondTx:
+		gas += int64(1+len(tx.Inputs))*GasTxSig + int64(len(tx.UnbondTo))*GasTxOutput
+	case *types.UnbondTx, *types.RebondTx, *types.EmergencyHaltTx:
+		gas += GasTxSig
+	case *types.DupeoutTx, *types.DupeProposalTx:
+		gas += 2 * GasTxSig
+	default:
+		if handler := getTxHandler(tx); handler != nil {
+			gas += handler.Gas(tx)
+		}
+	}
+	return gas
+}
+
+// Returns the intrinsic gas of tx in the next block,
+// which is none before types.TxGasVersion.
+func (s *State) TxIntrinsicGas(tx types.Tx) int64 {
+	if s.BlockVersionAt(s.LastBlockHeight+1) < types.TxGasVersion {
+		return 0
+	}
+	return IntrinsicGas(tx)
+}
+
+// Returns the most gas a tx may consume:
+// its gas limit for CallTxs, otherwise its intrinsic gas.
+func MaxTxGas(tx types.Tx) int64 {
+	if callTx, ok := tx.(*types.CallTx); ok {
+		return callTx.GasLimit
+	}
+	return IntrinsicGas(tx)
+}
+
+// Returns the longest prefix of txs whose total MaxTxGas fits in gasLimit.
+// Txs are not skipped, since later txs may depend on earlier ones.
+// A gasLimit of 0 means no limit.
+func TxsWithinGasLimit(txs []types.Tx, gasLimit int64) []types.Tx {
+	if gasLimit <= 0 {
+		return txs
+	}
+	var totalGas int64
+	for i, tx := range txs {
+		totalGas += MaxTxGas(tx)
+		if totalGas > gasLimit {
+			return txs[:i]
+		}
+	}
+	return txs
+}
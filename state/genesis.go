@@ -1,8 +1,12 @@
 package state
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"os"
+	"reflect"
 	"time"
 
 	"github.com/tendermint/tendermint/account"
@@ -13,6 +17,27 @@ import (
 	"github.com/tendermint/tendermint/types"
 )
 
+const (
+	// Version 0: genesis_time and unbond_to are optional.
+	// Version 1: genesis_time is required, so all nodes agree on it,
+	//            and every validator has an unbond_to.
+	GenesisVersion = 1
+)
+
+var (
+	ErrGenesisNoChainID          = errors.New("Error genesis has no chain_id")
+	ErrGenesisNoValidators       = errors.New("Error genesis has no validators")
+	ErrGenesisNoTime             = errors.New("Error genesis has no genesis_time")
+	ErrGenesisInvalidAccount     = errors.New("Error genesis has an invalid account")
+	ErrGenesisDuplicateAccount   = errors.New("Error genesis has a duplicate account")
+	ErrGenesisInvalidValidator   = errors.New("Error genesis has an invalid validator")
+	ErrGenesisDuplicateValidator = errors.New("Error genesis has a duplicate validator")
+	ErrGenesisNoUnbondTo         = errors.New("Error genesis validator has no unbond_to")
+	ErrGenesisInvalidUpgrade     = errors.New("Error genesis protocol_upgrades must increase in height and version")
+	ErrGenesisInvalidChangeLimit = errors.New("Error genesis validator_change_limit must be between 0 and 100")
+	ErrGenesisInvalidGasLimit    = errors.New("Error genesis block_gas_limit must not be negative")
+)
+
 type GenesisAccount struct {
 	Address []byte `json:"address"`
 	Amount  int64  `json:"amount"`
@@ -25,15 +50,115 @@ type GenesisValidator struct {
 }
 
 type GenesisDoc struct {
+	Version     int                `json:"version"`
 	GenesisTime time.Time          `json:"genesis_time"`
 	ChainID     string             `json:"chain_id"`
 	Accounts    []GenesisAccount   `json:"accounts"`
 	Validators  []GenesisValidator `json:"validators"`
+
+	// Block version upgrades, scheduled at agreed heights.
+	ProtocolUpgrades []types.ProtocolUpgrade `json:"protocol_upgrades"`
+
+	// Max percent of the bonded voting power that validator changes may
+	// change per block. Excess changes wait for later blocks. 0 is no limit.
+	ValidatorChangeLimit int `json:"validator_change_limit"`
+
+	// Max total gas of the txs in a block, from block version
+	// types.TxGasVersion. See TxsWithinGasLimit. 0 is no limit.
+	BlockGasLimit int64 `json:"block_gas_limit"`
+
+	// Top level fields we don't know about, e.g. from newer tools.
+	// They are written back out by GenesisDocToJSON.
+	unknown map[string]json.RawMessage
 }
 
-func GenesisDocFromJSON(jsonBlob []byte) (genState *GenesisDoc) {
+func (genDoc *GenesisDoc) ValidateBasic() error {
+	if genDoc.ChainID == "" {
+		return ErrGenesisNoChainID
+	}
+	if genDoc.Version >= 1 && genDoc.GenesisTime.IsZero() {
+		return ErrGenesisNoTime
+	}
+	accounts := make(map[string]bool)
+	for _, acc := range genDoc.Accounts {
+		if len(acc.Address) != 20 || acc.Amount < 0 {
+			return ErrGenesisInvalidAccount
+		}
+		if accounts[string(acc.Address)] {
+			return ErrGenesisDuplicateAccount
+		}
+		accounts[string(acc.Address)] = true
+	}
+	if len(genDoc.Validators) == 0 {
+		return ErrGenesisNoValidators
+	}
+	validators := make(map[string]bool)
+	for _, val := range genDoc.Validators {
+		if len(val.PubKey) == 0 || val.Amount <= 0 {
+			return ErrGenesisInvalidValidator
+		}
+		if validators[string(val.PubKey)] {
+			return ErrGenesisDuplicateValidator
+		}
+		validators[string(val.PubKey)] = true
+		if genDoc.Version >= 1 && len(val.UnbondTo) == 0 {
+			return ErrGenesisNoUnbondTo
+		}
+		for _, unbondTo := range val.UnbondTo {
+			if len(unbondTo.Address) != 20 || unbondTo.Amount < 0 {
+				return ErrGenesisInvalidValidator
+			}
+		}
+	}
+	lastUpgrade := types.ProtocolUpgrade{Height: 0, Version: 0}
+	for _, upgrade := range genDoc.ProtocolUpgrades {
+		if upgrade.Height <= lastUpgrade.Height || upgrade.Version <= lastUpgrade.Version {
+			return ErrGenesisInvalidUpgrade
+		}
+		lastUpgrade = upgrade
+	}
+	if genDoc.ValidatorChangeLimit < 0 || genDoc.ValidatorChangeLimit > 100 {
+		return ErrGenesisInvalidChangeLimit
+	}
+	if genDoc.BlockGasLimit < 0 {
+		return ErrGenesisInvalidGasLimit
+	}
+	return nil
+}
+
+// Parses and validates a GenesisDoc.
+// Unknown top level fields are kept, so they survive a migration.
+// A GenesisDoc from a newer version is accepted if its known fields are valid.
+func ParseGenesisDoc(jsonBlob []byte) (*GenesisDoc, error) {
 	var err error
-	binary.ReadJSON(&genState, jsonBlob, &err)
+	var genDoc *GenesisDoc
+	binary.ReadJSON(&genDoc, jsonBlob, &err)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(jsonBlob, &fields); err != nil {
+		return nil, err
+	}
+	for _, fieldInfo := range binary.GetTypeInfo(reflect.TypeOf(GenesisDoc{})).Fields {
+		delete(fields, fieldInfo.JSONName)
+	}
+	if len(fields) > 0 {
+		genDoc.unknown = fields
+	}
+	if genDoc.Version > GenesisVersion {
+		log.Warn("GenesisDoc is newer than this software", "version", genDoc.Version, "supported", GenesisVersion)
+	} else if genDoc.Version < GenesisVersion {
+		log.Warn("GenesisDoc is outdated. Upgrade it with `tendermint genesis migrate`", "version", genDoc.Version)
+	}
+	if err := genDoc.ValidateBasic(); err != nil {
+		return nil, err
+	}
+	return genDoc, nil
+}
+
+func GenesisDocFromJSON(jsonBlob []byte) (genState *GenesisDoc) {
+	genState, err := ParseGenesisDoc(jsonBlob)
 	if err != nil {
 		log.Error(Fmt("Couldn't read GenesisDoc: %v", err))
 		os.Exit(1)
@@ -41,6 +166,61 @@ func GenesisDocFromJSON(jsonBlob []byte) (genState *GenesisDoc) {
 	return
 }
 
+// Writes the GenesisDoc as indented JSON, including unknown fields.
+func GenesisDocToJSON(genDoc *GenesisDoc) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(binary.JSONBytes(genDoc), &fields); err != nil {
+		return nil, err
+	}
+	for name, value := range genDoc.unknown {
+		fields[name] = value
+	}
+	jsonBytes, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, jsonBytes, "", "  "); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// genesisMigrations[i] upgrades a GenesisDoc from version i to i+1.
+// A migration must not change what the genesis state hashes to, or nodes
+// migrated independently end up on different chains. Where a new version
+// requires consensus content, the migration refuses until it's set by hand.
+var genesisMigrations = []func(genDoc *GenesisDoc) error{
+	// 0 -> 1: genesis_time and unbond_to become required.
+	func(genDoc *GenesisDoc) error {
+		if genDoc.GenesisTime.IsZero() {
+			return ErrGenesisNoTime
+		}
+		for _, val := range genDoc.Validators {
+			if len(val.UnbondTo) == 0 {
+				return ErrGenesisNoUnbondTo
+			}
+		}
+		return nil
+	},
+}
+
+// Upgrades the GenesisDoc to GenesisVersion.
+// Returns true if anything was migrated, and an error if a migration
+// requires fields to be set first, in which case genDoc is unchanged.
+func MigrateGenesisDoc(genDoc *GenesisDoc) (bool, error) {
+	if genDoc.Version >= GenesisVersion {
+		return false, nil
+	}
+	for version := genDoc.Version; version < GenesisVersion; version++ {
+		if err := genesisMigrations[version](genDoc); err != nil {
+			return false, err
+		}
+	}
+	genDoc.Version = GenesisVersion
+	return true, nil
+}
+
 func MakeGenesisStateFromFile(db dbm.DB, genDocFile string) *State {
 	jsonBlob, err := ioutil.ReadFile(genDocFile)
 	if err != nil {
@@ -106,11 +286,13 @@ func MakeGenesisState(db dbm.DB, genDoc *GenesisDoc) *State {
 	// Make namereg tree
 	nameReg := merkle.NewIAVLTree(binary.BasicCodec, NameRegCodec, 0, db)
 	// TODO: add names to genesis.json
+	nameExpiry := merkle.NewIAVLTree(binary.BasicCodec, binary.BasicCodec, 0, db)
 
 	// IAVLTrees must be persisted before copy operations.
 	accounts.Save()
 	validatorInfos.Save()
 	nameReg.Save()
+	nameExpiry.Save()
 
 	return &State{
 		DB:                   db,
@@ -122,8 +304,12 @@ func MakeGenesisState(db dbm.DB, genDoc *GenesisDoc) *State {
 		BondedValidators:     NewValidatorSet(validators),
 		LastBondedValidators: NewValidatorSet(nil),
 		UnbondingValidators:  NewValidatorSet(nil),
+		ProtocolUpgrades:     genDoc.ProtocolUpgrades,
+		ValidatorChangeLimit: genDoc.ValidatorChangeLimit,
+		BlockGasLimit:        genDoc.BlockGasLimit,
 		accounts:             accounts,
 		validatorInfos:       validatorInfos,
 		nameReg:              nameReg,
+		nameExpiry:           nameExpiry,
 	}
 }
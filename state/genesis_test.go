@@ -0,0 +1,127 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	_ "github.com/tendermint/tendermint/config/tendermint_test"
+	dbm "github.com/tendermint/tendermint/db"
+)
+
+var legacyGenesis = `{
+  "chain_id": "tendermint_test",
+  "accounts": [
+    {"address": "E9B5D87313356465FAE33C406CE2C2979DE60BCB", "amount": 200000000}
+  ],
+  "validators": [
+    {"pub_key": [1, "583779C3BFA3F6C7E23C7D830A9C3D023A216B55079AD38BFED1207B94A19548"], "amount": 1000000}
+  ],
+  "app_options": {"fee": 12345678901234567890, "plugins": ["a", "b"]}
+}`
+
+func TestGenesisMigrate(t *testing.T) {
+	genDoc, err := ParseGenesisDoc([]byte(legacyGenesis))
+	if err != nil {
+		t.Fatalf("Error parsing legacy genesis: %v", err)
+	}
+	if genDoc.Version != 0 {
+		t.Fatalf("Expected version 0, got %v", genDoc.Version)
+	}
+
+	// Genesis time and unbond_to are consensus content, so the
+	// migration doesn't make them up.
+	if _, err := MigrateGenesisDoc(genDoc); err != ErrGenesisNoTime {
+		t.Fatalf("Expected ErrGenesisNoTime, got %v", err)
+	}
+	genDoc.GenesisTime = time.Unix(1440000000, 0)
+	if _, err := MigrateGenesisDoc(genDoc); err != ErrGenesisNoUnbondTo {
+		t.Fatalf("Expected ErrGenesisNoUnbondTo, got %v", err)
+	}
+	if genDoc.Version != 0 {
+		t.Fatalf("Expected a refused migration to leave version 0, got %v", genDoc.Version)
+	}
+	val := genDoc.Validators[0]
+	genDoc.Validators[0].UnbondTo = []GenesisAccount{{Address: val.PubKey.Address(), Amount: val.Amount}}
+	hash := MakeGenesisState(dbm.NewMemDB(), genDoc).Hash()
+
+	if migrated, err := MigrateGenesisDoc(genDoc); !migrated || err != nil {
+		t.Fatalf("Expected legacy genesis to be migrated, got %v", err)
+	}
+	if migrated, _ := MigrateGenesisDoc(genDoc); migrated {
+		t.Fatal("Expected migrated genesis to be current")
+	}
+	if err := genDoc.ValidateBasic(); err != nil {
+		t.Fatalf("Migrated genesis is invalid: %v", err)
+	}
+
+	jsonBlob, err := GenesisDocToJSON(genDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	genDoc2, err := ParseGenesisDoc(jsonBlob)
+	if err != nil {
+		t.Fatalf("Error parsing migrated genesis: %v", err)
+	}
+	if genDoc2.Version != GenesisVersion {
+		t.Errorf("Expected version %v, got %v", GenesisVersion, genDoc2.Version)
+	}
+	if hash2 := MakeGenesisState(dbm.NewMemDB(), genDoc2).Hash(); !bytes.Equal(hash, hash2) {
+		t.Errorf("Expected migration to keep the genesis state hash %X, got %X", hash, hash2)
+	}
+
+	// Unknown fields survive untouched, including big numbers.
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(jsonBlob, &fields); err != nil {
+		t.Fatal(err)
+	}
+	var compacted bytes.Buffer
+	json.Compact(&compacted, fields["app_options"])
+	if compacted.String() != `{"fee":12345678901234567890,"plugins":["a","b"]}` {
+		t.Errorf("Unknown field was not preserved: %s", fields["app_options"])
+	}
+}
+
+func TestGenesisValidateBasic(t *testing.T) {
+	genDoc, _, _ := RandGenesisDoc(2, false, 1000, 2, false, 1000)
+	if err := genDoc.ValidateBasic(); err != nil {
+		t.Fatalf("Expected valid genesis, got %v", err)
+	}
+
+	bad := *genDoc
+	bad.ChainID = ""
+	if err := bad.ValidateBasic(); err != ErrGenesisNoChainID {
+		t.Errorf("Expected ErrGenesisNoChainID, got %v", err)
+	}
+	bad = *genDoc
+	bad.GenesisTime = time.Time{}
+	if err := bad.ValidateBasic(); err != ErrGenesisNoTime {
+		t.Errorf("Expected ErrGenesisNoTime, got %v", err)
+	}
+	bad.Version = 0
+	if err := bad.ValidateBasic(); err != nil {
+		t.Errorf("Expected version 0 genesis without time to be valid, got %v", err)
+	}
+	bad = *genDoc
+	bad.Validators = nil
+	if err := bad.ValidateBasic(); err != ErrGenesisNoValidators {
+		t.Errorf("Expected ErrGenesisNoValidators, got %v", err)
+	}
+	bad = *genDoc
+	bad.Accounts = append([]GenesisAccount{}, genDoc.Accounts...)
+	bad.Accounts = append(bad.Accounts, genDoc.Accounts[0])
+	if err := bad.ValidateBasic(); err != ErrGenesisDuplicateAccount {
+		t.Errorf("Expected ErrGenesisDuplicateAccount, got %v", err)
+	}
+	bad = *genDoc
+	bad.ValidatorChangeLimit = 101
+	if err := bad.ValidateBasic(); err != ErrGenesisInvalidChangeLimit {
+		t.Errorf("Expected ErrGenesisInvalidChangeLimit, got %v", err)
+	}
+	bad = *genDoc
+	bad.BlockGasLimit = -1
+	if err := bad.ValidateBasic(); err != ErrGenesisInvalidGasLimit {
+		t.Errorf("Expected ErrGenesisInvalidGasLimit, got %v", err)
+	}
+}
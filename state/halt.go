@@ -0,0 +1,92 @@
+package state
+
+import (
+	"bytes"
+	"errors"
+
+	. "github.com/tendermint/tendermint/common"
+	"github.com/tendermint/tendermint/merkle"
+)
+
+var (
+	haltVoteWindowBlocks = int(100) // TODO adjust
+)
+
+var (
+	ErrHaltHeightTooLow     = errors.New("Error halt height must be after the vote height")
+	ErrHaltVoteExpired      = errors.New("Error halt vote height is outside the vote window")
+	ErrHaltAlreadyScheduled = errors.New("Error chain halt already scheduled")
+)
+
+// A pending EmergencyHaltTx vote, kept until quorum or expiry.
+type HaltVote struct {
+	Address    []byte `json:"address"`
+	Height     int    `json:"height"`
+	HaltHeight int    `json:"halt_height"`
+}
+
+// Returns true if no block may be committed at height,
+// because the chain has been voted to halt before it.
+func (s *State) IsHalted(height int) bool {
+	return s.HaltHeight != 0 && height > s.HaltHeight
+}
+
+// Records a verified halt vote, replacing any earlier vote by the same
+// validator. Once +2/3 of bonded voting power votes for the same halt
+// height, HaltHeight is set and the pending votes are cleared.
+// Returns true if this vote reached quorum.
+func (s *State) addHaltVote(vote HaltVote) bool {
+	// Copy so that State.Copy() snapshots aren't mutated.
+	votes := make([]HaltVote, 0, len(s.HaltVotes)+1)
+	for _, v := range s.HaltVotes {
+		if !bytes.Equal(v.Address, vote.Address) {
+			votes = append(votes, v)
+		}
+	}
+	votes = append(votes, vote)
+	s.HaltVotes = votes
+
+	var power int64
+	for _, v := range s.HaltVotes {
+		if v.HaltHeight != vote.HaltHeight {
+			continue
+		}
+		if _, val := s.BondedValidators.GetByAddress(v.Address); val != nil {
+			power += val.VotingPower
+		}
+	}
+	if power*3 <= s.BondedValidators.TotalVotingPower()*2 {
+		return false
+	}
+	s.HaltHeight = vote.HaltHeight
+	s.HaltVotes = nil
+	return true
+}
+
+// Drops halt votes that are too old to count towards quorum at height.
+func (s *State) pruneHaltVotes(height int) {
+	var votes []HaltVote
+	for _, v := range s.HaltVotes {
+		if v.Height+haltVoteWindowBlocks >= height {
+			votes = append(votes, v)
+		}
+	}
+	s.HaltVotes = votes
+}
+
+func (s *State) haltHashable() merkle.Hashable {
+	return haltHashable{s.HaltHeight, s.HaltVotes}
+}
+
+type haltHashable struct {
+	HaltHeight int
+	HaltVotes  []HaltVote
+}
+
+func (hh haltHashable) Hash() []byte {
+	return merkle.SimpleHashFromBinary(hh)
+}
+
+func (hv HaltVote) String() string {
+	return Fmt("HaltVote{%X,%v,%v}", hv.Address, hv.Height, hv.HaltHeight)
+}
@@ -0,0 +1,111 @@
+// Package kvtx is an example of an application's own Tx type, registered
+// with state.RegisterTxType. A KVTx stores a value under a key in the
+// storage of its signer's account, paying its input amount as a fee.
+//
+// An application imports its Tx packages, e.g.
+//
+//	import _ "github.com/tendermint/tendermint/state/kvtx"
+//
+// from its node binary so that they're registered before any block or
+// mempool tx is decoded. All the nodes of a chain must register the same
+// types with the same type bytes.
+package kvtx
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/tendermint/tendermint/binary"
+	. "github.com/tendermint/tendermint/common"
+	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+)
+
+const TxTypeKV = types.TxTypeCustomMin
+
+var (
+	ErrKVTxInvalidKey   = errors.New("Error invalid kv tx key")
+	ErrKVTxInvalidValue = errors.New("Error invalid kv tx value")
+)
+
+func init() {
+	sm.RegisterTxType(TxTypeKV, &KVTx{}, kvTxHandler{})
+}
+
+type KVTx struct {
+	Input *types.TxInput `json:"input"`
+	Key   []byte         `json:"key"`   // At most 32 bytes
+	Value []byte         `json:"value"` // At most 32 bytes
+}
+
+func (tx *KVTx) WriteSignBytes(chainID string, w io.Writer, n *int64, err *error) {
+	chainIDJSON, _ := json.Marshal(chainID)
+	binary.WriteTo([]byte(Fmt(`{"chain_id":%s`, chainIDJSON)), w, n, err)
+	binary.WriteTo([]byte(Fmt(`,"tx":[%v,{"input":`, TxTypeKV)), w, n, err)
+	tx.Input.WriteSignBytes(w, n, err)
+	binary.WriteTo([]byte(Fmt(`,"key":"%X","value":"%X"}]}`, tx.Key, tx.Value)), w, n, err)
+}
+
+func (tx *KVTx) String() string {
+	return Fmt("KVTx{%v -> %X: %X}", tx.Input, tx.Key, tx.Value)
+}
+
+func EventStringKVSet(addr []byte) string {
+	return Fmt("KV/Set/%X", addr)
+}
+
+// Returns the value under key in the storage of the account at addr,
+// left padded to 32 bytes.
+func GetValue(state *sm.State, addr []byte, key []byte) Word256 {
+	acc := state.GetAccount(addr)
+	if acc == nil {
+		return Zero256
+	}
+	storage := state.LoadStorage(acc.StorageRoot)
+	_, value := storage.Get(LeftPadWord256(key).Bytes())
+	if value == nil {
+		return Zero256
+	}
+	return LeftPadWord256(value.([]byte))
+}
+
+//-----------------------------------------------------------------------------
+
+type kvTxHandler struct{}
+
+func (kvTxHandler) ValidateBasic(tx_ types.Tx) error {
+	tx := tx_.(*KVTx)
+	if tx.Input == nil {
+		return types.ErrTxInvalidAddress
+	}
+	if err := tx.Input.ValidateBasic(); err != nil {
+		return err
+	}
+	if len(tx.Key) == 0 || len(tx.Key) > 32 {
+		return ErrKVTxInvalidKey
+	}
+	if len(tx.Value) == 0 || len(tx.Value) > 32 {
+		return ErrKVTxInvalidValue
+	}
+	return nil
+}
+
+func (kvTxHandler) Exec(ctx *sm.TxContext, tx_ types.Tx) error {
+	tx := tx_.(*KVTx)
+	// The input amount is the fee.
+	if _, err := ctx.SpendInputs([]*types.TxInput{tx.Input}); err != nil {
+		return err
+	}
+	err := ctx.SetStorage(tx.Input.Address, LeftPadWord256(tx.Key), LeftPadWord256(tx.Value))
+	if err != nil {
+		return err
+	}
+	ctx.FireEvent(EventStringKVSet(tx.Input.Address), tx)
+	return nil
+}
+
+func (kvTxHandler) Gas(tx_ types.Tx) int64 {
+	tx := tx_.(*KVTx)
+	return sm.GasTxSig + int64(len(tx.Key)+len(tx.Value))*sm.GasTxDataByte
+}
@@ -0,0 +1,114 @@
+package kvtx
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tendermint/tendermint/account"
+	"github.com/tendermint/tendermint/binary"
+	. "github.com/tendermint/tendermint/common"
+	_ "github.com/tendermint/tendermint/config/tendermint_test"
+	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+)
+
+type eventRecorder map[string][]interface{}
+
+func (er eventRecorder) FireEvent(event string, data interface{}) {
+	er[event] = append(er[event], data)
+}
+
+func newKVTx(privAcc *account.PrivAccount, sequence int, chainID string, key, value []byte) *KVTx {
+	tx := &KVTx{
+		Input: &types.TxInput{
+			Address:  privAcc.Address,
+			Amount:   10,
+			Sequence: sequence,
+			PubKey:   privAcc.PubKey,
+		},
+		Key:   key,
+		Value: value,
+	}
+	tx.Input.Signature = privAcc.Sign(chainID, tx)
+	return tx
+}
+
+func TestKVTxCodec(t *testing.T) {
+	privAcc := account.GenPrivAccount()
+	tx := newKVTx(privAcc, 1, "test_chain", []byte("key"), []byte("value"))
+
+	txBytes := binary.BinaryBytes(struct{ types.Tx }{tx})
+	if txBytes[0] != TxTypeKV {
+		t.Fatalf("Expected type byte %X, got %X", TxTypeKV, txBytes[0])
+	}
+	var n int64
+	var err error
+	tx2 := binary.ReadBinary(struct{ types.Tx }{}, bytes.NewReader(txBytes), &n, &err).(struct{ types.Tx }).Tx
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(binary.BinaryBytes(struct{ types.Tx }{tx2}), txBytes) {
+		t.Errorf("Expected binary round trip, got %v", tx2)
+	}
+
+	txJSON := binary.JSONBytes(struct{ types.Tx }{tx})
+	tx3 := binary.ReadJSON(struct{ types.Tx }{}, txJSON, &err).(struct{ types.Tx }).Tx
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(binary.BinaryBytes(struct{ types.Tx }{tx3}), txBytes) {
+		t.Errorf("Expected JSON round trip, got %v", tx3)
+	}
+}
+
+func TestKVTxExec(t *testing.T) {
+	state, privAccounts, _ := sm.RandGenesisState(1, false, 1000, 1, false, 1000)
+	privAcc := privAccounts[0]
+	acc := state.GetAccount(privAcc.Address)
+	key, value := []byte("key"), []byte("value")
+
+	if err := sm.ExecTx(sm.NewBlockCache(state), &KVTx{Input: &types.TxInput{}}, false, nil); err == nil {
+		t.Error("Expected an invalid tx to fail its basic validation")
+	}
+	if gas := sm.IntrinsicGas(newKVTx(privAcc, 1, state.ChainID, key, value)); gas != sm.GasTxBase+sm.GasTxSig+8 {
+		t.Errorf("Expected the handler's gas to be added, got %v", gas)
+	}
+
+	// Checking the tx, as the mempool does, doesn't change the state.
+	tx := newKVTx(privAcc, acc.Sequence+1, state.ChainID, key, value)
+	if err := sm.ExecTx(sm.NewBlockCache(state), tx, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	if state.GetAccount(privAcc.Address).Sequence != acc.Sequence {
+		t.Error("Expected checking the tx to leave the state alone")
+	}
+
+	evc := eventRecorder{}
+	cache := sm.NewBlockCache(state)
+	if err := sm.ExecTx(cache, tx, true, evc); err != nil {
+		t.Fatal(err)
+	}
+	cache.Sync()
+	newAcc := state.GetAccount(privAcc.Address)
+	if newAcc.Sequence != acc.Sequence+1 || newAcc.Balance != acc.Balance-10 {
+		t.Errorf("Expected the fee to be paid, got %v", newAcc)
+	}
+	if got := GetValue(state, privAcc.Address, key); got != LeftPadWord256(value) {
+		t.Errorf("Expected value %X, got %X", value, got)
+	}
+	if len(evc[EventStringKVSet(privAcc.Address)]) != 1 {
+		t.Errorf("Expected a %v event, got %v", EventStringKVSet(privAcc.Address), evc)
+	}
+
+	// A failed tx changes nothing, and fires no events.
+	evc = eventRecorder{}
+	cache = sm.NewBlockCache(state)
+	if err := sm.ExecTx(cache, tx, true, evc); err == nil {
+		t.Fatal("Expected a replayed tx to fail")
+	}
+	cache.Sync()
+	if state.GetAccount(privAcc.Address).Sequence != newAcc.Sequence || len(evc) != 0 {
+		t.Error("Expected a failed tx to change nothing")
+	}
+
+}
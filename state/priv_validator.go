@@ -162,7 +162,8 @@ func (privVal *PrivValidator) SignProposal(chainID string, proposal *Proposal) e
 	}
 }
 
-func (privVal *PrivValidator) SignRebondTx(chainID string, rebondTx *types.RebondTx) error {
+// signVersion is the sign-bytes version of the block the tx is for, see State.TxSignVersion.
+func (privVal *PrivValidator) SignRebondTx(chainID string, signVersion int, rebondTx *types.RebondTx) error {
 	privVal.mtx.Lock()
 	defer privVal.mtx.Unlock()
 	if privVal.LastHeight < rebondTx.Height {
@@ -175,7 +176,7 @@ func (privVal *PrivValidator) SignRebondTx(chainID string, rebondTx *types.Rebon
 		privVal.save()
 
 		// Sign
-		rebondTx.Signature = privVal.PrivKey.Sign(account.SignBytes(chainID, rebondTx)).(account.SignatureEd25519)
+		rebondTx.Signature = privVal.PrivKey.Sign(account.SignBytesVersion(signVersion, chainID, rebondTx)).(account.SignatureEd25519)
 		return nil
 	} else {
 		return errors.New(fmt.Sprintf("Attempt of duplicate signing of rebondTx: Height %v", rebondTx.Height))
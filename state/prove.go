@@ -0,0 +1,309 @@
+package state
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/tendermint/tendermint/account"
+	"github.com/tendermint/tendermint/binary"
+	. "github.com/tendermint/tendermint/common"
+	"github.com/tendermint/tendermint/merkle"
+	"github.com/tendermint/tendermint/types"
+)
+
+/*
+A Proof proves the value at a path, against the StateHash or the hash of
+the block at Proof.Height. Paths are:
+
+    accounts/<address>                  the account, against the StateHash
+    accounts/<address>/storage/<key>    a contract storage value, against the StateHash
+    validators/<address>                a bonded validator, against the StateHash
+    txs/<index>                         a tx of the block, against the block hash
+
+Addresses and storage keys are hex. The StateHash of the block at height H
+is the hash of the state after H, so validators/<address> proves that the
+validator signs blocks from H+1.
+
+Only the latest state can be proven, but txs of any stored block can be.
+*/
+
+var (
+	ErrProofInvalidPath = errors.New("Error invalid proof path")
+	ErrProofNoValue     = errors.New("Error no value at proof path")
+	ErrProofHeight      = errors.New("Error state proofs are only available at the latest height")
+	ErrProofInvalid     = errors.New("Error invalid proof")
+)
+
+// Proof ops, each of which hashes its input up to an output.
+// The input of the first op is Proof.Value, and the output of the last is
+// the root.
+const (
+	ProofOpIAVL    = byte(0x01) // A value in an IAVL tree, to the tree's root.
+	ProofOpSimple  = byte(0x02) // A hash leaf of a simple tree, to the tree's root.
+	ProofOpLeaf    = byte(0x03) // A value, sha256 hashed as a leaf of a simple tree, to the tree's root.
+	ProofOpStorage = byte(0x04) // A storage root, to the binary encoded account whose StorageRoot it is.
+)
+
+// Indices of State.BondedValidators and State.accounts in State.hashables()
+const (
+	bondedValidatorsHashableIndex = 0
+	accountsHashableIndex         = 2
+)
+
+type Proof struct {
+	Path   string     `json:"path"`
+	Height int        `json:"height"`
+	Value  []byte     `json:"value"` // Binary encoded account, storage value, validator or tx sign-bytes
+	Ops    []*ProofOp `json:"ops"`   // From the value up
+	Root   []byte     `json:"root"`  // The StateHash or block hash at Height
+}
+
+type ProofOp struct {
+	Type    byte                `json:"type"`
+	IAVL    *merkle.IAVLProof   `json:"iavl"`    // ProofOpIAVL
+	Simple  *merkle.SimpleProof `json:"simple"`  // ProofOpSimple, ProofOpLeaf
+	Account *account.Account    `json:"account"` // ProofOpStorage
+}
+
+func (op *ProofOp) apply(input []byte) ([]byte, error) {
+	switch op.Type {
+	case ProofOpIAVL:
+		if op.IAVL == nil || !op.IAVL.Verify(op.IAVL.LeafNode.KeyBytes, input, op.IAVL.RootHash) {
+			return nil, ErrProofInvalid
+		}
+		return op.IAVL.RootHash, nil
+	case ProofOpSimple, ProofOpLeaf:
+		leafHash := input
+		if op.Type == ProofOpLeaf {
+			hash := sha256.Sum256(input)
+			leafHash = hash[:]
+		}
+		if op.Simple == nil || !op.Simple.Verify(leafHash, op.Simple.RootHash) {
+			return nil, ErrProofInvalid
+		}
+		return op.Simple.RootHash, nil
+	case ProofOpStorage:
+		if op.Account == nil || len(input) == 0 || !bytes.Equal(input, op.Account.StorageRoot) {
+			return nil, ErrProofInvalid
+		}
+		return codecBytes(account.AccountCodec, op.Account), nil
+	default:
+		return nil, ErrProofInvalid
+	}
+}
+
+// Checks the type of op, and where it is in its tree.
+// For IAVL ops key is the key, for simple ops index is the leaf index,
+// or -1 for any.
+func (op *ProofOp) is(type_ byte, key []byte, index int) bool {
+	if op.Type != type_ {
+		return false
+	}
+	switch type_ {
+	case ProofOpIAVL:
+		return bytes.Equal(op.IAVL.LeafNode.KeyBytes, key)
+	case ProofOpSimple, ProofOpLeaf:
+		return index == -1 || op.Simple.Index == index
+	}
+	return true
+}
+
+// Verifies that proof proves its value at its path against root,
+// e.g. the StateHash or hash of a block the caller trusts.
+// Proof.Root is ignored.
+func VerifyProof(proof *Proof, root []byte) error {
+	if proof == nil || len(root) == 0 {
+		return ErrProofInvalid
+	}
+	path, err := parseProofPath(proof.Path)
+	if err != nil {
+		return err
+	}
+	hash := proof.Value
+	for _, op := range proof.Ops {
+		if hash, err = op.apply(hash); err != nil {
+			return err
+		}
+	}
+	if !bytes.Equal(hash, root) {
+		return ErrProofInvalid
+	}
+	// The ops hash up to root, now check that they do so from path.
+	if !path.checkOps(proof.Value, proof.Ops) {
+		return ErrProofInvalid
+	}
+	return nil
+}
+
+// Proves path against block, which must be the latest block for paths
+// into the state.
+func Prove(s *State, block *types.Block, path_ string) (*Proof, error) {
+	path, err := parseProofPath(path_)
+	if err != nil {
+		return nil, err
+	}
+	proof := &Proof{
+		Path:   path_,
+		Height: block.Height,
+	}
+	if path.kind == "txs" {
+		if path.index >= len(block.Txs) {
+			return nil, ErrProofNoValue
+		}
+		proof.Value, proof.Ops = proveTx(block, path.index)
+		proof.Root = block.Hash()
+		return proof, nil
+	}
+
+	if block.Height != s.LastBlockHeight {
+		return nil, ErrProofHeight
+	}
+	stateProofs := merkle.SimpleProofsFromHashables(s.hashables())
+	switch path.kind {
+	case "accounts", "storage":
+		acc := s.GetAccount(path.address)
+		if acc == nil {
+			return nil, ErrProofNoValue
+		}
+		accOps := []*ProofOp{
+			&ProofOp{Type: ProofOpIAVL, IAVL: s.accounts.(*merkle.IAVLTree).ConstructProof(path.address)},
+			&ProofOp{Type: ProofOpSimple, Simple: stateProofs[accountsHashableIndex]},
+		}
+		if path.kind == "accounts" {
+			proof.Value = codecBytes(account.AccountCodec, acc)
+			proof.Ops = accOps
+			break
+		}
+		storage := s.LoadStorage(acc.StorageRoot).(*merkle.IAVLTree)
+		_, value := storage.Get(path.key)
+		if value == nil {
+			return nil, ErrProofNoValue
+		}
+		proof.Value = codecBytes(binary.BasicCodec, value.([]byte))
+		proof.Ops = append([]*ProofOp{
+			&ProofOp{Type: ProofOpIAVL, IAVL: storage.ConstructProof(path.key)},
+			&ProofOp{Type: ProofOpStorage, Account: acc},
+		}, accOps...)
+	case "validators":
+		index, val := s.BondedValidators.GetByAddress(path.address)
+		if val == nil {
+			return nil, ErrProofNoValue
+		}
+		hashables := make([]merkle.Hashable, len(s.BondedValidators.Validators))
+		for i, val := range s.BondedValidators.Validators {
+			hashables[i] = val
+		}
+		proof.Value = binary.BinaryBytes(val)
+		proof.Ops = []*ProofOp{
+			&ProofOp{Type: ProofOpLeaf, Simple: merkle.SimpleProofsFromHashables(hashables)[index]},
+			&ProofOp{Type: ProofOpSimple, Simple: stateProofs[bondedValidatorsHashableIndex]},
+		}
+	}
+	proof.Root = block.StateHash
+	return proof, nil
+}
+
+// Returns the value and ops of the tx at index.
+func proveTx(block *types.Block, index int) ([]byte, []*ProofOp) {
+	txs := make([]merkle.Hashable, len(block.Txs))
+	for i, tx := range block.Txs {
+		txs[i] = hashBytes(merkle.SimpleHashFromBinary(types.TxSignBytes(block.ChainID, tx)))
+	}
+	blockLeaves := make([]merkle.Hashable, types.BlockHashLeaves)
+	blockLeaves[types.BlockHashHeaderLeaf] = hashBytes(block.Header.Hash())
+	blockLeaves[types.BlockHashDataLeaf] = hashBytes(block.Data.Hash())
+	blockLeaves[types.BlockHashLastValidationLeaf] = hashBytes(block.LastValidation.Hash())
+	value := binary.BinaryBytes(types.TxSignBytes(block.ChainID, block.Txs[index]))
+	return value, []*ProofOp{
+		&ProofOp{Type: ProofOpLeaf, Simple: merkle.SimpleProofsFromHashables(txs)[index]},
+		&ProofOp{Type: ProofOpSimple, Simple: merkle.SimpleProofsFromHashables(blockLeaves)[types.BlockHashDataLeaf]},
+	}
+}
+
+// A leaf that is already a hash
+type hashBytes []byte
+
+func (hb hashBytes) Hash() []byte {
+	return hb
+}
+
+//-------------------------------------
+
+type proofPath struct {
+	kind    string // "accounts", "storage", "validators" or "txs"
+	address []byte
+	key     []byte // 32 byte (left padded) storage key
+	index   int
+}
+
+func parseProofPath(path_ string) (*proofPath, error) {
+	parts := strings.Split(strings.Trim(path_, "/"), "/")
+	path := &proofPath{kind: parts[0]}
+	var err error
+	switch {
+	case len(parts) == 2 && (parts[0] == "accounts" || parts[0] == "validators"):
+		path.address, err = hex.DecodeString(parts[1])
+	case len(parts) == 4 && parts[0] == "accounts" && parts[2] == "storage":
+		path.kind = "storage"
+		if path.address, err = hex.DecodeString(parts[1]); err == nil {
+			var key []byte
+			key, err = hex.DecodeString(parts[3])
+			path.key = LeftPadWord256(key).Bytes()
+		}
+	case len(parts) == 2 && parts[0] == "txs":
+		path.index, err = strconv.Atoi(parts[1])
+		if path.index < 0 {
+			err = ErrProofInvalidPath
+		}
+	default:
+		err = ErrProofInvalidPath
+	}
+	if err != nil {
+		return nil, ErrProofInvalidPath
+	}
+	return path, nil
+}
+
+// Checks that ops prove value at path, and not some other value that
+// happens to be in the same trees.
+func (path *proofPath) checkOps(value []byte, ops []*ProofOp) bool {
+	addressKey := codecBytes(binary.BasicCodec, path.address)
+	switch path.kind {
+	case "accounts":
+		return len(ops) == 2 &&
+			ops[0].is(ProofOpIAVL, addressKey, 0) &&
+			ops[1].is(ProofOpSimple, nil, accountsHashableIndex)
+	case "storage":
+		return len(ops) == 4 &&
+			ops[0].is(ProofOpIAVL, codecBytes(binary.BasicCodec, path.key), 0) &&
+			ops[1].is(ProofOpStorage, nil, 0) &&
+			ops[2].is(ProofOpIAVL, addressKey, 0) &&
+			ops[3].is(ProofOpSimple, nil, accountsHashableIndex)
+	case "validators":
+		n, err := new(int64), new(error)
+		val := binary.ReadBinary(&Validator{}, bytes.NewReader(value), n, err).(*Validator)
+		return *err == nil && bytes.Equal(val.Address, path.address) &&
+			len(ops) == 2 &&
+			ops[0].is(ProofOpLeaf, nil, -1) &&
+			ops[1].is(ProofOpSimple, nil, bondedValidatorsHashableIndex)
+	case "txs":
+		return len(ops) == 2 &&
+			ops[0].is(ProofOpLeaf, nil, path.index) &&
+			ops[1].is(ProofOpSimple, nil, types.BlockHashDataLeaf) &&
+			ops[1].Simple.Total == types.BlockHashLeaves
+	}
+	return false
+}
+
+func codecBytes(codec binary.Codec, o interface{}) []byte {
+	buf, n, err := new(bytes.Buffer), new(int64), new(error)
+	codec.Encode(o, buf, n, err)
+	if *err != nil {
+		panic(Fmt("Failed to encode %v: %v", o, *err))
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,45 @@
+package state
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/tendermint/tendermint/binary"
+	dbm "github.com/tendermint/tendermint/db"
+)
+
+var (
+	prevStateKey = []byte("prevStateKey")
+)
+
+var (
+	ErrNoPrevState = errors.New("Error no previous state to roll back to")
+)
+
+// Keeps the saved state that is about to be replaced by the state at height,
+// so that RollbackState can restore it.
+func savePrevState(db dbm.DB, height int) {
+	buf := db.Get(stateKey)
+	if len(buf) == 0 {
+		return
+	}
+	r, n, err := bytes.NewReader(buf), new(int64), new(error)
+	binary.ReadString(r, n, err) // ChainID
+	if savedHeight := binary.ReadVarint(r, n, err); *err == nil && savedHeight < height {
+		db.Set(prevStateKey, buf)
+	}
+}
+
+// Replaces the saved state with the one saved before it, i.e. the state
+// one block earlier, and returns it. Only one block can be rolled back.
+// The merkle trees of the earlier state are still in db,
+// since saving never deletes tree nodes.
+func RollbackState(db dbm.DB) (*State, error) {
+	buf := db.Get(prevStateKey)
+	if len(buf) == 0 {
+		return nil, ErrNoPrevState
+	}
+	db.Set(stateKey, buf)
+	db.Delete(prevStateKey)
+	return LoadState(db), nil
+}
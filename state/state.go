@@ -35,50 +35,99 @@ type State struct {
 	BondedValidators     *ValidatorSet
 	LastBondedValidators *ValidatorSet
 	UnbondingValidators  *ValidatorSet
-	accounts             merkle.Tree // Shouldn't be accessed directly.
-	validatorInfos       merkle.Tree // Shouldn't be accessed directly.
-	nameReg              merkle.Tree // Shouldn't be accessed directly.
+	ProtocolUpgrades     []types.ProtocolUpgrade // From genesis. See BlockVersionAt.
+	HaltHeight           int                     // Last block before an emergency halt, or 0.
+	HaltVotes            []HaltVote              // Pending EmergencyHaltTx votes.
+	ValidatorChangeLimit int                     // From genesis. See validator_queue.go
+	ValidatorQueue       []*ValidatorChange      // Changes deferred by ValidatorChangeLimit.
+	BlockGasLimit        int64                   // From genesis. See TxsWithinGasLimit.
+	LastBlockGasUsed     []int64                 // Gas used by each tx of the last block executed. Not saved.
+	accounts             merkle.Tree             // Shouldn't be accessed directly.
+	validatorInfos       merkle.Tree             // Shouldn't be accessed directly.
+	nameReg              merkle.Tree             // Shouldn't be accessed directly.
+	nameExpiry           merkle.Tree             // Index of nameReg by expiry, not in the hash.
 
 	evc events.Fireable // typically an events.EventCache
 }
 
 func LoadState(db dbm.DB) *State {
-	s := &State{DB: db}
 	buf := db.Get(stateKey)
 	if len(buf) == 0 {
 		return nil
-	} else {
-		r, n, err := bytes.NewReader(buf), new(int64), new(error)
-		s.ChainID = binary.ReadString(r, n, err)
-		s.LastBlockHeight = binary.ReadVarint(r, n, err)
-		s.LastBlockHash = binary.ReadByteSlice(r, n, err)
-		s.LastBlockParts = binary.ReadBinary(types.PartSetHeader{}, r, n, err).(types.PartSetHeader)
-		s.LastBlockTime = binary.ReadTime(r, n, err)
-		s.BondedValidators = binary.ReadBinary(&ValidatorSet{}, r, n, err).(*ValidatorSet)
-		s.LastBondedValidators = binary.ReadBinary(&ValidatorSet{}, r, n, err).(*ValidatorSet)
-		s.UnbondingValidators = binary.ReadBinary(&ValidatorSet{}, r, n, err).(*ValidatorSet)
-		accountsHash := binary.ReadByteSlice(r, n, err)
-		s.accounts = merkle.NewIAVLTree(binary.BasicCodec, account.AccountCodec, defaultAccountsCacheCapacity, db)
-		s.accounts.Load(accountsHash)
-		validatorInfosHash := binary.ReadByteSlice(r, n, err)
-		s.validatorInfos = merkle.NewIAVLTree(binary.BasicCodec, ValidatorInfoCodec, 0, db)
-		s.validatorInfos.Load(validatorInfosHash)
-		nameRegHash := binary.ReadByteSlice(r, n, err)
-		s.nameReg = merkle.NewIAVLTree(binary.BasicCodec, NameRegCodec, 0, db)
-		s.nameReg.Load(nameRegHash)
-		if *err != nil {
-			// DATA HAS BEEN CORRUPTED OR THE SPEC HAS CHANGED
-			Exit(Fmt("Data has been corrupted or its spec has changed: %v\n", *err))
-		}
-		// TODO: ensure that buf is completely read.
+	}
+	s, roots, err := readState(db, buf)
+	if err != nil {
+		// DATA HAS BEEN CORRUPTED OR THE SPEC HAS CHANGED
+		Exit(Fmt("Data has been corrupted or its spec has changed: %v\n", err))
+	}
+	s.loadTrees(roots)
+	if s.nameExpiry.Size() != s.nameReg.Size() {
+		s.rebuildNameExpiry()
 	}
 	return s
 }
 
+// Reads a state as saved by Save, without loading its trees.
+func readState(db dbm.DB, buf []byte) (*State, *StateRoots, error) {
+	s, roots := &State{DB: db}, &StateRoots{}
+	r, n, err := bytes.NewReader(buf), new(int64), new(error)
+	s.ChainID = binary.ReadString(r, n, err)
+	s.LastBlockHeight = binary.ReadVarint(r, n, err)
+	s.LastBlockHash = binary.ReadByteSlice(r, n, err)
+	s.LastBlockParts = binary.ReadBinary(types.PartSetHeader{}, r, n, err).(types.PartSetHeader)
+	s.LastBlockTime = binary.ReadTime(r, n, err)
+	s.BondedValidators = binary.ReadBinary(&ValidatorSet{}, r, n, err).(*ValidatorSet)
+	s.LastBondedValidators = binary.ReadBinary(&ValidatorSet{}, r, n, err).(*ValidatorSet)
+	s.UnbondingValidators = binary.ReadBinary(&ValidatorSet{}, r, n, err).(*ValidatorSet)
+	roots.Accounts = binary.ReadByteSlice(r, n, err)
+	roots.ValidatorInfos = binary.ReadByteSlice(r, n, err)
+	roots.NameReg = binary.ReadByteSlice(r, n, err)
+	// Appended later; absent from older saves.
+	if r.Len() > 0 {
+		s.ProtocolUpgrades = binary.ReadBinary([]types.ProtocolUpgrade{}, r, n, err).([]types.ProtocolUpgrade)
+	}
+	if r.Len() > 0 {
+		s.HaltHeight = binary.ReadVarint(r, n, err)
+		s.HaltVotes = binary.ReadBinary([]HaltVote{}, r, n, err).([]HaltVote)
+	}
+	if r.Len() > 0 {
+		s.ValidatorChangeLimit = binary.ReadVarint(r, n, err)
+		s.ValidatorQueue = binary.ReadBinary([]*ValidatorChange{}, r, n, err).([]*ValidatorChange)
+	}
+	if r.Len() > 0 {
+		roots.NameExpiry = binary.ReadByteSlice(r, n, err)
+	}
+	if r.Len() > 0 {
+		s.BlockGasLimit = binary.ReadInt64(r, n, err)
+	}
+	// TODO: ensure that buf is completely read.
+	return s, roots, *err
+}
+
+func (s *State) loadTrees(roots *StateRoots) {
+	s.accounts = merkle.NewIAVLTree(binary.BasicCodec, account.AccountCodec, defaultAccountsCacheCapacity, s.DB)
+	s.accounts.Load(roots.Accounts)
+	s.validatorInfos = merkle.NewIAVLTree(binary.BasicCodec, ValidatorInfoCodec, 0, s.DB)
+	s.validatorInfos.Load(roots.ValidatorInfos)
+	s.nameReg = merkle.NewIAVLTree(binary.BasicCodec, NameRegCodec, 0, s.DB)
+	s.nameReg.Load(roots.NameReg)
+	s.nameExpiry = merkle.NewIAVLTree(binary.BasicCodec, binary.BasicCodec, 0, s.DB)
+	if hasTree(s, roots.NameExpiry) {
+		s.nameExpiry.Load(roots.NameExpiry)
+	}
+}
+
 func (s *State) Save() {
 	s.accounts.Save()
 	s.validatorInfos.Save()
 	s.nameReg.Save()
+	s.nameExpiry.Save()
+	savePrevState(s.DB, s.LastBlockHeight)
+	s.DB.Set(stateKey, s.bytes())
+}
+
+// The trees must be saved.
+func (s *State) bytes() []byte {
 	buf, n, err := new(bytes.Buffer), new(int64), new(error)
 	binary.WriteString(s.ChainID, buf, n, err)
 	binary.WriteVarint(s.LastBlockHeight, buf, n, err)
@@ -91,11 +140,18 @@ func (s *State) Save() {
 	binary.WriteByteSlice(s.accounts.Hash(), buf, n, err)
 	binary.WriteByteSlice(s.validatorInfos.Hash(), buf, n, err)
 	binary.WriteByteSlice(s.nameReg.Hash(), buf, n, err)
+	binary.WriteBinary(s.ProtocolUpgrades, buf, n, err)
+	binary.WriteVarint(s.HaltHeight, buf, n, err)
+	binary.WriteBinary(s.HaltVotes, buf, n, err)
+	binary.WriteVarint(s.ValidatorChangeLimit, buf, n, err)
+	binary.WriteBinary(s.ValidatorQueue, buf, n, err)
+	binary.WriteByteSlice(s.nameExpiry.Hash(), buf, n, err)
+	binary.WriteInt64(s.BlockGasLimit, buf, n, err)
 	if *err != nil {
 		// SOMETHING HAS GONE HORRIBLY WRONG
 		panic(*err)
 	}
-	s.DB.Set(stateKey, buf.Bytes())
+	return buf.Bytes()
 }
 
 // CONTRACT:
@@ -112,15 +168,28 @@ func (s *State) Copy() *State {
 		BondedValidators:     s.BondedValidators.Copy(),     // TODO remove need for Copy() here.
 		LastBondedValidators: s.LastBondedValidators.Copy(), // That is, make updates to the validator set
 		UnbondingValidators:  s.UnbondingValidators.Copy(),  // copy the valSet lazily.
+		ProtocolUpgrades:     s.ProtocolUpgrades,
+		HaltHeight:           s.HaltHeight,
+		HaltVotes:            s.HaltVotes, // Replaced, never mutated in place.
+		ValidatorChangeLimit: s.ValidatorChangeLimit,
+		ValidatorQueue:       s.ValidatorQueue, // Replaced, never mutated in place.
+		BlockGasLimit:        s.BlockGasLimit,
+		LastBlockGasUsed:     s.LastBlockGasUsed, // Replaced, never mutated in place.
 		accounts:             s.accounts.Copy(),
 		validatorInfos:       s.validatorInfos.Copy(),
 		nameReg:              s.nameReg.Copy(),
+		nameExpiry:           s.nameExpiry.Copy(),
 		evc:                  nil,
 	}
 }
 
 // Returns a hash that represents the state data, excluding Last*
 func (s *State) Hash() []byte {
+	return merkle.SimpleHashFromHashables(s.hashables())
+}
+
+// The leaves of the state hash. See Prove.
+func (s *State) hashables() []merkle.Hashable {
 	hashables := []merkle.Hashable{
 		s.BondedValidators,
 		s.UnbondingValidators,
@@ -128,7 +197,43 @@ func (s *State) Hash() []byte {
 		s.validatorInfos,
 		s.nameReg,
 	}
-	return merkle.SimpleHashFromHashables(hashables)
+	// Only included once there are halt votes, so other chains keep their hashes.
+	if s.HaltHeight != 0 || len(s.HaltVotes) != 0 {
+		hashables = append(hashables, s.haltHashable())
+	}
+	// Likewise only included while validator changes are queued.
+	if len(s.ValidatorQueue) != 0 {
+		hashables = append(hashables, validatorQueueHashable(s.ValidatorQueue))
+	}
+	return hashables
+}
+
+// Returns the block version in effect at height.
+// Txs in the block are signed with the same sign-bytes version.
+func (s *State) BlockVersionAt(height int) int {
+	return types.BlockVersionAt(s.ProtocolUpgrades, height)
+}
+
+// Returns the sign-bytes version of txs in the next block,
+// which txs signed against this state must use.
+func (s *State) TxSignVersion() int {
+	return s.BlockVersionAt(s.LastBlockHeight + 1)
+}
+
+// Returns the Header.Time of the next block, whose LastValidation is
+// lastValidation. The first block has the genesis time, later blocks
+// the median of the precommit timestamps, so no proposer's clock decides.
+// Returns false if the rule isn't in effect yet, in which case the
+// proposer picks the time. See types.VoteTimestampVersion.
+func (s *State) NextBlockTime(lastValidation *types.Validation) (time.Time, bool) {
+	if s.LastBlockHeight == 0 {
+		return s.LastBlockTime, s.BlockVersionAt(1) >= types.VoteTimestampVersion
+	}
+	// The precommits of the last block must have timestamps.
+	if s.BlockVersionAt(s.LastBlockHeight) < types.VoteTimestampVersion {
+		return time.Time{}, false
+	}
+	return s.LastBondedValidators.MedianTime(lastValidation), true
 }
 
 // Mutates the block in place and updates it with new state hash.
@@ -308,11 +413,18 @@ func (s *State) GetNameRegEntry(name string) *types.NameRegEntry {
 }
 
 func (s *State) UpdateNameRegEntry(entry *types.NameRegEntry) bool {
+	if _, value := s.nameReg.Get(entry.Name); value != nil {
+		s.nameExpiry.Remove(nameExpiryKey(value.(*types.NameRegEntry)))
+	}
+	s.nameExpiry.Set(nameExpiryKey(entry), []byte{})
 	return s.nameReg.Set(entry.Name, entry)
 }
 
 func (s *State) RemoveNameRegEntry(name string) bool {
-	_, removed := s.nameReg.Remove(name)
+	value, removed := s.nameReg.Remove(name)
+	if removed {
+		s.nameExpiry.Remove(nameExpiryKey(value.(*types.NameRegEntry)))
+	}
 	return removed
 }
 
@@ -320,6 +432,47 @@ func (s *State) GetNames() merkle.Tree {
 	return s.nameReg.Copy()
 }
 
+// Removes entries that have expired as of height.
+// An entry is live while Expires > LastBlockHeight.
+func (s *State) removeExpiredNameRegEntries(height int) {
+	expired := []string{}
+	s.nameExpiry.Iterate(func(key interface{}, value interface{}) bool {
+		expires, name := splitNameExpiryKey(key.([]byte))
+		if expires > height {
+			return true
+		}
+		expired = append(expired, name)
+		return false
+	})
+	for _, name := range expired {
+		log.Debug("Removing expired namereg entry", "name", name, "height", height)
+		s.RemoveNameRegEntry(name)
+	}
+}
+
+// Keys of nameExpiry start with the expiry, big-endian, so that
+// iteration is in order of expiry.
+func nameExpiryKey(entry *types.NameRegEntry) []byte {
+	key := make([]byte, 8+len(entry.Name))
+	PutUint64BE(key, uint64(entry.Expires))
+	copy(key[8:], entry.Name)
+	return key
+}
+
+func splitNameExpiryKey(key []byte) (expires int, name string) {
+	return int(GetUint64BE(key)), string(key[8:])
+}
+
+// Builds nameExpiry from nameReg, for states saved before it existed
+// and for states from a StateDiff, which doesn't include it.
+func (s *State) rebuildNameExpiry() {
+	s.nameExpiry = merkle.NewIAVLTree(binary.BasicCodec, binary.BasicCodec, 0, s.DB)
+	s.nameReg.Iterate(func(key interface{}, value interface{}) bool {
+		s.nameExpiry.Set(nameExpiryKey(value.(*types.NameRegEntry)), []byte{})
+		return false
+	})
+}
+
 func NameRegEncoder(o interface{}, w io.Writer, n *int64, err *error) {
 	binary.WriteBinary(o.(*types.NameRegEntry), w, n, err)
 }
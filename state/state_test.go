@@ -2,7 +2,11 @@ package state
 
 import (
 	"github.com/tendermint/tendermint/account"
+	"github.com/tendermint/tendermint/binary"
+	. "github.com/tendermint/tendermint/common"
 	_ "github.com/tendermint/tendermint/config/tendermint_test"
+	dbm "github.com/tendermint/tendermint/db"
+	"github.com/tendermint/tendermint/merkle"
 	"github.com/tendermint/tendermint/types"
 
 	"bytes"
@@ -75,11 +79,16 @@ func makeBlock(t *testing.T, state *State, validation *types.Validation, txs []t
 	if validation == nil {
 		validation = &types.Validation{}
 	}
+	blockTime, ok := state.NextBlockTime(validation)
+	if !ok {
+		blockTime = state.LastBlockTime.Add(time.Minute)
+	}
 	block := &types.Block{
 		Header: &types.Header{
+			Version:        state.BlockVersionAt(state.LastBlockHeight + 1),
 			ChainID:        state.ChainID,
 			Height:         state.LastBlockHeight + 1,
-			Time:           state.LastBlockTime.Add(time.Minute),
+			Time:           blockTime,
 			Fees:           0,
 			NumTxs:         len(txs),
 			LastBlockHash:  state.LastBlockHash,
@@ -169,6 +178,176 @@ func TestGenesisSaveLoad(t *testing.T) {
 	}
 }
 
+func TestRollbackState(t *testing.T) {
+	s0, _, _ := RandGenesisState(10, true, 1000, 5, true, 1000)
+	s0.Save()
+	hash0 := s0.Hash()
+	if _, err := RollbackState(s0.DB); err != ErrNoPrevState {
+		t.Errorf("Expected ErrNoPrevState at genesis, got %v", err)
+	}
+
+	block := makeBlock(t, s0, nil, nil)
+	if err := ExecBlock(s0, block, block.MakePartSet().Header()); err != nil {
+		t.Fatal("Error appending block:", err)
+	}
+	s0.Save()
+	s0.Save() // Saving again at the same height keeps the previous state.
+
+	s1, err := RollbackState(s0.DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s1.LastBlockHeight != 0 || !bytes.Equal(s1.Hash(), hash0) {
+		t.Errorf("Expected the genesis state back, got height %v", s1.LastBlockHeight)
+	}
+	if loaded := LoadState(s0.DB); !bytes.Equal(loaded.Hash(), hash0) {
+		t.Error("Expected the rolled back state to be saved")
+	}
+	if _, err := RollbackState(s0.DB); err != ErrNoPrevState {
+		t.Errorf("Expected only one block to roll back, got %v", err)
+	}
+}
+
+func TestProtocolUpgrades(t *testing.T) {
+	genDoc, _, _ := RandGenesisDoc(1, true, 1000, 1, true, 1000)
+	genDoc.ProtocolUpgrades = []types.ProtocolUpgrade{{Height: 3, Version: types.MaxBlockVersion + 1}}
+	if err := genDoc.ValidateBasic(); err != nil {
+		t.Fatal(err)
+	}
+	s0 := MakeGenesisState(dbm.NewMemDB(), genDoc)
+	s0.Save()
+
+	// The schedule survives a save & load.
+	s1 := LoadState(s0.DB)
+	if len(s1.ProtocolUpgrades) != 1 || s1.ProtocolUpgrades[0] != genDoc.ProtocolUpgrades[0] {
+		t.Fatalf("Expected upgrades %v, got %v", genDoc.ProtocolUpgrades, s1.ProtocolUpgrades)
+	}
+	if s1.BlockVersionAt(2) != 0 || s1.BlockVersionAt(3) != types.MaxBlockVersion+1 {
+		t.Errorf("Unexpected block versions %v, %v", s1.BlockVersionAt(2), s1.BlockVersionAt(3))
+	}
+
+	// Blocks must carry the scheduled version.
+	block := makeBlock(t, s1, nil, nil)
+	block.Version = types.MaxBlockVersion + 1
+	if err := ExecBlock(s1.Copy(), block, block.MakePartSet().Header()); err == nil {
+		t.Error("Expected block with the wrong version to be rejected")
+	}
+
+	// Upgrades must increase in height and version.
+	genDoc.ProtocolUpgrades = append(genDoc.ProtocolUpgrades, types.ProtocolUpgrade{Height: 3, Version: 5})
+	if err := genDoc.ValidateBasic(); err != ErrGenesisInvalidUpgrade {
+		t.Errorf("Expected ErrGenesisInvalidUpgrade, got %v", err)
+	}
+}
+
+func TestTxSignVersion(t *testing.T) {
+	genDoc, privAccounts, _ := RandGenesisDoc(2, false, 1000, 1, false, 1000)
+	genDoc.ProtocolUpgrades = []types.ProtocolUpgrade{{Height: 2, Version: types.TxGasVersion}}
+	s0 := MakeGenesisState(dbm.NewMemDB(), genDoc)
+	s0.Save()
+	send := func(signVersion, sequence int) *types.SendTx {
+		tx := types.NewSendTx()
+		tx.AddInputWithNonce(privAccounts[0].PubKey, 1, sequence)
+		tx.AddOutput(privAccounts[1].Address, 1)
+		tx.SignInput(s0.ChainID, signVersion, 0, privAccounts[0])
+		return tx
+	}
+
+	// Block 1 is before the upgrade.
+	if s0.TxSignVersion() != 0 {
+		t.Fatalf("Expected sign version 0 for block 1, got %v", s0.TxSignVersion())
+	}
+	if err := execTxWithStateNewBlock(s0, send(s0.TxSignVersion(), 1), true); err != nil {
+		t.Fatal(err)
+	}
+
+	// Txs for block 2 must be signed with the upgraded version.
+	if s0.TxSignVersion() != types.TxGasVersion {
+		t.Fatalf("Expected sign version %v for block 2, got %v", types.TxGasVersion, s0.TxSignVersion())
+	}
+	if err := execTxWithState(s0, send(0, 2), true); err != types.ErrTxInvalidSignature {
+		t.Errorf("Expected ErrTxInvalidSignature, got %v", err)
+	}
+	if err := execTxWithState(s0, send(s0.TxSignVersion(), 2), true); err != nil {
+		t.Fatal(err)
+	}
+	if acc := s0.GetAccount(privAccounts[1].Address); acc.Balance != 1002 {
+		t.Errorf("Expected balance 1002, got %v", acc.Balance)
+	}
+}
+
+func TestProve(t *testing.T) {
+	state, privAccounts, _ := RandGenesisState(3, false, 1000, 1, false, 1000)
+
+	// Give the first account some storage.
+	key, value := make([]byte, 32), make([]byte, 32)
+	key[31], value[31] = 1, 2
+	acc := state.GetAccount(privAccounts[0].Address)
+	storage := state.LoadStorage(nil)
+	storage.Set(key, value)
+	acc.StorageRoot = storage.Save()
+	state.UpdateAccount(acc)
+	state.Save()
+
+	tx := types.NewSendTx()
+	tx.AddInputWithNonce(privAccounts[0].PubKey, 1, acc.Sequence+1)
+	tx.AddOutput(privAccounts[1].Address, 1)
+	tx.Inputs[0].Signature = privAccounts[0].Sign(state.ChainID, tx)
+	block := makeBlock(t, state, nil, []types.Tx{tx})
+	if err := ExecBlock(state, block, block.MakePartSet().Header()); err != nil {
+		t.Fatal("Error appending initial block:", err)
+	}
+
+	_, val := state.BondedValidators.GetByIndex(0)
+	paths := []string{
+		Fmt("accounts/%X", privAccounts[1].Address),
+		Fmt("accounts/%X/storage/01", privAccounts[0].Address),
+		Fmt("validators/%X", val.Address),
+		"txs/0",
+	}
+	roots := [][]byte{block.StateHash, block.StateHash, block.StateHash, block.Hash()}
+	for i, path := range paths {
+		proof, err := Prove(state, block, path)
+		if err != nil {
+			t.Fatalf("Failed to prove %v: %v", path, err)
+		}
+		if !bytes.Equal(proof.Root, roots[i]) {
+			t.Errorf("Expected %v to be proven against %X, got %X", path, roots[i], proof.Root)
+		}
+		if err := VerifyProof(proof, roots[i]); err != nil {
+			t.Errorf("Expected proof of %v to verify: %v", path, err)
+		}
+		if err := VerifyProof(proof, make([]byte, 32)); err == nil {
+			t.Errorf("Expected proof of %v not to verify against a different root", path)
+		}
+		proof.Value = append([]byte{0x01}, proof.Value...)
+		if err := VerifyProof(proof, roots[i]); err == nil {
+			t.Errorf("Expected proof of %v not to verify a different value", path)
+		}
+	}
+
+	// A proof of one account doesn't prove another.
+	proof, _ := Prove(state, block, paths[0])
+	proof.Path = Fmt("accounts/%X", privAccounts[2].Address)
+	if err := VerifyProof(proof, block.StateHash); err == nil {
+		t.Error("Expected proof not to verify for a different path")
+	}
+
+	if _, err := Prove(state, block, Fmt("accounts/%X", make([]byte, 20))); err != ErrProofNoValue {
+		t.Errorf("Expected ErrProofNoValue for an unknown account, got %v", err)
+	}
+	if _, err := Prove(state, block, "txs/1"); err != ErrProofNoValue {
+		t.Errorf("Expected ErrProofNoValue for a missing tx, got %v", err)
+	}
+	if _, err := Prove(state, block, "blocks/1"); err != ErrProofInvalidPath {
+		t.Errorf("Expected ErrProofInvalidPath, got %v", err)
+	}
+	state.LastBlockHeight += 1
+	if _, err := Prove(state, block, paths[0]); err != ErrProofHeight {
+		t.Errorf("Expected ErrProofHeight for an old block, got %v", err)
+	}
+}
+
 func TestTxSequence(t *testing.T) {
 
 	state, privAccounts, _ := RandGenesisState(3, true, 1000, 1, true, 1000)
@@ -226,7 +405,7 @@ func TestNameTxs(t *testing.T) {
 	for _, name := range names {
 		amt := fee + int64(numDesiredBlocks)*types.NameCostPerByte*types.NameCostPerBlock*types.BaseEntryCost(name, data)
 		tx, _ := types.NewNameTx(state, privAccounts[0].PubKey, name, data, amt, fee)
-		tx.Sign(state.ChainID, privAccounts[0])
+		tx.Sign(state.ChainID, 0, privAccounts[0])
 
 		if err := execTxWithState(state, tx, true); err == nil {
 			t.Fatalf("Expected invalid name error from %s", name)
@@ -239,7 +418,7 @@ func TestNameTxs(t *testing.T) {
 	for _, data := range datas {
 		amt := fee + int64(numDesiredBlocks)*types.NameCostPerByte*types.NameCostPerBlock*types.BaseEntryCost(name, data)
 		tx, _ := types.NewNameTx(state, privAccounts[0].PubKey, name, data, amt, fee)
-		tx.Sign(state.ChainID, privAccounts[0])
+		tx.Sign(state.ChainID, 0, privAccounts[0])
 
 		if err := execTxWithState(state, tx, true); err == nil {
 			t.Fatalf("Expected invalid data error from %s", data)
@@ -270,7 +449,7 @@ func TestNameTxs(t *testing.T) {
 	data = "on this side of neptune there are 1234567890 people: first is OMNIVORE. Or is it. Ok this is pretty restrictive. No exclamations :(. Faces tho :')"
 	amt := fee + int64(numDesiredBlocks)*types.NameCostPerByte*types.NameCostPerBlock*types.BaseEntryCost(name, data)
 	tx, _ := types.NewNameTx(state, privAccounts[0].PubKey, name, data, amt, fee)
-	tx.Sign(state.ChainID, privAccounts[0])
+	tx.Sign(state.ChainID, 0, privAccounts[0])
 	if err := execTxWithState(state, tx, true); err != nil {
 		t.Fatal(err)
 	}
@@ -279,7 +458,7 @@ func TestNameTxs(t *testing.T) {
 
 	// fail to update it as non-owner, in same block
 	tx, _ = types.NewNameTx(state, privAccounts[1].PubKey, name, data, amt, fee)
-	tx.Sign(state.ChainID, privAccounts[1])
+	tx.Sign(state.ChainID, 0, privAccounts[1])
 	if err := execTxWithState(state, tx, true); err == nil {
 		t.Fatal("Expected error")
 	}
@@ -287,7 +466,7 @@ func TestNameTxs(t *testing.T) {
 	// update it as owner, just to increase expiry, in same block
 	// NOTE: we have to resend the data or it will clear it (is this what we want?)
 	tx, _ = types.NewNameTx(state, privAccounts[0].PubKey, name, data, amt, fee)
-	tx.Sign(state.ChainID, privAccounts[0])
+	tx.Sign(state.ChainID, 0, privAccounts[0])
 	if err := execTxWithStateNewBlock(state, tx, true); err != nil {
 		t.Fatal(err)
 	}
@@ -296,7 +475,7 @@ func TestNameTxs(t *testing.T) {
 
 	// update it as owner, just to increase expiry, in next block
 	tx, _ = types.NewNameTx(state, privAccounts[0].PubKey, name, data, amt, fee)
-	tx.Sign(state.ChainID, privAccounts[0])
+	tx.Sign(state.ChainID, 0, privAccounts[0])
 	if err := execTxWithStateNewBlock(state, tx, true); err != nil {
 		t.Fatal(err)
 	}
@@ -306,7 +485,7 @@ func TestNameTxs(t *testing.T) {
 	// fail to update it as non-owner
 	state.LastBlockHeight = entry.Expires - 1
 	tx, _ = types.NewNameTx(state, privAccounts[1].PubKey, name, data, amt, fee)
-	tx.Sign(state.ChainID, privAccounts[1])
+	tx.Sign(state.ChainID, 0, privAccounts[1])
 	if err := execTxWithState(state, tx, true); err == nil {
 		t.Fatal("Expected error")
 	}
@@ -314,7 +493,7 @@ func TestNameTxs(t *testing.T) {
 	// once expires, non-owner succeeds
 	state.LastBlockHeight = entry.Expires
 	tx, _ = types.NewNameTx(state, privAccounts[1].PubKey, name, data, amt, fee)
-	tx.Sign(state.ChainID, privAccounts[1])
+	tx.Sign(state.ChainID, 0, privAccounts[1])
 	if err := execTxWithState(state, tx, true); err != nil {
 		t.Fatal(err)
 	}
@@ -327,7 +506,7 @@ func TestNameTxs(t *testing.T) {
 	numDesiredBlocks = 10
 	amt = fee + (int64(numDesiredBlocks)*types.NameCostPerByte*types.NameCostPerBlock*types.BaseEntryCost(name, data) - oldCredit)
 	tx, _ = types.NewNameTx(state, privAccounts[1].PubKey, name, data, amt, fee)
-	tx.Sign(state.ChainID, privAccounts[1])
+	tx.Sign(state.ChainID, 0, privAccounts[1])
 	if err := execTxWithState(state, tx, true); err != nil {
 		t.Fatal(err)
 	}
@@ -338,7 +517,7 @@ func TestNameTxs(t *testing.T) {
 	amt = fee
 	data = ""
 	tx, _ = types.NewNameTx(state, privAccounts[1].PubKey, name, data, amt, fee)
-	tx.Sign(state.ChainID, privAccounts[1])
+	tx.Sign(state.ChainID, 0, privAccounts[1])
 	if err := execTxWithStateNewBlock(state, tx, true); err != nil {
 		t.Fatal(err)
 	}
@@ -353,7 +532,7 @@ func TestNameTxs(t *testing.T) {
 	data = "some data"
 	amt = fee + int64(numDesiredBlocks)*types.NameCostPerByte*types.NameCostPerBlock*types.BaseEntryCost(name, data)
 	tx, _ = types.NewNameTx(state, privAccounts[0].PubKey, name, data, amt, fee)
-	tx.Sign(state.ChainID, privAccounts[0])
+	tx.Sign(state.ChainID, 0, privAccounts[0])
 	if err := execTxWithState(state, tx, true); err != nil {
 		t.Fatal(err)
 	}
@@ -364,7 +543,7 @@ func TestNameTxs(t *testing.T) {
 	amt = fee
 	data = ""
 	tx, _ = types.NewNameTx(state, privAccounts[1].PubKey, name, data, amt, fee)
-	tx.Sign(state.ChainID, privAccounts[1])
+	tx.Sign(state.ChainID, 0, privAccounts[1])
 	if err := execTxWithStateNewBlock(state, tx, true); err != nil {
 		t.Fatal(err)
 	}
@@ -374,6 +553,120 @@ func TestNameTxs(t *testing.T) {
 	}
 }
 
+func TestNameTransferTx(t *testing.T) {
+	state, privAccounts, _ := RandGenesisState(3, true, 1000, 1, true, 1000)
+
+	types.MinNameRegistrationPeriod = 5
+	name, data, fee := "transfer_me", "some data", int64(10)
+	numDesiredBlocks := 5
+	amt := fee + int64(numDesiredBlocks)*types.NameCostPerByte*types.NameCostPerBlock*types.BaseEntryCost(name, data)
+	tx, _ := types.NewNameTx(state, privAccounts[0].PubKey, name, data, amt, fee)
+	tx.Sign(state.ChainID, 0, privAccounts[0])
+	if err := execTxWithState(state, tx, true); err != nil {
+		t.Fatal(err)
+	}
+	expires := state.GetNameRegEntry(name).Expires
+
+	// fail to transfer as non-owner
+	transferTx, _ := types.NewNameTransferTx(state, privAccounts[1].PubKey, name, privAccounts[1].Address, fee)
+	transferTx.Sign(state.ChainID, 0, privAccounts[1])
+	if err := execTxWithState(state, transferTx, true); err != types.ErrIncorrectOwner {
+		t.Fatalf("Expected ErrIncorrectOwner, got %v", err)
+	}
+
+	// transfer as owner, expiry is unchanged
+	transferTx, _ = types.NewNameTransferTx(state, privAccounts[0].PubKey, name, privAccounts[1].Address, fee)
+	transferTx.Sign(state.ChainID, 0, privAccounts[0])
+	if err := execTxWithState(state, transferTx, true); err != nil {
+		t.Fatal(err)
+	}
+	entry := state.GetNameRegEntry(name)
+	if !bytes.Equal(entry.Owner, privAccounts[1].Address) || entry.Expires != expires {
+		t.Fatalf("Unexpected entry after transfer: %v", entry)
+	}
+
+	// the input amount must equal the fee, rather than be burned
+	transferTx, _ = types.NewNameTransferTx(state, privAccounts[1].PubKey, name, privAccounts[2].Address, fee)
+	transferTx.Input.Amount = fee + 1
+	transferTx.Sign(state.ChainID, 0, privAccounts[1])
+	if err := execTxWithState(state, transferTx, true); err != types.ErrTxInvalidAmount {
+		t.Fatalf("Expected ErrTxInvalidAmount, got %v", err)
+	}
+
+	// expired entries are garbage collected
+	state.removeExpiredNameRegEntries(expires - 1)
+	if state.GetNameRegEntry(name) == nil {
+		t.Fatal("Expected entry to survive until it expires")
+	}
+	state.removeExpiredNameRegEntries(expires)
+	if state.GetNameRegEntry(name) != nil {
+		t.Fatal("Expected expired entry to be removed")
+	}
+}
+
+func TestNameExpiryIndex(t *testing.T) {
+	state, _, _ := RandGenesisState(1, true, 1000, 1, true, 1000)
+	for i, expires := range []int{30, 10, 20, 10} {
+		state.UpdateNameRegEntry(&types.NameRegEntry{Name: Fmt("name%v", i), Expires: expires})
+	}
+	// renewing moves the entry in the index
+	state.UpdateNameRegEntry(&types.NameRegEntry{Name: "name1", Expires: 40})
+	checkNames := func(state *State, names ...string) {
+		if state.nameReg.Size() != len(names) || state.nameExpiry.Size() != len(names) {
+			t.Fatalf("Expected %v names, got %v with %v in the index", len(names), state.nameReg.Size(), state.nameExpiry.Size())
+		}
+		for _, name := range names {
+			if state.GetNameRegEntry(name) == nil {
+				t.Fatalf("Expected %v to be registered", name)
+			}
+		}
+	}
+
+	state.removeExpiredNameRegEntries(19)
+	checkNames(state, "name0", "name1", "name2")
+	state.removeExpiredNameRegEntries(30)
+	checkNames(state, "name1")
+
+	// states saved without the index rebuild it
+	state.nameExpiry = merkle.NewIAVLTree(binary.BasicCodec, binary.BasicCodec, 0, state.DB)
+	state.Save()
+	state = LoadState(state.DB)
+	checkNames(state, "name1")
+	state.removeExpiredNameRegEntries(40)
+	checkNames(state)
+}
+
+func TestTxsWithinGasLimit(t *testing.T) {
+	sendTx := &types.SendTx{
+		Inputs:  []*types.TxInput{&types.TxInput{}},
+		Outputs: []*types.TxOutput{&types.TxOutput{}},
+	}
+	callTx := &types.CallTx{Input: &types.TxInput{}, GasLimit: 1000}
+	txs := []types.Tx{sendTx, callTx, sendTx}
+	sendGas := GasTxBase + GasTxSig + GasTxOutput
+	if IntrinsicGas(sendTx) != sendGas {
+		t.Fatalf("Expected SendTx gas %v, got %v", sendGas, IntrinsicGas(sendTx))
+	}
+
+	// CallTxs count their full gas limit.
+	cases := []struct {
+		gasLimit int64
+		numTxs   int
+	}{
+		{0, 3},
+		{sendGas - 1, 0},
+		{sendGas, 1},
+		{sendGas + 999, 1},
+		{sendGas + 1000, 2},
+		{2*sendGas + 1000, 3},
+	}
+	for _, c := range cases {
+		if got := TxsWithinGasLimit(txs, c.gasLimit); len(got) != c.numTxs {
+			t.Errorf("Gas limit %v: expected %v txs, got %v", c.gasLimit, c.numTxs, len(got))
+		}
+	}
+}
+
 // TODO: test overflows.
 // TODO: test for unbonding validators.
 func TestTxs(t *testing.T) {
@@ -569,6 +862,128 @@ proof-of-work chain as proof of what happened while they were gone `
 
 }
 
+func TestEmergencyHaltTx(t *testing.T) {
+	// 3 validators of equal power; +2/3 needs all three.
+	state, _, privValidators := RandGenesisState(1, false, 1000, 3, false, 1000)
+
+	haltVote := func(i, height, haltHeight int) error {
+		tx := types.NewEmergencyHaltTx(privValidators[i].Address, height, haltHeight)
+		tx.Signature = privValidators[i].PrivKey.Sign(account.SignBytes(state.ChainID, tx)).(account.SignatureEd25519)
+		return execTxWithState(state, tx, true)
+	}
+
+	if err := haltVote(0, 1, 1); err != ErrHaltHeightTooLow {
+		t.Errorf("Expected ErrHaltHeightTooLow, got %v", err)
+	}
+	if err := haltVote(0, 2, 5); err != ErrHaltVoteExpired {
+		t.Errorf("Expected ErrHaltVoteExpired, got %v", err)
+	}
+
+	// A validator's later vote replaces its earlier one.
+	for _, haltHeight := range []int{6, 5} {
+		if err := haltVote(0, 1, haltHeight); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(state.HaltVotes) != 1 {
+		t.Fatalf("Expected 1 pending halt vote, got %v", state.HaltVotes)
+	}
+
+	// Votes for other halt heights don't count.
+	if err := haltVote(1, 1, 6); err != nil {
+		t.Fatal(err)
+	}
+	if err := haltVote(2, 1, 5); err != nil {
+		t.Fatal(err)
+	}
+	if state.HaltHeight != 0 {
+		t.Fatalf("Expected no halt with 2/3 of voting power, got halt height %v", state.HaltHeight)
+	}
+
+	// Old votes expire.
+	pruned := state.Copy()
+	pruned.pruneHaltVotes(2 + haltVoteWindowBlocks)
+	if len(pruned.HaltVotes) != 0 {
+		t.Errorf("Expected expired halt votes to be pruned, got %v", pruned.HaltVotes)
+	}
+
+	// +2/3 schedules the halt.
+	stateHash := state.Hash()
+	if err := haltVote(1, 1, 5); err != nil {
+		t.Fatal(err)
+	}
+	if state.HaltHeight != 5 || len(state.HaltVotes) != 0 {
+		t.Fatalf("Expected halt height 5 and no pending votes, got %v %v", state.HaltHeight, state.HaltVotes)
+	}
+	if bytes.Equal(stateHash, state.Hash()) {
+		t.Error("Expected the halt to change the state hash")
+	}
+	if state.IsHalted(5) || !state.IsHalted(6) {
+		t.Error("Expected the chain to halt after height 5")
+	}
+	if err := haltVote(0, 1, 7); err != ErrHaltAlreadyScheduled {
+		t.Errorf("Expected ErrHaltAlreadyScheduled, got %v", err)
+	}
+
+	// The halt survives a save & load.
+	state.Save()
+	if loaded := LoadState(state.DB); loaded.HaltHeight != 5 {
+		t.Errorf("Expected loaded halt height 5, got %v", loaded.HaltHeight)
+	}
+}
+
+func TestDupeProposalTx(t *testing.T) {
+	state, _, privValidators := RandGenesisState(3, true, 1000, 3, true, 1000)
+	privVal := privValidators[0]
+	signProposal := func(p *types.SignedProposal) {
+		p.Signature = privVal.PrivKey.Sign(account.SignBytes(state.ChainID, p)).(account.SignatureEd25519)
+	}
+	proposalA := types.SignedProposal{
+		Height:           1,
+		Round:            0,
+		BlockPartsHeader: types.PartSetHeader{Total: 1, Hash: []byte("parts_a")},
+		POLRound:         -1,
+	}
+	signProposal(&proposalA)
+
+	// The same proposal twice isn't evidence.
+	tx := &types.DupeProposalTx{Address: privVal.Address, ProposalA: proposalA, ProposalB: proposalA}
+	if err := execTxWithState(state.Copy(), tx, true); err == nil {
+		t.Error("Expected identical proposals to fail")
+	}
+
+	// Nor are proposals for different rounds.
+	proposalB := proposalA
+	proposalB.Round = 1
+	signProposal(&proposalB)
+	tx.ProposalB = proposalB
+	if err := execTxWithState(state.Copy(), tx, true); err == nil {
+		t.Error("Expected proposals for different rounds to fail")
+	}
+
+	// Nor another validator's signature.
+	proposalB = proposalA
+	proposalB.BlockPartsHeader.Hash = []byte("parts_b")
+	proposalB.Signature = privValidators[1].PrivKey.Sign(account.SignBytes(state.ChainID, &proposalB)).(account.SignatureEd25519)
+	tx.ProposalB = proposalB
+	if err := execTxWithState(state.Copy(), tx, true); err != types.ErrTxInvalidSignature {
+		t.Errorf("Expected ErrTxInvalidSignature, got %v", err)
+	}
+
+	// Two proposals for the same round destroy the proposer.
+	signProposal(&proposalB)
+	tx.ProposalB = proposalB
+	if err := execTxWithState(state, tx, true); err != nil {
+		t.Fatal(err)
+	}
+	if state.BondedValidators.HasAddress(privVal.Address) {
+		t.Error("Expected the proposer to be unbonded")
+	}
+	if valInfo := state.GetValidatorInfo(privVal.Address); valInfo.DestroyedHeight != 1 {
+		t.Errorf("Expected the proposer to be destroyed at height 1, got %v", valInfo.DestroyedHeight)
+	}
+}
+
 func TestAddValidator(t *testing.T) {
 
 	// Generate a state, save & load it.
@@ -643,3 +1058,263 @@ func TestAddValidator(t *testing.T) {
 		t.Error("Error appending secondary block:", err)
 	}
 }
+
+func TestMedianBlockTime(t *testing.T) {
+	genDoc, _, privValidators := RandGenesisDoc(10, false, 1000, 1, false, 1000)
+	genDoc.ProtocolUpgrades = []types.ProtocolUpgrade{{Height: 2, Version: types.VoteTimestampVersion}}
+	s0 := MakeGenesisState(dbm.NewMemDB(), genDoc)
+	s0.Save()
+	precommit := func(block *types.Block, parts *types.PartSet, timestamp time.Time) *types.Vote {
+		vote := &types.Vote{
+			Version:    s0.BlockVersionAt(block.Height),
+			Height:     block.Height,
+			Type:       types.VoteTypePrecommit,
+			BlockHash:  block.Hash(),
+			BlockParts: parts.Header(),
+			Timestamp:  timestamp.UnixNano(),
+		}
+		privValidators[0].SignVote(s0.ChainID, vote)
+		return vote
+	}
+
+	// Before the upgrade, the proposer picks the time.
+	block1 := makeBlock(t, s0, nil, nil)
+	block1.Time = genDoc.GenesisTime.Add(time.Hour)
+	block1Parts := block1.MakePartSet()
+	if err := ExecBlock(s0, block1, block1Parts.Header()); err != nil {
+		t.Fatal("Error appending block 1:", err)
+	}
+	// The precommits for block 1 have no timestamp yet, so block 2 does.
+	precommit1 := precommit(block1, block1Parts, block1.Time.Add(time.Second))
+	if precommit1.Version != 0 {
+		t.Fatalf("Expected a version 0 precommit, got %v", precommit1.Version)
+	}
+	block2 := makeBlock(t, s0, &types.Validation{Precommits: []*types.Vote{precommit1}}, nil)
+	block2Parts := block2.MakePartSet()
+	if err := ExecBlock(s0, block2, block2Parts.Header()); err != nil {
+		t.Fatal("Error appending block 2:", err)
+	}
+
+	// From then on, block times are the median of the precommit timestamps.
+	timestamp := block2.Time.Add(time.Second)
+	validation2 := &types.Validation{Precommits: []*types.Vote{precommit(block2, block2Parts, timestamp)}}
+	block3 := makeBlock(t, s0, validation2, nil)
+	if !block3.Time.Equal(timestamp) {
+		t.Errorf("Expected block time %v from the precommit, got %v", timestamp, block3.Time)
+	}
+
+	// A proposer can't pick another time.
+	badBlock3 := makeBlock(t, s0, validation2, nil)
+	badBlock3.Time = badBlock3.Time.Add(time.Second)
+	if err := ExecBlock(s0.Copy(), badBlock3, badBlock3.MakePartSet().Header()); err == nil {
+		t.Error("Expected error executing a block with the wrong time")
+	}
+
+	// Precommits must have the version of their height.
+	oldPrecommit := validation2.Precommits[0].Copy()
+	oldPrecommit.Version, oldPrecommit.Timestamp = 0, 0
+	privValidators[0].SignVote(s0.ChainID, oldPrecommit)
+	badBlock3 = &types.Block{
+		Header:         block3.Header,
+		Data:           block3.Data,
+		LastValidation: &types.Validation{Precommits: []*types.Vote{oldPrecommit}},
+	}
+	if err := ExecBlock(s0.Copy(), badBlock3, badBlock3.MakePartSet().Header()); err != types.ErrVoteInvalidVersion {
+		t.Errorf("Expected ErrVoteInvalidVersion, got %v", err)
+	}
+
+	if err := ExecBlock(s0, block3, block3.MakePartSet().Header()); err != nil {
+		t.Error("Error appending block 3:", err)
+	}
+}
+
+func TestTxGas(t *testing.T) {
+	genDoc, privAccounts, _ := RandGenesisDoc(3, false, 1000, 1, false, 1000)
+	genDoc.ProtocolUpgrades = []types.ProtocolUpgrade{{Height: 1, Version: types.TxGasVersion}}
+	sendGas := GasTxBase + GasTxSig + GasTxOutput
+	genDoc.BlockGasLimit = sendGas + sendGas/2
+	s0 := MakeGenesisState(dbm.NewMemDB(), genDoc)
+	s0.Save()
+	send := func(i int) *types.SendTx {
+		tx := types.NewSendTx()
+		tx.AddInputWithNonce(privAccounts[i].PubKey, 1, 1)
+		tx.AddOutput(privAccounts[2].Address, 1)
+		tx.Inputs[0].Signature = privAccounts[i].PrivKey.Sign(account.SignBytesVersion(types.TxGasVersion, s0.ChainID, tx))
+		return tx
+	}
+
+	// CallTxs must cover the intrinsic gas.
+	callTx := &types.CallTx{
+		Input:    &types.TxInput{Address: privAccounts[0].Address, Amount: 1, Sequence: 1, PubKey: privAccounts[0].PubKey},
+		Address:  privAccounts[1].Address,
+		GasLimit: 10,
+	}
+	callTx.Input.Signature = privAccounts[0].PrivKey.Sign(account.SignBytesVersion(types.TxGasVersion, s0.ChainID, callTx))
+	if err := execTxWithState(s0.Copy(), callTx, true); err != types.ErrTxInsufficientGas {
+		t.Errorf("Expected ErrTxInsufficientGas, got %v", err)
+	}
+
+	// Only one SendTx fits in the block gas limit.
+	block1 := makeBlock(t, s0, nil, []types.Tx{send(0)})
+	badHeader := *block1.Header
+	badHeader.NumTxs = 2
+	badBlock1 := &types.Block{
+		Header:         &badHeader,
+		Data:           &types.Data{Txs: []types.Tx{send(0), send(1)}},
+		LastValidation: block1.LastValidation,
+	}
+	if err := ExecBlock(s0.Copy(), badBlock1, badBlock1.MakePartSet().Header()); err == nil {
+		t.Error("Expected error executing a block over the gas limit")
+	}
+	if err := ExecBlock(s0, block1, block1.MakePartSet().Header()); err != nil {
+		t.Fatal("Error appending block 1:", err)
+	}
+	if len(s0.LastBlockGasUsed) != 1 || s0.LastBlockGasUsed[0] != sendGas {
+		t.Errorf("Expected gas used %v, got %v", []int64{sendGas}, s0.LastBlockGasUsed)
+	}
+}
+
+func TestValidatorChangeLimit(t *testing.T) {
+	s0, privAccounts, privValidators := RandGenesisState(10, false, 1000, 1, false, 1000)
+	s0.ValidatorChangeLimit = 50
+
+	makeBondTx := func(acc *account.PrivAccount) *types.BondTx {
+		bondTx := &types.BondTx{
+			PubKey: acc.PubKey.(account.PubKeyEd25519),
+			Inputs: []*types.TxInput{
+				&types.TxInput{
+					Address:  acc.Address,
+					Amount:   1000,
+					Sequence: 1,
+					PubKey:   acc.PubKey,
+				},
+			},
+			UnbondTo: []*types.TxOutput{
+				&types.TxOutput{
+					Address: acc.Address,
+					Amount:  1000,
+				},
+			},
+		}
+		bondTx.Signature = acc.Sign(s0.ChainID, bondTx).(account.SignatureEd25519)
+		bondTx.Inputs[0].Signature = acc.Sign(s0.ChainID, bondTx)
+		return bondTx
+	}
+
+	// Each bond is more than the limit of 500, but the first
+	// change of a block always goes through.
+	block0 := makeBlock(t, s0, nil, []types.Tx{makeBondTx(privAccounts[0]), makeBondTx(privAccounts[1])})
+	block0Parts := block0.MakePartSet()
+	if err := ExecBlock(s0, block0, block0Parts.Header()); err != nil {
+		t.Fatal("Error appending initial block:", err)
+	}
+	if s0.BondedValidators.Size() != 2 || len(s0.ValidatorQueue) != 1 {
+		t.Fatalf("Expected 2 validators and 1 queued change, got %v and %v",
+			s0.BondedValidators.Size(), len(s0.ValidatorQueue))
+	}
+	if _, val := s0.BondedValidators.GetByAddress(privAccounts[1].Address); val != nil {
+		t.Error("Expected the second bond to be deferred")
+	}
+
+	// The queue survives a save & load.
+	s0.Save()
+	if loaded := LoadState(s0.DB); len(loaded.ValidatorQueue) != 1 || !bytes.Equal(loaded.Hash(), s0.Hash()) {
+		t.Error("Expected the loaded state to have the queued change")
+	}
+
+	// The limit is now 1000, so the deferred bond goes through.
+	precommit0 := &types.Vote{
+		Height:     1,
+		Round:      0,
+		Type:       types.VoteTypePrecommit,
+		BlockHash:  block0.Hash(),
+		BlockParts: block0Parts.Header(),
+	}
+	privValidators[0].SignVote(s0.ChainID, precommit0)
+	block1 := makeBlock(t, s0, &types.Validation{Precommits: []*types.Vote{precommit0}}, nil)
+	if err := ExecBlock(s0, block1, block1.MakePartSet().Header()); err != nil {
+		t.Fatal("Error appending secondary block:", err)
+	}
+	if s0.BondedValidators.Size() != 3 || len(s0.ValidatorQueue) != 0 {
+		t.Errorf("Expected 3 validators and no queued changes, got %v and %v",
+			s0.BondedValidators.Size(), len(s0.ValidatorQueue))
+	}
+}
+
+func TestStateDiff(t *testing.T) {
+	genDoc, privAccounts, privValidators := RandGenesisDoc(3, false, 1000, 1, false, 1000)
+	s0 := MakeGenesisState(dbm.NewMemDB(), genDoc)
+	s0.Save()
+	s1 := MakeGenesisState(dbm.NewMemDB(), genDoc)
+	s1.Save()
+	commit := func(block *types.Block) *types.Validation {
+		parts := block.MakePartSet()
+		if err := ExecBlock(s0, block, parts.Header()); err != nil {
+			t.Fatal("Error appending block:", err)
+		}
+		s0.Save()
+		precommit := &types.Vote{
+			Height:     block.Height,
+			Type:       types.VoteTypePrecommit,
+			BlockHash:  block.Hash(),
+			BlockParts: parts.Header(),
+		}
+		privValidators[0].SignVote(s0.ChainID, precommit)
+		return &types.Validation{Precommits: []*types.Vote{precommit}}
+	}
+
+	// Contract storage changes too. It isn't changed by a tx here, so the
+	// change is made before the first block.
+	acc := s0.GetAccount(privAccounts[1].Address)
+	storage := s0.LoadStorage(nil)
+	storage.Set(LeftPadWord256([]byte{1}).Bytes(), LeftPadWord256([]byte{2}).Bytes())
+	acc.StorageRoot = storage.Save()
+	s0.UpdateAccount(acc)
+	s0.Save()
+
+	tx := types.NewSendTx()
+	tx.AddInputWithNonce(privAccounts[0].PubKey, 10, 1)
+	tx.AddOutput(privAccounts[2].Address, 10)
+	tx.Inputs[0].Signature = privAccounts[0].Sign(s0.ChainID, tx)
+	block1 := makeBlock(t, s0, nil, []types.Tx{tx})
+	validation1 := commit(block1)
+	block2 := makeBlock(t, s0, validation1, nil)
+	validation2 := commit(block2)
+
+	diff, err := s0.Diff(s1.Roots())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Storage) != 1 {
+		t.Errorf("Expected 1 storage diff, got %v", len(diff.Storage))
+	}
+	if _, err := s0.Diff(&StateRoots{Accounts: []byte("unknown")}); err != ErrStateDiffUnknownRoots {
+		t.Errorf("Expected ErrStateDiffUnknownRoots, got %v", err)
+	}
+
+	// The blocks must follow s1, and the last must be committed.
+	if _, err := s1.ApplyDiff(diff, []*types.Block{block2}, validation2); err != ErrStateDiffInvalid {
+		t.Errorf("Expected ErrStateDiffInvalid, got %v", err)
+	}
+	forged := *validation2.Precommits[0]
+	forged.Signature = privAccounts[0].Sign(s0.ChainID, &forged).(account.SignatureEd25519)
+	forgedValidation := &types.Validation{Precommits: []*types.Vote{&forged}}
+	if _, err := s1.ApplyDiff(diff, []*types.Block{block1, block2}, forgedValidation); err != ErrStateDiffNotCommitted {
+		t.Errorf("Expected ErrStateDiffNotCommitted, got %v", err)
+	}
+
+	s2, err := s1.ApplyDiff(diff, []*types.Block{block1, block2}, validation2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s2.LastBlockHeight != 2 || !bytes.Equal(s2.Hash(), s0.Hash()) {
+		t.Fatalf("Expected the state of s0 at height 2, got height %v", s2.LastBlockHeight)
+	}
+	if s2.GetAccount(privAccounts[2].Address).Balance != 1010 {
+		t.Error("Expected the tx of the first block in the new state")
+	}
+	_, value := s2.LoadStorage(s2.GetAccount(privAccounts[1].Address).StorageRoot).Get(LeftPadWord256([]byte{1}).Bytes())
+	if !bytes.Equal(value.([]byte), LeftPadWord256([]byte{2}).Bytes()) {
+		t.Error("Expected the storage change in the new state")
+	}
+}
@@ -0,0 +1,119 @@
+package state
+
+import (
+	"bytes"
+
+	. "github.com/tendermint/tendermint/common"
+	"github.com/tendermint/tendermint/merkle"
+)
+
+/*
+If State.ValidatorChangeLimit is set, bonds, unbonds, rebonds and timeouts
+don't change BondedValidators right away. They are queued, and applied in
+order at the end of each block until they would change more than
+ValidatorChangeLimit percent of the voting power bonded at the start of the
+block. The rest wait for later blocks, so that light clients can rely on
+consecutive validator sets overlapping.
+
+Destroying a validator for a DupeoutTx is never deferred, but it uses up
+the limit of its block.
+*/
+
+const (
+	ValidatorChangeBond   = byte(0x01)
+	ValidatorChangeUnbond = byte(0x02)
+	ValidatorChangeRebond = byte(0x03)
+)
+
+// A change to BondedValidators deferred by ValidatorChangeLimit.
+// Unbonds and rebonds only use Validator.Address; the validator is
+// looked up when the change is applied.
+type ValidatorChange struct {
+	Type      byte       `json:"type"`
+	Validator *Validator `json:"validator"`
+}
+
+func (vc *ValidatorChange) String() string {
+	return Fmt("ValidatorChange{%X %X}", vc.Type, vc.Validator.Address)
+}
+
+func (s *State) validatorChangeLimited() bool {
+	return s.ValidatorChangeLimit > 0
+}
+
+// The voting power that may change in the next block.
+func (s *State) validatorChangeBudget() int64 {
+	return s.BondedValidators.TotalVotingPower() * int64(s.ValidatorChangeLimit) / 100
+}
+
+// Queues a change, unless the same change is already queued.
+func (s *State) queueValidatorChange(type_ byte, val *Validator) {
+	for _, vc := range s.ValidatorQueue {
+		if vc.Type == type_ && bytes.Equal(vc.Validator.Address, val.Address) {
+			return
+		}
+	}
+	// Copy so that State.Copy() snapshots aren't mutated.
+	queue := make([]*ValidatorChange, len(s.ValidatorQueue), len(s.ValidatorQueue)+1)
+	copy(queue, s.ValidatorQueue)
+	s.ValidatorQueue = append(queue, &ValidatorChange{Type: type_, Validator: val.Copy()})
+}
+
+// Applies queued changes in order while they fit in budget, of which
+// used was already used by the block.
+// If none was used, the first change is applied even if it alone exceeds
+// the budget, so that a large bond can't hold up the queue forever.
+// Changes that no longer apply, e.g. unbonding a destroyed validator,
+// are dropped.
+func (s *State) applyValidatorQueue(budget int64, used int64) {
+	applied := 0
+	for _, vc := range s.ValidatorQueue {
+		var power int64
+		var apply func()
+		switch vc.Type {
+		case ValidatorChangeBond:
+			val := vc.Validator.Copy()
+			power = val.VotingPower
+			apply = func() {
+				val.BondHeight = s.LastBlockHeight + 1
+				if !s.BondedValidators.Add(val) {
+					// SOMETHING HAS GONE HORRIBLY WRONG
+					panic("Failed to add validator")
+				}
+			}
+		case ValidatorChangeUnbond:
+			_, val := s.BondedValidators.GetByAddress(vc.Validator.Address)
+			if val != nil {
+				power = val.VotingPower
+				apply = func() { s.unbondValidator(val) }
+			}
+		case ValidatorChangeRebond:
+			_, val := s.UnbondingValidators.GetByAddress(vc.Validator.Address)
+			if val != nil {
+				power = val.VotingPower
+				apply = func() { s.rebondValidator(val) }
+			}
+		}
+		if apply != nil {
+			if used > 0 && used+power > budget {
+				break
+			}
+			log.Info("Applying queued validator change", "change", vc, "power", power)
+			apply()
+			used += power
+		}
+		applied++
+	}
+	if applied > 0 {
+		s.ValidatorQueue = s.ValidatorQueue[applied:]
+	}
+	if len(s.ValidatorQueue) == 0 {
+		s.ValidatorQueue = nil
+	}
+}
+
+type validatorQueueHashable []*ValidatorChange
+
+func (vqh validatorQueueHashable) Hash() []byte {
+	return merkle.SimpleHashFromBinary(vqh)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/tendermint/tendermint/account"
 	. "github.com/tendermint/tendermint/common"
@@ -250,6 +251,43 @@ func (valSet *ValidatorSet) VerifyValidation(chainID string,
 	}
 }
 
+// Returns the median of the precommit timestamps in v, weighted by
+// voting power, so that validators with less than half of the power
+// in v can't move it past the timestamps of the others.
+// CONTRACT: v was verified against valSet, see VerifyValidation.
+func (valSet *ValidatorSet) MedianTime(v *types.Validation) time.Time {
+	times := weightedTimes{}
+	totalPower := int64(0)
+	for idx, precommit := range v.Precommits {
+		if precommit == nil {
+			continue
+		}
+		_, val := valSet.GetByIndex(idx)
+		times = append(times, weightedTime{precommit.Time(), val.VotingPower})
+		totalPower += val.VotingPower
+	}
+	sort.Sort(times)
+	median := totalPower / 2
+	for _, wt := range times {
+		if median < wt.power {
+			return wt.time
+		}
+		median -= wt.power
+	}
+	return time.Time{}
+}
+
+type weightedTime struct {
+	time  time.Time
+	power int64
+}
+
+type weightedTimes []weightedTime
+
+func (wts weightedTimes) Len() int           { return len(wts) }
+func (wts weightedTimes) Less(i, j int) bool { return wts[i].time.Before(wts[j].time) }
+func (wts weightedTimes) Swap(i, j int)      { wts[i], wts[j] = wts[j], wts[i] }
+
 func (valSet *ValidatorSet) String() string {
 	return valSet.StringIndented("")
 }
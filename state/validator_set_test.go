@@ -3,10 +3,12 @@ package state
 import (
 	"github.com/tendermint/tendermint/account"
 	. "github.com/tendermint/tendermint/common"
+	"github.com/tendermint/tendermint/types"
 
 	"bytes"
 	"fmt"
 	"testing"
+	"time"
 )
 
 func randValidator_() *Validator {
@@ -51,6 +53,42 @@ func TestProposerSelection(t *testing.T) {
 	}
 }
 
+func TestMedianTime(t *testing.T) {
+	vset := randValidatorSet(4)
+	powers := []int64{1, 2, 3, 4}
+	for i, power := range powers {
+		vset.Validators[i].VotingPower = power
+	}
+	precommitAt := func(seconds int64) *types.Vote {
+		return &types.Vote{Timestamp: time.Unix(seconds, 0).UnixNano()}
+	}
+
+	// Total power 10: times 30 (1), 10 (2), 40 (3), 20 (4).
+	// Sorted: 10 (2), 20 (4), 30 (1), 40 (3). Cumulative 2, 6 > 10/2.
+	v := &types.Validation{
+		Precommits: []*types.Vote{precommitAt(30), precommitAt(10), precommitAt(40), precommitAt(20)},
+	}
+	if median := vset.MedianTime(v); !median.Equal(time.Unix(20, 0)) {
+		t.Errorf("Expected median time 20, got %v", median.Unix())
+	}
+
+	// Missing precommits don't count. Total power 6: 10 (2), 20 (4).
+	v = &types.Validation{
+		Precommits: []*types.Vote{nil, precommitAt(10), nil, precommitAt(20)},
+	}
+	if median := vset.MedianTime(v); !median.Equal(time.Unix(20, 0)) {
+		t.Errorf("Expected median time 20, got %v", median.Unix())
+	}
+
+	// The heaviest validator alone can't move the median past the others.
+	v = &types.Validation{
+		Precommits: []*types.Vote{precommitAt(10), precommitAt(11), precommitAt(12), precommitAt(1000)},
+	}
+	if median := vset.MedianTime(v); !median.Equal(time.Unix(12, 0)) {
+		t.Errorf("Expected median time 12, got %v", median.Unix())
+	}
+}
+
 func BenchmarkValidatorSetCopy(b *testing.B) {
 	b.StopTimer()
 	vset := NewValidatorSet([]*Validator{})
@@ -5,10 +5,10 @@ import (
 	"crypto/sha256"
 	"errors"
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
-	"github.com/tendermint/tendermint/account"
 	"github.com/tendermint/tendermint/binary"
 	. "github.com/tendermint/tendermint/common"
 	"github.com/tendermint/tendermint/merkle"
@@ -23,6 +23,9 @@ type Block struct {
 // Basic validation that doesn't involve state data.
 func (b *Block) ValidateBasic(chainID string, lastBlockHeight int, lastBlockHash []byte,
 	lastBlockParts PartSetHeader, lastBlockTime time.Time) error {
+	if b.Header == nil || b.Data == nil || b.LastValidation == nil {
+		return errors.New("Incomplete Block")
+	}
 	if b.ChainID != chainID {
 		return errors.New("Wrong Block.Header.ChainID")
 	}
@@ -38,13 +41,12 @@ func (b *Block) ValidateBasic(chainID string, lastBlockHeight int, lastBlockHash
 	if !b.LastBlockParts.Equals(lastBlockParts) {
 		return errors.New("Wrong Block.Header.LastBlockParts")
 	}
-	/*	TODO: Determine bounds
-		See blockchain/reactor "stopSyncingDurationMinutes"
-
-		if !b.Time.After(lastBlockTime) {
-			return errors.New("Invalid Block.Header.Time")
-		}
-	*/
+	// From VoteTimestampVersion on, the first block has the genesis time,
+	// later blocks a median of precommit timestamps, which honest
+	// validators keep monotonic.
+	if b.Version >= VoteTimestampVersion && b.Height > 1 && !b.Time.After(lastBlockTime) {
+		return errors.New("Invalid Block.Header.Time")
+	}
 	if b.Header.Height != 1 {
 		if err := b.LastValidation.ValidateBasic(); err != nil {
 			return err
@@ -57,6 +59,14 @@ func (b *Block) ValidateBasic(chainID string, lastBlockHeight int, lastBlockHash
 // Computes and returns the block hash.
 // If the block is incomplete (e.g. missing Header.StateHash)
 // then the hash is nil, to prevent the usage of that hash.
+// Indices of the leaves of Block.Hash()
+const (
+	BlockHashHeaderLeaf = iota
+	BlockHashDataLeaf
+	BlockHashLastValidationLeaf
+	BlockHashLeaves // The number of leaves
+)
+
 func (b *Block) Hash() []byte {
 	if b.Header == nil || b.Data == nil || b.LastValidation == nil {
 		return nil
@@ -71,7 +81,10 @@ func (b *Block) Hash() []byte {
 	}
 
 	// Merkle hash from subhashes.
-	hashes := [][]byte{hashHeader, hashData, hashLastValidation}
+	hashes := make([][]byte, BlockHashLeaves)
+	hashes[BlockHashHeaderLeaf] = hashHeader
+	hashes[BlockHashDataLeaf] = hashData
+	hashes[BlockHashLastValidationLeaf] = hashLastValidation
 	return merkle.SimpleHashFromHashes(hashes)
 }
 
@@ -79,6 +92,24 @@ func (b *Block) MakePartSet() *PartSet {
 	return NewPartSetFromData(binary.BinaryBytes(b))
 }
 
+// Returns an erasure coded PartSet with parityRatio parity parts per data part,
+// rounded up. A parityRatio of 0 gives a plain PartSet.
+func (b *Block) MakeErasurePartSet(parityRatio float64) *PartSet {
+	data := binary.BinaryBytes(b)
+	dataParts := (len(data) + partSize - 1) / partSize
+	parityParts := int(math.Ceil(float64(dataParts) * parityRatio))
+	return NewErasurePartSetFromData(data, parityParts)
+}
+
+// Returns a PartSet in the same mode as header, e.g. to check the
+// PartSetHeader that validators signed for the block.
+func (b *Block) MakePartSetFor(header PartSetHeader) *PartSet {
+	if header.DataParts > 0 {
+		return NewErasurePartSetFromData(binary.BinaryBytes(b), header.Total-header.DataParts)
+	}
+	return b.MakePartSet()
+}
+
 // Convenience.
 // A nil block never hashes to anything.
 // Nothing hashes to a nil hash.
@@ -122,6 +153,7 @@ func (b *Block) StringShort() string {
 //-----------------------------------------------------------------------------
 
 type Header struct {
+	Version        int           `json:"version" binary:"version"` // See BlockVersionAt
 	ChainID        string        `json:"chain_id"`
 	Height         int           `json:"height"`
 	Time           time.Time     `json:"time"`
@@ -154,6 +186,7 @@ func (h *Header) StringIndented(indent string) string {
 		return "nil-Header"
 	}
 	return fmt.Sprintf(`Header{
+%s  Version:        %v
 %s  ChainID:        %v
 %s  Height:         %v
 %s  Time:           %v
@@ -163,6 +196,7 @@ func (h *Header) StringIndented(indent string) string {
 %s  LastBlockParts: %v
 %s  StateHash:      %X
 %s}#%X`,
+		indent, h.Version,
 		indent, h.ChainID,
 		indent, h.Height,
 		indent, h.Time,
@@ -244,6 +278,10 @@ func (v *Validation) ValidateBasic() error {
 			return fmt.Errorf("Invalid validation precommit round. Expected %v, got %v",
 				round, precommit.Round)
 		}
+		// Ensure that each precommit is well formed
+		if err := precommit.ValidateBasic(); err != nil {
+			return fmt.Errorf("Invalid validation precommit: %v", err)
+		}
 	}
 	return nil
 }
@@ -297,7 +335,7 @@ func (data *Data) Hash() []byte {
 	if data.hash == nil {
 		bs := make([]interface{}, len(data.Txs))
 		for i, tx := range data.Txs {
-			bs[i] = account.SignBytes(config.GetString("chain_id"), tx)
+			bs[i] = TxSignBytes(config.GetString("chain_id"), tx)
 		}
 		data.hash = merkle.SimpleHashFromBinaries(bs)
 	}
@@ -0,0 +1,30 @@
+package types
+
+import (
+	"strings"
+)
+
+// Chain ID words that mark a development or test chain.
+var testChainIDWords = map[string]bool{
+	"test":     true,
+	"testnet":  true,
+	"dev":      true,
+	"devnet":   true,
+	"local":    true,
+	"localnet": true,
+}
+
+// Returns true if chainID names a non-production chain, i.e. one of its
+// "_" or "-" separated words is test, testnet, dev, devnet, local or localnet.
+// Features that must never run on a production chain check this.
+func IsTestChainID(chainID string) bool {
+	words := strings.FieldsFunc(strings.ToLower(chainID), func(r rune) bool {
+		return r == '_' || r == '-'
+	})
+	for _, word := range words {
+		if testChainIDWords[word] {
+			return true
+		}
+	}
+	return false
+}
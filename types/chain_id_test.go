@@ -0,0 +1,23 @@
+package types
+
+import (
+	"testing"
+)
+
+func TestIsTestChainID(t *testing.T) {
+	cases := map[string]bool{
+		"tendermint_test":      true,
+		"tendermint_testnet_6": true,
+		"my-devnet":            true,
+		"LOCAL":                true,
+		"mainnet":              false,
+		"tendermint_1":         false,
+		"contest":              false,
+		"":                     false,
+	}
+	for chainID, expected := range cases {
+		if IsTestChainID(chainID) != expected {
+			t.Errorf("IsTestChainID(%q): expected %v", chainID, expected)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package types
+
+import (
+	"errors"
+)
+
+/*
+Reed-Solomon erasure coding over GF(2^8), used by erasure-coded PartSets.
+
+The code is systematic: the first dataShards shards are the data itself,
+and parity shard i is the sum over data shards j of C[i][j] * data[j], where
+C is the Cauchy matrix C[i][j] = 1/(x_i + y_j), x_i = dataShards+i, y_j = j.
+Every square submatrix of [I; C] is invertible, so any dataShards of the
+shards are enough to recover the rest. Since the x_i and y_j must be distinct
+field elements, there can be at most 256 shards in total.
+*/
+
+const maxErasureShards = 256
+
+var (
+	ErrErasureTooFewShards  = errors.New("Error erasure too few shards")
+	ErrErasureShardSize     = errors.New("Error erasure shards differ in size")
+	ErrErasureTooManyShards = errors.New("Error erasure too many shards")
+)
+
+var (
+	gfExp [510]byte
+	gfLog [256]byte
+	gfMul [256][256]byte
+)
+
+func init() {
+	// Generator 2 over the polynomial x^8 + x^4 + x^3 + x^2 + 1.
+	x := 1
+	for i := 0; i < 255; i++ {
+		gfExp[i] = byte(x)
+		gfExp[i+255] = byte(x)
+		gfLog[x] = byte(i)
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= 0x11d
+		}
+	}
+	for a := 1; a < 256; a++ {
+		for b := 1; b < 256; b++ {
+			gfMul[a][b] = gfExp[int(gfLog[a])+int(gfLog[b])]
+		}
+	}
+}
+
+// CONTRACT: a != 0
+func gfInv(a byte) byte {
+	return gfExp[255-int(gfLog[a])]
+}
+
+// The coefficient of data shard j in parity shard i.
+func cauchyCoef(dataShards, i, j int) byte {
+	return gfInv(byte(dataShards+i) ^ byte(j))
+}
+
+// dst += c * src
+func gfMulAdd(dst, src []byte, c byte) {
+	if c == 0 {
+		return
+	}
+	mulRow := &gfMul[c]
+	for i, b := range src {
+		dst[i] ^= mulRow[b]
+	}
+}
+
+// Returns parityShards parity shards for the data shards,
+// which must all be the same size.
+func erasureEncode(data [][]byte, parityShards int) [][]byte {
+	if len(data)+parityShards > maxErasureShards {
+		panic(ErrErasureTooManyShards)
+	}
+	parity := make([][]byte, parityShards)
+	for i := range parity {
+		parity[i] = make([]byte, len(data[0]))
+		for j, shard := range data {
+			gfMulAdd(parity[i], shard, cauchyCoef(len(data), i, j))
+		}
+	}
+	return parity
+}
+
+// Fills in the nil shards from any dataShards non-nil shards.
+// The non-nil shards must all be the same size.
+func erasureReconstruct(shards [][]byte, dataShards int) error {
+	if len(shards) > maxErasureShards {
+		return ErrErasureTooManyShards
+	}
+	// Pick the first dataShards present shards.
+	indices := make([]int, 0, dataShards)
+	shardSize := -1
+	for i, shard := range shards {
+		if shard == nil {
+			continue
+		}
+		if shardSize == -1 {
+			shardSize = len(shard)
+		} else if len(shard) != shardSize {
+			return ErrErasureShardSize
+		}
+		if len(indices) < dataShards {
+			indices = append(indices, i)
+		}
+	}
+	if len(indices) < dataShards {
+		return ErrErasureTooFewShards
+	}
+
+	// The rows of the encoding matrix for the picked shards.
+	matrix := make([][]byte, dataShards)
+	for r, index := range indices {
+		matrix[r] = make([]byte, dataShards)
+		if index < dataShards {
+			matrix[r][index] = 1
+		} else {
+			for j := 0; j < dataShards; j++ {
+				matrix[r][j] = cauchyCoef(dataShards, index-dataShards, j)
+			}
+		}
+	}
+	inverse := gfInvertMatrix(matrix)
+
+	// Recover the missing data shards.
+	data := make([][]byte, dataShards)
+	for j := 0; j < dataShards; j++ {
+		if shards[j] != nil {
+			data[j] = shards[j]
+			continue
+		}
+		data[j] = make([]byte, shardSize)
+		for r, index := range indices {
+			gfMulAdd(data[j], shards[index], inverse[j][r])
+		}
+	}
+	// Recompute the missing parity shards.
+	for i := dataShards; i < len(shards); i++ {
+		if shards[i] != nil {
+			continue
+		}
+		shards[i] = make([]byte, shardSize)
+		for j, shard := range data {
+			gfMulAdd(shards[i], shard, cauchyCoef(dataShards, i-dataShards, j))
+		}
+	}
+	copy(shards, data)
+	return nil
+}
+
+// Inverts a square matrix by Gauss-Jordan elimination.
+// CONTRACT: the matrix is invertible, which holds for rows of [I; C].
+func gfInvertMatrix(matrix [][]byte) [][]byte {
+	size := len(matrix)
+	work := make([][]byte, size)
+	for r := range matrix {
+		work[r] = make([]byte, 2*size)
+		copy(work[r], matrix[r])
+		work[r][size+r] = 1
+	}
+	for c := 0; c < size; c++ {
+		// Find a pivot and swap it into place.
+		pivot := c
+		for work[pivot][c] == 0 {
+			pivot++
+			if pivot == size {
+				panic("Singular matrix")
+			}
+		}
+		work[c], work[pivot] = work[pivot], work[c]
+		// Scale the pivot row to 1, then eliminate the column elsewhere.
+		scale := gfInv(work[c][c])
+		for i := range work[c] {
+			work[c][i] = gfMul[scale][work[c][i]]
+		}
+		for r := 0; r < size; r++ {
+			if r != c && work[r][c] != 0 {
+				gfMulAdd(work[r], work[c], work[r][c])
+			}
+		}
+	}
+	inverse := make([][]byte, size)
+	for r := range work {
+		inverse[r] = work[r][size:]
+	}
+	return inverse
+}
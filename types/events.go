@@ -18,6 +18,10 @@ func EventStringAccReceive(addr []byte) string {
 	return fmt.Sprintf("Acc/%X/Receive", addr)
 }
 
+func EventStringTxCommitted(txId []byte) string {
+	return fmt.Sprintf("Tx/%X/Committed", txId)
+}
+
 func EventStringBond() string {
 	return "Bond"
 }
@@ -34,6 +38,10 @@ func EventStringDupeout() string {
 	return "Dupeout"
 }
 
+func EventStringEmergencyHalt() string {
+	return "EmergencyHalt"
+}
+
 func EventStringNewBlock() string {
 	return "NewBlock"
 }
@@ -42,6 +50,10 @@ func EventStringFork() string {
 	return "Fork"
 }
 
+func EventStringVoteDivergence() string {
+	return "VoteDivergence"
+}
+
 // Most event messages are basic types (a block, a transaction)
 // but some (an input to a call tx or a receive) are more exotic:
 
@@ -49,6 +61,7 @@ type EventMsgCallTx struct {
 	Tx        Tx     `json:"tx"`
 	Return    []byte `json:"return"`
 	Exception string `json:"exception"`
+	GasUsed   int64  `json:"gas_used"` // Including intrinsic gas.
 }
 
 type CallData struct {
@@ -67,16 +80,42 @@ type EventMsgCall struct {
 	Exception string    `json:"exception"`
 }
 
+// A tx was committed in the block at height.
+// TxId is as returned by BroadcastTx and BroadcastTxs.
+type EventMsgTxCommitted struct {
+	TxId   []byte `json:"tx_id"`
+	Height int    `json:"height"`
+	Index  int    `json:"index"` // In block.Data.Txs
+}
+
+// More than one block has +1/3 of the votes in a round.
+// This is an early warning of a fork.
+type EventMsgVoteDivergence struct {
+	Height     int           `json:"height"`
+	Round      int           `json:"round"`
+	Type       byte          `json:"type"`
+	Blocks     []*BlockPower `json:"blocks"`
+	TotalPower int64         `json:"total_power"`
+}
+
+type BlockPower struct {
+	Hash  []byte        `json:"hash"`
+	Parts PartSetHeader `json:"parts"`
+	Power int64         `json:"power"`
+}
+
 /*
 Acc/XYZ/Input -> full tx or {full tx, return value, exception}
 Acc/XYZ/Output -> full tx
 Acc/XYZ/Receive -> full tx, return value, exception, (optionally?) calldata
+Tx/XYZ/Committed -> tx id, height and index in block
 Bond -> full tx
 Unbond -> full tx
 Rebond -> full tx
-Dupeout -> full tx
+Dupeout -> full tx, a DupeoutTx or DupeProposalTx
 NewBlock -> full block
 Fork -> block A, block B
+VoteDivergence -> competing blocks and their voting power
 
 Log -> Fuck this
 NewPeer -> peer
@@ -13,6 +13,8 @@ type NodeInfo struct {
 	Host    string `json:"host"`
 	P2PPort uint16 `json:"p2p_port"`
 	RPCPort uint16 `json:"rpc_port"`
+
+	Protocol ProtocolVersion `json:"protocol"`
 }
 
 func (ni *NodeInfo) CompatibleWith(no *NodeInfo) error {
@@ -39,6 +41,11 @@ func (ni *NodeInfo) CompatibleWith(no *NodeInfo) error {
 		return fmt.Errorf("Peer is on a different minor version. Got %v, expected %v", om, im)
 	}
 
+	// protocol major version must match
+	if _, err := NegotiateProtocol(ni.Protocol, no.Protocol); err != nil {
+		return err
+	}
+
 	// nodes must be on the same chain_id
 	if ni.ChainID != no.ChainID {
 		return fmt.Errorf("Peer is on a different chain_id. Got %v, expected %v", no.ChainID, ni.ChainID)
@@ -15,11 +15,18 @@ import (
 
 const (
 	partSize = 4096 // 4KB
+
+	MaxBlockSize       = 22020096 // 21MB
+	MaxBlockPartsCount = (MaxBlockSize + partSize - 1) / partSize
 )
 
 var (
 	ErrPartSetUnexpectedIndex = errors.New("Error part set unexpected index")
 	ErrPartSetInvalidProof    = errors.New("Error part set invalid proof")
+	ErrPartSetInvalidHeader   = errors.New("Error part set invalid header")
+	ErrPartInvalidSize        = errors.New("Error part invalid size")
+	ErrPartInvalidProof       = errors.New("Error part invalid proof")
+	ErrPartSetInvalidErasure  = errors.New("Error part set parts are not a consistent erasure code")
 )
 
 type Part struct {
@@ -44,6 +51,44 @@ func (part *Part) Hash() []byte {
 	}
 }
 
+// Basic validation that doesn't involve the part set.
+// Bounds the part size and the proof so that a bad part
+// can be rejected before it is hashed or stored.
+func (part *Part) ValidateBasic() error {
+	if len(part.Bytes) == 0 || len(part.Bytes) > partSize {
+		return ErrPartInvalidSize
+	}
+	proof := part.Proof
+	if proof.Total <= 0 || proof.Total > MaxBlockPartsCount {
+		return ErrPartInvalidProof
+	}
+	if proof.Index < 0 || proof.Index >= proof.Total {
+		return ErrPartInvalidProof
+	}
+	// A proof has at most one inner hash per level of the tree.
+	if len(proof.InnerHashes) > simpleTreeDepth(proof.Total) {
+		return ErrPartInvalidProof
+	}
+	for _, hash := range proof.InnerHashes {
+		if len(hash) != sha256.Size {
+			return ErrPartInvalidProof
+		}
+	}
+	if len(proof.LeafHash) != sha256.Size || len(proof.RootHash) != sha256.Size {
+		return ErrPartInvalidProof
+	}
+	return nil
+}
+
+// Returns the maximum depth of a simple merkle tree with total leaves.
+func simpleTreeDepth(total int) int {
+	depth := 0
+	for n := 1; n < total; n *= 2 {
+		depth++
+	}
+	return depth
+}
+
 func (part *Part) String() string {
 	return part.StringIndented("")
 }
@@ -60,32 +105,89 @@ func (part *Part) StringIndented(indent string) string {
 
 //-------------------------------------
 
+// If DataParts is set, the PartSet is erasure coded: the first DataParts
+// parts are the data, the rest are parity, and any DataParts of the parts
+// are enough to reconstruct the others. See NewErasurePartSetFromData.
+// Erasure coded headers have Version ErasurePartSetVersion, so that plain
+// headers are encoded without DataParts.
 type PartSetHeader struct {
-	Total int    `json:"total"`
-	Hash  []byte `json:"hash"`
+	Version   int    `json:"version" binary:"version"`
+	Total     int    `json:"total"`
+	Hash      []byte `json:"hash"`
+	DataParts int    `json:"data_parts" binary:"since=1"` // 0 if not erasure coded.
 }
 
 func (psh PartSetHeader) String() string {
+	if psh.DataParts > 0 {
+		return fmt.Sprintf("PartSet{T:%v D:%v %X}", psh.Total, psh.DataParts, Fingerprint(psh.Hash))
+	}
 	return fmt.Sprintf("PartSet{T:%v %X}", psh.Total, Fingerprint(psh.Hash))
 }
 
+// Returns the number of parts that hold the data.
+func (psh PartSetHeader) DataTotal() int {
+	if psh.DataParts > 0 {
+		return psh.DataParts
+	}
+	return psh.Total
+}
+
+// Basic validation of the header's total and hash.
+// The zero header is valid.
+func (psh PartSetHeader) ValidateBasic() error {
+	if psh.Total < 0 || psh.Total > MaxBlockPartsCount {
+		return ErrPartSetInvalidHeader
+	}
+	if psh.Total == 0 {
+		if len(psh.Hash) != 0 {
+			return ErrPartSetInvalidHeader
+		}
+	} else if len(psh.Hash) != sha256.Size {
+		return ErrPartSetInvalidHeader
+	}
+	if psh.DataParts < 0 || psh.DataParts > psh.Total {
+		return ErrPartSetInvalidHeader
+	}
+	if psh.Version != partSetHeaderVersion(psh.DataParts) {
+		return ErrPartSetInvalidHeader
+	}
+	if psh.DataParts > 0 && psh.Total > maxErasureShards {
+		return ErrPartSetInvalidHeader
+	}
+	return nil
+}
+
+func partSetHeaderVersion(dataParts int) int {
+	if dataParts > 0 {
+		return ErasurePartSetVersion
+	}
+	return 0
+}
+
 func (psh PartSetHeader) IsZero() bool {
 	return psh.Total == 0
 }
 
 func (psh PartSetHeader) Equals(other PartSetHeader) bool {
-	return psh.Total == other.Total && bytes.Equal(psh.Hash, other.Hash)
+	return psh.Version == other.Version && psh.Total == other.Total &&
+		bytes.Equal(psh.Hash, other.Hash) && psh.DataParts == other.DataParts
 }
 
+// data_parts is omitted when 0, so the sign-bytes of plain PartSets are unchanged.
 func (psh PartSetHeader) WriteSignBytes(w io.Writer, n *int64, err *error) {
-	binary.WriteTo([]byte(Fmt(`{"hash":"%X","total":%v}`, psh.Hash, psh.Total)), w, n, err)
+	if psh.DataParts > 0 {
+		binary.WriteTo([]byte(Fmt(`{"data_parts":%v,"hash":"%X","total":%v}`, psh.DataParts, psh.Hash, psh.Total)), w, n, err)
+	} else {
+		binary.WriteTo([]byte(Fmt(`{"hash":"%X","total":%v}`, psh.Hash, psh.Total)), w, n, err)
+	}
 }
 
 //-------------------------------------
 
 type PartSet struct {
-	total int
-	hash  []byte
+	total     int
+	hash      []byte
+	dataParts int // 0 if not erasure coded.
 
 	mtx           sync.Mutex
 	parts         []*Part
@@ -99,35 +201,71 @@ func NewPartSetFromData(data []byte) *PartSet {
 	// divide data into 4kb parts.
 	total := (len(data) + partSize - 1) / partSize
 	parts := make([]*Part, total)
-	parts_ := make([]merkle.Hashable, total)
-	partsBitArray := NewBitArray(total)
 	for i := 0; i < total; i++ {
-		part := &Part{
+		parts[i] = &Part{
 			Bytes: data[i*partSize : MinInt(len(data), (i+1)*partSize)],
 		}
-		parts[i] = part
-		parts_[i] = part
-		partsBitArray.SetIndex(i, true)
 	}
-	// Compute merkle proofs
-	proofs := merkle.SimpleProofsFromHashables(parts_)
+	return newFullPartSet(parts, 0)
+}
+
+// Returns an immutable, full, erasure coded PartSet from the data bytes.
+// The data bytes are split into equal sized data parts of at most "partSize",
+// the last one zero padded, followed by parityParts parity parts.
+// Falls back to NewPartSetFromData if parityParts is 0 or there would be
+// more than 256 parts, the most the erasure code supports.
+func NewErasurePartSetFromData(data []byte, parityParts int) *PartSet {
+	dataParts := (len(data) + partSize - 1) / partSize
+	if parityParts <= 0 || dataParts == 0 || dataParts+parityParts > maxErasureShards {
+		return NewPartSetFromData(data)
+	}
+	shardSize := (len(data) + dataParts - 1) / dataParts
+	shards := make([][]byte, dataParts)
+	for i := range shards {
+		shards[i] = make([]byte, shardSize)
+		copy(shards[i], data[MinInt(len(data), i*shardSize):MinInt(len(data), (i+1)*shardSize)])
+	}
+	shards = append(shards, erasureEncode(shards, parityParts)...)
+	parts := make([]*Part, len(shards))
+	for i, shard := range shards {
+		parts[i] = &Part{Bytes: shard}
+	}
+	return newFullPartSet(parts, dataParts)
+}
+
+// Computes the merkle proofs of the parts.
+func newFullPartSet(parts []*Part, dataParts int) *PartSet {
+	total := len(parts)
+	proofs := merkle.SimpleProofsFromHashables(partsToHashables(parts))
+	partsBitArray := NewBitArray(total)
 	for i := 0; i < total; i++ {
 		parts[i].Proof = *proofs[i]
+		partsBitArray.SetIndex(i, true)
 	}
 	return &PartSet{
 		total:         total,
 		hash:          proofs[0].RootHash,
+		dataParts:     dataParts,
 		parts:         parts,
 		partsBitArray: partsBitArray,
 		count:         total,
 	}
 }
 
+func partsToHashables(parts []*Part) []merkle.Hashable {
+	hashables := make([]merkle.Hashable, len(parts))
+	for i, part := range parts {
+		hashables[i] = part
+	}
+	return hashables
+}
+
 // Returns an empty PartSet ready to be populated.
 func NewPartSetFromHeader(header PartSetHeader) *PartSet {
 	return &PartSet{
 		total:         header.Total,
 		hash:          header.Hash,
+		dataParts:     header.DataParts,
 		parts:         make([]*Part, header.Total),
 		partsBitArray: NewBitArray(header.Total),
 		count:         0,
@@ -139,8 +277,10 @@ func (ps *PartSet) Header() PartSetHeader {
 		return PartSetHeader{}
 	} else {
 		return PartSetHeader{
-			Total: ps.total,
-			Hash:  ps.hash,
+			Version:   partSetHeaderVersion(ps.dataParts),
+			Total:     ps.total,
+			Hash:      ps.hash,
+			DataParts: ps.dataParts,
 		}
 	}
 }
@@ -210,9 +350,54 @@ func (ps *PartSet) AddPart(part *Part) (bool, error) {
 	ps.parts[part.Proof.Index] = part
 	ps.partsBitArray.SetIndex(part.Proof.Index, true)
 	ps.count++
+
+	// With enough parts of an erasure coded PartSet, fill in the rest.
+	// If that fails, the part is dropped again, so that reconstruction
+	// is retried with the next part received.
+	if ps.dataParts > 0 && ps.count == ps.dataParts && ps.count < ps.total {
+		if err := ps.reconstruct(); err != nil {
+			ps.parts[part.Proof.Index] = nil
+			ps.partsBitArray.SetIndex(part.Proof.Index, false)
+			ps.count--
+			return false, err
+		}
+	}
 	return true, nil
 }
 
+// Reconstructs the missing parts from the parts we have.
+// Fails if re-encoding the data doesn't reproduce the PartSet hash,
+// so that every subset of the parts yields the same data or none at all.
+// CONTRACT: ps.mtx is held and ps.count == ps.dataParts.
+func (ps *PartSet) reconstruct() error {
+	shards := make([][]byte, ps.total)
+	for i, part := range ps.parts {
+		if part != nil {
+			shards[i] = part.Bytes
+		}
+	}
+	if err := erasureReconstruct(shards, ps.dataParts); err != nil {
+		return ErrPartSetInvalidErasure
+	}
+	parts := make([]*Part, ps.total)
+	for i, shard := range shards {
+		parts[i] = &Part{Bytes: shard}
+	}
+	proofs := merkle.SimpleProofsFromHashables(partsToHashables(parts))
+	if !bytes.Equal(proofs[0].RootHash, ps.hash) {
+		return ErrPartSetInvalidErasure
+	}
+	for i, part := range ps.parts {
+		if part == nil {
+			parts[i].Proof = *proofs[i]
+			ps.parts[i] = parts[i]
+			ps.partsBitArray.SetIndex(i, true)
+		}
+	}
+	ps.count = ps.total
+	return nil
+}
+
 func (ps *PartSet) GetPart(index int) *Part {
 	ps.mtx.Lock()
 	defer ps.mtx.Unlock()
@@ -227,13 +412,42 @@ func (ps *PartSet) GetReader() io.Reader {
 	if !ps.IsComplete() {
 		panic("Cannot GetReader() on incomplete PartSet")
 	}
+	// Erasure coded data may have trailing zero padding,
+	// which decoding a block ignores.
 	buf := []byte{}
-	for _, part := range ps.parts {
+	for _, part := range ps.parts[:ps.Header().DataTotal()] {
 		buf = append(buf, part.Bytes...)
 	}
 	return bytes.NewReader(buf)
 }
 
+// Returns the total size of the parts received so far.
+func (ps *PartSet) ByteSize() int64 {
+	if ps == nil {
+		return 0
+	}
+	ps.mtx.Lock()
+	defer ps.mtx.Unlock()
+	var size int64
+	for _, part := range ps.parts {
+		if part != nil {
+			size += int64(len(part.Bytes))
+		}
+	}
+	return size
+}
+
+// Drops the received parts, so that their memory is reclaimed even if
+// stale references to the PartSet remain (e.g. in a RoundState copy).
+// Afterwards the PartSet is as if no parts had been received.
+func (ps *PartSet) Release() {
+	ps.mtx.Lock()
+	defer ps.mtx.Unlock()
+	ps.parts = make([]*Part, ps.total)
+	ps.partsBitArray = NewBitArray(ps.total)
+	ps.count = 0
+}
+
 func (ps *PartSet) StringShort() string {
 	if ps == nil {
 		return "nil-PartSet"
@@ -3,8 +3,10 @@ package types
 import (
 	"bytes"
 	"io/ioutil"
+	"math/rand"
 	"testing"
 
+	"github.com/tendermint/tendermint/binary"
 	. "github.com/tendermint/tendermint/common"
 )
 
@@ -84,3 +86,142 @@ func TestWrongProof(t *testing.T) {
 	}
 
 }
+
+func TestPartValidateBasic(t *testing.T) {
+
+	partSet := NewPartSetFromData(RandBytes(partSize * 10))
+	for i := 0; i < partSet.Total(); i++ {
+		if err := partSet.GetPart(i).ValidateBasic(); err != nil {
+			t.Errorf("Part %v should be valid, error: %v", i, err)
+		}
+	}
+	if err := partSet.Header().ValidateBasic(); err != nil {
+		t.Errorf("PartSetHeader should be valid, error: %v", err)
+	}
+
+	// Oversized part
+	part := *partSet.GetPart(0)
+	part.Bytes = RandBytes(partSize + 1)
+	if err := part.ValidateBasic(); err != ErrPartInvalidSize {
+		t.Errorf("Expected ErrPartInvalidSize, got %v", err)
+	}
+
+	// Index out of range
+	part = *partSet.GetPart(0)
+	part.Proof.Index = part.Proof.Total
+	if err := part.ValidateBasic(); err != ErrPartInvalidProof {
+		t.Errorf("Expected ErrPartInvalidProof, got %v", err)
+	}
+
+	// Too many parts
+	header := PartSetHeader{Total: MaxBlockPartsCount + 1, Hash: partSet.Hash()}
+	if err := header.ValidateBasic(); err != ErrPartSetInvalidHeader {
+		t.Errorf("Expected ErrPartSetInvalidHeader, got %v", err)
+	}
+}
+
+func TestErasurePartSet(t *testing.T) {
+	// 10 data parts, the last one short, and 5 parity parts.
+	data := RandBytes(partSize*9 + 100)
+	partSet := NewErasurePartSetFromData(data, 5)
+	header := partSet.Header()
+	if header.Total != 15 || header.DataParts != 10 {
+		t.Fatalf("Expected 15 parts with 10 data parts, got %v", header)
+	}
+	if err := header.ValidateBasic(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Any 10 of the 15 parts reconstruct the rest.
+	for trial := 0; trial < 5; trial++ {
+		partSet2 := NewPartSetFromHeader(header)
+		for _, i := range rand.Perm(15)[:10] {
+			if added, err := partSet2.AddPart(partSet.GetPart(i)); !added || err != nil {
+				t.Fatalf("Failed to add part %v, error: %v", i, err)
+			}
+		}
+		if !partSet2.IsComplete() {
+			t.Fatal("Expected PartSet to be complete with 10 parts")
+		}
+		for i := 0; i < 15; i++ {
+			if !bytes.Equal(partSet2.GetPart(i).Bytes, partSet.GetPart(i).Bytes) {
+				t.Fatalf("Reconstructed part %v differs", i)
+			}
+		}
+		data2, _ := ioutil.ReadAll(partSet2.GetReader())
+		if !bytes.Equal(data, data2[:len(data)]) {
+			t.Fatal("Got wrong data")
+		}
+	}
+}
+
+func TestErasurePartSetInconsistent(t *testing.T) {
+	// A proposer could commit to parity parts that don't match the data.
+	data := RandBytes(partSize * 4)
+	dataParts := make([]*Part, 4)
+	for i := range dataParts {
+		dataParts[i] = &Part{Bytes: data[i*partSize : (i+1)*partSize]}
+	}
+	badParts := append(dataParts, &Part{Bytes: RandBytes(partSize)}, &Part{Bytes: RandBytes(partSize)})
+	partSet := newFullPartSet(badParts, 4)
+
+	partSet2 := NewPartSetFromHeader(partSet.Header())
+	for i := 0; i < 3; i++ {
+		if added, err := partSet2.AddPart(partSet.GetPart(i)); !added || err != nil {
+			t.Fatalf("Failed to add part %v, error: %v", i, err)
+		}
+	}
+	// The part that fails reconstruction is dropped again,
+	// and reconstruction is retried with the next part.
+	for _, i := range []int{3, 4, 3} {
+		added, err := partSet2.AddPart(partSet.GetPart(i))
+		if added || err != ErrPartSetInvalidErasure {
+			t.Errorf("Expected part %v to fail with ErrPartSetInvalidErasure, got %v, %v", i, added, err)
+		}
+		if partSet2.Count() != 3 || partSet2.BitArray().GetIndex(i) {
+			t.Errorf("Expected part %v to be dropped", i)
+		}
+	}
+	if partSet2.IsComplete() {
+		t.Error("Expected PartSet not to complete")
+	}
+}
+
+func TestPartSetHeaderVersion(t *testing.T) {
+	data := RandBytes(partSize * 4)
+	plain := NewPartSetFromData(data).Header()
+	erasure := NewErasurePartSetFromData(data, 2).Header()
+	if plain.Version != 0 || erasure.Version != ErasurePartSetVersion {
+		t.Fatalf("Unexpected versions %v and %v", plain.Version, erasure.Version)
+	}
+
+	// Plain headers are encoded without DataParts.
+	unversioned := struct {
+		Total int
+		Hash  []byte
+	}{plain.Total, plain.Hash}
+	if !bytes.Equal(binary.BinaryBytes(plain), binary.BinaryBytes(unversioned)) {
+		t.Error("Expected a plain header to be encoded without version and DataParts")
+	}
+	n, err := new(int64), new(error)
+	erasure2 := binary.ReadBinary(PartSetHeader{}, bytes.NewReader(binary.BinaryBytes(erasure)), n, err).(PartSetHeader)
+	if *err != nil || !erasure2.Equals(erasure) {
+		t.Errorf("Expected %v to decode to itself, got %v, %v", erasure, erasure2, *err)
+	}
+
+	// DataParts needs the erasure version.
+	erasure.Version = 0
+	if err := erasure.ValidateBasic(); err != ErrPartSetInvalidHeader {
+		t.Errorf("Expected ErrPartSetInvalidHeader, got %v", err)
+	}
+}
+
+func TestErasurePartSetFallback(t *testing.T) {
+	data := RandBytes(partSize * 200)
+	if partSet := NewErasurePartSetFromData(data, 100); partSet.Header().DataParts != 0 {
+		t.Errorf("Expected a plain PartSet for more than %v parts", maxErasureShards)
+	}
+	if partSet := NewErasurePartSetFromData(data, 0); partSet.Header().DataParts != 0 {
+		t.Error("Expected a plain PartSet without parity")
+	}
+}
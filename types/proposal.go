@@ -0,0 +1,38 @@
+package types
+
+import (
+	"io"
+
+	"github.com/tendermint/tendermint/account"
+	"github.com/tendermint/tendermint/binary"
+	. "github.com/tendermint/tendermint/common"
+)
+
+// A signed consensus proposal, as evidence in a DupeProposalTx.
+// It has the encoding and sign bytes of consensus/types.Proposal,
+// which this package can't import.
+type SignedProposal struct {
+	Height           int                      `json:"height"`
+	Round            int                      `json:"round"`
+	BlockPartsHeader PartSetHeader            `json:"block_parts_header"`
+	POLRound         int                      `json:"pol_round"` // -1 if null.
+	Signature        account.SignatureEd25519 `json:"signature"`
+}
+
+func (p *SignedProposal) WriteSignBytes(chainID string, w io.Writer, n *int64, err *error) {
+	WriteProposalSignBytes(chainID, p.Height, p.Round, p.BlockPartsHeader, p.POLRound, w, n, err)
+}
+
+func (p *SignedProposal) String() string {
+	return Fmt("SignedProposal{%v/%v %v %v %v}", p.Height, p.Round,
+		p.BlockPartsHeader, p.POLRound, p.Signature)
+}
+
+// The sign bytes of a proposal, see consensus/types.Proposal.
+func WriteProposalSignBytes(chainID string, height int, round int, parts PartSetHeader, polRound int, w io.Writer, n *int64, err *error) {
+	binary.WriteTo([]byte(Fmt(`{"chain_id":"%s"`, chainID)), w, n, err)
+	binary.WriteTo([]byte(`,"proposal":{"block_parts_header":`), w, n, err)
+	parts.WriteSignBytes(w, n, err)
+	binary.WriteTo([]byte(Fmt(`,"height":%v,"pol_round":%v`, height, polRound)), w, n, err)
+	binary.WriteTo([]byte(Fmt(`,"round":%v}}`, round)), w, n, err)
+}
@@ -0,0 +1,86 @@
+package types
+
+import (
+	"fmt"
+)
+
+/*
+The p2p wire protocol version is exchanged in the handshake (NodeInfo.Protocol).
+Peers must share the major version; bump it for changes old peers can't parse.
+The minor version is negotiated down to the lower of the two, so additive
+changes (e.g. a new message on an existing channel) can be gated on it.
+*/
+const (
+	P2PProtocolMajor = 1
+	P2PProtocolMinor = 0
+)
+
+type ProtocolVersion struct {
+	Major int `json:"major"`
+	Minor int `json:"minor"`
+}
+
+func CurrentProtocolVersion() ProtocolVersion {
+	return ProtocolVersion{P2PProtocolMajor, P2PProtocolMinor}
+}
+
+func (pv ProtocolVersion) String() string {
+	return fmt.Sprintf("%v.%v", pv.Major, pv.Minor)
+}
+
+// Returns the protocol version spoken with a peer,
+// or an error if the major versions differ.
+func NegotiateProtocol(ours, theirs ProtocolVersion) (ProtocolVersion, error) {
+	if ours.Major != theirs.Major {
+		return ProtocolVersion{}, fmt.Errorf("Peer is on a different protocol major version. Got %v, expected %v", theirs, ours)
+	}
+	if theirs.Minor < ours.Minor {
+		return theirs, nil
+	}
+	return ours, nil
+}
+
+//-----------------------------------------------------------------------------
+
+/*
+The block version is recorded in Header.Version. It is not negotiated: the
+version in effect at each height is fixed by the chain's upgrade schedule
+(GenesisDoc.ProtocolUpgrades), so every node switches at the same height.
+It also selects the sign-bytes version (see account.SignBytesVersion) that
+txs in the block are signed with.
+Version 0 is not written (see binary.Options.Version), so blocks of chains
+without upgrades keep the encoding and hash they had before versioning.
+*/
+
+// The highest block version this software can validate.
+const MaxBlockVersion = 1
+
+// Block versions that change the rules:
+const (
+	// Votes have a Timestamp, and block times are the median of the
+	// precommit timestamps. See State.NextBlockTime.
+	VoteTimestampVersion = 1
+	// Proposals may be erasure coded. See PartSetHeader.DataParts.
+	ErasurePartSetVersion = 1
+	// Txs pay intrinsic gas, and blocks are limited to the genesis
+	// BlockGasLimit. See state.IntrinsicGas.
+	TxGasVersion = 1
+)
+
+type ProtocolUpgrade struct {
+	Height  int `json:"height"`
+	Version int `json:"version"`
+}
+
+// Returns the block version in effect at height.
+// CONTRACT: upgrades are sorted by height.
+func BlockVersionAt(upgrades []ProtocolUpgrade, height int) int {
+	version := 0
+	for _, upgrade := range upgrades {
+		if upgrade.Height > height {
+			break
+		}
+		version = upgrade.Version
+	}
+	return version
+}
@@ -0,0 +1,50 @@
+package types
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tendermint/tendermint/account"
+)
+
+func TestNegotiateProtocol(t *testing.T) {
+	ours := ProtocolVersion{1, 2}
+	if _, err := NegotiateProtocol(ours, ProtocolVersion{2, 2}); err == nil {
+		t.Error("Expected a different major version to be rejected")
+	}
+	if pv, err := NegotiateProtocol(ours, ProtocolVersion{1, 1}); err != nil || pv != (ProtocolVersion{1, 1}) {
+		t.Errorf("Expected 1.1, got %v %v", pv, err)
+	}
+	if pv, err := NegotiateProtocol(ours, ProtocolVersion{1, 3}); err != nil || pv != ours {
+		t.Errorf("Expected 1.2, got %v %v", pv, err)
+	}
+}
+
+func TestBlockVersionAt(t *testing.T) {
+	upgrades := []ProtocolUpgrade{{0, 0}, {10, 1}, {20, 2}}
+	cases := map[int]int{1: 0, 9: 0, 10: 1, 19: 1, 20: 2, 100: 2}
+	for height, version := range cases {
+		if got := BlockVersionAt(upgrades, height); got != version {
+			t.Errorf("Height %v: expected version %v, got %v", height, version, got)
+		}
+	}
+	if got := BlockVersionAt(nil, 5); got != 0 {
+		t.Errorf("Expected version 0 without upgrades, got %v", got)
+	}
+}
+
+func TestSignBytesVersion(t *testing.T) {
+	sendTx := &SendTx{
+		Inputs:  []*TxInput{&TxInput{Address: []byte("input1"), Amount: 12345, Sequence: 1}},
+		Outputs: []*TxOutput{&TxOutput{Address: []byte("output1"), Amount: 12345}},
+	}
+	v0 := account.SignBytes(chainID, sendTx)
+	if !bytes.Equal(account.SignBytesVersion(0, chainID, sendTx), v0) {
+		t.Error("Expected version 0 sign-bytes to equal SignBytes")
+	}
+	v1 := account.SignBytesVersion(1, chainID, sendTx)
+	expected := `{"sign_version":1,"sign_bytes":` + string(v0) + `}`
+	if string(v1) != expected {
+		t.Errorf("Got unexpected sign-bytes for version 1. Expected:\n%v\nGot:\n%v", expected, string(v1))
+	}
+}
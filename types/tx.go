@@ -16,11 +16,13 @@ var (
 	ErrTxInvalidAmount        = errors.New("Error invalid amount")
 	ErrTxInsufficientFunds    = errors.New("Error insufficient funds")
 	ErrTxInsufficientGasPrice = errors.New("Error insufficient gas price")
+	ErrTxInsufficientGas      = errors.New("Error insufficient gas")
 	ErrTxUnknownPubKey        = errors.New("Error unknown pubkey")
 	ErrTxInvalidPubKey        = errors.New("Error invalid pubkey")
 	ErrTxInvalidSignature     = errors.New("Error invalid signature")
 	ErrTxInvalidString        = errors.New("Error invalid string")
 	ErrIncorrectOwner         = errors.New("Error incorrect owner")
+	ErrTxNameNotFound         = errors.New("Error name not found")
 )
 
 type ErrTxInvalidSequence struct {
@@ -39,11 +41,16 @@ Account Txs:
  - SendTx         Send coins to address
  - CallTx         Send a msg to a contract that runs in the vm
  - NameTx	  Store some value under a name in the global namereg
+ - NameTransferTx Transfer ownership of a name in the global namereg
 
 Validation Txs:
  - BondTx         New validator posts a bond
  - UnbondTx       Validator leaves
  - DupeoutTx      Validator dupes out (equivocates)
+ - DupeProposalTx Proposer dupes out (signs two proposals for a round)
+ - EmergencyHaltTx Validator votes to halt the chain at a height
+
+Applications can add their own Txs with state.RegisterTxType.
 */
 type Tx interface {
 	WriteSignBytes(chainID string, w io.Writer, n *int64, err *error)
@@ -52,15 +59,21 @@ type Tx interface {
 // Types of Tx implementations
 const (
 	// Account transactions
-	TxTypeSend = byte(0x01)
-	TxTypeCall = byte(0x02)
-	TxTypeName = byte(0x03)
+	TxTypeSend         = byte(0x01)
+	TxTypeCall         = byte(0x02)
+	TxTypeName         = byte(0x03)
+	TxTypeNameTransfer = byte(0x04)
 
 	// Validation transactions
-	TxTypeBond    = byte(0x11)
-	TxTypeUnbond  = byte(0x12)
-	TxTypeRebond  = byte(0x13)
-	TxTypeDupeout = byte(0x14)
+	TxTypeBond          = byte(0x11)
+	TxTypeUnbond        = byte(0x12)
+	TxTypeRebond        = byte(0x13)
+	TxTypeDupeout       = byte(0x14)
+	TxTypeEmergencyHalt = byte(0x15)
+	TxTypeDupeProposal  = byte(0x16)
+
+	// Application transactions, see RegisterTxType
+	TxTypeCustomMin = byte(0x80)
 )
 
 // for binary.readReflect
@@ -69,12 +82,25 @@ var _ = binary.RegisterInterface(
 	binary.ConcreteType{&SendTx{}, TxTypeSend},
 	binary.ConcreteType{&CallTx{}, TxTypeCall},
 	binary.ConcreteType{&NameTx{}, TxTypeName},
+	binary.ConcreteType{&NameTransferTx{}, TxTypeNameTransfer},
 	binary.ConcreteType{&BondTx{}, TxTypeBond},
 	binary.ConcreteType{&UnbondTx{}, TxTypeUnbond},
 	binary.ConcreteType{&RebondTx{}, TxTypeRebond},
 	binary.ConcreteType{&DupeoutTx{}, TxTypeDupeout},
+	binary.ConcreteType{&EmergencyHaltTx{}, TxTypeEmergencyHalt},
+	binary.ConcreteType{&DupeProposalTx{}, TxTypeDupeProposal},
 )
 
+// Registers an application's Tx type for the codecs.
+// Use state.RegisterTxType to also register how it's executed.
+func RegisterTxType(typeByte byte, tx Tx) {
+	if typeByte < TxTypeCustomMin {
+		// SANITY CHECK
+		panic(Fmt("Tx type byte %X is reserved, must be at least %X", typeByte, TxTypeCustomMin))
+	}
+	binary.RegisterConcreteType(struct{ Tx }{}, binary.ConcreteType{O: tx, Byte: typeByte})
+}
+
 //-----------------------------------------------------------------------------
 
 type TxInput struct {
@@ -230,6 +256,30 @@ func (tx *NameTx) String() string {
 
 //-----------------------------------------------------------------------------
 
+// Transfers an unexpired name to a new owner.
+// The expiry is unchanged. The input amount must equal the fee.
+type NameTransferTx struct {
+	Input    *TxInput `json:"input"`
+	Name     string   `json:"name"`
+	NewOwner []byte   `json:"new_owner"`
+	Fee      int64    `json:"fee"`
+}
+
+func (tx *NameTransferTx) WriteSignBytes(chainID string, w io.Writer, n *int64, err *error) {
+	binary.WriteTo([]byte(Fmt(`{"chain_id":%s`, jsonEscape(chainID))), w, n, err)
+	binary.WriteTo([]byte(Fmt(`,"tx":[%v,{"fee":%v`, TxTypeNameTransfer, tx.Fee)), w, n, err)
+	binary.WriteTo([]byte(`,"input":`), w, n, err)
+	tx.Input.WriteSignBytes(w, n, err)
+	binary.WriteTo([]byte(Fmt(`,"name":%s,"new_owner":"%X"`, jsonEscape(tx.Name), tx.NewOwner)), w, n, err)
+	binary.WriteTo([]byte(`}]}`), w, n, err)
+}
+
+func (tx *NameTransferTx) String() string {
+	return Fmt("NameTransferTx{%v -> %s: %X}", tx.Input, tx.Name, tx.NewOwner)
+}
+
+//-----------------------------------------------------------------------------
+
 type BondTx struct {
 	PubKey    account.PubKeyEd25519    `json:"pub_key"`
 	Signature account.SignatureEd25519 `json:"signature"`
@@ -314,9 +364,59 @@ func (tx *DupeoutTx) String() string {
 
 //-----------------------------------------------------------------------------
 
+// Evidence that a validator signed two different proposals for the
+// same height and round.
+type DupeProposalTx struct {
+	Address   []byte         `json:"address"`
+	ProposalA SignedProposal `json:"proposal_a"`
+	ProposalB SignedProposal `json:"proposal_b"`
+}
+
+func (tx *DupeProposalTx) WriteSignBytes(chainID string, w io.Writer, n *int64, err *error) {
+	panic("DupeProposalTx has no sign bytes")
+}
+
+func (tx *DupeProposalTx) String() string {
+	return Fmt("DupeProposalTx{%X,%v,%v}", tx.Address, tx.ProposalA, tx.ProposalB)
+}
+
+//-----------------------------------------------------------------------------
+
+// A bonded validator's vote to halt the chain after HaltHeight.
+// Once votes for the same HaltHeight from +2/3 of bonded voting power
+// are on chain, every node stops after committing block HaltHeight.
+// Height is the height the vote was signed for; the vote expires
+// if quorum isn't reached soon after.
+type EmergencyHaltTx struct {
+	Address    []byte                   `json:"address"`
+	Height     int                      `json:"height"`
+	HaltHeight int                      `json:"halt_height"`
+	Signature  account.SignatureEd25519 `json:"signature"`
+}
+
+func (tx *EmergencyHaltTx) WriteSignBytes(chainID string, w io.Writer, n *int64, err *error) {
+	binary.WriteTo([]byte(Fmt(`{"chain_id":%s`, jsonEscape(chainID))), w, n, err)
+	binary.WriteTo([]byte(Fmt(`,"tx":[%v,{"address":"%X","halt_height":%v,"height":%v}]}`, TxTypeEmergencyHalt, tx.Address, tx.HaltHeight, tx.Height)), w, n, err)
+}
+
+func (tx *EmergencyHaltTx) String() string {
+	return Fmt("EmergencyHaltTx{%X,%v,%v,%v}", tx.Address, tx.Height, tx.HaltHeight, tx.Signature)
+}
+
+//-----------------------------------------------------------------------------
+
 func TxId(chainID string, tx Tx) []byte {
-	signBytes := account.SignBytes(chainID, tx)
-	return binary.BinaryRipemd160(signBytes)
+	return binary.BinaryRipemd160(TxSignBytes(chainID, tx))
+}
+
+// The bytes by which a tx is identified and hashed into a block.
+// Evidence txs have no sign bytes, so their binary encoding is used instead.
+func TxSignBytes(chainID string, tx Tx) []byte {
+	switch tx.(type) {
+	case *DupeoutTx, *DupeProposalTx:
+		return binary.BinaryBytes(struct{ Tx }{tx})
+	}
+	return account.SignBytes(chainID, tx)
 }
 
 //--------------------------------------------------------------------------------
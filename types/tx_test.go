@@ -119,6 +119,21 @@ func TestUnbondTxSignable(t *testing.T) {
 	}
 }
 
+func TestEmergencyHaltTxSignable(t *testing.T) {
+	haltTx := &EmergencyHaltTx{
+		Address:    []byte("address1"),
+		Height:     111,
+		HaltHeight: 222,
+	}
+	signBytes := account.SignBytes(chainID, haltTx)
+	signStr := string(signBytes)
+	expected := Fmt(`{"chain_id":"%s","tx":[21,{"address":"6164647265737331","halt_height":222,"height":111}]}`,
+		config.GetString("chain_id"))
+	if signStr != expected {
+		t.Errorf("Got unexpected sign string for EmergencyHaltTx")
+	}
+}
+
 func TestRebondTxSignable(t *testing.T) {
 	rebondTx := &RebondTx{
 		Address: []byte("address1"),
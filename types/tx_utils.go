@@ -48,12 +48,12 @@ func (tx *SendTx) AddOutput(addr []byte, amt int64) error {
 	return nil
 }
 
-func (tx *SendTx) SignInput(chainID string, i int, privAccount *account.PrivAccount) error {
+func (tx *SendTx) SignInput(chainID string, signVersion int, i int, privAccount *account.PrivAccount) error {
 	if i >= len(tx.Inputs) {
 		return fmt.Errorf("Index %v is greater than number of inputs (%v)", i, len(tx.Inputs))
 	}
 	tx.Inputs[i].PubKey = privAccount.PubKey
-	tx.Inputs[i].Signature = privAccount.Sign(chainID, tx)
+	tx.Inputs[i].Signature = privAccount.SignVersion(signVersion, chainID, tx)
 	return nil
 }
 
@@ -90,9 +90,9 @@ func NewCallTxWithNonce(from account.PubKey, to, data []byte, amt, gasLimit, fee
 	}
 }
 
-func (tx *CallTx) Sign(chainID string, privAccount *account.PrivAccount) {
+func (tx *CallTx) Sign(chainID string, signVersion int, privAccount *account.PrivAccount) {
 	tx.Input.PubKey = privAccount.PubKey
-	tx.Input.Signature = privAccount.Sign(chainID, tx)
+	tx.Input.Signature = privAccount.SignVersion(signVersion, chainID, tx)
 }
 
 //----------------------------------------------------------------------------
@@ -127,9 +127,39 @@ func NewNameTxWithNonce(from account.PubKey, name, data string, amt, fee int64,
 	}
 }
 
-func (tx *NameTx) Sign(chainID string, privAccount *account.PrivAccount) {
+func (tx *NameTx) Sign(chainID string, signVersion int, privAccount *account.PrivAccount) {
 	tx.Input.PubKey = privAccount.PubKey
-	tx.Input.Signature = privAccount.Sign(chainID, tx)
+	tx.Input.Signature = privAccount.SignVersion(signVersion, chainID, tx)
+}
+
+//----------------------------------------------------------------------------
+// NameTransferTx interface for creating tx
+
+func NewNameTransferTx(st AccountGetter, from account.PubKey, name string, newOwner []byte, fee int64) (*NameTransferTx, error) {
+	addr := from.Address()
+	acc := st.GetAccount(addr)
+	if acc == nil {
+		return nil, fmt.Errorf("Invalid address %X from pubkey %X", addr, from)
+	}
+
+	nonce := acc.Sequence + 1
+	return &NameTransferTx{
+		Input: &TxInput{
+			Address:   addr,
+			Amount:    fee,
+			Sequence:  nonce,
+			Signature: account.SignatureEd25519{},
+			PubKey:    from,
+		},
+		Name:     name,
+		NewOwner: newOwner,
+		Fee:      fee,
+	}, nil
+}
+
+func (tx *NameTransferTx) Sign(chainID string, signVersion int, privAccount *account.PrivAccount) {
+	tx.Input.PubKey = privAccount.PubKey
+	tx.Input.Signature = privAccount.SignVersion(signVersion, chainID, tx)
 }
 
 //----------------------------------------------------------------------------
@@ -176,8 +206,8 @@ func (tx *BondTx) AddOutput(addr []byte, amt int64) error {
 	return nil
 }
 
-func (tx *BondTx) SignBond(chainID string, privAccount *account.PrivAccount) error {
-	sig := privAccount.Sign(chainID, tx)
+func (tx *BondTx) SignBond(chainID string, signVersion int, privAccount *account.PrivAccount) error {
+	sig := privAccount.SignVersion(signVersion, chainID, tx)
 	sigEd, ok := sig.(account.SignatureEd25519)
 	if !ok {
 		return fmt.Errorf("Bond signer must be ED25519")
@@ -186,12 +216,12 @@ func (tx *BondTx) SignBond(chainID string, privAccount *account.PrivAccount) err
 	return nil
 }
 
-func (tx *BondTx) SignInput(chainID string, i int, privAccount *account.PrivAccount) error {
+func (tx *BondTx) SignInput(chainID string, signVersion int, i int, privAccount *account.PrivAccount) error {
 	if i >= len(tx.Inputs) {
 		return fmt.Errorf("Index %v is greater than number of inputs (%v)", i, len(tx.Inputs))
 	}
 	tx.Inputs[i].PubKey = privAccount.PubKey
-	tx.Inputs[i].Signature = privAccount.Sign(chainID, tx)
+	tx.Inputs[i].Signature = privAccount.SignVersion(signVersion, chainID, tx)
 	return nil
 }
 
@@ -205,8 +235,8 @@ func NewUnbondTx(addr []byte, height int) *UnbondTx {
 	}
 }
 
-func (tx *UnbondTx) Sign(chainID string, privAccount *account.PrivAccount) {
-	tx.Signature = privAccount.Sign(chainID, tx).(account.SignatureEd25519)
+func (tx *UnbondTx) Sign(chainID string, signVersion int, privAccount *account.PrivAccount) {
+	tx.Signature = privAccount.SignVersion(signVersion, chainID, tx).(account.SignatureEd25519)
 }
 
 //----------------------------------------------------------------------
@@ -219,6 +249,21 @@ func NewRebondTx(addr []byte, height int) *RebondTx {
 	}
 }
 
-func (tx *RebondTx) Sign(chainID string, privAccount *account.PrivAccount) {
-	tx.Signature = privAccount.Sign(chainID, tx).(account.SignatureEd25519)
+func (tx *RebondTx) Sign(chainID string, signVersion int, privAccount *account.PrivAccount) {
+	tx.Signature = privAccount.SignVersion(signVersion, chainID, tx).(account.SignatureEd25519)
+}
+
+//----------------------------------------------------------------------
+// EmergencyHaltTx interface for creating tx
+
+func NewEmergencyHaltTx(addr []byte, height, haltHeight int) *EmergencyHaltTx {
+	return &EmergencyHaltTx{
+		Address:    addr,
+		Height:     height,
+		HaltHeight: haltHeight,
+	}
+}
+
+func (tx *EmergencyHaltTx) Sign(chainID string, signVersion int, privAccount *account.PrivAccount) {
+	tx.Signature = privAccount.SignVersion(signVersion, chainID, tx).(account.SignatureEd25519)
 }
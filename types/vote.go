@@ -1,10 +1,13 @@
 package types
 
 import (
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"io"
+	"time"
 
+	"github.com/tendermint/tendermint/Godeps/_workspace/src/github.com/tendermint/ed25519"
 	"github.com/tendermint/tendermint/account"
 	"github.com/tendermint/tendermint/binary"
 	. "github.com/tendermint/tendermint/common"
@@ -15,6 +18,10 @@ var (
 	ErrVoteInvalidAccount   = errors.New("Invalid round vote account")
 	ErrVoteInvalidSignature = errors.New("Invalid round vote signature")
 	ErrVoteInvalidBlockHash = errors.New("Invalid block hash")
+	ErrVoteInvalidHeight    = errors.New("Invalid vote height")
+	ErrVoteInvalidRound     = errors.New("Invalid vote round")
+	ErrVoteInvalidType      = errors.New("Invalid vote type")
+	ErrVoteInvalidVersion   = errors.New("Invalid vote version")
 )
 
 type ErrVoteConflictingSignature struct {
@@ -27,12 +34,15 @@ func (err *ErrVoteConflictingSignature) Error() string {
 }
 
 // Represents a prevote, precommit, or commit vote from validators for consensus.
+// The Version is the block version at Height, see BlockVersionAt.
 type Vote struct {
+	Version    int                      `json:"version" binary:"version"`
 	Height     int                      `json:"height"`
 	Round      int                      `json:"round"`
 	Type       byte                     `json:"type"`
-	BlockHash  []byte                   `json:"block_hash"`  // empty if vote is nil.
-	BlockParts PartSetHeader            `json:"block_parts"` // zero if vote is nil.
+	BlockHash  []byte                   `json:"block_hash"`                 // empty if vote is nil.
+	BlockParts PartSetHeader            `json:"block_parts"`                // zero if vote is nil.
+	Timestamp  int64                    `json:"timestamp" binary:"since=1"` // UnixNano of the validator's clock. See State.NextBlockTime
 	Signature  account.SignatureEd25519 `json:"signature"`
 }
 
@@ -45,7 +55,39 @@ const (
 func (vote *Vote) WriteSignBytes(chainID string, w io.Writer, n *int64, err *error) {
 	binary.WriteTo([]byte(Fmt(`{"chain_id":"%s"`, chainID)), w, n, err)
 	binary.WriteTo([]byte(Fmt(`,"vote":{"block_hash":"%X","block_parts":%v`, vote.BlockHash, vote.BlockParts)), w, n, err)
-	binary.WriteTo([]byte(Fmt(`,"height":%v,"round":%v,"type":%v}}`, vote.Height, vote.Round, vote.Type)), w, n, err)
+	if vote.Version >= VoteTimestampVersion {
+		binary.WriteTo([]byte(Fmt(`,"height":%v,"round":%v,"timestamp":%v,"type":%v}}`, vote.Height, vote.Round, vote.Timestamp, vote.Type)), w, n, err)
+	} else {
+		binary.WriteTo([]byte(Fmt(`,"height":%v,"round":%v,"type":%v}}`, vote.Height, vote.Round, vote.Type)), w, n, err)
+	}
+}
+
+func (vote *Vote) Time() time.Time {
+	return time.Unix(0, vote.Timestamp)
+}
+
+// Basic validation that doesn't involve state data.
+// Rejects malformed votes before any signature verification.
+func (vote *Vote) ValidateBasic() error {
+	if vote.Height <= 0 {
+		return ErrVoteInvalidHeight
+	}
+	if vote.Round < 0 {
+		return ErrVoteInvalidRound
+	}
+	if vote.Type != VoteTypePrevote && vote.Type != VoteTypePrecommit {
+		return ErrVoteInvalidType
+	}
+	if len(vote.BlockHash) != 0 && len(vote.BlockHash) != sha256.Size {
+		return ErrVoteInvalidBlockHash
+	}
+	if err := vote.BlockParts.ValidateBasic(); err != nil {
+		return err
+	}
+	if len(vote.Signature) != ed25519.SignatureSize {
+		return ErrVoteInvalidSignature
+	}
+	return nil
 }
 
 func (vote *Vote) Copy() *Vote {
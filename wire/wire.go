@@ -0,0 +1,327 @@
+// Package wire describes the binary encoding of everything that goes into
+// blocks, state and peer messages, so that codec changes which would break
+// consensus with existing nodes can be caught before they are released.
+//
+// Spec() walks the binary codec registry from the consensus roots, and
+// Samples() are fixed values whose encodings at each block version are
+// checked against golden files in testdata/. See wire_test.go for how to
+// update them.
+package wire
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	acm "github.com/tendermint/tendermint/account"
+	"github.com/tendermint/tendermint/binary"
+	bc "github.com/tendermint/tendermint/blockchain"
+	. "github.com/tendermint/tendermint/common"
+	cs "github.com/tendermint/tendermint/consensus"
+	cstypes "github.com/tendermint/tendermint/consensus/types"
+	mempl "github.com/tendermint/tendermint/mempool"
+	"github.com/tendermint/tendermint/merkle"
+	"github.com/tendermint/tendermint/p2p"
+	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+)
+
+// Types whose encoding is part of consensus, either because they are
+// hashed or signed, or because peers exchange them.
+var roots = []interface{}{
+	&types.Block{},
+	&types.Part{},
+	&types.Vote{},
+	&cstypes.Proposal{},
+	&acm.Account{},
+	&sm.Validator{},
+	&sm.ValidatorInfo{},
+	&types.NameRegEntry{},
+	struct{ types.Tx }{},
+	struct{ acm.PubKey }{},
+	struct{ acm.Signature }{},
+	struct{ cs.ConsensusMessage }{},
+	struct{ bc.BlockchainMessage }{},
+	struct{ mempl.MempoolMessage }{},
+	struct{ p2p.PexMessage }{},
+}
+
+func Spec() []*binary.TypeDesc {
+	return binary.DescribeTypes(roots...)
+}
+
+func SpecJSON() []byte {
+	specJSON, err := json.MarshalIndent(Spec(), "", "\t")
+	if err != nil {
+		panic(err)
+	}
+	return specJSON
+}
+
+// Sample is a fixed value of a consensus type.
+// Interface values are wrapped as struct{X}{value} so that they are
+// written with their type byte.
+type Sample struct {
+	Name  string
+	Value interface{}
+}
+
+const sampleChainID = "wire_sample"
+
+var sampleTime = time.Unix(1420070400, 0)
+
+// Returns the samples as of block version, see types.BlockVersionAt,
+// in a fixed order. Signatures are deterministic since ed25519 signing is.
+func Samples(version int) []Sample {
+	privAcc := acm.GenPrivAccountFromSecret([]byte("wire_sample"))
+	pubKey := privAcc.PubKey.(acm.PubKeyEd25519)
+	sign := func(o acm.Signable) acm.SignatureEd25519 {
+		return privAcc.Sign(sampleChainID, o).(acm.SignatureEd25519)
+	}
+	// Txs are signed with the sign-bytes of the block version.
+	signTx := func(o acm.Signable) acm.SignatureEd25519 {
+		return privAcc.PrivKey.Sign(acm.SignBytesVersion(version, sampleChainID, o)).(acm.SignatureEd25519)
+	}
+
+	partsHeader := types.PartSetHeader{
+		Total: 3,
+		Hash:  []byte("parts_hash"),
+	}
+	vote := &types.Vote{
+		Height:     7,
+		Round:      1,
+		Type:       types.VoteTypePrecommit,
+		BlockHash:  []byte("block_hash"),
+		BlockParts: partsHeader,
+	}
+	if version >= types.VoteTimestampVersion {
+		vote.Version = types.VoteTimestampVersion
+		vote.Timestamp = sampleTime.UnixNano()
+	}
+	vote.Signature = sign(vote)
+	input := func(amount int64) *types.TxInput {
+		return &types.TxInput{
+			Address:  privAcc.Address,
+			Amount:   amount,
+			Sequence: 1,
+			PubKey:   pubKey,
+		}
+	}
+	output := &types.TxOutput{
+		Address: []byte("output_address______"),
+		Amount:  100,
+	}
+
+	sendTx := &types.SendTx{
+		Inputs:  []*types.TxInput{input(100)},
+		Outputs: []*types.TxOutput{output},
+	}
+	sendTx.Inputs[0].Signature = signTx(sendTx)
+	callTx := &types.CallTx{
+		Input:    input(10),
+		Address:  []byte("contract_address____"),
+		GasLimit: 1000,
+		Fee:      1,
+		Data:     []byte{0x01, 0x02},
+	}
+	callTx.Input.Signature = signTx(callTx)
+	nameTx := &types.NameTx{
+		Input: input(10),
+		Name:  "name",
+		Data:  "data",
+		Fee:   1,
+	}
+	nameTx.Input.Signature = signTx(nameTx)
+	nameTransferTx := &types.NameTransferTx{
+		Input:    input(1),
+		Name:     "name",
+		NewOwner: []byte("new_owner___________"),
+		Fee:      1,
+	}
+	nameTransferTx.Input.Signature = signTx(nameTransferTx)
+	bondTx := &types.BondTx{
+		PubKey:   pubKey,
+		Inputs:   []*types.TxInput{input(100)},
+		UnbondTo: []*types.TxOutput{output},
+	}
+	bondTx.Signature = signTx(bondTx)
+	bondTx.Inputs[0].Signature = signTx(bondTx)
+	unbondTx := &types.UnbondTx{
+		Address: privAcc.Address,
+		Height:  7,
+	}
+	unbondTx.Signature = signTx(unbondTx)
+	rebondTx := &types.RebondTx{
+		Address: privAcc.Address,
+		Height:  7,
+	}
+	rebondTx.Signature = signTx(rebondTx)
+	voteB := *vote
+	voteB.BlockHash = []byte("other_block_hash")
+	voteB.Signature = sign(&voteB)
+	dupeoutTx := &types.DupeoutTx{
+		Address: privAcc.Address,
+		VoteA:   *vote,
+		VoteB:   voteB,
+	}
+	proposalA := &types.SignedProposal{
+		Height:           7,
+		Round:            1,
+		BlockPartsHeader: partsHeader,
+		POLRound:         -1,
+	}
+	proposalA.Signature = sign(proposalA)
+	proposalB := *proposalA
+	proposalB.BlockPartsHeader.Hash = []byte("other_parts_hash")
+	proposalB.Signature = sign(&proposalB)
+	dupeProposalTx := &types.DupeProposalTx{
+		Address:   privAcc.Address,
+		ProposalA: *proposalA,
+		ProposalB: proposalB,
+	}
+	emergencyHaltTx := &types.EmergencyHaltTx{
+		Address:    privAcc.Address,
+		Height:     7,
+		HaltHeight: 10,
+	}
+	emergencyHaltTx.Signature = signTx(emergencyHaltTx)
+	txs := []types.Tx{sendTx, callTx, nameTx, nameTransferTx, bondTx,
+		unbondTx, rebondTx, dupeoutTx, emergencyHaltTx, dupeProposalTx}
+	// The block has the txs that predate block versions, so that at
+	// version 0 it is the block of the baseline. Later txs have samples
+	// of their own.
+	blockTxs := []types.Tx{sendTx, callTx, nameTx, bondTx, unbondTx, rebondTx, dupeoutTx}
+
+	block := &types.Block{
+		Header: &types.Header{
+			Version:        version,
+			ChainID:        sampleChainID,
+			Height:         8,
+			Time:           sampleTime,
+			Fees:           5,
+			NumTxs:         len(blockTxs),
+			LastBlockHash:  vote.BlockHash,
+			LastBlockParts: partsHeader,
+			StateHash:      []byte("state_hash"),
+		},
+		Data: &types.Data{
+			Txs: blockTxs,
+		},
+		LastValidation: &types.Validation{
+			Precommits: []*types.Vote{vote, nil},
+		},
+	}
+	part := &types.Part{
+		Proof: merkle.SimpleProof{
+			Index:       1,
+			Total:       3,
+			LeafHash:    []byte("leaf_hash"),
+			InnerHashes: [][]byte{[]byte("inner_hash_1"), []byte("inner_hash_2")},
+		},
+		Bytes: []byte("part_bytes"),
+	}
+	proposal := cstypes.NewProposal(8, 0, partsHeader, -1)
+	proposal.Signature = sign(proposal)
+	bitArray := NewBitArray(3)
+	bitArray.SetIndex(1, true)
+
+	samples := []Sample{
+		{"block", block},
+		{"part", part},
+		{"vote", vote},
+		{"proposal", proposal},
+		{"account", &acm.Account{
+			Address:     privAcc.Address,
+			PubKey:      pubKey,
+			Sequence:    1,
+			Balance:     1000,
+			Code:        []byte{0x60, 0x00},
+			StorageRoot: []byte("storage_root"),
+		}},
+		{"validator", &sm.Validator{
+			Address:          privAcc.Address,
+			PubKey:           pubKey,
+			BondHeight:       1,
+			UnbondHeight:     0,
+			LastCommitHeight: 7,
+			VotingPower:      100,
+			Accum:            -50,
+		}},
+		{"validator_info", &sm.ValidatorInfo{
+			Address:         privAcc.Address,
+			PubKey:          pubKey,
+			UnbondTo:        []*types.TxOutput{output},
+			FirstBondHeight: 1,
+			FirstBondAmount: 100,
+		}},
+		{"name_reg_entry", &types.NameRegEntry{
+			Name:    "name",
+			Owner:   privAcc.Address,
+			Data:    "data",
+			Expires: 1000,
+		}},
+	}
+	if version >= types.ErasurePartSetVersion {
+		samples = append(samples, Sample{"part_set_header/erasure", &types.PartSetHeader{
+			Version:   types.ErasurePartSetVersion,
+			Total:     5,
+			Hash:      []byte("parts_hash"),
+			DataParts: 3,
+		}})
+	}
+	for _, tx := range txs {
+		samples = append(samples, Sample{"tx/" + Fmt("%T", tx)[len("*types."):], struct{ types.Tx }{tx}})
+	}
+	msgs := []cs.ConsensusMessage{
+		&cs.NewRoundStepMessage{
+			Height:                8,
+			Round:                 0,
+			Step:                  cs.RoundStepPropose,
+			SecondsSinceStartTime: 1,
+			LastCommitRound:       0,
+		},
+		&cs.CommitStepMessage{
+			Height:           7,
+			BlockPartsHeader: partsHeader,
+			BlockParts:       bitArray,
+		},
+		&cs.ProposalMessage{
+			Proposal: proposal,
+		},
+		&cs.ProposalPOLMessage{
+			Height:           8,
+			ProposalPOLRound: 0,
+			ProposalPOL:      bitArray,
+		},
+		&cs.BlockPartMessage{
+			Height: 8,
+			Round:  0,
+			Part:   part,
+		},
+		&cs.VoteMessage{
+			ValidatorIndex: 0,
+			Vote:           vote,
+		},
+		&cs.HasVoteMessage{
+			Height: 7,
+			Round:  1,
+			Type:   types.VoteTypePrecommit,
+			Index:  0,
+		},
+	}
+	for _, msg := range msgs {
+		samples = append(samples, Sample{"consensus/" + Fmt("%T", msg)[len("*consensus."):], struct{ cs.ConsensusMessage }{msg}})
+	}
+	samples = append(samples, Sample{"mempool/TxMessage", struct{ mempl.MempoolMessage }{&mempl.TxMessage{Tx: sendTx}}})
+	samples = append(samples, Sample{"mempool/TxsMessage", struct{ mempl.MempoolMessage }{&mempl.TxsMessage{Txs: []types.Tx{sendTx, callTx}}}})
+	return samples
+}
+
+// Returns the hex encoding of each sample at block version by name.
+func SampleEncodings(version int) map[string]string {
+	encodings := make(map[string]string)
+	for _, sample := range Samples(version) {
+		encodings[sample.Name] = hex.EncodeToString(binary.BinaryBytes(sample.Value))
+	}
+	return encodings
+}
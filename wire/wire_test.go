@@ -0,0 +1,146 @@
+package wire
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/tendermint/tendermint/binary"
+	_ "github.com/tendermint/tendermint/config/tendermint_test"
+	"github.com/tendermint/tendermint/types"
+)
+
+// If a codec change is intended, regenerate the golden files with
+//
+//	go test ./wire -update
+//
+// and review the diff. Sample encodings are recorded by block version,
+// and the recorded ones can't change, since nodes at those versions use
+// them: -update only adds samples and versions. A change to an existing
+// encoding must be gated on a new block version (see types.MaxBlockVersion),
+// whose samples are then recorded alongside.
+var update = flag.Bool("update", false, "Update the golden files in testdata/")
+
+const (
+	specFile     = "testdata/wire_spec.json"
+	samplesFile  = "testdata/samples.json"
+	baselineFile = "testdata/baseline.json"
+)
+
+func TestWireSpec(t *testing.T) {
+	specJSON := SpecJSON()
+	if *update {
+		writeGolden(t, specFile, specJSON)
+		return
+	}
+	golden, err := ioutil.ReadFile(specFile)
+	if err != nil {
+		t.Fatalf("Error reading %v: %v", specFile, err)
+	}
+	if !bytes.Equal(bytes.TrimSpace(golden), bytes.TrimSpace(specJSON)) {
+		t.Errorf("Wire spec changed from %v. Got:\n%s", specFile, specJSON)
+	}
+}
+
+// Sample encodings by block version, then by name.
+type versionedEncodings map[int]map[string]string
+
+func readGoldenEncodings(t *testing.T, file string, o interface{}) {
+	golden, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatalf("Error reading %v: %v", file, err)
+	}
+	if err := json.Unmarshal(golden, o); err != nil {
+		t.Fatalf("Error parsing %v: %v", file, err)
+	}
+}
+
+// Returns an error for each recorded encoding that isn't generated the same.
+func checkRecorded(recorded, encodings versionedEncodings) []error {
+	errs := []error{}
+	for version, goldenEncodings := range recorded {
+		for name, goldenHex := range goldenEncodings {
+			if hex, ok := encodings[version][name]; !ok {
+				errs = append(errs, fmt.Errorf("Sample %v at version %v is recorded but no longer generated", name, version))
+			} else if hex != goldenHex {
+				errs = append(errs, fmt.Errorf("Encoding of %v at version %v changed.\nGot:      %v\nExpected: %v", name, version, hex, goldenHex))
+			}
+		}
+	}
+	return errs
+}
+
+func TestWireSamples(t *testing.T) {
+	encodings := versionedEncodings{}
+	for version := 0; version <= types.MaxBlockVersion; version++ {
+		encodings[version] = SampleEncodings(version)
+	}
+	recorded := versionedEncodings{}
+	readGoldenEncodings(t, samplesFile, &recorded)
+	errs := checkRecorded(recorded, encodings)
+	for _, err := range errs {
+		t.Error(err)
+	}
+	if *update {
+		if len(errs) > 0 {
+			t.Fatalf("Not updating %v: recorded encodings can't change without a new block version", samplesFile)
+		}
+		samplesJSON, err := json.MarshalIndent(encodings, "", "\t")
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeGolden(t, samplesFile, samplesJSON)
+		return
+	}
+
+	for version := 0; version <= types.MaxBlockVersion; version++ {
+		for _, sample := range Samples(version) {
+			goldenHex, ok := recorded[version][sample.Name]
+			if !ok {
+				t.Errorf("Sample %v at version %v is missing from %v", sample.Name, version, samplesFile)
+				continue
+			}
+			// The golden bytes must also decode to the same value.
+			bz, _ := hex.DecodeString(goldenHex)
+			rt := reflect.TypeOf(sample.Value)
+			var o interface{}
+			if rt.Kind() == reflect.Ptr {
+				o = reflect.New(rt.Elem()).Interface()
+			} else {
+				o = reflect.Zero(rt).Interface()
+			}
+			n, err := new(int64), new(error)
+			o = binary.ReadBinary(o, bytes.NewReader(bz), n, err)
+			if *err != nil {
+				t.Errorf("Error decoding %v at version %v: %v", sample.Name, version, *err)
+				continue
+			}
+			if !bytes.Equal(binary.BinaryBytes(o), bz) {
+				t.Errorf("Decoded %v at version %v does not re-encode to the golden bytes", sample.Name, version)
+			}
+		}
+	}
+}
+
+// The baseline encodings are those of the samples that existed before
+// block versions, as encoded by the code of that time. Version 0 must
+// still encode them the same. The file is never updated.
+func TestWireBaseline(t *testing.T) {
+	baseline := map[string]string{}
+	readGoldenEncodings(t, baselineFile, &baseline)
+	for _, err := range checkRecorded(versionedEncodings{0: baseline}, versionedEncodings{0: SampleEncodings(0)}) {
+		t.Error(err)
+	}
+}
+
+func writeGolden(t *testing.T, file string, bz []byte) {
+	if err := ioutil.WriteFile(file, append(bz, '\n'), 0644); err != nil {
+		t.Fatalf("Error writing %v: %v", file, err)
+	}
+	t.Logf("Updated %v", file)
+}